@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"skyhawk-security-microservice/internal/archival"
+	"skyhawk-security-microservice/internal/database"
+)
+
+func main() {
+	// Parse command line flags
+	retentionDays := flag.Int("retention-days", 0, "Archive events older than this many days (defaults to ARCHIVE_RETENTION_DAYS or 90)")
+	before := flag.String("before", "", "Archive events created before this RFC3339 timestamp; overrides -retention-days")
+	flag.Parse()
+
+	config, err := archival.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure event archival: %v", err)
+	}
+	if config == nil {
+		log.Fatalf("ARCHIVE_S3_BUCKET must be set")
+	}
+
+	cutoff := time.Now().Add(-config.Retention)
+	if *retentionDays > 0 {
+		cutoff = time.Now().AddDate(0, 0, -*retentionDays)
+	}
+	if *before != "" {
+		cutoff, err = time.Parse(time.RFC3339, *before)
+		if err != nil {
+			log.Fatalf("Invalid -before timestamp: %v", err)
+		}
+	}
+
+	db, err := database.NewConnection()
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Stop between batches on interrupt; archived batches are already deleted
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	archiver, err := archival.NewS3Archiver(ctx, db, *config)
+	if err != nil {
+		log.Fatalf("Failed to create S3 archiver: %v", err)
+	}
+
+	log.Printf("Archiving events created before %s to s3://%s", cutoff.Format(time.RFC3339), config.Bucket)
+	archived, err := archiver.Archive(ctx, cutoff)
+	if err != nil {
+		log.Printf("Archival failed after %d events: %v", archived, err)
+		os.Exit(1)
+	}
+	log.Printf("Archived %d events", archived)
+}
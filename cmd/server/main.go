@@ -1,14 +1,80 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"log"
 	"os"
+	"strconv"
+	"time"
 
+	"skyhawk-security-microservice/internal/archival"
+	"skyhawk-security-microservice/internal/config"
 	"skyhawk-security-microservice/internal/database"
+	"skyhawk-security-microservice/internal/handler"
+	"skyhawk-security-microservice/internal/logger"
+	"skyhawk-security-microservice/internal/metrics"
+	"skyhawk-security-microservice/internal/models"
+	"skyhawk-security-microservice/internal/notifier"
+	"skyhawk-security-microservice/internal/repository"
+	"skyhawk-security-microservice/internal/scheduler"
 	"skyhawk-security-microservice/internal/server"
+	"skyhawk-security-microservice/internal/tracing"
 )
 
+// indexBloatAlertPercent is the estimated bloat above which VacuumJob alerts about an index
+const indexBloatAlertPercent = 50
+
 func main() {
+	seed := flag.Int("seed", 0, "insert this many generated events before starting; not available with GIN_MODE=release")
+	flag.Parse()
+
+	// Take connection settings from the configuration file unless set in the environment
+	if configFile := os.Getenv("CONFIG_FILE"); configFile != "" {
+		cfg, err := config.Load(configFile)
+		if err != nil {
+			log.Fatalf("Failed to load configuration: %v", err)
+		}
+		cfg.SetConnectionEnv()
+	}
+
+	logLevel, err := config.LogLevelFromEnv()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	logger.InitGlobalLogger(logLevel)
+
+	features, err := config.LoadFeatureFlags()
+	if err != nil {
+		log.Fatalf("Invalid feature flags: %v", err)
+	}
+
+	ginMode, err := config.GinModeFromEnv()
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	// Get port from environment or use default
+	port := 8080
+	if envPort := os.Getenv("PORT"); envPort != "" {
+		if parsed, err := strconv.Atoi(envPort); err == nil {
+			port = parsed
+		}
+	}
+
+	// Report the effective configuration before connecting, so misconfiguration is visible
+	config.LogStartup(config.StartupInfo{
+		Service:  "server",
+		Port:     port,
+		AMQPURL:  handler.AMQPURLFromEnv(),
+		LogLevel: logLevel,
+		GinMode:  ginMode,
+	})
+
+	// Read trace context from incoming requests and carry it with published messages
+	tracing.SetupPropagation()
+
 	// Connect to database
 	db, err := database.NewConnection()
 	if err != nil {
@@ -16,15 +82,182 @@ func main() {
 	}
 	defer db.Close()
 
-	// Get port from environment or use default
-	port := 8080
-	if envPort := os.Getenv("PORT"); envPort != "" {
-		port = 8080 // You can parse envPort to int if needed
+	// Apply pending schema migrations when enabled
+	if os.Getenv("MIGRATE_ON_START") == "true" {
+		if err := db.Migrate(); err != nil {
+			log.Fatalf("Failed to apply database migrations: %v", err)
+		}
+		log.Println("Database migrations are up to date")
+	}
+
+	// Fill a development database with generated events
+	if *seed > 0 {
+		if ginMode == config.GinModeRelease {
+			log.Fatalf("--seed is not available in production (GIN_MODE=release)")
+		}
+		if err := database.NewSeeder(db).Seed(context.Background(), *seed); err != nil {
+			log.Fatalf("Failed to seed the database: %v", err)
+		}
+		log.Printf("Seeded %d events", *seed)
+	}
+
+	// Export connection pool statistics at /metrics
+	if features.MetricsEnabled {
+		stopPoolMetrics := metrics.StartPoolMetricsExporter(db, metrics.Registry, 10*time.Second)
+		defer stopPoolMetrics()
 	}
 
+	// Run event cleanup, archival and vacuum jobs in the background
+	jobs := newScheduler(db)
+	jobs.Start(context.Background())
+	defer jobs.Stop()
+
 	// Create and start server
-	srv := server.NewServer(db)
+	srv := server.NewServer(db, jobs, features, ginMode)
+
+	// Serve gRPC alongside HTTP
+	grpcPort := 9090
+	if envPort := os.Getenv("GRPC_PORT"); envPort != "" {
+		if parsed, err := strconv.Atoi(envPort); err == nil {
+			grpcPort = parsed
+		}
+	}
+	srv.EnableGRPC(grpcPort, os.Getenv("GRPC_AUTH_TOKEN"))
+
 	if err := srv.Start(port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
-} 
\ No newline at end of file
+}
+
+// newScheduler registers the maintenance jobs: DeleteExpiredEvents every hour when
+// EVENT_RETENTION_DAYS is set, ArchiveOldEvents daily at midnight UTC when ARCHIVE_S3_BUCKET is
+// set, and VacuumJob weekly. Each run takes an advisory lock, so only one server runs a job at a time.
+func newScheduler(db *database.DB) *scheduler.Scheduler {
+	eventRepo := repository.NewEventRepository(db)
+	jobs := scheduler.New()
+	jobs.SetRunStore(scheduler.NewRunStore(db))
+	jobs.SetLocker(scheduler.NewAdvisoryLocker(db))
+
+	// Events are only deleted when a retention period is configured
+	var retention time.Duration
+	if value := os.Getenv("EVENT_RETENTION_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 {
+			log.Fatalf("Invalid EVENT_RETENTION_DAYS: %s", value)
+		}
+		retention = time.Duration(days) * 24 * time.Hour
+	}
+
+	archiveConfig, err := archival.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure event archival: %v", err)
+	}
+	if archiveConfig != nil {
+		// Deleting events before they are archived would lose them
+		if retention > 0 && retention <= archiveConfig.Retention {
+			log.Fatalf("EVENT_RETENTION_DAYS must be longer than ARCHIVE_RETENTION_DAYS")
+		}
+
+		archiver, err := archival.NewS3Archiver(context.Background(), db, *archiveConfig)
+		if err != nil {
+			log.Fatalf("Failed to create S3 archiver: %v", err)
+		}
+		cleanup := archival.NewCleanupJob(archiver)
+		jobs.Register(scheduler.Job{
+			Name:     "ArchiveOldEvents",
+			Interval: 24 * time.Hour,
+			Aligned:  true,
+			Fn: func(ctx context.Context) error {
+				archived, err := cleanup.RunOnce(ctx)
+				if archived > 0 {
+					log.Printf("Event archival moved %d events to S3", archived)
+				}
+				return err
+			},
+		})
+		log.Printf("Daily event archival to s3://%s enabled", archiveConfig.Bucket)
+	}
+
+	if retention > 0 {
+		jobs.Register(scheduler.Job{
+			Name:     "DeleteExpiredEvents",
+			Interval: time.Hour,
+			Fn: func(ctx context.Context) error {
+				deleted, err := eventRepo.DeleteEventsCreatedBefore(ctx, time.Now().Add(-retention))
+				if deleted > 0 {
+					log.Printf("Deleted %d events older than %s", deleted, retention)
+				}
+				return err
+			},
+		})
+		log.Printf("Hourly deletion of events older than %s enabled", retention)
+	}
+
+	// Alert about bloated indexes through the notifiers configured in the environment
+	notifiers, err := notifier.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to configure notifiers: %v", err)
+	}
+
+	jobs.Register(scheduler.Job{
+		Name:     "VacuumJob",
+		Interval: 7 * 24 * time.Hour,
+		Aligned:  true,
+		Fn: func(ctx context.Context) error {
+			if err := db.RunVacuumAnalyze(ctx, "security_events"); err != nil {
+				return err
+			}
+			return checkIndexBloat(ctx, db, notifiers)
+		},
+	})
+
+	return jobs
+}
+
+// checkIndexBloat notifies about every index whose estimated bloat exceeds indexBloatAlertPercent
+func checkIndexBloat(ctx context.Context, db *database.DB, notifiers []notifier.Notifier) error {
+	indexes, err := db.GetIndexBloat(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		if index.BloatPercent <= indexBloatAlertPercent {
+			continue
+		}
+		alert := newIndexBloatEvent(index, time.Now())
+		log.Printf("Index %s is bloated: %s", index.Index, alert.Description)
+
+		for _, n := range notifiers {
+			notifyCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			if err := n.Notify(notifyCtx, alert); err != nil {
+				log.Printf("Failed to send index bloat alert for %s: %v", index.Index, err)
+			}
+			cancel()
+		}
+	}
+	return nil
+}
+
+// newIndexBloatEvent builds the event describing a bloated index
+func newIndexBloatEvent(index database.IndexBloat, now time.Time) *models.Event {
+	return &models.Event{
+		EventID:     models.GenerateEventID(),
+		EventType:   "index_bloat",
+		Severity:    models.SeverityHigh,
+		Source:      "db-maintenance",
+		Description: fmt.Sprintf("Index %s on %s.%s is an estimated %.1f%% bloated (%d of %d bytes wasted); consider REINDEX", index.Index, index.Schema, index.Table, index.BloatPercent, index.BloatBytes, index.SizeBytes),
+		EventData: models.EventData{
+			"schema":         index.Schema,
+			"table":          index.Table,
+			"index":          index.Index,
+			"size_bytes":     index.SizeBytes,
+			"expected_bytes": index.ExpectedBytes,
+			"bloat_percent":  index.BloatPercent,
+			"checked_at":     now.UTC().Format(time.RFC3339),
+		},
+		// Weekly alerts about the same index share an incident
+		CorrelationID: "index-bloat-" + index.Schema + "." + index.Index,
+		CreatedAt:     now,
+	}
+}
@@ -17,10 +17,142 @@ CREATE TABLE security_events (
     source VARCHAR(255) NOT NULL,
     description TEXT,
     event_data JSONB,
+    correlation_id VARCHAR(255),
+    tenant_id VARCHAR(64),
+    attack_techniques TEXT[] NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'acknowledged', 'resolved')),
+    status_changed_by VARCHAR(255),
+    status_note TEXT,
+    acknowledged_at TIMESTAMP WITH TIME ZONE,
+    acknowledged_by VARCHAR(255),
+    acknowledgement_note TEXT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
     updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
 );
 
+-- ========================================
+-- WEBHOOK SUBSCRIPTIONS
+-- ========================================
+
+-- External endpoints subscribed to security events
+CREATE TABLE webhook_subscriptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    target_url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Outcome of each webhook delivery
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('succeeded', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_code INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_deliveries_subscription_id ON webhook_deliveries(subscription_id, created_at DESC);
+
+-- ========================================
+-- EVENT AGGREGATION
+-- ========================================
+
+-- Aggregation settings shared by the API and workers (single row)
+CREATE TABLE aggregation_settings (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    config JSONB NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- ========================================
+-- THRESHOLD ALERTING
+-- ========================================
+
+-- Threshold alert rules evaluated by the workers
+CREATE TABLE alert_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    event_type VARCHAR(100) NOT NULL,
+    severity VARCHAR(20) NOT NULL DEFAULT '',
+    threshold_count INTEGER NOT NULL CHECK (threshold_count > 0),
+    window_ms BIGINT NOT NULL CHECK (window_ms > 0),
+    cooldown_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- ========================================
+-- ARCHIVAL
+-- ========================================
+
+-- History of S3 archival runs, reported by the archive status endpoint
+CREATE TABLE archive_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    archived_before TIMESTAMP WITH TIME ZONE NOT NULL,
+    rows_archived BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+-- ========================================
+-- SCHEDULED JOBS
+-- ========================================
+
+-- History of scheduled job runs such as event cleanup, archival and vacuum
+CREATE TABLE scheduled_job_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    job_name VARCHAR(100) NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+-- ========================================
+-- EVENT TIMELINE
+-- ========================================
+
+-- Status changes of events, recorded by a trigger on security_events.status
+CREATE TABLE event_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(255) NOT NULL REFERENCES security_events(event_id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    changed_by VARCHAR(255),
+    note TEXT,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- Queue deliveries of events to workers and how their processing ended
+CREATE TABLE event_processing_log (
+    id BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(255) NOT NULL REFERENCES security_events(event_id) ON DELETE CASCADE,
+    queue_name VARCHAR(255) NOT NULL,
+    attempt INTEGER NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    outcome VARCHAR(20) NOT NULL,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+-- ========================================
+-- MESSAGE DEDUPLICATION
+-- ========================================
+
+-- Messages a worker finished processing, so redelivered copies are skipped. Rows are
+-- inserted in the transaction that processes the message and pruned after the dedup window.
+CREATE TABLE processed_messages (
+    queue_name VARCHAR(255) NOT NULL,
+    message_id VARCHAR(255) NOT NULL,
+    processed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (queue_name, message_id)
+);
+
 -- ========================================
 -- BASIC INDEXES
 -- ========================================
@@ -29,7 +161,21 @@ CREATE TABLE security_events (
 CREATE INDEX idx_security_events_event_type ON security_events(event_type);
 CREATE INDEX idx_security_events_severity ON security_events(severity);
 CREATE INDEX idx_security_events_created_at ON security_events(created_at);
+CREATE INDEX idx_security_events_created_at_id ON security_events(created_at DESC, id DESC);
+CREATE INDEX idx_security_events_source_created_at ON security_events(source, created_at DESC);
+CREATE INDEX idx_security_events_correlation_id ON security_events(correlation_id);
+CREATE INDEX idx_security_events_tenant_id ON security_events(tenant_id) WHERE tenant_id IS NOT NULL;
 CREATE INDEX idx_security_events_event_data ON security_events USING GIN (event_data);
+CREATE INDEX idx_security_events_data_source_ip ON security_events((event_data->>'source_ip'), created_at DESC, id DESC);
+CREATE INDEX idx_security_events_data_username ON security_events((event_data->>'username'), created_at DESC, id DESC);
+CREATE INDEX idx_security_events_data_session_id ON security_events((event_data->>'session_id'), created_at DESC, id DESC);
+CREATE INDEX idx_security_events_data_file_path ON security_events((event_data->>'file_path'), created_at DESC, id DESC);
+CREATE INDEX idx_security_events_attack_techniques ON security_events USING GIN (attack_techniques);
+CREATE INDEX idx_archive_runs_started_at ON archive_runs(started_at DESC);
+CREATE INDEX idx_scheduled_job_runs_job_started_at ON scheduled_job_runs(job_name, started_at DESC);
+CREATE INDEX idx_event_transitions_event_id ON event_transitions(event_id, changed_at, id);
+CREATE INDEX idx_event_processing_log_event_id ON event_processing_log(event_id, started_at, id);
+CREATE INDEX idx_processed_messages_processed_at ON processed_messages(processed_at);
 
 -- ========================================
 -- TRIGGER FOR UPDATED_AT
@@ -50,6 +196,34 @@ CREATE TRIGGER update_security_events_updated_at
     FOR EACH ROW 
     EXECUTE FUNCTION update_updated_at_column();
 
+-- ========================================
+-- TRIGGER FOR EVENT TRANSITIONS
+-- ========================================
+
+-- Record creation and every change of an event's status in event_transitions
+CREATE OR REPLACE FUNCTION record_event_transition()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO event_transitions (event_id, from_status, to_status, changed_at)
+        VALUES (NEW.event_id, NULL, 'open', NEW.created_at);
+        IF NEW.status <> 'open' THEN
+            INSERT INTO event_transitions (event_id, from_status, to_status, changed_by, note, changed_at)
+            VALUES (NEW.event_id, 'open', NEW.status, NEW.status_changed_by, NEW.status_note, NEW.created_at);
+        END IF;
+    ELSIF OLD.status IS DISTINCT FROM NEW.status THEN
+        INSERT INTO event_transitions (event_id, from_status, to_status, changed_by, note, changed_at)
+        VALUES (NEW.event_id, OLD.status, NEW.status, NEW.status_changed_by, NEW.status_note, clock_timestamp());
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER record_security_event_transition
+    AFTER INSERT OR UPDATE OF status ON security_events
+    FOR EACH ROW
+    EXECUTE FUNCTION record_event_transition();
+
 -- ========================================
 -- SAMPLE DATA
 -- ========================================
@@ -0,0 +1,57 @@
+package aggregation
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"skyhawk-security-microservice/internal/database"
+)
+
+// Store persists the aggregation settings shared by the API and the workers
+type Store struct {
+	db *database.DB
+}
+
+// NewStore creates a new aggregation settings store
+func NewStore(db *database.DB) *Store {
+	return &Store{db: db}
+}
+
+// Load returns the stored settings, or the defaults when none have been saved
+func (s *Store) Load() (Config, error) {
+	var raw []byte
+	err := s.db.QueryRow(`SELECT config FROM aggregation_settings WHERE id = 1`).Scan(&raw)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return DefaultConfig(), nil
+		}
+		return Config{}, fmt.Errorf("failed to load aggregation settings: %v", err)
+	}
+
+	config := DefaultConfig()
+	if err := json.Unmarshal(raw, &config); err != nil {
+		return Config{}, fmt.Errorf("failed to decode aggregation settings: %v", err)
+	}
+
+	return normalize(config), nil
+}
+
+// Save stores the settings
+func (s *Store) Save(config Config) error {
+	raw, err := json.Marshal(normalize(config))
+	if err != nil {
+		return fmt.Errorf("failed to encode aggregation settings: %v", err)
+	}
+
+	query := `
+		INSERT INTO aggregation_settings (id, config, updated_at)
+		VALUES (1, $1, NOW())
+		ON CONFLICT (id) DO UPDATE SET config = EXCLUDED.config, updated_at = NOW()`
+
+	if _, err := s.db.Exec(query, raw); err != nil {
+		return fmt.Errorf("failed to save aggregation settings: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package aggregation
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// Config controls how events are aggregated
+type Config struct {
+	Enabled bool `json:"enabled"`
+	// WindowSeconds is how long a group stays open after its first event
+	WindowSeconds int `json:"window_seconds" binding:"omitempty,min=1,max=3600"`
+	// EventTypes limits aggregation to these types; empty aggregates every type
+	EventTypes []string `json:"event_types"`
+	// MinCount is the minimum group size that produces an aggregate event
+	MinCount int `json:"min_count" binding:"omitempty,min=1"`
+}
+
+// DefaultConfig returns the default aggregation settings (disabled, 60s windows)
+func DefaultConfig() Config {
+	return Config{
+		Enabled:       false,
+		WindowSeconds: 60,
+		EventTypes:    []string{},
+		MinCount:      2,
+	}
+}
+
+// Window returns the window duration
+func (c Config) Window() time.Duration {
+	return time.Duration(c.WindowSeconds) * time.Second
+}
+
+// EmitFunc receives aggregate events when a window closes
+type EmitFunc func(event *models.Event)
+
+// AggregationWindow groups events by (event_type, source, severity) within a time window
+// and emits a single aggregate event per group when the window closes
+type AggregationWindow struct {
+	mu     sync.RWMutex
+	config Config
+	groups sync.Map
+	emit   EmitFunc
+}
+
+// group tracks the events seen for one key within the current window
+type group struct {
+	mu        sync.Mutex
+	template  models.Event
+	count     int
+	firstSeen time.Time
+	lastSeen  time.Time
+	timer     *time.Timer
+	closed    bool
+}
+
+// NewAggregationWindow creates an aggregator that passes aggregate events to emit
+func NewAggregationWindow(config Config, emit EmitFunc) *AggregationWindow {
+	return &AggregationWindow{
+		config: normalize(config),
+		emit:   emit,
+	}
+}
+
+// Configure replaces the aggregation settings; open windows keep their original duration
+func (w *AggregationWindow) Configure(config Config) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.config = normalize(config)
+}
+
+// Config returns the current aggregation settings
+func (w *AggregationWindow) Config() Config {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.config
+}
+
+// GroupKey returns the aggregation key for an event
+func GroupKey(event *models.Event) string {
+	return strings.Join([]string{event.EventType, event.Source, strings.ToLower(event.Severity)}, "|")
+}
+
+// Add records an event in its group's window. It returns false when the event is not
+// subject to aggregation under the current settings.
+func (w *AggregationWindow) Add(event *models.Event) bool {
+	config := w.Config()
+	if !config.Enabled || !config.matches(event.EventType) {
+		return false
+	}
+
+	key := GroupKey(event)
+	now := time.Now()
+
+	for {
+		value, loaded := w.groups.LoadOrStore(key, &group{template: *event, firstSeen: now})
+		g := value.(*group)
+
+		g.mu.Lock()
+		if g.closed {
+			// The window closed between load and lock; start a new one
+			g.mu.Unlock()
+			continue
+		}
+
+		if !loaded {
+			g.timer = time.AfterFunc(config.Window(), func() {
+				w.close(key, g)
+			})
+		}
+		g.count++
+		g.lastSeen = now
+		g.mu.Unlock()
+
+		return true
+	}
+}
+
+// Flush closes every open window immediately, emitting their aggregates
+func (w *AggregationWindow) Flush() {
+	w.groups.Range(func(key, value interface{}) bool {
+		g := value.(*group)
+		g.mu.Lock()
+		if g.timer != nil {
+			g.timer.Stop()
+		}
+		g.mu.Unlock()
+		w.close(key.(string), g)
+		return true
+	})
+}
+
+// close ends a group's window and emits its aggregate event
+func (w *AggregationWindow) close(key string, g *group) {
+	w.groups.CompareAndDelete(key, g)
+
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return
+	}
+	g.closed = true
+	count, template, firstSeen, lastSeen := g.count, g.template, g.firstSeen, g.lastSeen
+	g.mu.Unlock()
+
+	if count < w.Config().MinCount || w.emit == nil {
+		return
+	}
+
+	w.emit(newAggregateEvent(&template, count, firstSeen, lastSeen))
+}
+
+// newAggregateEvent builds the summary event for a closed window
+func newAggregateEvent(template *models.Event, count int, firstSeen, lastSeen time.Time) *models.Event {
+	eventID := models.GenerateEventID()
+	return &models.Event{
+		EventID:     eventID,
+		EventType:   "aggregated_" + template.EventType,
+		Severity:    template.Severity,
+		Source:      template.Source,
+		Description: fmt.Sprintf("%d %s events from %s", count, template.EventType, template.Source),
+		EventData: models.EventData{
+			"count":               count,
+			"first_seen":          firstSeen.UTC().Format(time.RFC3339Nano),
+			"last_seen":           lastSeen.UTC().Format(time.RFC3339Nano),
+			"original_event_type": template.EventType,
+			"sample_event_id":     template.EventID,
+		},
+		CorrelationID: eventID,
+	}
+}
+
+// matches reports whether an event type is aggregated under the config
+func (c Config) matches(eventType string) bool {
+	if len(c.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range c.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize fills in defaults for unset fields
+func normalize(config Config) Config {
+	defaults := DefaultConfig()
+	if config.WindowSeconds <= 0 {
+		config.WindowSeconds = defaults.WindowSeconds
+	}
+	if config.MinCount <= 0 {
+		config.MinCount = defaults.MinCount
+	}
+	if config.EventTypes == nil {
+		config.EventTypes = []string{}
+	}
+	return config
+}
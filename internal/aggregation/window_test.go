@@ -0,0 +1,150 @@
+package aggregation
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// emitted collects the aggregate events emitted by a window
+type emitted struct {
+	mu     sync.Mutex
+	events []*models.Event
+}
+
+func (e *emitted) emit(event *models.Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = append(e.events, event)
+}
+
+func (e *emitted) all() []*models.Event {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]*models.Event(nil), e.events...)
+}
+
+func loginFailure(id string) *models.Event {
+	return &models.Event{EventID: id, EventType: "login_failure", Source: "auth-service", Severity: models.SeverityHigh}
+}
+
+func TestAggregationWindowEmitsSingleAggregate(t *testing.T) {
+	out := &emitted{}
+	window := NewAggregationWindow(Config{Enabled: true, WindowSeconds: 1}, out.emit)
+
+	start := time.Now()
+	for i := 0; i < 100; i++ {
+		if !window.Add(loginFailure("evt-1")) {
+			t.Fatal("Add returned false for an aggregated event")
+		}
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for len(out.all()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("aggregate emitted after %s, want the window to stay open for 1s", elapsed)
+	}
+	// Give a duplicate emit time to show
+	time.Sleep(100 * time.Millisecond)
+
+	events := out.all()
+	if len(events) != 1 {
+		t.Fatalf("emitted %d aggregates, want 1", len(events))
+	}
+	aggregate := events[0]
+	if aggregate.EventType != "aggregated_login_failure" || aggregate.Source != "auth-service" || aggregate.Severity != models.SeverityHigh {
+		t.Errorf("aggregate = %+v, want an aggregated_login_failure from auth-service", aggregate)
+	}
+	if count := aggregate.EventData["count"]; count != 100 {
+		t.Errorf("count = %v, want 100", count)
+	}
+	firstSeen, err := time.Parse(time.RFC3339Nano, aggregate.EventData["first_seen"].(string))
+	if err != nil {
+		t.Fatalf("first_seen: %v", err)
+	}
+	lastSeen, err := time.Parse(time.RFC3339Nano, aggregate.EventData["last_seen"].(string))
+	if err != nil {
+		t.Fatalf("last_seen: %v", err)
+	}
+	if lastSeen.Before(firstSeen) {
+		t.Errorf("last_seen %s is before first_seen %s", lastSeen, firstSeen)
+	}
+}
+
+func TestAggregationWindowGroupsByTypeSourceAndSeverity(t *testing.T) {
+	out := &emitted{}
+	window := NewAggregationWindow(Config{Enabled: true, MinCount: 1}, out.emit)
+
+	window.Add(loginFailure("evt-1"))
+	window.Add(loginFailure("evt-2"))
+	window.Add(&models.Event{EventType: "login_failure", Source: "vpn", Severity: models.SeverityHigh})
+	window.Add(&models.Event{EventType: "login_failure", Source: "auth-service", Severity: "HIGH"})
+	window.Add(&models.Event{EventType: "login_failure", Source: "auth-service", Severity: models.SeverityLow})
+	window.Flush()
+
+	counts := make(map[string]interface{})
+	for _, event := range out.all() {
+		counts[event.Source+"/"+event.Severity] = event.EventData["count"]
+	}
+	want := map[string]interface{}{"auth-service/high": 3, "vpn/high": 1, "auth-service/low": 1}
+	if len(counts) != len(want) {
+		t.Fatalf("aggregates = %v, want %v", counts, want)
+	}
+	for key, count := range want {
+		if counts[key] != count {
+			t.Errorf("group %s count = %v, want %v", key, counts[key], count)
+		}
+	}
+}
+
+func TestAggregationWindowSkipsGroupsBelowMinCount(t *testing.T) {
+	out := &emitted{}
+	window := NewAggregationWindow(Config{Enabled: true, MinCount: 2}, out.emit)
+
+	window.Add(loginFailure("evt-1"))
+	window.Flush()
+
+	if events := out.all(); len(events) != 0 {
+		t.Errorf("emitted %d aggregates for a single event, want 0 below min_count", len(events))
+	}
+}
+
+func TestAggregationWindowIgnoresUnselectedEvents(t *testing.T) {
+	out := &emitted{}
+
+	disabled := NewAggregationWindow(Config{}, out.emit)
+	if disabled.Add(loginFailure("evt-1")) {
+		t.Error("Add returned true with aggregation disabled")
+	}
+
+	window := NewAggregationWindow(Config{Enabled: true, EventTypes: []string{"port_scan"}}, out.emit)
+	if window.Add(loginFailure("evt-1")) {
+		t.Error("Add returned true for an event type that is not aggregated")
+	}
+	if !window.Add(&models.Event{EventType: "port_scan"}) {
+		t.Error("Add returned false for a configured event type")
+	}
+}
+
+func TestAggregationWindowStartsNewWindowAfterClose(t *testing.T) {
+	out := &emitted{}
+	window := NewAggregationWindow(Config{Enabled: true, MinCount: 1}, out.emit)
+
+	window.Add(loginFailure("evt-1"))
+	window.Flush()
+	window.Add(loginFailure("evt-2"))
+	window.Add(loginFailure("evt-3"))
+	window.Flush()
+
+	events := out.all()
+	if len(events) != 2 {
+		t.Fatalf("emitted %d aggregates, want 2", len(events))
+	}
+	if events[0].EventData["count"] != 1 || events[1].EventData["count"] != 2 {
+		t.Errorf("counts = %v, %v; want 1 then 2", events[0].EventData["count"], events[1].EventData["count"])
+	}
+}
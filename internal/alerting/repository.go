@@ -0,0 +1,101 @@
+package alerting
+
+import (
+	"fmt"
+	"time"
+
+	"skyhawk-security-microservice/internal/database"
+)
+
+// Repository persists threshold alert rules
+type Repository struct {
+	db *database.DB
+}
+
+// NewRepository creates a new alert rule repository
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// CreateRule stores a new threshold rule
+func (r *Repository) CreateRule(rule *ThresholdAlertRule) error {
+	query := `
+		INSERT INTO alert_rules (event_type, severity, threshold_count, window_ms, cooldown_ms)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRow(
+		query,
+		rule.EventType,
+		rule.Severity,
+		rule.Count,
+		rule.Window.Milliseconds(),
+		rule.Cooldown.Milliseconds(),
+	).Scan(&rule.ID, &rule.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create alert rule: %v", err)
+	}
+
+	return nil
+}
+
+// ListRules retrieves all threshold rules
+func (r *Repository) ListRules() ([]*ThresholdAlertRule, error) {
+	query := `
+		SELECT id, event_type, severity, threshold_count, window_ms, cooldown_ms, created_at
+		FROM alert_rules
+		ORDER BY created_at`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query alert rules: %v", err)
+	}
+	defer rows.Close()
+
+	var rules []*ThresholdAlertRule
+	for rows.Next() {
+		rule := &ThresholdAlertRule{}
+		var windowMs, cooldownMs int64
+		err := rows.Scan(
+			&rule.ID,
+			&rule.EventType,
+			&rule.Severity,
+			&rule.Count,
+			&windowMs,
+			&cooldownMs,
+			&rule.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert rule: %v", err)
+		}
+		rule.Window = time.Duration(windowMs) * time.Millisecond
+		rule.Cooldown = time.Duration(cooldownMs) * time.Millisecond
+		rules = append(rules, rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert rules: %v", err)
+	}
+
+	return rules, nil
+}
+
+// DeleteRule deletes a threshold rule
+func (r *Repository) DeleteRule(id string) error {
+	result, err := r.db.Exec(`DELETE FROM alert_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert rule: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("alert rule not found")
+	}
+
+	return nil
+}
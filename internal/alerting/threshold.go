@@ -0,0 +1,251 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+	"skyhawk-security-microservice/internal/notifier"
+)
+
+// AlertEventType is the event type of the alerts raised by the monitor
+const AlertEventType = "threshold_alert"
+
+// ThresholdAlertRule fires when more than Count events of EventType arrive within Window.
+// An empty Severity matches events of any severity.
+type ThresholdAlertRule struct {
+	ID        string        `json:"id"`
+	EventType string        `json:"event_type"`
+	Severity  string        `json:"severity"`
+	Count     int           `json:"count"`
+	Window    time.Duration `json:"-"`
+	Cooldown  time.Duration `json:"-"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// MarshalJSON renders the window and cooldown as duration strings
+func (r ThresholdAlertRule) MarshalJSON() ([]byte, error) {
+	type rule ThresholdAlertRule
+	return json.Marshal(struct {
+		rule
+		Window   string `json:"window"`
+		Cooldown string `json:"cooldown"`
+	}{rule(r), r.Window.String(), r.Cooldown.String()})
+}
+
+// Matches reports whether an event counts towards the rule
+func (r *ThresholdAlertRule) Matches(event *models.Event) bool {
+	if event.EventType != r.EventType {
+		return false
+	}
+	return r.Severity == "" || strings.EqualFold(event.Severity, r.Severity)
+}
+
+// CreateThresholdRuleRequest represents the request to create a threshold rule
+type CreateThresholdRuleRequest struct {
+	EventType string `json:"event_type" binding:"required"`
+	Severity  string `json:"severity" binding:"omitempty,oneof=low medium high critical"`
+	Count     int    `json:"count" binding:"required,min=1"`
+	// Window and Cooldown are Go duration strings, e.g. "60s" or "5m"
+	Window   string `json:"window" binding:"required"`
+	Cooldown string `json:"cooldown"`
+}
+
+// ToRule validates the request and converts it into a rule
+func (req *CreateThresholdRuleRequest) ToRule() (*ThresholdAlertRule, error) {
+	window, err := time.ParseDuration(req.Window)
+	if err != nil || window <= 0 {
+		return nil, fmt.Errorf("window must be a positive duration")
+	}
+
+	var cooldown time.Duration
+	if req.Cooldown != "" {
+		cooldown, err = time.ParseDuration(req.Cooldown)
+		if err != nil || cooldown < 0 {
+			return nil, fmt.Errorf("cooldown must be a non-negative duration")
+		}
+	}
+
+	return &ThresholdAlertRule{
+		EventType: req.EventType,
+		Severity:  strings.ToLower(req.Severity),
+		Count:     req.Count,
+		Window:    window,
+		Cooldown:  cooldown,
+	}, nil
+}
+
+// ruleState tracks the recent arrivals for one rule. times is a circular buffer
+// holding the last Count+1 matching arrivals, oldest at next once full.
+type ruleState struct {
+	rule      ThresholdAlertRule
+	times     []time.Time
+	next      int
+	full      bool
+	lastFired time.Time
+}
+
+// record adds an arrival and reports whether the rule's threshold is now exceeded
+func (s *ruleState) record(now time.Time) bool {
+	s.times[s.next] = now
+	s.next = (s.next + 1) % len(s.times)
+	if s.next == 0 {
+		s.full = true
+	}
+	if !s.full {
+		return false
+	}
+
+	oldest := s.times[s.next]
+	return now.Sub(oldest) <= s.rule.Window
+}
+
+// ThresholdMonitor counts events against threshold rules and notifies when a rule is breached
+type ThresholdMonitor struct {
+	ctx       context.Context
+	mu        sync.Mutex
+	states    []*ruleState
+	notifiers []notifier.Notifier
+	events    chan *models.Event
+	now       func() time.Time
+}
+
+// NewThresholdMonitor creates a monitor with no rules
+func NewThresholdMonitor(ctx context.Context) *ThresholdMonitor {
+	return &ThresholdMonitor{
+		ctx:    ctx,
+		events: make(chan *models.Event, 1024),
+		now:    time.Now,
+	}
+}
+
+// AddNotifier registers a notifier that receives threshold alerts
+func (m *ThresholdMonitor) AddNotifier(n notifier.Notifier) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.notifiers = append(m.notifiers, n)
+}
+
+// SetRules replaces the monitored rules. Rules that are unchanged keep their
+// arrival history and cooldown.
+func (m *ThresholdMonitor) SetRules(rules []*ThresholdAlertRule) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing := make(map[string]*ruleState, len(m.states))
+	for _, state := range m.states {
+		existing[state.rule.ID] = state
+	}
+
+	states := make([]*ruleState, 0, len(rules))
+	for _, rule := range rules {
+		if state, ok := existing[rule.ID]; ok && sameRule(&state.rule, rule) {
+			states = append(states, state)
+			continue
+		}
+		states = append(states, &ruleState{
+			rule:  *rule,
+			times: make([]time.Time, rule.Count+1),
+		})
+	}
+	m.states = states
+}
+
+// sameRule reports whether two rules count and fire identically
+func sameRule(a, b *ThresholdAlertRule) bool {
+	return a.EventType == b.EventType && a.Severity == b.Severity &&
+		a.Count == b.Count && a.Window == b.Window && a.Cooldown == b.Cooldown
+}
+
+// Observe queues an event for evaluation without blocking the caller
+func (m *ThresholdMonitor) Observe(event *models.Event) {
+	select {
+	case m.events <- event:
+	default:
+		log.Printf("Threshold monitor backlog full, dropping event %s", event.EventID)
+	}
+}
+
+// Run evaluates observed events until the monitor's context is cancelled
+func (m *ThresholdMonitor) Run() {
+	for {
+		select {
+		case event := <-m.events:
+			m.evaluate(event)
+		case <-m.ctx.Done():
+			return
+		}
+	}
+}
+
+// evaluate counts an event against every matching rule and fires breached rules
+func (m *ThresholdMonitor) evaluate(event *models.Event) {
+	now := m.now()
+
+	m.mu.Lock()
+	var fired []ThresholdAlertRule
+	for _, state := range m.states {
+		if !state.rule.Matches(event) || !state.record(now) {
+			continue
+		}
+		if !state.lastFired.IsZero() && now.Sub(state.lastFired) < state.rule.Cooldown {
+			continue
+		}
+		state.lastFired = now
+		fired = append(fired, state.rule)
+	}
+	notifiers := m.notifiers
+	m.mu.Unlock()
+
+	for i := range fired {
+		m.fire(&fired[i], now, notifiers)
+	}
+}
+
+// fire sends an alert for a breached rule to every notifier asynchronously
+func (m *ThresholdMonitor) fire(rule *ThresholdAlertRule, now time.Time, notifiers []notifier.Notifier) {
+	alert := newAlertEvent(rule, now)
+	log.Printf("Threshold rule %s breached: %s", rule.ID, alert.Description)
+
+	for _, n := range notifiers {
+		go func(n notifier.Notifier) {
+			ctx, cancel := context.WithTimeout(m.ctx, 30*time.Second)
+			defer cancel()
+
+			if err := n.Notify(ctx, alert); err != nil {
+				log.Printf("Failed to send threshold alert for rule %s: %v", rule.ID, err)
+			}
+		}(n)
+	}
+}
+
+// newAlertEvent builds the event describing a threshold breach
+func newAlertEvent(rule *ThresholdAlertRule, now time.Time) *models.Event {
+	severity := rule.Severity
+	if severity == "" {
+		severity = models.SeverityHigh
+	}
+
+	return &models.Event{
+		EventID:     models.GenerateEventID(),
+		EventType:   AlertEventType,
+		Severity:    severity,
+		Source:      "threshold-monitor",
+		Description: fmt.Sprintf("More than %d %s events within %s", rule.Count, rule.EventType, rule.Window),
+		EventData: models.EventData{
+			"rule_id":    rule.ID,
+			"event_type": rule.EventType,
+			"count":      rule.Count,
+			"window":     rule.Window.String(),
+			"fired_at":   now.UTC().Format(time.RFC3339),
+		},
+		// Repeated breaches of a rule share an incident
+		CorrelationID: "threshold-" + rule.ID,
+		CreatedAt:     now,
+	}
+}
@@ -0,0 +1,176 @@
+package alerting
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// channelNotifier forwards every alert to a channel
+type channelNotifier chan *models.Event
+
+func (n channelNotifier) Notify(ctx context.Context, event *models.Event) error {
+	n <- event
+	return nil
+}
+
+// fakeClock is a manually advanced clock for the monitor
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestMonitor(t *testing.T, rule *ThresholdAlertRule) (*ThresholdMonitor, *fakeClock, channelNotifier) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	clock := &fakeClock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	alerts := make(channelNotifier, 10)
+	monitor := NewThresholdMonitor(ctx)
+	monitor.now = clock.Now
+	monitor.AddNotifier(alerts)
+	monitor.SetRules([]*ThresholdAlertRule{rule})
+	return monitor, clock, alerts
+}
+
+// expectAlerts waits for want alerts and fails if more arrive shortly after
+func expectAlerts(t *testing.T, alerts channelNotifier, want int) []*models.Event {
+	t.Helper()
+	var received []*models.Event
+	for len(received) < want {
+		select {
+		case alert := <-alerts:
+			received = append(received, alert)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d alerts, want %d", len(received), want)
+		}
+	}
+	select {
+	case alert := <-alerts:
+		t.Fatalf("received an unexpected extra alert %+v", alert)
+	case <-time.After(50 * time.Millisecond):
+	}
+	return received
+}
+
+func failedLogin() *models.Event {
+	return &models.Event{EventID: "evt-1", EventType: "login_failure", Severity: models.SeverityHigh, Source: "auth-service"}
+}
+
+func TestThresholdMonitorFiresOnceAndHonoursCooldown(t *testing.T) {
+	rule := &ThresholdAlertRule{ID: "rule-1", EventType: "login_failure", Count: 10, Window: time.Minute, Cooldown: 5 * time.Minute}
+	monitor, clock, alerts := newTestMonitor(t, rule)
+
+	// 10 events within the window reach the threshold without exceeding it
+	for i := 0; i < 10; i++ {
+		monitor.evaluate(failedLogin())
+		clock.Advance(5 * time.Second)
+	}
+	expectAlerts(t, alerts, 0)
+
+	// The 11th event within 60s breaches the rule
+	monitor.evaluate(failedLogin())
+	alert := expectAlerts(t, alerts, 1)[0]
+	if alert.EventType != AlertEventType || alert.CorrelationID != "threshold-rule-1" || alert.EventData["rule_id"] != "rule-1" {
+		t.Errorf("alert = %+v, want a threshold alert for rule-1", alert)
+	}
+
+	// Further breaches within the cooldown are suppressed
+	for i := 0; i < 20; i++ {
+		clock.Advance(time.Second)
+		monitor.evaluate(failedLogin())
+	}
+	expectAlerts(t, alerts, 0)
+
+	// Once the cooldown has elapsed the next breach fires again
+	clock.Advance(5 * time.Minute)
+	for i := 0; i < 11; i++ {
+		monitor.evaluate(failedLogin())
+	}
+	expectAlerts(t, alerts, 1)
+}
+
+func TestThresholdMonitorIgnoresEventsOutsideWindow(t *testing.T) {
+	rule := &ThresholdAlertRule{ID: "rule-1", EventType: "login_failure", Count: 10, Window: time.Minute}
+	monitor, clock, alerts := newTestMonitor(t, rule)
+
+	// 11 events spread over more than a minute never exceed 10 per window
+	for i := 0; i < 11; i++ {
+		monitor.evaluate(failedLogin())
+		clock.Advance(7 * time.Second)
+	}
+	expectAlerts(t, alerts, 0)
+}
+
+func TestThresholdMonitorCountsOnlyMatchingEvents(t *testing.T) {
+	rule := &ThresholdAlertRule{ID: "rule-1", EventType: "login_failure", Severity: models.SeverityCritical, Count: 1, Window: time.Minute}
+	monitor, _, alerts := newTestMonitor(t, rule)
+
+	monitor.evaluate(failedLogin())
+	monitor.evaluate(&models.Event{EventType: "port_scan", Severity: models.SeverityCritical})
+	monitor.evaluate(failedLogin())
+	expectAlerts(t, alerts, 0)
+
+	critical := failedLogin()
+	critical.Severity = "CRITICAL"
+	monitor.evaluate(critical)
+	monitor.evaluate(critical)
+	if alert := expectAlerts(t, alerts, 1)[0]; alert.Severity != models.SeverityCritical {
+		t.Errorf("alert severity = %q, want the rule severity critical", alert.Severity)
+	}
+}
+
+func TestThresholdMonitorSetRulesKeepsUnchangedHistory(t *testing.T) {
+	rule := &ThresholdAlertRule{ID: "rule-1", EventType: "login_failure", Count: 2, Window: time.Minute}
+	monitor, _, alerts := newTestMonitor(t, rule)
+
+	monitor.evaluate(failedLogin())
+	monitor.evaluate(failedLogin())
+	unchanged := *rule
+	monitor.SetRules([]*ThresholdAlertRule{&unchanged})
+	monitor.evaluate(failedLogin())
+	expectAlerts(t, alerts, 1)
+
+	changed := *rule
+	changed.Count = 3
+	monitor.SetRules([]*ThresholdAlertRule{&changed})
+	monitor.evaluate(failedLogin())
+	expectAlerts(t, alerts, 0)
+}
+
+func TestCreateThresholdRuleRequestToRule(t *testing.T) {
+	req := &CreateThresholdRuleRequest{EventType: "login_failure", Severity: "HIGH", Count: 10, Window: "60s", Cooldown: "5m"}
+	rule, err := req.ToRule()
+	if err != nil {
+		t.Fatalf("ToRule: %v", err)
+	}
+	if rule.Window != time.Minute || rule.Cooldown != 5*time.Minute || rule.Severity != models.SeverityHigh {
+		t.Errorf("rule = %+v, want a 1m window, 5m cooldown and high severity", rule)
+	}
+
+	for _, invalid := range []CreateThresholdRuleRequest{
+		{EventType: "login_failure", Count: 10, Window: "soon"},
+		{EventType: "login_failure", Count: 10, Window: "0s"},
+		{EventType: "login_failure", Count: 10, Window: "60s", Cooldown: "-1s"},
+	} {
+		if _, err := invalid.ToRule(); err == nil {
+			t.Errorf("ToRule(%+v) returned nil, want an error", invalid)
+		}
+	}
+}
+
+func TestThresholdMonitorRunEvaluatesObservedEvents(t *testing.T) {
+	rule := &ThresholdAlertRule{ID: "rule-1", EventType: "login_failure", Count: 1, Window: time.Minute}
+	monitor, _, alerts := newTestMonitor(t, rule)
+	go monitor.Run()
+
+	monitor.Observe(failedLogin())
+	monitor.Observe(failedLogin())
+	expectAlerts(t, alerts, 1)
+}
@@ -0,0 +1,126 @@
+// Package api defines the JSON envelopes of the HTTP API. Successful responses carry their
+// payload in data and request details in meta; failed ones describe the error with its type,
+// message, details and status code.
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	apperrors "skyhawk-security-microservice/internal/errors"
+)
+
+// Meta describes the request a response answers
+type Meta struct {
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// SuccessResponse is the envelope of a successful response
+type SuccessResponse[T any] struct {
+	Data T     `json:"data"`
+	Meta *Meta `json:"meta,omitempty"`
+}
+
+// PaginationMeta describes the page of a paginated response. Offset pages set Page and
+// TotalPages; cursor pages set NextCursor while more items follow. TotalCount is left out when
+// it is not known.
+type PaginationMeta struct {
+	RequestID  string `json:"request_id,omitempty"`
+	TotalCount *int64 `json:"total_count,omitempty"`
+	Page       int    `json:"page,omitempty"`
+	Limit      int    `json:"limit"`
+	TotalPages int    `json:"total_pages,omitempty"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// PaginatedResponse is the envelope of a page of items
+type PaginatedResponse[T any] struct {
+	Data []T            `json:"data"`
+	Meta PaginationMeta `json:"meta"`
+}
+
+// ErrorResponse is the body of a failed response
+type ErrorResponse struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+	Details string `json:"details,omitempty"`
+	Code    int    `json:"code"`
+	// Fields lists every invalid request field of a validation error
+	Fields    []apperrors.FieldError `json:"fields,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+}
+
+// NewErrorResponse describes appErr for the request with the given ID
+func NewErrorResponse(appErr *apperrors.AppError, requestID string) ErrorResponse {
+	return ErrorResponse{
+		Type:      string(appErr.Type),
+		Message:   appErr.Message,
+		Details:   appErr.Details,
+		Code:      apperrors.GetStatusCode(appErr),
+		Fields:    appErr.Fields,
+		RequestID: requestID,
+	}
+}
+
+// OK responds with 200 and data
+func OK[T any](c *gin.Context, data T) {
+	Respond(c, http.StatusOK, data)
+}
+
+// Created responds with 201 and the created data
+func Created[T any](c *gin.Context, data T) {
+	Respond(c, http.StatusCreated, data)
+}
+
+// Respond responds with statusCode and data, for the successful responses that are neither 200
+// nor 201
+func Respond[T any](c *gin.Context, statusCode int, data T) {
+	c.JSON(statusCode, SuccessResponse[T]{Data: data, Meta: meta(c)})
+}
+
+// Paginated responds with 200 and page page, starting at 1, of limit items out of totalCount
+func Paginated[T any](c *gin.Context, data []T, totalCount int64, page, limit int) {
+	totalPages := 0
+	if limit > 0 {
+		totalPages = int((totalCount + int64(limit) - 1) / int64(limit))
+	}
+	respondPage(c, data, PaginationMeta{TotalCount: &totalCount, Page: page, Limit: limit, TotalPages: totalPages})
+}
+
+// CursorPaginated responds with 200 and a page of up to limit items, followed by the items of
+// nextCursor unless it is empty. totalCount may be nil when it is not known.
+func CursorPaginated[T any](c *gin.Context, data []T, totalCount *int64, limit int, nextCursor string) {
+	respondPage(c, data, PaginationMeta{TotalCount: totalCount, Limit: limit, NextCursor: nextCursor})
+}
+
+// respondPage responds with 200 and a page of items, never encoding an empty page as null
+func respondPage[T any](c *gin.Context, data []T, pageMeta PaginationMeta) {
+	if data == nil {
+		data = []T{}
+	}
+	pageMeta.RequestID = c.GetString("request_id")
+	c.JSON(http.StatusOK, PaginatedResponse[T]{Data: data, Meta: pageMeta})
+}
+
+// Fail responds with the status code and description of err and aborts the request. An
+// *apperrors.AppError anywhere in the chain of err is described; any other error is answered
+// as an internal error without revealing it. The error is attached to the request, so the
+// error handler middleware logs it.
+func Fail(c *gin.Context, err error) {
+	var appErr *apperrors.AppError
+	if !errors.As(err, &appErr) {
+		appErr = apperrors.NewInternalError("Internal server error", err)
+	}
+	c.Error(err)
+	c.AbortWithStatusJSON(apperrors.GetStatusCode(appErr), NewErrorResponse(appErr, c.GetString("request_id")))
+}
+
+// meta returns the meta of a response to the request, nil when there is nothing to report
+func meta(c *gin.Context) *Meta {
+	requestID := c.GetString("request_id")
+	if requestID == "" {
+		return nil
+	}
+	return &Meta{RequestID: requestID}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	apperrors "skyhawk-security-microservice/internal/errors"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// respond runs handler for a request with the ID req-1 and returns the response
+func respond(handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("request_id", "req-1") })
+	router.GET("/", handler)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rec
+}
+
+// assertGolden compares a JSON body with testdata/<name>.golden.json
+func assertGolden(t *testing.T, name string, body []byte) {
+	t.Helper()
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		t.Fatalf("%s: body %q is not JSON: %v", name, body, err)
+	}
+	indented.WriteByte('\n')
+
+	path := filepath.Join("testdata", name+".golden.json")
+	if *update {
+		if err := os.WriteFile(path, indented.Bytes(), 0o644); err != nil {
+			t.Fatalf("write %s: %v", path, err)
+		}
+	}
+	golden, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if !bytes.Equal(indented.Bytes(), golden) {
+		t.Errorf("%s response differs from %s:\n%s\nwant:\n%s", name, path, indented.Bytes(), golden)
+	}
+}
+
+type testItem struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestResponsesMatchGoldenEnvelopes(t *testing.T) {
+	validation := func() error {
+		var problems apperrors.ValidationErrors
+		problems.Add("severity", "must be one of low, medium, high, critical")
+		problems.Add("source", "is required")
+		return problems.Err()
+	}
+
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+		status  int
+	}{
+		{"created", func(c *gin.Context) { Created(c, testItem{ID: "evt-1", Name: "login_failure"}) }, http.StatusCreated},
+		{"paginated", func(c *gin.Context) { Paginated(c, []testItem{{ID: "evt-1", Name: "login_failure"}}, 3, 2, 1) }, http.StatusOK},
+		{"cursor_paginated_empty", func(c *gin.Context) { CursorPaginated[testItem](c, nil, nil, 50, "") }, http.StatusOK},
+		{"validation_error", func(c *gin.Context) { Fail(c, validation()) }, http.StatusBadRequest},
+		{"not_found_error", func(c *gin.Context) { Fail(c, apperrors.NewNotFoundError("Event", "evt-1")) }, http.StatusNotFound},
+		{"internal_error", func(c *gin.Context) { Fail(c, errors.New("pq: password authentication failed")) }, http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := respond(tt.handler)
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d", rec.Code, tt.status)
+			}
+			assertGolden(t, tt.name, rec.Body.Bytes())
+		})
+	}
+}
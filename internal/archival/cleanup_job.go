@@ -0,0 +1,60 @@
+package archival
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// CleanupJob archives events past the retention period once a day
+type CleanupJob struct {
+	archiver *S3Archiver
+	interval time.Duration
+}
+
+// NewCleanupJob creates a daily archival job
+func NewCleanupJob(archiver *S3Archiver) *CleanupJob {
+	return &CleanupJob{
+		archiver: archiver,
+		interval: 24 * time.Hour,
+	}
+}
+
+// RunOnce archives every event older than the retention period
+func (j *CleanupJob) RunOnce(ctx context.Context) (int64, error) {
+	return j.archiver.Archive(ctx, time.Now().Add(-j.archiver.Retention()))
+}
+
+// Start runs the job immediately and then on every interval until the returned
+// function is called. Stopping cancels a run in progress between batches.
+func (j *CleanupJob) Start() (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(j.interval)
+		defer ticker.Stop()
+
+		for {
+			archived, err := j.RunOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				log.Printf("Event archival failed after %d events: %v", archived, err)
+			} else if archived > 0 {
+				log.Printf("Event archival moved %d events to S3", archived)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
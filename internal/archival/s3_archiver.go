@@ -0,0 +1,232 @@
+package archival
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/google/uuid"
+	"skyhawk-security-microservice/internal/database"
+	"skyhawk-security-microservice/internal/models"
+	"skyhawk-security-microservice/internal/repository"
+)
+
+const (
+	// DefaultRetention is how long events stay in PostgreSQL before they are archived
+	DefaultRetention = 90 * 24 * time.Hour
+	// DefaultBatchSize is the number of events written to each archive object
+	DefaultBatchSize = 1000
+)
+
+// Config holds the S3 archival settings
+type Config struct {
+	Bucket string
+	// Prefix is the key prefix archive objects are written under
+	Prefix string
+	// Region overrides the region from the AWS environment
+	Region string
+	// Endpoint overrides the S3 endpoint, e.g. LocalStack; it implies path style addressing
+	Endpoint string
+	// KMSKeyID selects SSE-KMS encryption with this key instead of SSE-S3
+	KMSKeyID  string
+	Retention time.Duration
+	BatchSize int
+}
+
+// ConfigFromEnv reads the archival settings from ARCHIVE_S3_BUCKET, ARCHIVE_S3_PREFIX,
+// ARCHIVE_S3_REGION, ARCHIVE_S3_ENDPOINT, ARCHIVE_S3_KMS_KEY_ID and ARCHIVE_RETENTION_DAYS.
+// It returns nil when no bucket is configured.
+func ConfigFromEnv() (*Config, error) {
+	bucket := os.Getenv("ARCHIVE_S3_BUCKET")
+	if bucket == "" {
+		return nil, nil
+	}
+
+	config := &Config{
+		Bucket:    bucket,
+		Prefix:    os.Getenv("ARCHIVE_S3_PREFIX"),
+		Region:    os.Getenv("ARCHIVE_S3_REGION"),
+		Endpoint:  os.Getenv("ARCHIVE_S3_ENDPOINT"),
+		KMSKeyID:  os.Getenv("ARCHIVE_S3_KMS_KEY_ID"),
+		Retention: DefaultRetention,
+	}
+
+	if value := os.Getenv("ARCHIVE_RETENTION_DAYS"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 {
+			return nil, fmt.Errorf("invalid ARCHIVE_RETENTION_DAYS: %s", value)
+		}
+		config.Retention = time.Duration(days) * 24 * time.Hour
+	}
+
+	return config, nil
+}
+
+// S3Archiver moves old events from PostgreSQL to gzip compressed JSON Lines objects in S3
+type S3Archiver struct {
+	client    *s3.Client
+	config    Config
+	eventRepo *repository.EventRepository
+	store     *StatusStore
+}
+
+// NewS3Archiver creates an archiver using the default AWS credential chain
+func NewS3Archiver(ctx context.Context, db *database.DB, config Config) (*S3Archiver, error) {
+	if config.Prefix == "" {
+		config.Prefix = "events"
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.Retention <= 0 {
+		config.Retention = DefaultRetention
+	}
+
+	var opts []func(*awsconfig.LoadOptions) error
+	if config.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(config.Region))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if config.Endpoint != "" {
+			o.BaseEndpoint = aws.String(config.Endpoint)
+			o.UsePathStyle = true
+		}
+	})
+
+	return &S3Archiver{
+		client:    client,
+		config:    config,
+		eventRepo: repository.NewEventRepository(db),
+		store:     NewStatusStore(db),
+	}, nil
+}
+
+// Retention returns the configured retention period
+func (a *S3Archiver) Retention() time.Duration {
+	return a.config.Retention
+}
+
+// Archive uploads events created before the given time to S3 and deletes them from the
+// database, one batch per object. Rows are only deleted once their object is stored,
+// so a failed run leaves the remaining events in place for the next one. Every run is
+// recorded for the archive status endpoint.
+func (a *S3Archiver) Archive(ctx context.Context, before time.Time) (int64, error) {
+	startedAt := time.Now()
+	archived, err := a.archive(ctx, before)
+
+	run := Run{
+		StartedAt:      startedAt,
+		FinishedAt:     time.Now(),
+		ArchivedBefore: before,
+		RowsArchived:   archived,
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+	if recordErr := a.store.RecordRun(run); recordErr != nil {
+		log.Printf("Failed to record archive run: %v", recordErr)
+	}
+
+	return archived, err
+}
+
+// archive runs the batch loop of Archive
+func (a *S3Archiver) archive(ctx context.Context, before time.Time) (int64, error) {
+	var archived int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return archived, err
+		}
+
+		events, err := a.eventRepo.ListEventsCreatedBefore(ctx, before, a.config.BatchSize)
+		if err != nil {
+			return archived, err
+		}
+		if len(events) == 0 {
+			return archived, nil
+		}
+
+		key, err := a.upload(ctx, events)
+		if err != nil {
+			return archived, err
+		}
+
+		eventIDs := make([]string, len(events))
+		for i, event := range events {
+			eventIDs[i] = event.EventID
+		}
+		result, err := a.eventRepo.DeleteEvents(ctx, eventIDs)
+		if err != nil {
+			return archived, fmt.Errorf("failed to delete events archived to %s: %w", key, err)
+		}
+		archived += int64(result.Deleted)
+		log.Printf("Archived %d events to s3://%s/%s", result.Deleted, a.config.Bucket, key)
+
+		if len(events) < a.config.BatchSize {
+			return archived, nil
+		}
+	}
+}
+
+// upload writes events as one gzip compressed JSON Lines object, keyed by the
+// creation date of the oldest event, and returns the object key
+func (a *S3Archiver) upload(ctx context.Context, events []*models.Event) (string, error) {
+	body, err := encodeJSONLines(events)
+	if err != nil {
+		return "", err
+	}
+
+	key := fmt.Sprintf("%s/%s/%s.jsonl.gz", a.config.Prefix, events[0].CreatedAt.UTC().Format("2006/01/02"), uuid.NewString())
+
+	input := &s3.PutObjectInput{
+		Bucket:               aws.String(a.config.Bucket),
+		Key:                  aws.String(key),
+		Body:                 bytes.NewReader(body),
+		ContentType:          aws.String("application/x-ndjson"),
+		ServerSideEncryption: types.ServerSideEncryptionAes256,
+	}
+	if a.config.KMSKeyID != "" {
+		input.ServerSideEncryption = types.ServerSideEncryptionAwsKms
+		input.SSEKMSKeyId = aws.String(a.config.KMSKeyID)
+	}
+
+	if _, err := a.client.PutObject(ctx, input); err != nil {
+		return "", fmt.Errorf("failed to upload s3://%s/%s: %w", a.config.Bucket, key, err)
+	}
+
+	return key, nil
+}
+
+// encodeJSONLines encodes events one JSON object per line and gzips the result
+func encodeJSONLines(events []*models.Event) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+
+	encoder := json.NewEncoder(gz)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return nil, fmt.Errorf("failed to encode event %s: %w", event.EventID, err)
+		}
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
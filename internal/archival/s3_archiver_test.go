@@ -0,0 +1,200 @@
+package archival
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// putObject is a PutObject request received by the mock S3 server
+type putObject struct {
+	path   string
+	header http.Header
+	body   []byte
+}
+
+// newTestS3Client returns a client for endpoint with static test credentials
+func newTestS3Client(endpoint string) *s3.Client {
+	return s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(endpoint),
+		UsePathStyle: true,
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+		}),
+	})
+}
+
+// newMockS3 starts an S3 endpoint that records PutObject requests
+func newMockS3(t *testing.T) (*s3.Client, *[]putObject) {
+	t.Helper()
+	var puts []putObject
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			http.Error(w, "unexpected request", http.StatusMethodNotAllowed)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		puts = append(puts, putObject{path: r.URL.Path, header: r.Header.Clone(), body: body})
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return newTestS3Client(server.URL), &puts
+}
+
+func archiveTestEvents() []*models.Event {
+	return []*models.Event{
+		{EventID: "evt-1", EventType: "login_failure", Severity: models.SeverityHigh, Source: "auth-service", EventData: models.EventData{"ip": "10.0.0.1"}, CreatedAt: time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)},
+		{EventID: "evt-2", EventType: "port_scan", Severity: models.SeverityLow, Source: "ids", CreatedAt: time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC)},
+	}
+}
+
+// decodeArchive decompresses a JSON Lines archive into events, failing on any invalid line
+func decodeArchive(t *testing.T, body []byte) []models.Event {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("archive is not gzip compressed: %v", err)
+	}
+	var events []models.Event
+	scanner := bufio.NewScanner(gz)
+	for scanner.Scan() {
+		var event models.Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("archive line %q is not valid JSON: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("read archive: %v", err)
+	}
+	return events
+}
+
+func TestUploadWritesEncryptedJSONLines(t *testing.T) {
+	client, puts := newMockS3(t)
+	archiver := &S3Archiver{client: client, config: Config{Bucket: "archive", Prefix: "events"}}
+
+	key, err := archiver.upload(context.Background(), archiveTestEvents())
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+
+	if !regexp.MustCompile(`^events/2025/03/04/[0-9a-f-]{36}\.jsonl\.gz$`).MatchString(key) {
+		t.Errorf("key = %q, want events/YYYY/MM/DD/<uuid>.jsonl.gz dated by the oldest event", key)
+	}
+	if len(*puts) != 1 {
+		t.Fatalf("S3 received %d uploads, want 1", len(*puts))
+	}
+	put := (*puts)[0]
+	if put.path != "/archive/"+key {
+		t.Errorf("uploaded to %q, want /archive/%s", put.path, key)
+	}
+	if sse := put.header.Get("X-Amz-Server-Side-Encryption"); sse != "AES256" {
+		t.Errorf("server-side encryption = %q, want AES256", sse)
+	}
+
+	events := decodeArchive(t, put.body)
+	if len(events) != 2 || events[0].EventID != "evt-1" || events[1].EventID != "evt-2" {
+		t.Fatalf("archive holds %+v, want evt-1 and evt-2", events)
+	}
+	if events[0].EventData["ip"] != "10.0.0.1" {
+		t.Errorf("event_data = %v, want it preserved", events[0].EventData)
+	}
+}
+
+func TestUploadUsesKMSKey(t *testing.T) {
+	client, puts := newMockS3(t)
+	archiver := &S3Archiver{client: client, config: Config{Bucket: "archive", Prefix: "events", KMSKeyID: "key-1"}}
+
+	if _, err := archiver.upload(context.Background(), archiveTestEvents()); err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+	header := (*puts)[0].header
+	if header.Get("X-Amz-Server-Side-Encryption") != "aws:kms" || header.Get("X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id") != "key-1" {
+		t.Errorf("encryption headers = %v, want SSE-KMS with key-1", header)
+	}
+}
+
+func TestUploadReportsS3Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "<Error><Code>AccessDenied</Code></Error>", http.StatusForbidden)
+	}))
+	defer server.Close()
+	archiver := &S3Archiver{client: newTestS3Client(server.URL), config: Config{Bucket: "archive", Prefix: "events"}}
+
+	if _, err := archiver.upload(context.Background(), archiveTestEvents()); err == nil || !strings.Contains(err.Error(), "s3://archive/events/") {
+		t.Errorf("upload returned %v, want an error naming the object", err)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("ARCHIVE_S3_BUCKET", "")
+	if config, err := ConfigFromEnv(); config != nil || err != nil {
+		t.Errorf("ConfigFromEnv without a bucket = %+v, %v; want nil, nil", config, err)
+	}
+
+	t.Setenv("ARCHIVE_S3_BUCKET", "archive")
+	t.Setenv("ARCHIVE_RETENTION_DAYS", "30")
+	config, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ConfigFromEnv: %v", err)
+	}
+	if config.Bucket != "archive" || config.Retention != 30*24*time.Hour {
+		t.Errorf("config = %+v, want bucket archive with 30 days retention", config)
+	}
+
+	t.Setenv("ARCHIVE_RETENTION_DAYS", "0")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("ConfigFromEnv accepted ARCHIVE_RETENTION_DAYS=0")
+	}
+}
+
+// TestUploadToLocalStack uploads to a LocalStack S3 endpoint set in LOCALSTACK_S3_ENDPOINT,
+// e.g. http://localhost:4566, and reads the object back
+func TestUploadToLocalStack(t *testing.T) {
+	endpoint := os.Getenv("LOCALSTACK_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("LOCALSTACK_S3_ENDPOINT not set")
+	}
+
+	ctx := context.Background()
+	client := newTestS3Client(endpoint)
+	bucket := "skyhawk-archive-test"
+	if _, err := client.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: aws.String(bucket)}); err != nil && !strings.Contains(err.Error(), "BucketAlreadyOwnedByYou") {
+		t.Fatalf("CreateBucket: %v", err)
+	}
+
+	archiver := &S3Archiver{client: client, config: Config{Bucket: bucket, Prefix: "events"}}
+	key, err := archiver.upload(ctx, archiveTestEvents())
+	if err != nil {
+		t.Fatalf("upload: %v", err)
+	}
+
+	object, err := client.GetObject(ctx, &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String(key)})
+	if err != nil {
+		t.Fatalf("GetObject: %v", err)
+	}
+	defer object.Body.Close()
+	body, err := io.ReadAll(object.Body)
+	if err != nil {
+		t.Fatalf("read object: %v", err)
+	}
+	if events := decodeArchive(t, body); len(events) != 2 {
+		t.Errorf("archive holds %d events, want 2", len(events))
+	}
+}
@@ -0,0 +1,74 @@
+package archival
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"skyhawk-security-microservice/internal/database"
+)
+
+// Run records the outcome of one archival run
+type Run struct {
+	StartedAt      time.Time `json:"started_at"`
+	FinishedAt     time.Time `json:"finished_at"`
+	ArchivedBefore time.Time `json:"archived_before"`
+	RowsArchived   int64     `json:"rows_archived"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// Status summarizes archival activity
+type Status struct {
+	LastRun           *Run  `json:"last_run"`
+	TotalRowsArchived int64 `json:"total_rows_archived"`
+}
+
+// StatusStore persists archival runs so the API can report on runs made by
+// the cleanup job or the archiver command
+type StatusStore struct {
+	db *database.DB
+}
+
+// NewStatusStore creates a new archival status store
+func NewStatusStore(db *database.DB) *StatusStore {
+	return &StatusStore{db: db}
+}
+
+// RecordRun stores an archival run
+func (s *StatusStore) RecordRun(run Run) error {
+	query := `
+		INSERT INTO archive_runs (started_at, finished_at, archived_before, rows_archived, error)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	if _, err := s.db.Exec(query, run.StartedAt, run.FinishedAt, run.ArchivedBefore, run.RowsArchived, run.Error); err != nil {
+		return fmt.Errorf("failed to record archive run: %v", err)
+	}
+
+	return nil
+}
+
+// Status returns the most recent run and the number of rows archived across all runs
+func (s *StatusStore) Status() (*Status, error) {
+	status := &Status{}
+
+	err := s.db.QueryRow(`SELECT COALESCE(SUM(rows_archived), 0) FROM archive_runs`).Scan(&status.TotalRowsArchived)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count archived rows: %v", err)
+	}
+
+	var run Run
+	err = s.db.QueryRow(`
+		SELECT started_at, finished_at, archived_before, rows_archived, error
+		FROM archive_runs
+		ORDER BY started_at DESC
+		LIMIT 1`).Scan(&run.StartedAt, &run.FinishedAt, &run.ArchivedBefore, &run.RowsArchived, &run.Error)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return status, nil
+		}
+		return nil, fmt.Errorf("failed to load last archive run: %v", err)
+	}
+	status.LastRun = &run
+
+	return status, nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"skyhawk-security-microservice/internal/crypto"
+)
+
+// DefaultPIIFields are the event_data fields encrypted when FIELD_ENCRYPTION_FIELDS is not set
+var DefaultPIIFields = []string{"email", "ssn", "phone_number"}
+
+// FieldEncryption configures encryption of PII fields in event_data
+type FieldEncryption struct {
+	// Encrypter is nil when field encryption is disabled
+	Encrypter *crypto.FieldEncrypter
+	Fields    []string
+}
+
+// Enabled reports whether fields are encrypted
+func (f FieldEncryption) Enabled() bool {
+	return f.Encrypter != nil && len(f.Fields) > 0
+}
+
+// FieldEncryptionFromEnv reads the hex-encoded 32-byte key from FIELD_ENCRYPTION_KEY and the
+// comma separated fields to protect from FIELD_ENCRYPTION_FIELDS. Encryption is disabled when
+// no key is set.
+func FieldEncryptionFromEnv() (FieldEncryption, error) {
+	fields := DefaultPIIFields
+	if value := os.Getenv("FIELD_ENCRYPTION_FIELDS"); value != "" {
+		fields = nil
+		for _, field := range strings.Split(value, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	key := os.Getenv("FIELD_ENCRYPTION_KEY")
+	if key == "" {
+		return FieldEncryption{Fields: fields}, nil
+	}
+
+	enc, err := crypto.NewFieldEncrypterFromHex(key)
+	if err != nil {
+		return FieldEncryption{}, fmt.Errorf("invalid FIELD_ENCRYPTION_KEY: %v", err)
+	}
+	return FieldEncryption{Encrypter: enc, Fields: fields}, nil
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// FeatureFlags switch optional subsystems on or off, so deployments such as development or CI
+// can run without RabbitMQ or the alerting backends. Every feature is enabled by default.
+type FeatureFlags struct {
+	// QueueEnabled publishes new events to RabbitMQ for asynchronous processing
+	QueueEnabled bool `yaml:"queue_enabled" json:"queue_enabled"`
+	// EnrichmentEnabled runs the enrichment pipeline, such as MITRE ATT&CK tagging, on new events
+	EnrichmentEnabled bool `yaml:"enrichment_enabled" json:"enrichment_enabled"`
+	// AlertingEnabled sends notifications for processed events, threshold alerts and incident
+	// resolutions
+	AlertingEnabled bool `yaml:"alerting_enabled" json:"alerting_enabled"`
+	// MetricsEnabled serves Prometheus metrics
+	MetricsEnabled bool `yaml:"metrics_enabled" json:"metrics_enabled"`
+	// AuditLogEnabled logs an audit entry for administrative actions
+	AuditLogEnabled bool `yaml:"audit_log_enabled" json:"audit_log_enabled"`
+}
+
+// DefaultFeatureFlags returns the flags with every feature enabled
+func DefaultFeatureFlags() FeatureFlags {
+	return FeatureFlags{
+		QueueEnabled:      true,
+		EnrichmentEnabled: true,
+		AlertingEnabled:   true,
+		MetricsEnabled:    true,
+		AuditLogEnabled:   true,
+	}
+}
+
+// ApplyEnv overrides the flags with the FEATURE_QUEUE_ENABLED, FEATURE_ENRICHMENT_ENABLED,
+// FEATURE_ALERTING_ENABLED, FEATURE_METRICS_ENABLED and FEATURE_AUDIT_LOG_ENABLED
+// environment variables that are set
+func (f FeatureFlags) ApplyEnv() (FeatureFlags, error) {
+	vars := []struct {
+		key   string
+		value *bool
+	}{
+		{"FEATURE_QUEUE_ENABLED", &f.QueueEnabled},
+		{"FEATURE_ENRICHMENT_ENABLED", &f.EnrichmentEnabled},
+		{"FEATURE_ALERTING_ENABLED", &f.AlertingEnabled},
+		{"FEATURE_METRICS_ENABLED", &f.MetricsEnabled},
+		{"FEATURE_AUDIT_LOG_ENABLED", &f.AuditLogEnabled},
+	}
+	for _, v := range vars {
+		value := os.Getenv(v.key)
+		if value == "" {
+			continue
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return f, fmt.Errorf("invalid %s: %s", v.key, value)
+		}
+		*v.value = enabled
+	}
+	return f, nil
+}
+
+// LoadFeatureFlags reads the features section of the configuration file named by CONFIG_FILE,
+// if any, and applies the FEATURE_* environment variables on top. Features not mentioned in
+// either stay enabled.
+func LoadFeatureFlags() (FeatureFlags, error) {
+	flags := DefaultFeatureFlags()
+	if configFile := os.Getenv("CONFIG_FILE"); configFile != "" {
+		cfg, err := Load(configFile)
+		if err != nil {
+			return flags, err
+		}
+		flags = cfg.Features
+	}
+	return flags.ApplyEnv()
+}
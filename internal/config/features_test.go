@@ -0,0 +1,39 @@
+package config
+
+import "testing"
+
+func TestLoadFeatureFlagsKeepsUnmentionedFeaturesEnabled(t *testing.T) {
+	t.Setenv("CONFIG_FILE", writeConfig(t, "features:\n  queue_enabled: false\n"))
+	t.Setenv("FEATURE_ALERTING_ENABLED", "false")
+
+	flags, err := LoadFeatureFlags()
+	if err != nil {
+		t.Fatalf("LoadFeatureFlags: %v", err)
+	}
+	want := DefaultFeatureFlags()
+	want.QueueEnabled = false
+	want.AlertingEnabled = false
+	if flags != want {
+		t.Errorf("LoadFeatureFlags() = %+v, want %+v", flags, want)
+	}
+}
+
+func TestApplyEnvOverridesConfigFile(t *testing.T) {
+	t.Setenv("FEATURE_QUEUE_ENABLED", "true")
+
+	flags, err := FeatureFlags{}.ApplyEnv()
+	if err != nil {
+		t.Fatalf("ApplyEnv: %v", err)
+	}
+	if !flags.QueueEnabled || flags.EnrichmentEnabled {
+		t.Errorf("ApplyEnv() = %+v, want only the queue enabled", flags)
+	}
+}
+
+func TestApplyEnvRejectsInvalidValue(t *testing.T) {
+	t.Setenv("FEATURE_QUEUE_ENABLED", "sometimes")
+
+	if _, err := DefaultFeatureFlags().ApplyEnv(); err == nil {
+		t.Error("ApplyEnv accepted FEATURE_QUEUE_ENABLED=sometimes")
+	}
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GinMode is the mode the Gin HTTP framework runs in
+type GinMode string
+
+const (
+	// GinModeDebug logs every route and warns about unsafe settings; for local development
+	GinModeDebug GinMode = gin.DebugMode
+	// GinModeRelease disables the debug output
+	GinModeRelease GinMode = gin.ReleaseMode
+	// GinModeTest is meant for tests
+	GinModeTest GinMode = gin.TestMode
+)
+
+// ParseGinMode parses debug, release or test
+func ParseGinMode(value string) (GinMode, error) {
+	switch mode := GinMode(value); mode {
+	case GinModeDebug, GinModeRelease, GinModeTest:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid GIN_MODE: %s (expected %s, %s or %s)", value, GinModeDebug, GinModeRelease, GinModeTest)
+}
+
+// GinModeFromEnv reads the Gin mode from GIN_MODE, release when it is not set, so only
+// deployments that ask for debug mode get its verbose output
+func GinModeFromEnv() (GinMode, error) {
+	value := os.Getenv("GIN_MODE")
+	if value == "" {
+		return GinModeRelease, nil
+	}
+	return ParseGinMode(value)
+}
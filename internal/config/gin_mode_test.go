@@ -0,0 +1,32 @@
+package config
+
+import "testing"
+
+func TestGinModeFromEnv(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    GinMode
+		wantErr bool
+	}{
+		{value: "", want: GinModeRelease},
+		{value: "debug", want: GinModeDebug},
+		{value: "release", want: GinModeRelease},
+		{value: "test", want: GinModeTest},
+		{value: "production", wantErr: true},
+		{value: "DEBUG", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("GIN_MODE", tt.value)
+		mode, err := GinModeFromEnv()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("GIN_MODE=%q: got mode %q, want an error", tt.value, mode)
+			}
+			continue
+		}
+		if err != nil || mode != tt.want {
+			t.Errorf("GIN_MODE=%q: got %q, %v, want %q", tt.value, mode, err, tt.want)
+		}
+	}
+}
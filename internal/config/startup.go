@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/streadway/amqp"
+	"skyhawk-security-microservice/internal/database"
+	"skyhawk-security-microservice/internal/logger"
+)
+
+// LogLevelFromEnv reads the minimum level of the structured logger from LOG_LEVEL, INFO
+// when it is not set
+func LogLevelFromEnv() (logger.Level, error) {
+	value := os.Getenv("LOG_LEVEL")
+	if value == "" {
+		return logger.INFO, nil
+	}
+	level, err := logger.ParseLevel(value)
+	if err != nil {
+		return logger.INFO, fmt.Errorf("invalid LOG_LEVEL: %s", value)
+	}
+	return level, nil
+}
+
+// StartupInfo is the effective configuration a service reports when it starts
+type StartupInfo struct {
+	Service string
+	// Port is the port the service listens on; 0 leaves it out
+	Port int
+	// AMQPURL is the broker the service connects to
+	AMQPURL string
+	// Workers is the number of consumers, or the minimum when autoscaling; 0 leaves it out
+	Workers int
+	// MaxWorkers is the maximum number of consumers when autoscaling; 0 leaves it out
+	MaxWorkers int
+	LogLevel   logger.Level
+	// GinMode is the mode of the HTTP framework; empty leaves it out
+	GinMode GinMode
+}
+
+// Fields returns the configuration as log fields, adding the database settings read from the
+// environment. Passwords are masked and only the host and vhost of the broker are included.
+func (s StartupInfo) Fields() logger.Fields {
+	db := database.ConnectionConfigFromEnv()
+	fields := logger.Fields{
+		"service":   s.Service,
+		"db_host":   db.Host,
+		"db_port":   db.Port,
+		"db_name":   db.Name,
+		"db_user":   db.User,
+		"log_level": s.LogLevel.String(),
+	}
+	if db.Password != "" {
+		fields["db_password"] = redacted
+	}
+	if s.Port != 0 {
+		fields["port"] = s.Port
+	}
+	if s.Workers != 0 {
+		fields["workers"] = s.Workers
+	}
+	if s.MaxWorkers != 0 {
+		fields["max_workers"] = s.MaxWorkers
+	}
+	if s.GinMode != "" {
+		fields["gin_mode"] = string(s.GinMode)
+	}
+
+	if uri, err := amqp.ParseURI(s.AMQPURL); err == nil {
+		fields["amqp_host"] = fmt.Sprintf("%s:%d", uri.Host, uri.Port)
+		fields["amqp_vhost"] = uri.Vhost
+	} else {
+		fields["amqp_url"] = logger.RedactCredentials(s.AMQPURL)
+	}
+
+	return fields
+}
+
+// LogStartup logs the effective configuration of a service as a single INFO entry
+func LogStartup(info StartupInfo) {
+	logger.Info("Effective configuration", info.Fields())
+}
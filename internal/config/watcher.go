@@ -0,0 +1,158 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay coalesces the burst of events editors produce when saving a file
+const reloadDelay = 100 * time.Millisecond
+
+// ChangeFunc applies a new configuration. Returning an error keeps that component on
+// its previous settings; the remaining callbacks still run.
+type ChangeFunc func(newConfig Config) error
+
+// ConfigWatcher reloads the configuration file when it changes on disk and hands the
+// new configuration to the registered callbacks. Connection settings are fixed at
+// startup; changing them requires a restart.
+type ConfigWatcher struct {
+	path string
+
+	mu        sync.Mutex
+	current   Config
+	callbacks []ChangeFunc
+
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// NewConfigWatcher loads the configuration file. Call Start to begin watching it.
+func NewConfigWatcher(path string) (*ConfigWatcher, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config path: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConfigWatcher{
+		path:    path,
+		current: cfg,
+		done:    make(chan struct{}),
+	}, nil
+}
+
+// Config returns the configuration currently in effect
+func (w *ConfigWatcher) Config() Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// OnChange registers a callback that is called with every reloaded configuration
+func (w *ConfigWatcher) OnChange(fn ChangeFunc) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callbacks = append(w.callbacks, fn)
+}
+
+// Reload reads the configuration file and applies it. A file that fails to parse is
+// rejected as a whole; callback errors are joined into the returned error.
+func (w *ConfigWatcher) Reload() error {
+	cfg, err := Load(w.path)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if cfg.Database != w.current.Database || cfg.RabbitMQ != w.current.RabbitMQ {
+		log.Printf("Connection settings in %s changed; restart the service to apply them", w.path)
+	}
+	cfg.Database = w.current.Database
+	cfg.RabbitMQ = w.current.RabbitMQ
+
+	var errs []error
+	for _, fn := range w.callbacks {
+		if err := fn(cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	w.current = cfg
+
+	return errors.Join(errs...)
+}
+
+// Start watches the configuration file and reloads it whenever it is written or
+// recreated. The directory is watched so that editors which save by replacing the
+// file are picked up.
+func (w *ConfigWatcher) Start() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %v", err)
+	}
+	if err := watcher.Add(filepath.Dir(w.path)); err != nil {
+		watcher.Close()
+		return fmt.Errorf("failed to watch config directory: %v", err)
+	}
+	w.watcher = watcher
+
+	go w.run()
+	return nil
+}
+
+// run reloads the configuration after the file settles
+func (w *ConfigWatcher) run() {
+	defer close(w.done)
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				timer.Stop()
+				return
+			}
+			if filepath.Clean(event.Name) != w.path || !(event.Has(fsnotify.Write) || event.Has(fsnotify.Create)) {
+				continue
+			}
+			timer.Reset(reloadDelay)
+
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				timer.Stop()
+				return
+			}
+			log.Printf("Config watcher error: %v", err)
+
+		case <-timer.C:
+			if err := w.Reload(); err != nil {
+				log.Printf("Failed to reload %s: %v", w.path, err)
+				continue
+			}
+			log.Printf("Reloaded configuration from %s", w.path)
+		}
+	}
+}
+
+// Close stops watching the configuration file
+func (w *ConfigWatcher) Close() error {
+	if w.watcher == nil {
+		return nil
+	}
+	err := w.watcher.Close()
+	<-w.done
+	return err
+}
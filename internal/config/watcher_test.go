@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes contents to config.yaml in a temporary directory and returns its path
+func writeConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func TestReloadKeepsConnectionSettings(t *testing.T) {
+	path := writeConfig(t, "database:\n  host: db-1\nrabbitmq:\n  url: amqp://rabbit-1/\n")
+	watcher, err := NewConfigWatcher(path)
+	if err != nil {
+		t.Fatalf("NewConfigWatcher: %v", err)
+	}
+
+	var applied Config
+	watcher.OnChange(func(cfg Config) error {
+		applied = cfg
+		return nil
+	})
+
+	updated := "database:\n  host: db-2\nrabbitmq:\n  url: amqp://rabbit-2/\nrouting:\n  - match: {severity: critical}\n    target: security_events_critical\n"
+	if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := watcher.Reload(); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+
+	if applied.Database.Host != "db-1" || applied.RabbitMQ.URL != "amqp://rabbit-1/" {
+		t.Errorf("callback got connection settings %+v %+v, want the startup ones", applied.Database, applied.RabbitMQ)
+	}
+	if len(applied.Routing) != 1 || applied.Routing[0].Target != "security_events_critical" {
+		t.Errorf("callback got routing %+v, want the new rule", applied.Routing)
+	}
+	if watcher.Config().Database.Host != "db-1" {
+		t.Errorf("current database host = %q, want db-1", watcher.Config().Database.Host)
+	}
+}
+
+func TestReloadRejectsInvalidFile(t *testing.T) {
+	path := writeConfig(t, "slack:\n  channel: \"#security\"\n")
+	watcher, err := NewConfigWatcher(path)
+	if err != nil {
+		t.Fatalf("NewConfigWatcher: %v", err)
+	}
+	called := false
+	watcher.OnChange(func(Config) error {
+		called = true
+		return nil
+	})
+
+	if err := os.WriteFile(path, []byte("slack: [unterminated"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if err := watcher.Reload(); err == nil {
+		t.Fatal("Reload returned nil for an invalid file")
+	}
+	if called {
+		t.Error("callback ran for an invalid file")
+	}
+	if watcher.Config().Slack.Channel != "#security" {
+		t.Errorf("current config = %+v, want the previous one", watcher.Config())
+	}
+}
+
+func TestReloadRunsEveryCallback(t *testing.T) {
+	watcher, err := NewConfigWatcher(writeConfig(t, "slack:\n  channel: \"#security\"\n"))
+	if err != nil {
+		t.Fatalf("NewConfigWatcher: %v", err)
+	}
+	ran := 0
+	watcher.OnChange(func(Config) error {
+		ran++
+		return errors.New("bad routing rules")
+	})
+	watcher.OnChange(func(Config) error {
+		ran++
+		return nil
+	})
+
+	err = watcher.Reload()
+	if err == nil || !strings.Contains(err.Error(), "bad routing rules") {
+		t.Errorf("Reload returned %v, want the callback error", err)
+	}
+	if ran != 2 {
+		t.Errorf("%d callbacks ran, want 2", ran)
+	}
+}
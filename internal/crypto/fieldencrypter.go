@@ -0,0 +1,106 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// ciphertextPrefix marks values produced by FieldEncrypter.Encrypt. Values marked with
+// legacyCiphertextPrefix were sealed without associated data and can still be decrypted.
+const (
+	ciphertextPrefix       = "enc:v2:"
+	legacyCiphertextPrefix = "enc:v1:"
+)
+
+// Algorithm names the cipher used by FieldEncrypter
+const Algorithm = "AES-256-GCM"
+
+// FieldEncrypter encrypts individual values with AES-256-GCM. Ciphertexts are strings of
+// the form enc:v2:<base64 nonce and sealed data>, so they can be stored in JSON documents.
+type FieldEncrypter struct {
+	Key [32]byte
+}
+
+// NewFieldEncrypterFromHex creates an encrypter from a hex-encoded 32-byte key
+func NewFieldEncrypterFromHex(key string) (*FieldEncrypter, error) {
+	raw, err := hex.DecodeString(strings.TrimSpace(key))
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %v", err)
+	}
+	if len(raw) != 32 {
+		return nil, fmt.Errorf("invalid encryption key: got %d bytes, want 32", len(raw))
+	}
+
+	enc := &FieldEncrypter{}
+	copy(enc.Key[:], raw)
+	return enc, nil
+}
+
+// Encrypt seals plaintext under a random nonce. associatedData, such as the name of the
+// field holding the value, is authenticated but not stored; Decrypt needs the same bytes.
+func (e *FieldEncrypter) Encrypt(plaintext, associatedData []byte) (string, error) {
+	gcm, err := e.gcm()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %v", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, associatedData)
+	return ciphertextPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt with the same associatedData. Legacy enc:v1:
+// values carry no associated data, so associatedData is ignored for them.
+func (e *FieldEncrypter) Decrypt(ciphertext string, associatedData []byte) ([]byte, error) {
+	encoded, found := strings.CutPrefix(ciphertext, ciphertextPrefix)
+	if !found {
+		encoded, found = strings.CutPrefix(ciphertext, legacyCiphertextPrefix)
+		if !found {
+			return nil, fmt.Errorf("value is not encrypted")
+		}
+		associatedData = nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext encoding: %v", err)
+	}
+
+	gcm, err := e.gcm()
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, data := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, data, associatedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt value: %v", err)
+	}
+	return plaintext, nil
+}
+
+// IsEncrypted reports whether a value looks like a FieldEncrypter ciphertext
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, ciphertextPrefix) || strings.HasPrefix(value, legacyCiphertextPrefix)
+}
+
+// gcm builds the AEAD for the key
+func (e *FieldEncrypter) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(e.Key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,90 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func newTestEncrypter(t *testing.T) *FieldEncrypter {
+	t.Helper()
+	enc, err := NewFieldEncrypterFromHex(strings.Repeat("ab", 32))
+	if err != nil {
+		t.Fatalf("NewFieldEncrypterFromHex: %v", err)
+	}
+	return enc
+}
+
+func TestFieldEncrypterRoundTrip(t *testing.T) {
+	enc := newTestEncrypter(t)
+
+	ciphertext, err := enc.Encrypt([]byte(`"alice@example.com"`), []byte("email"))
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !IsEncrypted(ciphertext) || !strings.HasPrefix(ciphertext, "enc:v2:") {
+		t.Errorf("ciphertext %q does not carry the enc:v2: prefix", ciphertext)
+	}
+	if strings.Contains(ciphertext, "alice") {
+		t.Errorf("ciphertext %q leaks the plaintext", ciphertext)
+	}
+
+	plaintext, err := enc.Decrypt(ciphertext, []byte("email"))
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if string(plaintext) != `"alice@example.com"` {
+		t.Errorf("Decrypt = %s, want the original plaintext", plaintext)
+	}
+}
+
+func TestFieldEncrypterRejectsOtherAssociatedData(t *testing.T) {
+	enc := newTestEncrypter(t)
+
+	ciphertext, err := enc.Encrypt([]byte(`"123-45-6789"`), []byte("ssn"))
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if _, err := enc.Decrypt(ciphertext, []byte("email")); err == nil {
+		t.Error("Decrypt with another field name succeeded, want an error")
+	}
+}
+
+func TestFieldEncrypterDecryptsLegacyValues(t *testing.T) {
+	enc := newTestEncrypter(t)
+
+	// enc:v1: values were sealed without associated data
+	gcm, err := enc.gcm()
+	if err != nil {
+		t.Fatalf("gcm: %v", err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	legacy := "enc:v1:" + base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte("42"), nil))
+
+	plaintext, err := enc.Decrypt(legacy, []byte("email"))
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if !bytes.Equal(plaintext, []byte("42")) {
+		t.Errorf("Decrypt = %s, want 42", plaintext)
+	}
+}
+
+func TestFieldEncrypterRejectsInvalidCiphertext(t *testing.T) {
+	enc := newTestEncrypter(t)
+
+	for _, value := range []string{"plaintext", "enc:v2:not base64!", "enc:v2:" + base64.StdEncoding.EncodeToString([]byte("short")), "enc:v1:garbage"} {
+		if _, err := enc.Decrypt(value, nil); err == nil {
+			t.Errorf("Decrypt(%q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestNewFieldEncrypterFromHexRejectsBadKeys(t *testing.T) {
+	for _, key := range []string{"", "zz", strings.Repeat("ab", 16)} {
+		if _, err := NewFieldEncrypterFromHex(key); err == nil {
+			t.Errorf("NewFieldEncrypterFromHex(%q) succeeded, want an error", key)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"log"
@@ -8,22 +9,70 @@ import (
 	"time"
 
 	_ "github.com/lib/pq"
+	"skyhawk-security-microservice/internal/logger"
 )
 
 type DB struct {
 	*sql.DB
 }
 
+// DefaultPingTimeout bounds each connection attempt, so a server that accepts connections
+// but never answers cannot hang startup
+const DefaultPingTimeout = 5 * time.Second
+
+// Connection attempts at startup; variables so tests can shorten them
+var (
+	// maxPingAttempts is how often the connection is tried before giving up
+	maxPingAttempts = 10
+	// pingRetryDelay is the pause between failed connection attempts
+	pingRetryDelay = 2 * time.Second
+)
+
+// ConnectionConfig holds the PostgreSQL connection settings
+type ConnectionConfig struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+	// PingTimeout bounds each connection attempt, and the handshake of later connections
+	// rounded up to whole seconds; 0 uses DefaultPingTimeout
+	PingTimeout time.Duration
+}
+
+// ConnectionConfigFromEnv reads the connection settings from DB_HOST, DB_PORT, DB_USER,
+// DB_PASSWORD, DB_NAME and DB_PING_TIMEOUT, using the local development defaults for unset variables
+func ConnectionConfigFromEnv() ConnectionConfig {
+	return ConnectionConfig{
+		Host:        getEnv("DB_HOST", "localhost"),
+		Port:        getEnv("DB_PORT", "5432"),
+		User:        getEnv("DB_USER", "postgres"),
+		Password:    getEnv("DB_PASSWORD", "password"),
+		Name:        getEnv("DB_NAME", "skyhawk_security"),
+		PingTimeout: getEnvDuration("DB_PING_TIMEOUT", DefaultPingTimeout),
+	}
+}
+
+// NewConnection connects with the settings read from the environment
 func NewConnection() (*DB, error) {
-	host := getEnv("DB_HOST", "localhost")
-	port := getEnv("DB_PORT", "5432")
-	user := getEnv("DB_USER", "postgres")
-	password := getEnv("DB_PASSWORD", "password")
-	dbname := getEnv("DB_NAME", "skyhawk_security")
+	return Connect(ConnectionConfigFromEnv())
+}
+
+// Connect opens a connection pool and pings the database until it answers, giving up after
+// maxPingAttempts attempts of at most cfg.PingTimeout each
+func Connect(cfg ConnectionConfig) (*DB, error) {
+	pingTimeout := cfg.PingTimeout
+	if pingTimeout <= 0 {
+		pingTimeout = DefaultPingTimeout
+	}
+
+	// Create connection string. lib/pq does not watch the context while it connects, so
+	// connect_timeout bounds the handshake of every new connection to the ping timeout in whole seconds.
+	connectTimeout := int64((pingTimeout + time.Second - 1) / time.Second)
+	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable connect_timeout=%d",
+		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.Name, connectTimeout)
 
-	// Create connection string
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		host, port, user, password, dbname)
+	log.Printf("Connecting to PostgreSQL: %s", logger.RedactCredentials(connStr))
 
 	// Open database connection
 	db, err := sql.Open("postgres", connStr)
@@ -31,18 +80,23 @@ func NewConnection() (*DB, error) {
 		return nil, fmt.Errorf("failed to open database: %v", err)
 	}
 
-	// Test the connection with retries
-	maxRetries := 10
-	for i := 0; i < maxRetries; i++ {
-		if err := db.Ping(); err != nil {
-			log.Printf("Attempt %d: Failed to ping database: %v", i+1, err)
-			if i < maxRetries-1 {
-				time.Sleep(2 * time.Second)
-				continue
-			}
-			return nil, fmt.Errorf("failed to ping database after %d attempts: %v", maxRetries, err)
+	// Test the connection with retries, giving each attempt its own deadline
+	for attempt := 1; attempt <= maxPingAttempts; attempt++ {
+		err := ping(db, pingTimeout)
+		if err == nil {
+			break
+		}
+		logger.Warn("Failed to ping database", logger.Fields{
+			"attempt":           attempt,
+			"remaining_retries": maxPingAttempts - attempt,
+			"timeout":           pingTimeout.String(),
+			"error":             logger.RedactCredentials(err.Error()),
+		})
+		if attempt == maxPingAttempts {
+			db.Close()
+			return nil, fmt.Errorf("failed to ping database after %d attempts: %v", maxPingAttempts, err)
 		}
-		break
+		time.Sleep(pingRetryDelay)
 	}
 
 	log.Println("✅ Successfully connected to PostgreSQL database")
@@ -55,10 +109,62 @@ func (db *DB) Close() error {
 	return db.DB.Close()
 }
 
+// ping checks the connection, failing once timeout passes
+func ping(db *sql.DB, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return db.PingContext(ctx)
+}
+
 // getEnv gets environment variable with fallback
 func getEnv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return fallback
-} 
\ No newline at end of file
+}
+
+// getEnvDuration reads a duration such as "5s" from the environment, using fallback when
+// the variable is unset or invalid
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		logger.Warn("Invalid duration, using default", logger.Fields{"key": key, "value": value, "default": fallback.String()})
+		return fallback
+	}
+	return duration
+}
+
+// PoolStats summarizes the state of the connection pool
+type PoolStats struct {
+	MaxOpenConnections int    `json:"max_open_connections"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDuration       string `json:"wait_duration"`
+	MaxIdleClosed      int64  `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64  `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64  `json:"max_lifetime_closed"`
+}
+
+// PoolStats returns the current connection pool statistics
+func (db *DB) PoolStats() PoolStats {
+	stats := db.Stats()
+	return PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDuration:       stats.WaitDuration.String(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// silentListener accepts connections and never answers them, like a server that is up but hung
+func silentListener(t *testing.T) (host, port string, accepted func() int) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	var mu sync.Mutex
+	var conns []net.Conn
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+	t.Cleanup(func() {
+		listener.Close()
+		mu.Lock()
+		defer mu.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+	})
+
+	host, port, _ = net.SplitHostPort(listener.Addr().String())
+	return host, port, func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(conns)
+	}
+}
+
+// shortenPingRetries makes Connect give up after attempts quick retries during the test
+func shortenPingRetries(t *testing.T, attempts int) {
+	t.Helper()
+	savedAttempts, savedDelay := maxPingAttempts, pingRetryDelay
+	maxPingAttempts, pingRetryDelay = attempts, 10*time.Millisecond
+	t.Cleanup(func() { maxPingAttempts, pingRetryDelay = savedAttempts, savedDelay })
+}
+
+func TestConnectTimesOutAgainstUnresponsiveServer(t *testing.T) {
+	host, port, accepted := silentListener(t)
+	shortenPingRetries(t, 2)
+
+	cfg := ConnectionConfig{
+		Host:        host,
+		Port:        port,
+		User:        "postgres",
+		Password:    "password",
+		Name:        "skyhawk_security",
+		PingTimeout: time.Second,
+	}
+
+	start := time.Now()
+	db, err := Connect(cfg)
+	elapsed := time.Since(start)
+
+	if err == nil {
+		db.Close()
+		t.Fatal("Connect succeeded against a server that never answers")
+	}
+	if !strings.Contains(err.Error(), "after 2 attempts") {
+		t.Errorf("Connect returned %v, want the attempts to be reported", err)
+	}
+	// Each attempt waits out the ping timeout; none may hang beyond it
+	if elapsed < 2*time.Second || elapsed > 5*time.Second {
+		t.Errorf("Connect gave up after %s, want about 2 attempts of 1s", elapsed)
+	}
+	if n := accepted(); n != 2 {
+		t.Errorf("server accepted %d connections, want one per attempt", n)
+	}
+}
+
+func TestConnectionConfigFromEnvPingTimeout(t *testing.T) {
+	t.Setenv("DB_PING_TIMEOUT", "250ms")
+	if got := ConnectionConfigFromEnv().PingTimeout; got != 250*time.Millisecond {
+		t.Errorf("PingTimeout = %s, want 250ms", got)
+	}
+
+	t.Setenv("DB_PING_TIMEOUT", "soon")
+	if got := ConnectionConfigFromEnv().PingTimeout; got != DefaultPingTimeout {
+		t.Errorf("PingTimeout = %s for an invalid value, want the default %s", got, DefaultPingTimeout)
+	}
+}
@@ -0,0 +1,26 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// uniqueViolation is the Postgres SQLSTATE raised when an insert or update breaks a unique constraint
+const uniqueViolation = "23505"
+
+// IsUniqueViolation reports whether err, or an error it wraps, is a Postgres unique constraint violation
+func IsUniqueViolation(err error) bool {
+	_, ok := UniqueViolation(err)
+	return ok
+}
+
+// UniqueViolation returns the Postgres error of a unique constraint violation, whose Constraint
+// field names the violated constraint
+func UniqueViolation(err error) (*pq.Error, bool) {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return pqErr, true
+	}
+	return nil, false
+}
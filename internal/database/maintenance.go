@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// tableNamePattern matches a table name, optionally qualified by its schema
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Page layout constants of the bloat estimate: the page header and B-tree special space, and
+// the index tuple header plus line pointer of every entry
+const (
+	btreePageOverhead  = 24 + 16
+	btreeTupleOverhead = 8 + 4
+	btreeFillFactor    = 0.9
+)
+
+// IndexBloat estimates how much of a B-tree index is wasted space
+type IndexBloat struct {
+	Schema        string  `json:"schema"`
+	Table         string  `json:"table"`
+	Index         string  `json:"index"`
+	SizeBytes     int64   `json:"size_bytes"`
+	ExpectedBytes int64   `json:"expected_bytes"`
+	BloatBytes    int64   `json:"bloat_bytes"`
+	BloatPercent  float64 `json:"bloat_percent"`
+	Scans         int64   `json:"scans"`
+}
+
+// RunVacuumAnalyze reclaims the space of dead rows in tableName and refreshes its planner
+// statistics. VACUUM cannot run inside a transaction or take parameters, so the name is
+// validated and quoted instead.
+func (db *DB) RunVacuumAnalyze(ctx context.Context, tableName string) error {
+	if !tableNamePattern.MatchString(tableName) {
+		return fmt.Errorf("invalid table name: %q", tableName)
+	}
+
+	parts := strings.Split(tableName, ".")
+	for i := range parts {
+		parts[i] = pq.QuoteIdentifier(parts[i])
+	}
+
+	if _, err := db.ExecContext(ctx, "VACUUM (ANALYZE, VERBOSE) "+strings.Join(parts, ".")); err != nil {
+		return fmt.Errorf("failed to vacuum %s: %v", tableName, err)
+	}
+	return nil
+}
+
+// GetIndexBloat estimates the bloat of every B-tree index of the user tables, most bloated
+// first. The expected size of an index is derived from its tuple count and the average width
+// of its key columns, so the estimate is only as fresh as the last ANALYZE.
+func (db *DB) GetIndexBloat(ctx context.Context) ([]IndexBloat, error) {
+	query := `
+		SELECT s.schemaname, s.relname, s.indexrelname,
+		       pg_relation_size(s.indexrelid), c.reltuples, COALESCE(w.key_width, 8),
+		       COALESCE(s.idx_scan, 0), current_setting('block_size')::int
+		FROM pg_stat_user_indexes s
+		JOIN pg_class c ON c.oid = s.indexrelid
+		JOIN pg_am am ON am.oid = c.relam AND am.amname = 'btree'
+		JOIN pg_index i ON i.indexrelid = s.indexrelid
+		LEFT JOIN LATERAL (
+			SELECT SUM(st.avg_width) AS key_width
+			FROM pg_attribute a
+			JOIN pg_stats st ON st.schemaname = s.schemaname AND st.tablename = s.relname
+				AND st.attname = a.attname
+			WHERE a.attrelid = s.relid AND a.attnum = ANY(i.indkey)
+		) w ON true
+		WHERE c.reltuples >= 0`
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query index sizes: %v", err)
+	}
+	defer rows.Close()
+
+	indexes := []IndexBloat{}
+	for rows.Next() {
+		var bloat IndexBloat
+		var tuples float64
+		var keyWidth, blockSize int64
+		if err := rows.Scan(&bloat.Schema, &bloat.Table, &bloat.Index, &bloat.SizeBytes,
+			&tuples, &keyWidth, &bloat.Scans, &blockSize); err != nil {
+			return nil, fmt.Errorf("failed to scan index size: %v", err)
+		}
+
+		bloat.ExpectedBytes = expectedIndexSize(tuples, keyWidth, blockSize)
+		if bloat.SizeBytes > bloat.ExpectedBytes {
+			bloat.BloatBytes = bloat.SizeBytes - bloat.ExpectedBytes
+			bloat.BloatPercent = math.Round(float64(bloat.BloatBytes)/float64(bloat.SizeBytes)*1000) / 10
+		}
+		indexes = append(indexes, bloat)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read index sizes: %v", err)
+	}
+
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return indexes[i].BloatPercent > indexes[j].BloatPercent
+	})
+
+	return indexes, nil
+}
+
+// expectedIndexSize estimates the size of a freshly built B-tree index holding tuples entries
+// of keyWidth bytes, including its metapage
+func expectedIndexSize(tuples float64, keyWidth, blockSize int64) int64 {
+	// Keys are padded to 8 bytes
+	tupleSize := float64(btreeTupleOverhead + (keyWidth+7)/8*8)
+	pageCapacity := float64(blockSize-btreePageOverhead) * btreeFillFactor
+	pages := math.Ceil(tuples*tupleSize/pageCapacity) + 1
+	return int64(pages) * blockSize
+}
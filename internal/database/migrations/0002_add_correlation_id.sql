@@ -0,0 +1,4 @@
+-- Correlation ID used to group related events and deduplicate alerts
+ALTER TABLE security_events ADD COLUMN IF NOT EXISTS correlation_id VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_correlation_id ON security_events(correlation_id);
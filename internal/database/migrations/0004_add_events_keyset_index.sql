@@ -0,0 +1,2 @@
+-- Supports keyset pagination ordered by (created_at DESC, id DESC)
+CREATE INDEX IF NOT EXISTS idx_security_events_created_at_id ON security_events(created_at DESC, id DESC);
@@ -0,0 +1,6 @@
+-- Event aggregation settings shared by the API and workers (single row)
+CREATE TABLE IF NOT EXISTS aggregation_settings (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    config JSONB NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
@@ -0,0 +1,10 @@
+-- Threshold alert rules evaluated by the workers
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    event_type VARCHAR(100) NOT NULL,
+    severity VARCHAR(20) NOT NULL DEFAULT '',
+    threshold_count INTEGER NOT NULL CHECK (threshold_count > 0),
+    window_ms BIGINT NOT NULL CHECK (window_ms > 0),
+    cooldown_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
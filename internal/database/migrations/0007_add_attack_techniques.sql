@@ -0,0 +1,4 @@
+-- MITRE ATT&CK technique IDs tagged on each event
+ALTER TABLE security_events ADD COLUMN IF NOT EXISTS attack_techniques TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS idx_security_events_attack_techniques ON security_events USING GIN (attack_techniques);
@@ -0,0 +1,11 @@
+-- History of S3 archival runs, reported by the archive status endpoint
+CREATE TABLE IF NOT EXISTS archive_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    archived_before TIMESTAMP WITH TIME ZONE NOT NULL,
+    rows_archived BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_archive_runs_started_at ON archive_runs(started_at DESC);
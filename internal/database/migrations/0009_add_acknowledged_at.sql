@@ -0,0 +1,2 @@
+-- When an event was acknowledged; NULL while it is still open
+ALTER TABLE security_events ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMP WITH TIME ZONE;
@@ -0,0 +1,10 @@
+-- History of scheduled job runs such as event cleanup, archival and vacuum
+CREATE TABLE IF NOT EXISTS scheduled_job_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    job_name VARCHAR(100) NOT NULL,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_job_runs_job_started_at ON scheduled_job_runs(job_name, started_at DESC);
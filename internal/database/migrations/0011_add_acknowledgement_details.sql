@@ -0,0 +1,3 @@
+-- Who acknowledged an event and why; set by bulk status updates
+ALTER TABLE security_events ADD COLUMN IF NOT EXISTS acknowledged_by VARCHAR(255);
+ALTER TABLE security_events ADD COLUMN IF NOT EXISTS acknowledgement_note TEXT;
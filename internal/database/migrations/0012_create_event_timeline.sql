@@ -0,0 +1,69 @@
+-- Status of events: open, acknowledged or resolved. status_changed_by and status_note
+-- describe the latest change and are copied into its transition. Acknowledged events start
+-- out acknowledged.
+ALTER TABLE security_events ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'open'
+    CHECK (status IN ('open', 'acknowledged', 'resolved'));
+ALTER TABLE security_events ADD COLUMN IF NOT EXISTS status_changed_by VARCHAR(255);
+ALTER TABLE security_events ADD COLUMN IF NOT EXISTS status_note TEXT;
+UPDATE security_events SET status = 'acknowledged' WHERE acknowledged_at IS NOT NULL;
+
+-- Status changes of events, recorded by a trigger on security_events.status
+CREATE TABLE IF NOT EXISTS event_transitions (
+    id BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(255) NOT NULL REFERENCES security_events(event_id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    changed_by VARCHAR(255),
+    note TEXT,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_transitions_event_id ON event_transitions(event_id, changed_at, id);
+
+-- Queue deliveries of events to workers and how their processing ended
+CREATE TABLE IF NOT EXISTS event_processing_log (
+    id BIGSERIAL PRIMARY KEY,
+    event_id VARCHAR(255) NOT NULL REFERENCES security_events(event_id) ON DELETE CASCADE,
+    queue_name VARCHAR(255) NOT NULL,
+    attempt INTEGER NOT NULL,
+    published_at TIMESTAMP WITH TIME ZONE,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    finished_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    outcome VARCHAR(20) NOT NULL,
+    error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_processing_log_event_id ON event_processing_log(event_id, started_at, id);
+
+CREATE OR REPLACE FUNCTION record_event_transition()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        INSERT INTO event_transitions (event_id, from_status, to_status, changed_at)
+        VALUES (NEW.event_id, NULL, 'open', NEW.created_at);
+        IF NEW.status <> 'open' THEN
+            INSERT INTO event_transitions (event_id, from_status, to_status, changed_by, note, changed_at)
+            VALUES (NEW.event_id, 'open', NEW.status, NEW.status_changed_by, NEW.status_note, NEW.created_at);
+        END IF;
+    ELSIF OLD.status IS DISTINCT FROM NEW.status THEN
+        INSERT INTO event_transitions (event_id, from_status, to_status, changed_by, note, changed_at)
+        VALUES (NEW.event_id, OLD.status, NEW.status, NEW.status_changed_by, NEW.status_note, clock_timestamp());
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+DROP TRIGGER IF EXISTS record_security_event_transition ON security_events;
+CREATE TRIGGER record_security_event_transition
+    AFTER INSERT OR UPDATE OF status ON security_events
+    FOR EACH ROW
+    EXECUTE FUNCTION record_event_transition();
+
+-- Reconstruct the transitions of existing events
+INSERT INTO event_transitions (event_id, from_status, to_status, changed_at)
+SELECT event_id, NULL, 'open', created_at FROM security_events;
+
+INSERT INTO event_transitions (event_id, from_status, to_status, changed_by, note, changed_at)
+SELECT event_id, 'open', 'acknowledged', acknowledged_by, acknowledgement_note, acknowledged_at
+FROM security_events
+WHERE acknowledged_at IS NOT NULL;
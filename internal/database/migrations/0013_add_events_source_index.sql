@@ -0,0 +1,2 @@
+-- Supports the most recent events of a source, ordered by created_at DESC
+CREATE INDEX IF NOT EXISTS idx_security_events_source_created_at ON security_events(source, created_at DESC);
@@ -0,0 +1,10 @@
+-- Messages a worker finished processing, so redelivered copies are skipped. Rows are
+-- inserted in the transaction that processes the message and pruned after the dedup window.
+CREATE TABLE IF NOT EXISTS processed_messages (
+    queue_name VARCHAR(255) NOT NULL,
+    message_id VARCHAR(255) NOT NULL,
+    processed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (queue_name, message_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_processed_messages_processed_at ON processed_messages(processed_at);
@@ -0,0 +1,6 @@
+-- Support filtering events by commonly queried event_data keys, newest first. Queries must
+-- use the same expressions, with the key as a literal, for the planner to pick these indexes.
+CREATE INDEX IF NOT EXISTS idx_security_events_data_source_ip ON security_events((event_data->>'source_ip'), created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_security_events_data_username ON security_events((event_data->>'username'), created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_security_events_data_session_id ON security_events((event_data->>'session_id'), created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_security_events_data_file_path ON security_events((event_data->>'file_path'), created_at DESC, id DESC);
@@ -0,0 +1,4 @@
+-- Tenant that owns an event; events of a tenant may be published to a queue of their own
+ALTER TABLE security_events ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_tenant_id ON security_events(tenant_id) WHERE tenant_id IS NOT NULL;
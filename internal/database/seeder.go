@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// seederRandSeed seeds the generator of the seeder, so every run generates the same events
+const seederRandSeed = 20240101
+
+// seededEventPrefix starts the event ID of every seeded event
+const seededEventPrefix = "seed-"
+
+// seededEventWindow is how far back the creation times of seeded events are spread
+const seededEventWindow = 7 * 24 * time.Hour
+
+var (
+	seedEventTypes = []string{"login", "failed_login", "brute_force", "port_scan", "file_access", "data_access", "malware", "phishing", "privilege_escalation", "data_exfiltration"}
+	seedSources    = []string{"firewall", "ids", "auth-service", "endpoint-agent", "waf", "vpn-gateway"}
+	seedUsernames  = []string{"alice", "bob", "carol", "dave", "eve", "mallory", "admin", "svc-backup"}
+	seedFilePaths  = []string{"/etc/passwd", "/etc/shadow", "/var/log/auth.log", "/home/alice/.ssh/id_rsa", "/srv/data/customers.csv"}
+	seedCountries  = []string{"US", "DE", "GB", "FR", "NL", "BR", "IN", "JP"}
+)
+
+// Seeder fills the database with generated events for development and test environments
+type Seeder struct {
+	DB *DB
+}
+
+// NewSeeder creates a seeder for db
+func NewSeeder(db *DB) *Seeder {
+	return &Seeder{DB: db}
+}
+
+// Seed inserts count generated events. The generator has a fixed seed, so the same count
+// always yields the same events, and events already seeded are skipped. With SEED_TRUNCATE=true
+// every event, and the rows referencing one, is deleted first.
+func (s *Seeder) Seed(ctx context.Context, count int) error {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to start seeding: %w", err)
+	}
+	defer tx.Rollback()
+
+	if os.Getenv("SEED_TRUNCATE") == "true" {
+		if _, err := tx.ExecContext(ctx, `TRUNCATE security_events CASCADE`); err != nil {
+			return fmt.Errorf("failed to truncate events: %w", err)
+		}
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO security_events (event_id, event_type, severity, source, description, event_data, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		ON CONFLICT (event_id) DO NOTHING`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare seeding: %w", err)
+	}
+	defer stmt.Close()
+
+	rng := rand.New(rand.NewSource(seederRandSeed))
+	now := time.Now()
+	for i := 0; i < count; i++ {
+		event := seedEvent(rng, i, now)
+		eventData, err := json.Marshal(event.EventData)
+		if err != nil {
+			return fmt.Errorf("failed to encode seeded event %s: %w", event.EventID, err)
+		}
+		if _, err := stmt.ExecContext(ctx, event.EventID, event.EventType, event.Severity, event.Source,
+			event.Description, eventData, event.CreatedAt); err != nil {
+			return fmt.Errorf("failed to insert seeded event %s: %w", event.EventID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit seeded events: %w", err)
+	}
+	return nil
+}
+
+// seedEvent generates the i-th seeded event, created within seededEventWindow before now
+func seedEvent(rng *rand.Rand, i int, now time.Time) *models.Event {
+	eventType := seedEventTypes[rng.Intn(len(seedEventTypes))]
+	source := seedSources[rng.Intn(len(seedSources))]
+	severity := models.Severities[rng.Intn(len(models.Severities))]
+	username := seedUsernames[rng.Intn(len(seedUsernames))]
+	sourceIP := fmt.Sprintf("10.%d.%d.%d", rng.Intn(256), rng.Intn(256), 1+rng.Intn(254))
+
+	data := models.EventData{
+		"source_ip":  sourceIP,
+		"username":   username,
+		"session_id": fmt.Sprintf("%08x", rng.Uint32()),
+		"country":    seedCountries[rng.Intn(len(seedCountries))],
+	}
+	switch eventType {
+	case "failed_login", "brute_force":
+		data["attempts"] = 1 + rng.Intn(50)
+	case "port_scan":
+		data["ports_scanned"] = 10 + rng.Intn(1000)
+	case "file_access", "data_access", "data_exfiltration":
+		data["file_path"] = seedFilePaths[rng.Intn(len(seedFilePaths))]
+		data["bytes"] = rng.Intn(10 << 20)
+	}
+
+	return &models.Event{
+		EventID:     fmt.Sprintf("%s%06d", seededEventPrefix, i+1),
+		EventType:   eventType,
+		Severity:    severity,
+		Source:      source,
+		Description: fmt.Sprintf("Seeded %s event for %s from %s", eventType, username, sourceIP),
+		EventData:   data,
+		CreatedAt:   now.Add(-time.Duration(rng.Int63n(int64(seededEventWindow)))),
+	}
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/rand"
+	"os"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+func TestSeedEventIsReproducible(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	first, second := rand.New(rand.NewSource(seederRandSeed)), rand.New(rand.NewSource(seederRandSeed))
+
+	for i := 0; i < 20; i++ {
+		a, b := seedEvent(first, i, now), seedEvent(second, i, now)
+		if !reflect.DeepEqual(a, b) {
+			t.Fatalf("seeded event %d differs between runs: %+v and %+v", i, a, b)
+		}
+		if want := fmt.Sprintf("seed-%06d", i+1); a.EventID != want {
+			t.Errorf("event ID = %q, want %q", a.EventID, want)
+		}
+		if !slices.Contains(models.Severities, a.Severity) {
+			t.Errorf("event %s has severity %q", a.EventID, a.Severity)
+		}
+		if a.CreatedAt.After(now) || a.CreatedAt.Before(now.Add(-seededEventWindow)) {
+			t.Errorf("event %s created at %s, outside the seeded window", a.EventID, a.CreatedAt)
+		}
+	}
+}
+
+// TestSeedAgainstPostgres seeds a security_events table created in a schema of its own in the
+// database at POSTGRES_TEST_DSN
+func TestSeedAgainstPostgres(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN not set")
+	}
+
+	conn, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer conn.Close()
+	// One connection, so the search_path below applies to every statement of the seeder
+	conn.SetMaxOpenConns(1)
+	db := &DB{DB: conn}
+	ctx := context.Background()
+
+	schema := fmt.Sprintf("seed_test_%d", time.Now().UnixNano())
+	for _, query := range []string{
+		"CREATE SCHEMA " + schema,
+		"SET search_path TO " + schema,
+		`CREATE TABLE security_events (
+			id SERIAL PRIMARY KEY,
+			event_id VARCHAR(255) UNIQUE NOT NULL,
+			event_type VARCHAR(100) NOT NULL,
+			severity VARCHAR(20) NOT NULL,
+			source VARCHAR(255) NOT NULL,
+			description TEXT,
+			event_data JSONB,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+			updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+	} {
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	t.Cleanup(func() { db.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE") })
+
+	countEvents := func() int {
+		t.Helper()
+		var count int
+		if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM security_events").Scan(&count); err != nil {
+			t.Fatalf("count events: %v", err)
+		}
+		return count
+	}
+	seeder := NewSeeder(db)
+
+	if err := seeder.Seed(ctx, 50); err != nil {
+		t.Fatalf("Seed: %v", err)
+	}
+	if got := countEvents(); got != 50 {
+		t.Errorf("seeding 50 events left %d rows", got)
+	}
+
+	if _, err := db.ExecContext(ctx, `INSERT INTO security_events (event_id, event_type, severity, source, created_at, updated_at)
+		VALUES ('manual-1', 'login', 'low', 'test', NOW(), NOW())`); err != nil {
+		t.Fatalf("insert event: %v", err)
+	}
+	t.Setenv("SEED_TRUNCATE", "true")
+	if err := seeder.Seed(ctx, 20); err != nil {
+		t.Fatalf("Seed with SEED_TRUNCATE: %v", err)
+	}
+	if got := countEvents(); got != 20 {
+		t.Errorf("seeding 20 events with SEED_TRUNCATE=true left %d rows, want only the new ones", got)
+	}
+}
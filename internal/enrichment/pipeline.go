@@ -0,0 +1,41 @@
+package enrichment
+
+import (
+	"errors"
+	"fmt"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// Enricher adds derived information to an event before it is stored
+type Enricher interface {
+	Name() string
+	Enrich(event *models.Event) error
+}
+
+// Pipeline runs enrichers in registration order
+type Pipeline struct {
+	enrichers []Enricher
+}
+
+// NewPipeline creates a pipeline with the given enrichers
+func NewPipeline(enrichers ...Enricher) *Pipeline {
+	return &Pipeline{enrichers: enrichers}
+}
+
+// Add appends an enricher to the pipeline
+func (p *Pipeline) Add(enricher Enricher) {
+	p.enrichers = append(p.enrichers, enricher)
+}
+
+// Enrich runs every enricher on the event. A failing enricher does not stop the
+// others; all failures are returned together.
+func (p *Pipeline) Enrich(event *models.Event) error {
+	var errs []error
+	for _, enricher := range p.enrichers {
+		if err := enricher.Enrich(event); err != nil {
+			errs = append(errs, fmt.Errorf("%s enrichment failed: %w", enricher.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -1,20 +1,31 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"net/http"
+	"runtime"
+	"strings"
+
+	"skyhawk-security-microservice/internal/logger"
 )
 
 // ErrorType represents the type of error
 type ErrorType string
 
 const (
-	ErrorTypeValidation   ErrorType = "VALIDATION_ERROR"
-	ErrorTypeNotFound     ErrorType = "NOT_FOUND"
-	ErrorTypeConflict     ErrorType = "CONFLICT"
-	ErrorTypeInternal     ErrorType = "INTERNAL_ERROR"
-	ErrorTypeUnauthorized ErrorType = "UNAUTHORIZED"
-	ErrorTypeForbidden    ErrorType = "FORBIDDEN"
+	ErrorTypeValidation    ErrorType = "VALIDATION_ERROR"
+	ErrorTypeNotFound      ErrorType = "NOT_FOUND"
+	ErrorTypeConflict      ErrorType = "CONFLICT"
+	ErrorTypeInternal      ErrorType = "INTERNAL_ERROR"
+	ErrorTypeUnauthorized  ErrorType = "UNAUTHORIZED"
+	ErrorTypeForbidden     ErrorType = "FORBIDDEN"
+	ErrorTypeTimeout       ErrorType = "TIMEOUT"
+	ErrorTypeRateLimited   ErrorType = "RATE_LIMITED"
+	ErrorTypeUnsupported   ErrorType = "UNSUPPORTED_MEDIA_TYPE"
+	ErrorTypeUnprocessable ErrorType = "UNPROCESSABLE"
+	ErrorTypeUnavailable   ErrorType = "SERVICE_UNAVAILABLE"
+	ErrorTypeUpstream      ErrorType = "UPSTREAM_ERROR"
 )
 
 // AppError represents an application error
@@ -24,6 +35,111 @@ type AppError struct {
 	Details    string    `json:"details,omitempty"`
 	StatusCode int       `json:"-"`
 	Err        error     `json:"-"`
+	// Fields lists every invalid request field of a validation error
+	Fields []FieldError `json:"fields,omitempty"`
+	// Context carries structured values, such as the ID of the affected resource, for logging
+	Context map[string]interface{} `json:"-"`
+	stack   []uintptr
+}
+
+// FieldError describes the problem with one request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors collects the problems of a request so they can be reported at once
+type ValidationErrors struct {
+	fields []FieldError
+}
+
+// Add records a problem with a field, named as in the request body
+func (v *ValidationErrors) Add(field, message string) {
+	v.fields = append(v.fields, FieldError{Field: field, Message: message})
+}
+
+// Err returns a validation error listing every recorded problem, or nil when there are none
+func (v *ValidationErrors) Err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+
+	problems := make([]string, len(v.fields))
+	for i, f := range v.fields {
+		problems[i] = f.Field + " " + f.Message
+	}
+
+	appErr := newAppError(ErrorTypeValidation, "Invalid request", strings.Join(problems, "; "), http.StatusBadRequest, nil)
+	appErr.Fields = v.fields
+	return appErr
+}
+
+// newAppError creates an error and records the stack of the caller of the constructor
+func newAppError(errorType ErrorType, message, details string, statusCode int, err error) *AppError {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	return &AppError{
+		Type:       errorType,
+		Message:    message,
+		Details:    details,
+		StatusCode: statusCode,
+		Err:        err,
+		stack:      pcs[:n],
+	}
+}
+
+// WithContext adds a structured value to the error and returns it for chaining
+func (e *AppError) WithContext(key string, value interface{}) *AppError {
+	if e.Context == nil {
+		e.Context = make(map[string]interface{})
+	}
+	e.Context[key] = value
+	return e
+}
+
+// LogFields flattens the error into structured log fields: its context entries plus
+// type, message, code, details, invalid fields, cause and the stack where it was created
+func (e *AppError) LogFields() logger.Fields {
+	fields := make(logger.Fields, len(e.Context)+7)
+	for k, v := range e.Context {
+		fields[k] = v
+	}
+
+	fields["type"] = string(e.Type)
+	fields["message"] = e.Message
+	fields["code"] = e.StatusCode
+	if e.Details != "" {
+		fields["details"] = e.Details
+	}
+	if len(e.Fields) > 0 {
+		fields["fields"] = e.Fields
+	}
+	if e.Err != nil {
+		fields["error"] = e.Err.Error()
+	}
+	if stack := e.StackTrace(); stack != "" {
+		fields["stack"] = stack
+	}
+
+	return fields
+}
+
+// StackTrace formats the stack recorded when the error was created, one "function file:line" frame per line
+func (e *AppError) StackTrace() string {
+	if len(e.stack) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	frames := runtime.CallersFrames(e.stack)
+	for {
+		frame, more := frames.Next()
+		fmt.Fprintf(&b, "%s %s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n")
 }
 
 // Error implements the error interface
@@ -41,76 +157,83 @@ func (e *AppError) Unwrap() error {
 
 // NewValidationError creates a validation error
 func NewValidationError(message string, details string) *AppError {
-	return &AppError{
-		Type:       ErrorTypeValidation,
-		Message:    message,
-		Details:    details,
-		StatusCode: http.StatusBadRequest,
-	}
+	return newAppError(ErrorTypeValidation, message, details, http.StatusBadRequest, nil)
 }
 
 // NewNotFoundError creates a not found error
 func NewNotFoundError(resource string, id string) *AppError {
-	return &AppError{
-		Type:       ErrorTypeNotFound,
-		Message:    fmt.Sprintf("%s not found", resource),
-		Details:    fmt.Sprintf("ID: %s", id),
-		StatusCode: http.StatusNotFound,
-	}
+	return newAppError(ErrorTypeNotFound, fmt.Sprintf("%s not found", resource), fmt.Sprintf("ID: %s", id), http.StatusNotFound, nil)
 }
 
 // NewConflictError creates a conflict error
 func NewConflictError(message string, details string) *AppError {
-	return &AppError{
-		Type:       ErrorTypeConflict,
-		Message:    message,
-		Details:    details,
-		StatusCode: http.StatusConflict,
-	}
+	return newAppError(ErrorTypeConflict, message, details, http.StatusConflict, nil)
 }
 
 // NewInternalError creates an internal error
 func NewInternalError(message string, err error) *AppError {
-	return &AppError{
-		Type:       ErrorTypeInternal,
-		Message:    message,
-		StatusCode: http.StatusInternalServerError,
-		Err:        err,
-	}
+	return newAppError(ErrorTypeInternal, message, "", http.StatusInternalServerError, err)
 }
 
 // NewUnauthorizedError creates an unauthorized error
 func NewUnauthorizedError(message string) *AppError {
-	return &AppError{
-		Type:       ErrorTypeUnauthorized,
-		Message:    message,
-		StatusCode: http.StatusUnauthorized,
-	}
+	return newAppError(ErrorTypeUnauthorized, message, "", http.StatusUnauthorized, nil)
 }
 
 // NewForbiddenError creates a forbidden error
 func NewForbiddenError(message string) *AppError {
-	return &AppError{
-		Type:       ErrorTypeForbidden,
-		Message:    message,
-		StatusCode: http.StatusForbidden,
-	}
+	return newAppError(ErrorTypeForbidden, message, "", http.StatusForbidden, nil)
+}
+
+// NewTimeoutError creates an error for a request that ran past its deadline
+func NewTimeoutError(message string, details string) *AppError {
+	return newAppError(ErrorTypeTimeout, message, details, http.StatusServiceUnavailable, nil)
+}
+
+// NewRateLimitError creates an error for a client that sent too many requests
+func NewRateLimitError(message string, details string) *AppError {
+	return newAppError(ErrorTypeRateLimited, message, details, http.StatusTooManyRequests, nil)
+}
+
+// NewUnsupportedMediaTypeError creates an error for a request body in an unsupported format
+func NewUnsupportedMediaTypeError(message string, details string) *AppError {
+	return newAppError(ErrorTypeUnsupported, message, details, http.StatusUnsupportedMediaType, nil)
+}
+
+// NewUnprocessableError creates an error for a well-formed request that cannot be carried out
+func NewUnprocessableError(message string, details string) *AppError {
+	return newAppError(ErrorTypeUnprocessable, message, details, http.StatusUnprocessableEntity, nil)
+}
+
+// NewUnavailableError creates an error for a feature or dependency that is not available
+func NewUnavailableError(message string) *AppError {
+	return newAppError(ErrorTypeUnavailable, message, "", http.StatusServiceUnavailable, nil)
+}
+
+// NewUpstreamError creates an error for a failed call to an external service
+func NewUpstreamError(message string, err error) *AppError {
+	return newAppError(ErrorTypeUpstream, message, "", http.StatusBadGateway, err)
 }
 
 // WrapError wraps an existing error with additional context
 func WrapError(err error, message string) *AppError {
 	if appErr, ok := err.(*AppError); ok {
-		// If it's already an AppError, just update the message
+		// If it's already an AppError, just update the message; its context is kept
 		appErr.Message = fmt.Sprintf("%s: %s", message, appErr.Message)
 		return appErr
 	}
 
-	return &AppError{
-		Type:       ErrorTypeInternal,
-		Message:    message,
-		StatusCode: http.StatusInternalServerError,
-		Err:        err,
+	wrapped := newAppError(ErrorTypeInternal, message, "", http.StatusInternalServerError, err)
+
+	// An AppError further down the chain keeps its context
+	var inner *AppError
+	if stderrors.As(err, &inner) {
+		for k, v := range inner.Context {
+			wrapped.WithContext(k, v)
+		}
 	}
+
+	return wrapped
 }
 
 // IsNotFound checks if an error is a not found error
@@ -143,4 +266,4 @@ func GetStatusCode(err error) int {
 		return appErr.StatusCode
 	}
 	return http.StatusInternalServerError
-} 
\ No newline at end of file
+}
@@ -0,0 +1,93 @@
+package errors
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLogFieldsIncludesTypeAndContext(t *testing.T) {
+	fields := NewNotFoundError("event", "abc").WithContext("event_id", "abc").LogFields()
+
+	if fields["type"] != string(ErrorTypeNotFound) {
+		t.Errorf("type = %v, want %s", fields["type"], ErrorTypeNotFound)
+	}
+	if fields["event_id"] != "abc" {
+		t.Errorf("event_id = %v, want abc", fields["event_id"])
+	}
+	if fields["message"] != "event not found" || fields["code"] != http.StatusNotFound || fields["details"] != "ID: abc" {
+		t.Errorf("fields = %v, want message, code and details", fields)
+	}
+	stack, _ := fields["stack"].(string)
+	if !strings.Contains(stack, "TestLogFieldsIncludesTypeAndContext") {
+		t.Errorf("stack = %q, want it to start at the caller of the constructor", stack)
+	}
+	if strings.Contains(stack, "newAppError") {
+		t.Errorf("stack = %q, want the constructor frames left out", stack)
+	}
+}
+
+func TestLogFieldsIncludesCauseAndInvalidFields(t *testing.T) {
+	fields := NewInternalError("failed to store event", fmt.Errorf("connection refused")).LogFields()
+	if fields["error"] != "connection refused" {
+		t.Errorf("error = %v, want the cause", fields["error"])
+	}
+
+	var problems ValidationErrors
+	problems.Add("severity", "is required")
+	validation := problems.Err().(*AppError)
+	fieldErrors, _ := validation.LogFields()["fields"].([]FieldError)
+	if len(fieldErrors) != 1 || fieldErrors[0].Field != "severity" {
+		t.Errorf("fields = %v, want the severity problem", fieldErrors)
+	}
+}
+
+func TestWrapErrorKeepsAppErrorContext(t *testing.T) {
+	original := NewNotFoundError("event", "abc").WithContext("event_id", "abc")
+
+	wrapped := WrapError(original, "failed to acknowledge event")
+	if wrapped.Type != ErrorTypeNotFound || wrapped.Context["event_id"] != "abc" {
+		t.Errorf("wrapped = %+v, want the not found error with its context", wrapped)
+	}
+	if wrapped.Message != "failed to acknowledge event: event not found" {
+		t.Errorf("message = %q, want the wrapping message prefixed", wrapped.Message)
+	}
+}
+
+func TestWrapErrorMergesContextFromChain(t *testing.T) {
+	inner := NewConflictError("event already acknowledged", "").WithContext("event_id", "abc")
+	chained := fmt.Errorf("repository: %w", inner)
+
+	wrapped := WrapError(chained, "failed to acknowledge event").WithContext("user", "analyst")
+	if wrapped.Type != ErrorTypeInternal {
+		t.Errorf("type = %s, want INTERNAL_ERROR for a non-AppError", wrapped.Type)
+	}
+	if wrapped.Context["event_id"] != "abc" || wrapped.Context["user"] != "analyst" {
+		t.Errorf("context = %v, want event_id from the chain and the new user", wrapped.Context)
+	}
+	if inner.Context["user"] != nil {
+		t.Error("adding context to the wrapper changed the inner error")
+	}
+	if !stderrors.Is(wrapped, inner) {
+		t.Error("wrapped error does not unwrap to the inner error")
+	}
+}
+
+func TestValidationErrorsErr(t *testing.T) {
+	var problems ValidationErrors
+	if err := problems.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil without problems", err)
+	}
+
+	problems.Add("event_type", "is required")
+	problems.Add("severity", "must be one of low medium high critical")
+	appErr, ok := problems.Err().(*AppError)
+	if !ok {
+		t.Fatalf("Err() returned %T, want *AppError", problems.Err())
+	}
+	if appErr.StatusCode != http.StatusBadRequest || appErr.Details != "event_type is required; severity must be one of low medium high critical" {
+		t.Errorf("error = %+v, want a 400 listing both problems", appErr)
+	}
+}
@@ -0,0 +1,117 @@
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// CEF header values identifying this service
+const (
+	cefVersion       = 0
+	cefDeviceVendor  = "Skyhawk"
+	cefDeviceProduct = "SecurityMicroservice"
+	cefDeviceVersion = "1.0"
+)
+
+// CEFSeverity maps an event severity to the CEF numeric severity (0-10)
+func CEFSeverity(severity string) int {
+	switch strings.ToLower(severity) {
+	case models.SeverityCritical:
+		return 10
+	case models.SeverityHigh:
+		return 7
+	case models.SeverityMedium:
+		return 5
+	case models.SeverityLow:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// cefEventDataKeys maps well-known event_data keys to CEF extension keys
+var cefEventDataKeys = map[string]string{
+	"ip":        "src",
+	"source_ip": "src",
+	"dest_ip":   "dst",
+	"user":      "suser",
+	"file":      "fname",
+	"host":      "shost",
+}
+
+// ToCEF formats an event as a single CEF line:
+// CEF:Version|Device Vendor|Device Product|Device Version|Signature ID|Name|Severity|Extension
+func ToCEF(event *models.Event) string {
+	name := event.Description
+	if name == "" {
+		name = event.EventType
+	}
+
+	header := strings.Join([]string{
+		fmt.Sprintf("CEF:%d", cefVersion),
+		escapeHeader(cefDeviceVendor),
+		escapeHeader(cefDeviceProduct),
+		escapeHeader(cefDeviceVersion),
+		escapeHeader(event.EventType),
+		escapeHeader(name),
+		strconv.Itoa(CEFSeverity(event.Severity)),
+	}, "|")
+
+	return header + "|" + cefExtension(event)
+}
+
+// cefExtension builds the space separated key=value extension for an event
+func cefExtension(event *models.Event) string {
+	ext := []string{
+		"externalId=" + escapeExtension(event.EventID),
+		"cat=" + escapeExtension(event.EventType),
+		"dvchost=" + escapeExtension(event.Source),
+	}
+	if !event.CreatedAt.IsZero() {
+		ext = append(ext, "rt="+strconv.FormatInt(event.CreatedAt.UnixMilli(), 10))
+	}
+	if event.Description != "" {
+		ext = append(ext, "msg="+escapeExtension(event.Description))
+	}
+
+	// Sort keys so the output is deterministic
+	keys := make([]string, 0, len(event.EventData))
+	for key := range event.EventData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		cefKey, ok := cefEventDataKeys[key]
+		if !ok || seen[cefKey] {
+			continue
+		}
+		seen[cefKey] = true
+		ext = append(ext, cefKey+"="+escapeExtension(fmt.Sprint(event.EventData[key])))
+	}
+
+	return strings.Join(ext, " ")
+}
+
+// escapeHeader escapes backslashes and pipes in CEF header fields
+func escapeHeader(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "|", `\|`)
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ").Replace(value)
+}
+
+// escapeExtension escapes backslashes, equals signs and newlines in CEF extension values
+func escapeExtension(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		"=", `\=`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\r`,
+	).Replace(value)
+}
@@ -0,0 +1,187 @@
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// cefEventDataFields maps CEF extension keys back to the event_data keys ToCEF writes them from
+var cefEventDataFields = map[string]string{
+	"src":   "source_ip",
+	"dst":   "dest_ip",
+	"suser": "user",
+	"fname": "file",
+	"shost": "host",
+}
+
+// ParseCEF parses a CEF line into an event creation request. Text before "CEF:", such as a
+// syslog prefix, is ignored. The signature ID becomes the event type, the CEF severity is
+// mapped to low, medium, high or critical and the reporting device (dvchost, dvc or the
+// device product) becomes the source. The device fields and extension are kept in event_data.
+func ParseCEF(body []byte) (*models.CreateEventRequest, error) {
+	line := strings.TrimSpace(string(body))
+	start := strings.Index(line, "CEF:")
+	if start < 0 {
+		return nil, fmt.Errorf("not a CEF message: missing CEF: header")
+	}
+
+	header, extension, err := splitCEFHeader(line[start:])
+	if err != nil {
+		return nil, err
+	}
+	ext := parseCEFExtension(extension)
+
+	req := &models.CreateEventRequest{
+		EventType:   firstNonEmpty(header[4], ext["cat"]),
+		Severity:    severityFromCEF(header[6]),
+		Source:      firstNonEmpty(ext["dvchost"], ext["dvc"], header[2]),
+		Description: firstNonEmpty(ext["msg"], header[5]),
+		EventData: models.EventData{
+			"cef_version":    strings.TrimPrefix(header[0], "CEF:"),
+			"device_vendor":  header[1],
+			"device_product": header[2],
+			"device_version": header[3],
+		},
+	}
+	for key, value := range ext {
+		if key == "msg" || key == "dvchost" {
+			continue
+		}
+		if name, ok := cefEventDataFields[key]; ok {
+			key = name
+		}
+		req.EventData[key] = value
+	}
+
+	return req, nil
+}
+
+// splitCEFHeader splits a CEF line into its seven unescaped header fields and the extension
+func splitCEFHeader(line string) ([]string, string, error) {
+	fields := make([]string, 0, 7)
+	var field strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && i+1 < len(line) && (line[i+1] == '|' || line[i+1] == '\\'):
+			i++
+			field.WriteByte(line[i])
+		case c == '|':
+			fields = append(fields, strings.TrimSpace(field.String()))
+			field.Reset()
+			if len(fields) == 7 {
+				return fields, line[i+1:], nil
+			}
+		default:
+			field.WriteByte(c)
+		}
+	}
+	return nil, "", fmt.Errorf("CEF header has %d fields, expected 7", len(fields)+1)
+}
+
+// parseCEFExtension parses the space separated key=value pairs of a CEF extension. Values may
+// contain spaces; a value ends where the next key starts.
+func parseCEFExtension(extension string) map[string]string {
+	type pair struct {
+		key        string
+		keyStart   int
+		valueStart int
+	}
+
+	var pairs []pair
+	for i := 0; i < len(extension); i++ {
+		if extension[i] == '\\' {
+			i++
+			continue
+		}
+		if extension[i] != '=' {
+			continue
+		}
+		start := i
+		for start > 0 && isCEFKeyChar(extension[start-1]) {
+			start--
+		}
+		// Keys start the extension or follow a space; anything else is part of a value
+		if start == i || (start > 0 && extension[start-1] != ' ') {
+			continue
+		}
+		pairs = append(pairs, pair{key: extension[start:i], keyStart: start, valueStart: i + 1})
+	}
+
+	ext := make(map[string]string, len(pairs))
+	for n, p := range pairs {
+		end := len(extension)
+		if n+1 < len(pairs) {
+			end = pairs[n+1].keyStart
+		}
+		ext[p.key] = unescapeCEFExtension(strings.TrimRight(extension[p.valueStart:end], " "))
+	}
+	return ext
+}
+
+// isCEFKeyChar reports whether c may appear in a CEF extension key
+func isCEFKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '-' || c == '[' || c == ']'
+}
+
+// unescapeCEFExtension reverses escapeExtension
+func unescapeCEFExtension(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+// severityFromCEF maps a numeric (0-10) or named CEF severity to an event severity. Values it
+// does not recognize are returned unchanged for the severity normalizer to handle.
+func severityFromCEF(severity string) string {
+	if n, err := strconv.Atoi(severity); err == nil {
+		switch {
+		case n >= 9:
+			return models.SeverityCritical
+		case n >= 7:
+			return models.SeverityHigh
+		case n >= 4:
+			return models.SeverityMedium
+		default:
+			return models.SeverityLow
+		}
+	}
+
+	switch strings.ToLower(severity) {
+	case "very-high", "very high":
+		return models.SeverityCritical
+	case "unknown":
+		return ""
+	}
+	return strings.ToLower(severity)
+}
+
+// firstNonEmpty returns the first of values that is not blank
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if strings.TrimSpace(value) != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,283 @@
+package format
+
+import (
+	"bufio"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// cefExtensionKey matches the key=value start of a CEF extension pair
+var cefExtensionKey = regexp.MustCompile(`^([A-Za-z0-9_.\[\]-]+)=`)
+
+// validateCEF checks that line is a well-formed CEF line and returns its unescaped header
+// fields and the raw extension values by key
+func validateCEF(t *testing.T, line string) ([]string, map[string]string) {
+	t.Helper()
+
+	if strings.ContainsAny(line, "\r\n") {
+		t.Fatalf("CEF line contains a line break: %q", line)
+	}
+	if !strings.HasPrefix(line, "CEF:") {
+		t.Fatalf("CEF line %q does not start with CEF:", line)
+	}
+
+	var header []string
+	var field strings.Builder
+	rest := ""
+	for i := 0; i < len(line) && len(header) < 7; i++ {
+		switch line[i] {
+		case '\\':
+			i++
+			if i == len(line) || (line[i] != '|' && line[i] != '\\') {
+				t.Fatalf("invalid escape in CEF header of %q", line)
+			}
+			field.WriteByte(line[i])
+		case '|':
+			header = append(header, field.String())
+			field.Reset()
+			if len(header) == 7 {
+				rest = line[i+1:]
+			}
+		default:
+			field.WriteByte(line[i])
+		}
+	}
+	if len(header) != 7 {
+		t.Fatalf("CEF line %q has %d header fields, want 7", line, len(header))
+	}
+	if header[0] != "CEF:0" {
+		t.Errorf("version = %q, want CEF:0", header[0])
+	}
+	for i, name := range []string{"version", "device vendor", "device product", "device version", "signature ID", "name", "severity"} {
+		if header[i] == "" {
+			t.Errorf("required header field %s is empty", name)
+		}
+	}
+	if severity, err := strconv.Atoi(header[6]); err != nil || severity < 0 || severity > 10 {
+		t.Errorf("severity = %q, want an integer from 0 to 10", header[6])
+	}
+
+	// Values may contain spaces, so a space separated token that does not start a new
+	// key=value pair continues the previous value
+	extension := make(map[string]string)
+	key := ""
+	for _, token := range strings.Split(rest, " ") {
+		if match := cefExtensionKey.FindStringSubmatch(token); match != nil {
+			key = match[1]
+			if _, ok := extension[key]; ok {
+				t.Errorf("duplicate extension key %s in %q", key, rest)
+			}
+			extension[key] = token[len(match[0]):]
+		} else if key == "" {
+			t.Fatalf("extension %q does not start with a key=value pair", rest)
+		} else {
+			extension[key] += " " + token
+		}
+		if hasUnescapedEquals(extension[key]) {
+			t.Errorf("extension value %q has an unescaped equals sign", extension[key])
+		}
+	}
+	return header, extension
+}
+
+// hasUnescapedEquals reports whether value contains an equals sign not preceded by a backslash escape
+func hasUnescapedEquals(value string) bool {
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			i++
+		case '=':
+			return true
+		}
+	}
+	return false
+}
+
+func TestToCEF(t *testing.T) {
+	event := &models.Event{
+		EventID:     "evt-1",
+		EventType:   "login_failure",
+		Severity:    models.SeverityHigh,
+		Source:      "auth-service",
+		Description: "5 failed logins",
+		EventData:   models.EventData{"ip": "10.0.0.1", "user": "admin", "attempts": 5},
+		CreatedAt:   time.UnixMilli(1700000000000),
+	}
+
+	header, extension := validateCEF(t, ToCEF(event))
+
+	wantHeader := []string{"CEF:0", "Skyhawk", "SecurityMicroservice", "1.0", "login_failure", "5 failed logins", "7"}
+	for i, want := range wantHeader {
+		if header[i] != want {
+			t.Errorf("header field %d = %q, want %q", i, header[i], want)
+		}
+	}
+	wantExtension := map[string]string{
+		"externalId": "evt-1",
+		"cat":        "login_failure",
+		"dvchost":    "auth-service",
+		"rt":         "1700000000000",
+		"msg":        "5 failed logins",
+		"src":        "10.0.0.1",
+		"suser":      "admin",
+	}
+	for key, want := range wantExtension {
+		if extension[key] != want {
+			t.Errorf("extension %s = %q, want %q", key, extension[key], want)
+		}
+	}
+	if len(extension) != len(wantExtension) {
+		t.Errorf("extension = %v, want only the mapped keys %v", extension, wantExtension)
+	}
+}
+
+func TestToCEFEscapesSpecialCharacters(t *testing.T) {
+	event := &models.Event{
+		EventID:     "evt-1",
+		EventType:   "cmd|exec",
+		Severity:    models.SeverityCritical,
+		Source:      `host\a`,
+		Description: "ran a=b\nthen |c",
+	}
+
+	header, extension := validateCEF(t, ToCEF(event))
+
+	if header[4] != "cmd|exec" {
+		t.Errorf("signature ID = %q, want the pipe preserved", header[4])
+	}
+	if header[5] != "ran a=b then |c" {
+		t.Errorf("name = %q, want the newline folded and the pipe preserved", header[5])
+	}
+	if header[6] != "10" {
+		t.Errorf("severity = %q, want 10", header[6])
+	}
+	if extension["msg"] != `ran a\=b\nthen |c` {
+		t.Errorf("msg = %q, want equals and newline escaped", extension["msg"])
+	}
+	if extension["dvchost"] != `host\\a` {
+		t.Errorf("dvchost = %q, want the backslash escaped", extension["dvchost"])
+	}
+}
+
+func TestToCEFFallsBackToEventTypeName(t *testing.T) {
+	header, extension := validateCEF(t, ToCEF(&models.Event{EventID: "evt-1", EventType: "port_scan", Source: "ids"}))
+
+	if header[5] != "port_scan" {
+		t.Errorf("name = %q, want the event type without a description", header[5])
+	}
+	if _, ok := extension["msg"]; ok {
+		t.Error("extension has msg without a description")
+	}
+	if _, ok := extension["rt"]; ok {
+		t.Error("extension has rt without a creation time")
+	}
+}
+
+func TestCEFSeverity(t *testing.T) {
+	tests := map[string]int{
+		models.SeverityCritical: 10,
+		models.SeverityHigh:     7,
+		models.SeverityMedium:   5,
+		models.SeverityLow:      3,
+		"info":                  1,
+		"CRITICAL":              10,
+	}
+	for severity, want := range tests {
+		if got := CEFSeverity(severity); got != want {
+			t.Errorf("CEFSeverity(%q) = %d, want %d", severity, got, want)
+		}
+	}
+}
+
+func TestSyslogSenderSendsCEFOverUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer conn.Close()
+
+	host, port, _ := net.SplitHostPort(conn.LocalAddr().String())
+	sender, err := NewSyslogSender("udp", host, port)
+	if err != nil {
+		t.Fatalf("NewSyslogSender: %v", err)
+	}
+	defer sender.Close()
+
+	cef := ToCEF(&models.Event{EventID: "evt-1", EventType: "port_scan", Severity: models.SeverityLow, Source: "ids"})
+	if err := sender.Send(cef); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	line := strings.TrimSuffix(string(buf[:n]), "\n")
+	if !strings.HasPrefix(line, "<165>1 ") {
+		t.Errorf("syslog line %q does not start with the local4.notice priority", line)
+	}
+	if !strings.HasSuffix(line, " skyhawk - - - "+cef) {
+		t.Errorf("syslog line %q does not carry the CEF message", line)
+	}
+}
+
+func TestSyslogSenderSendsCEFOverTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	lines := make(chan string, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	sender, err := NewSyslogSender("tcp", host, port)
+	if err != nil {
+		t.Fatalf("NewSyslogSender: %v", err)
+	}
+	defer sender.Close()
+
+	for _, message := range []string{"first", "second"} {
+		if err := sender.Send(message); err != nil {
+			t.Fatalf("Send(%s): %v", message, err)
+		}
+	}
+	for _, want := range []string{"first", "second"} {
+		select {
+		case line := <-lines:
+			if !strings.HasSuffix(line, " "+want) {
+				t.Errorf("syslog line %q, want message %q", line, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %q", want)
+		}
+	}
+}
+
+func TestNewSyslogSenderValidatesConfig(t *testing.T) {
+	if _, err := NewSyslogSender("unix", "localhost", "514"); err == nil {
+		t.Error("NewSyslogSender accepted an unsupported network")
+	}
+	if _, err := NewSyslogSender("udp", "", "514"); err == nil {
+		t.Error("NewSyslogSender accepted an empty host")
+	}
+}
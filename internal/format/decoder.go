@@ -0,0 +1,74 @@
+package format
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// Media types accepted by POST /api/v1/events besides JSON
+const (
+	CEFMediaType    = "text/plain"
+	SyslogMediaType = "application/syslog"
+)
+
+// Decoder parses a request body in a non-JSON format into an event creation request
+type Decoder interface {
+	Decode(body []byte) (*models.CreateEventRequest, error)
+}
+
+// DecoderFunc adapts a function to the Decoder interface
+type DecoderFunc func(body []byte) (*models.CreateEventRequest, error)
+
+// Decode calls f(body)
+func (f DecoderFunc) Decode(body []byte) (*models.CreateEventRequest, error) {
+	return f(body)
+}
+
+// DecoderRegistry selects the decoder of a request body by its media type
+type DecoderRegistry struct {
+	mu       sync.RWMutex
+	decoders map[string]Decoder
+}
+
+// NewDecoderRegistry creates a registry with the CEF and RFC 5424 syslog decoders
+func NewDecoderRegistry() *DecoderRegistry {
+	r := &DecoderRegistry{decoders: make(map[string]Decoder)}
+	r.Register(CEFMediaType, DecoderFunc(ParseCEF))
+	r.Register(SyslogMediaType, DecoderFunc(ParseSyslog))
+	return r
+}
+
+// Register sets the decoder of a media type, replacing any previous one
+func (r *DecoderRegistry) Register(mediaType string, decoder Decoder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.decoders[strings.ToLower(mediaType)] = decoder
+}
+
+// Lookup returns the decoder of a media type, ignoring case and parameters such as charset
+func (r *DecoderRegistry) Lookup(mediaType string) (Decoder, bool) {
+	if i := strings.IndexByte(mediaType, ';'); i >= 0 {
+		mediaType = mediaType[:i]
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	decoder, ok := r.decoders[strings.ToLower(strings.TrimSpace(mediaType))]
+	return decoder, ok
+}
+
+// MediaTypes returns the registered media types, sorted
+func (r *DecoderRegistry) MediaTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := make([]string, 0, len(r.decoders))
+	for mediaType := range r.decoders {
+		types = append(types, mediaType)
+	}
+	sort.Strings(types)
+	return types
+}
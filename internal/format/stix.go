@@ -0,0 +1,322 @@
+package format
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// STIXContentType is the media type of STIX 2.1 documents
+const STIXContentType = "application/stix+json;version=2.1"
+
+const stixSpecVersion = "2.1"
+
+// stixNamespace is the UUIDv5 namespace for the deterministic IDs of objects created
+// by this service, so exporting the same event twice yields the same IDs
+var stixNamespace = [16]byte{0x6a, 0x3e, 0x41, 0x5c, 0x1f, 0x0b, 0x4d, 0x2e, 0x9a, 0x57, 0x83, 0xc1, 0x64, 0x2f, 0x0d, 0xb8}
+
+// stixSCONamespace is the namespace STIX 2.1 defines for cyber-observable object IDs
+var stixSCONamespace = [16]byte{0x00, 0xab, 0xed, 0xb4, 0xaa, 0x42, 0x46, 0x6c, 0x9c, 0x01, 0xfe, 0xd2, 0x33, 0x15, 0xa9, 0xb7}
+
+// STIXBundle is a STIX 2.1 bundle
+type STIXBundle struct {
+	Type    string        `json:"type"`
+	ID      string        `json:"id"`
+	Objects []interface{} `json:"objects"`
+}
+
+// STIXExternalReference points to an external source such as a MITRE ATT&CK technique
+type STIXExternalReference struct {
+	SourceName string `json:"source_name"`
+	ExternalID string `json:"external_id,omitempty"`
+	URL        string `json:"url,omitempty"`
+}
+
+// STIXThreatActor is a STIX threat-actor domain object
+type STIXThreatActor struct {
+	Type             string   `json:"type"`
+	SpecVersion      string   `json:"spec_version"`
+	ID               string   `json:"id"`
+	Created          string   `json:"created"`
+	Modified         string   `json:"modified"`
+	Name             string   `json:"name"`
+	ThreatActorTypes []string `json:"threat_actor_types"`
+}
+
+// STIXIndicator is a STIX indicator domain object
+type STIXIndicator struct {
+	Type               string                  `json:"type"`
+	SpecVersion        string                  `json:"spec_version"`
+	ID                 string                  `json:"id"`
+	Created            string                  `json:"created"`
+	Modified           string                  `json:"modified"`
+	Name               string                  `json:"name"`
+	Description        string                  `json:"description,omitempty"`
+	IndicatorTypes     []string                `json:"indicator_types"`
+	Pattern            string                  `json:"pattern"`
+	PatternType        string                  `json:"pattern_type"`
+	ValidFrom          string                  `json:"valid_from"`
+	Labels             []string                `json:"labels,omitempty"`
+	ExternalReferences []STIXExternalReference `json:"external_references,omitempty"`
+}
+
+// STIXObservedData is a STIX observed-data domain object
+type STIXObservedData struct {
+	Type           string   `json:"type"`
+	SpecVersion    string   `json:"spec_version"`
+	ID             string   `json:"id"`
+	Created        string   `json:"created"`
+	Modified       string   `json:"modified"`
+	FirstObserved  string   `json:"first_observed"`
+	LastObserved   string   `json:"last_observed"`
+	NumberObserved int      `json:"number_observed"`
+	ObjectRefs     []string `json:"object_refs"`
+}
+
+// STIXRelationship is a STIX relationship object
+type STIXRelationship struct {
+	Type             string `json:"type"`
+	SpecVersion      string `json:"spec_version"`
+	ID               string `json:"id"`
+	Created          string `json:"created"`
+	Modified         string `json:"modified"`
+	RelationshipType string `json:"relationship_type"`
+	SourceRef        string `json:"source_ref"`
+	TargetRef        string `json:"target_ref"`
+}
+
+// STIXIPv4Address is a STIX ipv4-addr cyber-observable object
+type STIXIPv4Address struct {
+	Type        string `json:"type"`
+	SpecVersion string `json:"spec_version"`
+	ID          string `json:"id"`
+	Value       string `json:"value"`
+}
+
+// STIXEventObservable is a custom cyber-observable recording the event itself when it
+// carries no standard observable such as an IP address
+type STIXEventObservable struct {
+	Type        string `json:"type"`
+	SpecVersion string `json:"spec_version"`
+	ID          string `json:"id"`
+	EventID     string `json:"event_id"`
+	EventType   string `json:"event_type"`
+	Source      string `json:"source"`
+	Severity    string `json:"severity"`
+}
+
+// ToSTIX converts a single event into a STIX bundle
+func ToSTIX(event *models.Event) *STIXBundle {
+	return ToSTIXBundle([]*models.Event{event})
+}
+
+// ToSTIXBundle converts events into one STIX bundle. Each event yields an indicator and
+// observed-data; each event source yields one threat-actor and each source IP one ipv4-addr.
+// When a correlation ID groups several events, their indicators are linked to the
+// threat-actor with "indicates" relationships.
+func ToSTIXBundle(events []*models.Event) *STIXBundle {
+	bundle := &STIXBundle{
+		Type:    "bundle",
+		ID:      "bundle--" + newRandomUUID(),
+		Objects: []interface{}{},
+	}
+
+	actors := make(map[string]string)
+	observables := make(map[string]bool)
+	correlated := make(map[string]int)
+	for _, event := range events {
+		if event.CorrelationID != "" {
+			correlated[event.CorrelationID]++
+		}
+	}
+
+	for _, event := range events {
+		timestamp := stixTime(event.CreatedAt)
+
+		actorID, ok := actors[event.Source]
+		if !ok {
+			actorID = stixID("threat-actor", "source:"+event.Source)
+			actors[event.Source] = actorID
+			bundle.Objects = append(bundle.Objects, &STIXThreatActor{
+				Type:             "threat-actor",
+				SpecVersion:      stixSpecVersion,
+				ID:               actorID,
+				Created:          timestamp,
+				Modified:         timestamp,
+				Name:             event.Source,
+				ThreatActorTypes: []string{"unknown"},
+			})
+		}
+
+		observable, pattern := stixObservable(event)
+		indicator := newSTIXIndicator(event, pattern, timestamp)
+		bundle.Objects = append(bundle.Objects, indicator)
+		// Events from the same IP share one ipv4-addr object
+		if id := observableID(observable); !observables[id] {
+			observables[id] = true
+			bundle.Objects = append(bundle.Objects, observable)
+		}
+
+		bundle.Objects = append(bundle.Objects, &STIXObservedData{
+			Type:           "observed-data",
+			SpecVersion:    stixSpecVersion,
+			ID:             stixID("observed-data", "event:"+event.EventID),
+			Created:        timestamp,
+			Modified:       timestamp,
+			FirstObserved:  timestamp,
+			LastObserved:   timestamp,
+			NumberObserved: 1,
+			ObjectRefs:     []string{observableID(observable)},
+		})
+
+		if correlated[event.CorrelationID] > 1 {
+			bundle.Objects = append(bundle.Objects, &STIXRelationship{
+				Type:             "relationship",
+				SpecVersion:      stixSpecVersion,
+				ID:               stixID("relationship", "indicates:"+indicator.ID+":"+actorID),
+				Created:          timestamp,
+				Modified:         timestamp,
+				RelationshipType: "indicates",
+				SourceRef:        indicator.ID,
+				TargetRef:        actorID,
+			})
+		}
+	}
+
+	return bundle
+}
+
+// newSTIXIndicator builds the indicator for an event
+func newSTIXIndicator(event *models.Event, pattern, timestamp string) *STIXIndicator {
+	name := event.Description
+	if name == "" {
+		name = event.EventType + " event from " + event.Source
+	}
+
+	indicatorType := "anomalous-activity"
+	if models.SeverityLevel(event.Severity) >= models.SeverityLevel(models.SeverityHigh) {
+		indicatorType = "malicious-activity"
+	}
+
+	indicator := &STIXIndicator{
+		Type:           "indicator",
+		SpecVersion:    stixSpecVersion,
+		ID:             stixID("indicator", "event:"+event.EventID),
+		Created:        timestamp,
+		Modified:       timestamp,
+		Name:           name,
+		Description:    event.Description,
+		IndicatorTypes: []string{indicatorType},
+		Pattern:        pattern,
+		PatternType:    "stix",
+		ValidFrom:      timestamp,
+		Labels:         []string{strings.ToLower(event.Severity), event.EventType},
+	}
+	for _, technique := range event.ATTACKTechniques {
+		indicator.ExternalReferences = append(indicator.ExternalReferences, STIXExternalReference{
+			SourceName: "mitre-attack",
+			ExternalID: technique,
+			URL:        "https://attack.mitre.org/techniques/" + strings.ReplaceAll(technique, ".", "/") + "/",
+		})
+	}
+
+	return indicator
+}
+
+// stixObservable returns the observable for an event and the indicator pattern matching it.
+// The event's source IP is used when present.
+func stixObservable(event *models.Event) (interface{}, string) {
+	for _, key := range []string{"source_ip", "ip"} {
+		value, _ := event.EventData[key].(string)
+		if ip := net.ParseIP(value); ip != nil && ip.To4() != nil {
+			address := ip.String()
+			return &STIXIPv4Address{
+				Type:        "ipv4-addr",
+				SpecVersion: stixSpecVersion,
+				ID:          "ipv4-addr--" + uuid5(stixSCONamespace, fmt.Sprintf(`{"value":%q}`, address)),
+				Value:       address,
+			}, fmt.Sprintf("[ipv4-addr:value = '%s']", address)
+		}
+	}
+
+	return &STIXEventObservable{
+		Type:        "x-skyhawk-event",
+		SpecVersion: stixSpecVersion,
+		ID:          stixID("x-skyhawk-event", "event:"+event.EventID),
+		EventID:     event.EventID,
+		EventType:   event.EventType,
+		Source:      event.Source,
+		Severity:    event.Severity,
+	}, fmt.Sprintf("[x-skyhawk-event:event_type = '%s']", escapePattern(event.EventType))
+}
+
+// observableID returns the ID of an observable built by stixObservable
+func observableID(observable interface{}) string {
+	switch o := observable.(type) {
+	case *STIXIPv4Address:
+		return o.ID
+	case *STIXEventObservable:
+		return o.ID
+	default:
+		return ""
+	}
+}
+
+// MarshalSTIX encodes a bundle as indented JSON
+func MarshalSTIX(bundle *STIXBundle) ([]byte, error) {
+	return json.MarshalIndent(bundle, "", "  ")
+}
+
+// stixTime formats a timestamp as STIX requires: UTC with millisecond precision
+func stixTime(t time.Time) string {
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// stixID builds a deterministic STIX identifier for an object type and name
+func stixID(objectType, name string) string {
+	return objectType + "--" + uuid5(stixNamespace, objectType+":"+name)
+}
+
+// escapePattern escapes a string literal for use in a STIX pattern
+func escapePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+}
+
+// uuid5 computes an RFC 4122 version 5 (SHA-1, name based) UUID
+func uuid5(namespace [16]byte, name string) string {
+	hash := sha1.New()
+	hash.Write(namespace[:])
+	hash.Write([]byte(name))
+	sum := hash.Sum(nil)
+
+	var u [16]byte
+	copy(u[:], sum[:16])
+	u[6] = (u[6] & 0x0f) | 0x50 // version 5
+	u[8] = (u[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return formatUUID(u)
+}
+
+// newRandomUUID returns a version 4 UUID
+func newRandomUUID() string {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+	u[6] = (u[6] & 0x0f) | 0x40 // version 4
+	u[8] = (u[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return formatUUID(u)
+}
+
+// formatUUID renders a UUID in its canonical 8-4-4-4-12 form
+func formatUUID(u [16]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
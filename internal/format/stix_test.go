@@ -0,0 +1,261 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// stixIdentifier matches a STIX 2.1 identifier: object-type--UUID
+var stixIdentifier = regexp.MustCompile(`^([a-z0-9-]+)--[0-9a-f]{8}-[0-9a-f]{4}-[1-5][0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+// stixTimestamp matches a STIX 2.1 timestamp in UTC
+var stixTimestamp = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?Z$`)
+
+// stixRequiredProperties lists the properties STIX 2.1 requires for each object type used by the export
+var stixRequiredProperties = map[string][]string{
+	"indicator":       {"created", "modified", "pattern", "pattern_type", "valid_from"},
+	"threat-actor":    {"created", "modified", "name"},
+	"observed-data":   {"created", "modified", "first_observed", "last_observed", "number_observed", "object_refs"},
+	"relationship":    {"created", "modified", "relationship_type", "source_ref", "target_ref"},
+	"ipv4-addr":       {"value"},
+	"x-skyhawk-event": {},
+}
+
+// stixCyberObservables lists the object types used by the export that are cyber-observable
+// objects (SCOs). They carry no created or modified timestamps and have UUIDv5 identifiers
+// derived from their properties.
+var stixCyberObservables = map[string]bool{"ipv4-addr": true, "x-skyhawk-event": true}
+
+// validateSTIXBundle checks a bundle against the STIX 2.1 common and per-type property
+// rules and that every reference resolves within the bundle. It returns the objects by ID.
+func validateSTIXBundle(t *testing.T, bundle *STIXBundle) map[string]map[string]interface{} {
+	t.Helper()
+
+	data, err := MarshalSTIX(bundle)
+	if err != nil {
+		t.Fatalf("MarshalSTIX: %v", err)
+	}
+	var decoded struct {
+		Type    string                   `json:"type"`
+		ID      string                   `json:"id"`
+		Objects []map[string]interface{} `json:"objects"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("bundle is not valid JSON: %v", err)
+	}
+	if decoded.Type != "bundle" {
+		t.Errorf("bundle type = %q, want bundle", decoded.Type)
+	}
+	if match := stixIdentifier.FindStringSubmatch(decoded.ID); match == nil || match[1] != "bundle" {
+		t.Errorf("bundle id %q is not a bundle identifier", decoded.ID)
+	}
+
+	objects := make(map[string]map[string]interface{})
+	for _, object := range decoded.Objects {
+		objectType, _ := object["type"].(string)
+		id, _ := object["id"].(string)
+		required, known := stixRequiredProperties[objectType]
+		if !known {
+			t.Errorf("object %s has unexpected type %q", id, objectType)
+			continue
+		}
+		if match := stixIdentifier.FindStringSubmatch(id); match == nil || match[1] != objectType {
+			t.Errorf("object id %q is not a %s identifier", id, objectType)
+		}
+		if object["spec_version"] != "2.1" {
+			t.Errorf("object %s spec_version = %v, want 2.1", id, object["spec_version"])
+		}
+		if _, duplicate := objects[id]; duplicate {
+			t.Errorf("object id %s appears twice", id)
+		}
+		objects[id] = object
+
+		for _, property := range required {
+			if value, ok := object[property]; !ok || value == "" || value == nil {
+				t.Errorf("%s %s is missing required property %s", objectType, id, property)
+			}
+		}
+		for _, property := range []string{"created", "modified", "valid_from", "first_observed", "last_observed"} {
+			if value, ok := object[property].(string); ok && !stixTimestamp.MatchString(value) {
+				t.Errorf("%s %s has invalid %s timestamp %q", objectType, id, property, value)
+			}
+		}
+		// Timestamps of the same format compare in time order as strings
+		if created, _ := object["created"].(string); object["modified"] != nil && object["modified"].(string) < created {
+			t.Errorf("%s %s was modified before it was created", objectType, id)
+		}
+		if first, _ := object["first_observed"].(string); object["last_observed"] != nil && object["last_observed"].(string) < first {
+			t.Errorf("%s %s was last observed before it was first observed", objectType, id)
+		}
+		if count, ok := object["number_observed"].(float64); ok && (count < 1 || count > 999999999) {
+			t.Errorf("%s %s number_observed = %v, want between 1 and 999,999,999", objectType, id, count)
+		}
+
+		if stixCyberObservables[objectType] {
+			for _, property := range []string{"created", "modified"} {
+				if _, ok := object[property]; ok {
+					t.Errorf("cyber-observable %s %s has %s, which only domain objects carry", objectType, id, property)
+				}
+			}
+			if parsed, err := uuid.Parse(strings.TrimPrefix(id, objectType+"--")); err == nil && parsed.Version() != 5 {
+				t.Errorf("cyber-observable %s has a version %d UUID, want a deterministic version 5", id, parsed.Version())
+			}
+		}
+		if value, ok := object["value"].(string); ok && objectType == "ipv4-addr" {
+			if addr, err := netip.ParseAddr(value); err != nil || !addr.Is4() {
+				if _, err := netip.ParsePrefix(value); err != nil {
+					t.Errorf("ipv4-addr %s value %q is not an IPv4 address or CIDR block", id, value)
+				}
+			}
+		}
+	}
+
+	for id, object := range objects {
+		refs := []interface{}{object["source_ref"], object["target_ref"]}
+		if objectRefs, ok := object["object_refs"].([]interface{}); ok {
+			refs = append(refs, objectRefs...)
+			for _, ref := range objectRefs {
+				if referenced, ok := objects[ref.(string)]; ok && !stixCyberObservables[referenced["type"].(string)] {
+					t.Errorf("observed-data %s refers to %v, which is not a cyber-observable", id, ref)
+				}
+			}
+		}
+		for _, ref := range refs {
+			if ref == nil {
+				continue
+			}
+			if _, ok := objects[ref.(string)]; !ok {
+				t.Errorf("object %s references %v, which is not in the bundle", id, ref)
+			}
+		}
+	}
+	return objects
+}
+
+// objectsOfType returns the objects of a type
+func objectsOfType(objects map[string]map[string]interface{}, objectType string) []map[string]interface{} {
+	var matched []map[string]interface{}
+	for _, object := range objects {
+		if object["type"] == objectType {
+			matched = append(matched, object)
+		}
+	}
+	return matched
+}
+
+func stixTestEvent(id, correlationID string) *models.Event {
+	return &models.Event{
+		EventID:          id,
+		EventType:        "login_failure",
+		Severity:         models.SeverityHigh,
+		Source:           "auth-service",
+		Description:      "5 failed logins",
+		EventData:        models.EventData{"source_ip": "203.0.113.7"},
+		CorrelationID:    correlationID,
+		ATTACKTechniques: []string{"T1110.001"},
+		CreatedAt:        time.Date(2026, 1, 2, 3, 4, 5, 6000000, time.UTC),
+	}
+}
+
+func TestToSTIXIsSpecCompliant(t *testing.T) {
+	objects := validateSTIXBundle(t, ToSTIX(stixTestEvent("evt-1", "evt-1")))
+
+	indicators := objectsOfType(objects, "indicator")
+	if len(indicators) != 1 {
+		t.Fatalf("bundle has %d indicators, want 1", len(indicators))
+	}
+	indicator := indicators[0]
+	if indicator["pattern"] != "[ipv4-addr:value = '203.0.113.7']" || indicator["pattern_type"] != "stix" {
+		t.Errorf("pattern = %v (%v), want the source IP pattern", indicator["pattern"], indicator["pattern_type"])
+	}
+	if indicator["created"] != "2026-01-02T03:04:05.006Z" {
+		t.Errorf("created = %v, want millisecond UTC timestamp", indicator["created"])
+	}
+	if types := indicator["indicator_types"].([]interface{}); len(types) != 1 || types[0] != "malicious-activity" {
+		t.Errorf("indicator_types = %v, want malicious-activity for a high event", types)
+	}
+	refs, _ := indicator["external_references"].([]interface{})
+	if len(refs) != 1 || refs[0].(map[string]interface{})["url"] != "https://attack.mitre.org/techniques/T1110/001/" {
+		t.Errorf("external_references = %v, want the ATT&CK sub-technique", refs)
+	}
+
+	if len(objectsOfType(objects, "threat-actor")) != 1 || len(objectsOfType(objects, "observed-data")) != 1 || len(objectsOfType(objects, "ipv4-addr")) != 1 {
+		t.Errorf("bundle objects = %v, want one threat-actor, observed-data and ipv4-addr", objects)
+	}
+	if relationships := objectsOfType(objects, "relationship"); len(relationships) != 0 {
+		t.Errorf("bundle has %d relationships for an uncorrelated event, want 0", len(relationships))
+	}
+}
+
+func TestToSTIXBundleLinksCorrelatedEvents(t *testing.T) {
+	events := []*models.Event{
+		stixTestEvent("evt-1", "corr-1"),
+		stixTestEvent("evt-2", "corr-1"),
+		stixTestEvent("evt-3", "corr-2"),
+	}
+	objects := validateSTIXBundle(t, ToSTIXBundle(events))
+
+	actors := objectsOfType(objects, "threat-actor")
+	if len(actors) != 1 {
+		t.Fatalf("bundle has %d threat-actors, want one per source", len(actors))
+	}
+	relationships := objectsOfType(objects, "relationship")
+	if len(relationships) != 2 {
+		t.Fatalf("bundle has %d relationships, want one per correlated event", len(relationships))
+	}
+	for _, relationship := range relationships {
+		if relationship["relationship_type"] != "indicates" || relationship["target_ref"] != actors[0]["id"] {
+			t.Errorf("relationship = %v, want indicator indicates the threat-actor", relationship)
+		}
+		if objects[relationship["source_ref"].(string)]["type"] != "indicator" {
+			t.Errorf("relationship source %v is not an indicator", relationship["source_ref"])
+		}
+	}
+}
+
+func TestToSTIXWithoutIPUsesEventObservable(t *testing.T) {
+	event := stixTestEvent("evt-1", "")
+	event.EventType = "o'brien"
+	event.EventData = nil
+	objects := validateSTIXBundle(t, ToSTIX(event))
+
+	indicator := objectsOfType(objects, "indicator")[0]
+	if indicator["pattern"] != `[x-skyhawk-event:event_type = 'o\'brien']` {
+		t.Errorf("pattern = %v, want an escaped event type pattern", indicator["pattern"])
+	}
+	if len(objectsOfType(objects, "x-skyhawk-event")) != 1 {
+		t.Error("bundle has no x-skyhawk-event observable")
+	}
+}
+
+func TestToSTIXIDsAreDeterministic(t *testing.T) {
+	first := validateSTIXBundle(t, ToSTIX(stixTestEvent("evt-1", "")))
+	second := validateSTIXBundle(t, ToSTIX(stixTestEvent("evt-1", "")))
+
+	for id := range first {
+		if _, ok := second[id]; !ok {
+			t.Errorf("object %s is missing from a second export of the same event", id)
+		}
+	}
+}
+
+func TestUUID5MatchesRFC4122(t *testing.T) {
+	namespace := uuid.UUID(stixSCONamespace)
+	name := `{"value":"203.0.113.7"}`
+
+	want := uuid.NewSHA1(namespace, []byte(name)).String()
+	if got := uuid5(stixSCONamespace, name); got != want {
+		t.Errorf("uuid5 = %s, want %s", got, want)
+	}
+	if got := fmt.Sprint(uuid.MustParse(newRandomUUID()).Version()); got != "VERSION_4" {
+		t.Errorf("newRandomUUID version = %s, want VERSION_4", got)
+	}
+}
@@ -0,0 +1,112 @@
+package format
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// Syslog priority for CEF messages: facility local4 (20), severity notice (5)
+const syslogPriority = 20*8 + 5
+
+// SyslogSender forwards CEF messages to a syslog endpoint over UDP or TCP
+type SyslogSender struct {
+	network  string
+	address  string
+	hostname string
+	timeout  time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSender creates a syslog sender for the given network ("udp" or "tcp") and host:port
+func NewSyslogSender(network, host, port string) (*SyslogSender, error) {
+	network = strings.ToLower(network)
+	if network == "" {
+		network = "udp"
+	}
+	if network != "udp" && network != "tcp" {
+		return nil, fmt.Errorf("unsupported syslog network: %s", network)
+	}
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("syslog host and port are required")
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "skyhawk"
+	}
+
+	return &SyslogSender{
+		network:  network,
+		address:  net.JoinHostPort(host, port),
+		hostname: hostname,
+		timeout:  5 * time.Second,
+	}, nil
+}
+
+// NewSyslogSenderFromEnv creates a syslog sender from SYSLOG_HOST, SYSLOG_PORT and SYSLOG_PROTOCOL.
+// It returns nil when SYSLOG_HOST is not set.
+func NewSyslogSenderFromEnv() (*SyslogSender, error) {
+	host := os.Getenv("SYSLOG_HOST")
+	if host == "" {
+		return nil, nil
+	}
+	port := os.Getenv("SYSLOG_PORT")
+	if port == "" {
+		port = "514"
+	}
+	return NewSyslogSender(os.Getenv("SYSLOG_PROTOCOL"), host, port)
+}
+
+// Send writes a single message framed as an RFC 5424 syslog line
+func (s *SyslogSender) Send(message string) error {
+	line := fmt.Sprintf("<%d>1 %s %s skyhawk - - - %s\n",
+		syslogPriority, time.Now().UTC().Format(time.RFC3339), s.hostname, message)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network, s.address, s.timeout)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog: %w", err)
+		}
+		s.conn = conn
+	}
+
+	s.conn.SetWriteDeadline(time.Now().Add(s.timeout))
+	if _, err := s.conn.Write([]byte(line)); err != nil {
+		// Drop the connection so the next send reconnects
+		s.conn.Close()
+		s.conn = nil
+		return fmt.Errorf("failed to write to syslog: %w", err)
+	}
+
+	return nil
+}
+
+// Notify forwards the event to syslog in CEF
+func (s *SyslogSender) Notify(ctx context.Context, event *models.Event) error {
+	return s.Send(ToCEF(event))
+}
+
+// Close closes the syslog connection
+func (s *SyslogSender) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,143 @@
+package format
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// syslogNil is the RFC 5424 placeholder for an empty header field
+const syslogNil = "-"
+
+// ParseSyslog parses an RFC 5424 syslog message into an event creation request. A CEF
+// payload is parsed with ParseCEF, taking the syslog hostname as the source when the CEF
+// extension names no device. Otherwise MSGID (or APP-NAME) becomes the event type, HOSTNAME
+// the source and MSG the description, and the syslog severity is mapped to an event severity.
+func ParseSyslog(body []byte) (*models.CreateEventRequest, error) {
+	line := strings.TrimSpace(string(body))
+
+	end := strings.IndexByte(line, '>')
+	if !strings.HasPrefix(line, "<") || end < 2 || end > 4 {
+		return nil, fmt.Errorf("invalid syslog message: missing <PRI>")
+	}
+	priority, err := strconv.Atoi(line[1:end])
+	if err != nil || priority < 0 || priority > 191 {
+		return nil, fmt.Errorf("invalid syslog priority: %s", line[1:end])
+	}
+
+	// VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA [MSG]
+	parts := strings.SplitN(line[end+1:], " ", 7)
+	if parts[0] != "1" {
+		return nil, fmt.Errorf("unsupported syslog version %q, expected RFC 5424 version 1", parts[0])
+	}
+	if len(parts) < 7 {
+		return nil, fmt.Errorf("invalid syslog message: expected 7 header fields, got %d", len(parts))
+	}
+	structuredData, msg, err := splitStructuredData(parts[6])
+	if err != nil {
+		return nil, err
+	}
+	msg = strings.TrimPrefix(msg, "\ufeff") // UTF-8 BOM
+
+	timestamp := syslogValue(parts[1])
+	hostname := syslogValue(parts[2])
+	appName := syslogValue(parts[3])
+	procID := syslogValue(parts[4])
+	msgID := syslogValue(parts[5])
+
+	var req *models.CreateEventRequest
+	if strings.HasPrefix(msg, "CEF:") {
+		req, err = ParseCEF([]byte(msg))
+		if err != nil {
+			return nil, err
+		}
+		if hostname != "" && req.Source == req.EventData["device_product"] {
+			req.Source = hostname
+		}
+	} else {
+		req = &models.CreateEventRequest{
+			EventType:   firstNonEmpty(msgID, appName, "syslog"),
+			Severity:    severityFromSyslog(priority % 8),
+			Source:      firstNonEmpty(hostname, appName),
+			Description: msg,
+			EventData:   models.EventData{},
+		}
+	}
+
+	req.EventData["syslog_facility"] = priority / 8
+	req.EventData["syslog_severity"] = priority % 8
+	for key, value := range map[string]string{
+		"syslog_timestamp": timestamp,
+		"hostname":         hostname,
+		"app_name":         appName,
+		"procid":           procID,
+		"msgid":            msgID,
+		"structured_data":  structuredData,
+	} {
+		if value != "" {
+			req.EventData[key] = value
+		}
+	}
+
+	return req, nil
+}
+
+// splitStructuredData splits the STRUCTURED-DATA field, "-" or one or more [...] elements whose
+// quoted parameter values may contain escaped quotes and brackets, from the MSG that follows it
+func splitStructuredData(s string) (string, string, error) {
+	if s == syslogNil || strings.HasPrefix(s, syslogNil+" ") {
+		return "", strings.TrimPrefix(s[1:], " "), nil
+	}
+	if !strings.HasPrefix(s, "[") {
+		return "", "", fmt.Errorf("invalid syslog structured data")
+	}
+
+	i := 0
+	for i < len(s) && s[i] == '[' {
+		inQuote := false
+		j := i + 1
+		for ; j < len(s); j++ {
+			c := s[j]
+			if c == '\\' && inQuote {
+				j++
+				continue
+			}
+			if c == '"' {
+				inQuote = !inQuote
+			}
+			if c == ']' && !inQuote {
+				break
+			}
+		}
+		if j >= len(s) {
+			return "", "", fmt.Errorf("unterminated syslog structured data")
+		}
+		i = j + 1
+	}
+
+	return s[:i], strings.TrimPrefix(s[i:], " "), nil
+}
+
+// syslogValue returns a header field, or "" for the nil value
+func syslogValue(field string) string {
+	if field == syslogNil {
+		return ""
+	}
+	return field
+}
+
+// severityFromSyslog maps a syslog severity (0 emergency to 7 debug) to an event severity
+func severityFromSyslog(severity int) string {
+	switch {
+	case severity <= 2:
+		return models.SeverityCritical
+	case severity == 3:
+		return models.SeverityHigh
+	case severity == 4:
+		return models.SeverityMedium
+	default:
+		return models.SeverityLow
+	}
+}
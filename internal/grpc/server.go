@@ -0,0 +1,395 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"skyhawk-security-microservice/internal/enrichment"
+	apperrors "skyhawk-security-microservice/internal/errors"
+	"skyhawk-security-microservice/internal/grpc/eventpb"
+	"skyhawk-security-microservice/internal/models"
+	"skyhawk-security-microservice/internal/queue"
+	"skyhawk-security-microservice/internal/repository"
+	"skyhawk-security-microservice/internal/validation"
+)
+
+// MaxBulkCreateEvents is the maximum number of events a BulkCreateEvents stream may send
+const MaxBulkCreateEvents = 10000
+
+// EventStore is the subset of the event repository the gRPC service needs
+type EventStore interface {
+	CreateEvent(ctx context.Context, event *models.Event) error
+	GetEventByID(ctx context.Context, id string) (*models.Event, error)
+	GetAllEvents(ctx context.Context) ([]*models.Event, error)
+	UpdateEvent(ctx context.Context, eventID string, updates *models.UpdateEventRequest) (*models.Event, error)
+	DeleteEvent(ctx context.Context, eventID string) error
+}
+
+// EventServer implements eventpb.EventServiceServer on top of the event repository
+type EventServer struct {
+	eventpb.UnimplementedEventServiceServer
+	eventRepo  EventStore
+	validator  *validation.EventValidator
+	enrichment *enrichment.Pipeline
+	publish    func(ctx context.Context, event *models.Event)
+	// publishBatch publishes the events of a bulk stream once it ends
+	publishBatch func(ctx context.Context, events []*models.Event) []queue.PublishResult
+}
+
+// NewEventServer creates a new gRPC event service
+func NewEventServer(eventRepo EventStore) *EventServer {
+	return &EventServer{
+		eventRepo: eventRepo,
+		validator: validation.NewEventValidator(),
+	}
+}
+
+// SetValidator configures the validator applied to new events
+func (s *EventServer) SetValidator(validator *validation.EventValidator) {
+	s.validator = validator
+}
+
+// SetEnrichmentPipeline configures the enrichers applied to new events before they are stored
+func (s *EventServer) SetEnrichmentPipeline(pipeline *enrichment.Pipeline) {
+	s.enrichment = pipeline
+}
+
+// SetPublisher configures the function that queues stored events for processing. It must
+// not block.
+func (s *EventServer) SetPublisher(publish func(ctx context.Context, event *models.Event)) {
+	s.publish = publish
+}
+
+// SetBatchPublisher configures the function that queues the events created by a
+// BulkCreateEvents stream, returning the outcome of each; it may return nil when events are not
+// queued. Without one, each event is queued through the publisher as it is created.
+func (s *EventServer) SetBatchPublisher(publish func(ctx context.Context, events []*models.Event) []queue.PublishResult) {
+	s.publishBatch = publish
+}
+
+// NewServer creates a gRPC server with the event service, reflection and interceptors registered.
+// Authentication is enabled when authToken is not empty.
+func NewServer(eventServer *EventServer, authToken string) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			RecoveryUnaryInterceptor(),
+			LoggingUnaryInterceptor(),
+			AuthUnaryInterceptor(authToken),
+		),
+		grpc.ChainStreamInterceptor(
+			RecoveryStreamInterceptor(),
+			LoggingStreamInterceptor(),
+			AuthStreamInterceptor(authToken),
+		),
+	)
+
+	eventpb.RegisterEventServiceServer(server, eventServer)
+	reflection.Register(server)
+
+	return server
+}
+
+// Serve listens on the given port and serves gRPC requests until the server is stopped
+func Serve(server *grpc.Server, port int) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on :%d: %v", port, err)
+	}
+
+	log.Printf("Starting gRPC server on :%d", port)
+	return server.Serve(listener)
+}
+
+// Shutdown stops the server from accepting new calls and waits for running calls to finish.
+// Calls still running when ctx is done, such as long event streams, are cancelled.
+func Shutdown(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Printf("gRPC graceful shutdown timed out, cancelling running calls")
+		server.Stop()
+		<-stopped
+	}
+}
+
+// CreateEvent creates a new security event
+func (s *EventServer) CreateEvent(ctx context.Context, req *eventpb.CreateEventRequest) (*eventpb.Event, error) {
+	event, err := s.createEvent(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return toProto(event)
+}
+
+// BulkCreateEvents creates every event sent on the stream. A rejected event does not end the
+// stream; its error is reported in the response along with its position. With a batch
+// publisher, the created events are queued once the client closes the stream, and those that
+// could not be queued are reported with a queue error; they stay stored.
+func (s *EventServer) BulkCreateEvents(stream eventpb.EventService_BulkCreateEventsServer) error {
+	resp := &eventpb.BulkCreateEventsResponse{}
+	var created []*models.Event
+	var createdResults []*eventpb.BulkCreateEventResult
+	for index := int32(0); ; index++ {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			s.publishCreated(stream.Context(), resp, created, createdResults)
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+		if index >= MaxBulkCreateEvents {
+			return status.Errorf(codes.ResourceExhausted, "at most %d events may be sent per stream", MaxBulkCreateEvents)
+		}
+
+		result := &eventpb.BulkCreateEventResult{Index: index}
+		var event *models.Event
+		if s.publishBatch != nil {
+			event, err = s.storeEvent(stream.Context(), req)
+		} else {
+			event, err = s.createEvent(stream.Context(), req)
+		}
+		if err != nil {
+			result.Error = status.Convert(err).Message()
+			resp.Failed++
+		} else {
+			result.EventId = event.EventID
+			resp.Created++
+			created = append(created, event)
+			createdResults = append(createdResults, result)
+		}
+		resp.Results = append(resp.Results, result)
+	}
+}
+
+// publishCreated queues the events created by a bulk stream through the batch publisher,
+// recording the queue error of each event that was not published in its result
+func (s *EventServer) publishCreated(ctx context.Context, resp *eventpb.BulkCreateEventsResponse, events []*models.Event, results []*eventpb.BulkCreateEventResult) {
+	if s.publishBatch == nil || len(events) == 0 {
+		return
+	}
+	for i, published := range s.publishBatch(ctx, events) {
+		if !published.Published() {
+			results[i].QueueError = published.Err.Error()
+			resp.QueueFailed++
+		}
+	}
+}
+
+// createEvent validates, enriches and stores a new event, then queues it for processing
+func (s *EventServer) createEvent(ctx context.Context, req *eventpb.CreateEventRequest) (*models.Event, error) {
+	event, err := s.storeEvent(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.publish != nil {
+		s.publish(ctx, event)
+	}
+
+	return event, nil
+}
+
+// storeEvent validates, enriches and stores a new event
+func (s *EventServer) storeEvent(ctx context.Context, req *eventpb.CreateEventRequest) (*models.Event, error) {
+	createReq := models.CreateEventRequest{
+		EventType:     req.GetEventType(),
+		Severity:      req.GetSeverity(),
+		Source:        req.GetSource(),
+		Description:   req.GetDescription(),
+		EventData:     eventDataFromStruct(req.GetEventData()),
+		CorrelationID: req.GetCorrelationId(),
+		TenantID:      req.GetTenantId(),
+	}
+
+	var problems apperrors.ValidationErrors
+	if strings.TrimSpace(createReq.EventType) == "" {
+		problems.Add("event_type", "is required")
+	}
+	s.validator.Validate(&createReq, &problems)
+	if err := problems.Err(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.(*apperrors.AppError).Details)
+	}
+
+	event := &models.Event{
+		EventID:       models.GenerateEventID(),
+		EventType:     createReq.EventType,
+		Severity:      createReq.Severity,
+		Source:        createReq.Source,
+		Description:   createReq.Description,
+		EventData:     createReq.EventData,
+		CorrelationID: createReq.CorrelationID,
+		TenantID:      createReq.TenantID,
+	}
+	if event.CorrelationID == "" {
+		event.CorrelationID = event.EventID
+	}
+
+	if s.enrichment != nil {
+		if err := s.enrichment.Enrich(event); err != nil {
+			log.Printf("Failed to enrich event %s: %v", event.EventID, err)
+		}
+	}
+
+	if err := s.eventRepo.CreateEvent(ctx, event); err != nil {
+		return nil, repositoryError(err, "failed to create event")
+	}
+
+	return event, nil
+}
+
+// GetEvent retrieves a single event
+func (s *EventServer) GetEvent(ctx context.Context, req *eventpb.GetEventRequest) (*eventpb.Event, error) {
+	event, err := s.eventRepo.GetEventByID(ctx, req.GetEventId())
+	if err != nil {
+		return nil, repositoryError(err, "failed to retrieve event")
+	}
+
+	return toProto(event)
+}
+
+// ListEvents retrieves all events
+func (s *EventServer) ListEvents(ctx context.Context, req *eventpb.ListEventsRequest) (*eventpb.ListEventsResponse, error) {
+	events, err := s.eventRepo.GetAllEvents(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "failed to retrieve events")
+	}
+
+	resp := &eventpb.ListEventsResponse{
+		Events: make([]*eventpb.Event, 0, len(events)),
+		Total:  int32(len(events)),
+	}
+	for _, event := range events {
+		pbEvent, err := toProto(event)
+		if err != nil {
+			return nil, err
+		}
+		resp.Events = append(resp.Events, pbEvent)
+	}
+
+	return resp, nil
+}
+
+// UpdateEvent updates an existing event
+func (s *EventServer) UpdateEvent(ctx context.Context, req *eventpb.UpdateEventRequest) (*eventpb.Event, error) {
+	event, err := s.eventRepo.UpdateEvent(ctx, req.GetEventId(), &models.UpdateEventRequest{
+		EventType:   req.GetEventType(),
+		Severity:    req.GetSeverity(),
+		Source:      req.GetSource(),
+		Description: req.GetDescription(),
+		EventData:   eventDataFromStruct(req.GetEventData()),
+	})
+	if err != nil {
+		return nil, repositoryError(err, "failed to update event")
+	}
+
+	return toProto(event)
+}
+
+// DeleteEvent deletes an event
+func (s *EventServer) DeleteEvent(ctx context.Context, req *eventpb.DeleteEventRequest) (*eventpb.DeleteEventResponse, error) {
+	if err := s.eventRepo.DeleteEvent(ctx, req.GetEventId()); err != nil {
+		return nil, repositoryError(err, "failed to delete event")
+	}
+
+	return &eventpb.DeleteEventResponse{EventId: req.GetEventId()}, nil
+}
+
+// StreamEvents streams stored events matching the optional filters
+func (s *EventServer) StreamEvents(req *eventpb.StreamEventsRequest, stream eventpb.EventService_StreamEventsServer) error {
+	events, err := s.eventRepo.GetAllEvents(stream.Context())
+	if err != nil {
+		return status.Error(codes.Internal, "failed to retrieve events")
+	}
+
+	for _, event := range events {
+		if req.GetSeverity() != "" && event.Severity != req.GetSeverity() {
+			continue
+		}
+		if req.GetEventType() != "" && event.EventType != req.GetEventType() {
+			continue
+		}
+
+		pbEvent, err := toProto(event)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(pbEvent); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// repositoryError maps repository errors to gRPC status errors
+func repositoryError(err error, message string) error {
+	if err.Error() == "event not found" {
+		return status.Error(codes.NotFound, "event not found")
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, message)
+	}
+	if errors.Is(err, repository.ErrContextCancelled) {
+		return status.Error(codes.Canceled, message)
+	}
+	if apperrors.IsValidation(err) {
+		return status.Error(codes.InvalidArgument, err.(*apperrors.AppError).Message)
+	}
+	if apperrors.IsConflict(err) {
+		return status.Error(codes.AlreadyExists, err.(*apperrors.AppError).Message)
+	}
+	return status.Error(codes.Internal, message)
+}
+
+// toProto converts an event model into its protobuf representation
+func toProto(event *models.Event) (*eventpb.Event, error) {
+	pbEvent := &eventpb.Event{
+		Id:            event.ID,
+		EventId:       event.EventID,
+		EventType:     event.EventType,
+		Severity:      event.Severity,
+		Source:        event.Source,
+		Description:   event.Description,
+		CorrelationId: event.CorrelationID,
+		TenantId:      event.TenantID,
+		CreatedAt:     timestamppb.New(event.CreatedAt),
+		UpdatedAt:     timestamppb.New(event.UpdatedAt),
+	}
+
+	if event.EventData != nil {
+		data, err := structpb.NewStruct(event.EventData)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "failed to encode event data")
+		}
+		pbEvent.EventData = data
+	}
+
+	return pbEvent, nil
+}
+
+// eventDataFromStruct converts a protobuf Struct into event data
+func eventDataFromStruct(data *structpb.Struct) models.EventData {
+	if data == nil {
+		return nil
+	}
+	return models.EventData(data.AsMap())
+}
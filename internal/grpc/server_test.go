@@ -0,0 +1,313 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+	"skyhawk-security-microservice/internal/grpc/eventpb"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// memoryEventStore is an in-memory EventStore
+type memoryEventStore struct {
+	mu     sync.Mutex
+	events map[string]*models.Event
+	order  []string
+}
+
+func newMemoryEventStore() *memoryEventStore {
+	return &memoryEventStore{events: make(map[string]*models.Event)}
+}
+
+func (s *memoryEventStore) CreateEvent(ctx context.Context, event *models.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event.ID = fmt.Sprint(len(s.order) + 1)
+	s.events[event.EventID] = event
+	s.order = append(s.order, event.EventID)
+	return nil
+}
+
+func (s *memoryEventStore) GetEventByID(ctx context.Context, id string) (*models.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event, ok := s.events[id]
+	if !ok {
+		return nil, fmt.Errorf("event not found")
+	}
+	return event, nil
+}
+
+func (s *memoryEventStore) GetAllEvents(ctx context.Context) ([]*models.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := make([]*models.Event, 0, len(s.events))
+	for _, id := range s.order {
+		if event, ok := s.events[id]; ok {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (s *memoryEventStore) UpdateEvent(ctx context.Context, eventID string, updates *models.UpdateEventRequest) (*models.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event, ok := s.events[eventID]
+	if !ok {
+		return nil, fmt.Errorf("event not found")
+	}
+	if updates.Severity != "" {
+		event.Severity = updates.Severity
+	}
+	if updates.Description != "" {
+		event.Description = updates.Description
+	}
+	return event, nil
+}
+
+func (s *memoryEventStore) DeleteEvent(ctx context.Context, eventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.events[eventID]; !ok {
+		return fmt.Errorf("event not found")
+	}
+	delete(s.events, eventID)
+	return nil
+}
+
+// dialTestServer serves eventServer over an in-memory connection and returns a client for it
+func dialTestServer(t *testing.T, eventServer *EventServer, authToken string) eventpb.EventServiceClient {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer(eventServer, authToken)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return eventpb.NewEventServiceClient(conn)
+}
+
+func createTestEvent(t *testing.T, client eventpb.EventServiceClient, eventType, severity string) *eventpb.Event {
+	t.Helper()
+	data, _ := structpb.NewStruct(map[string]interface{}{"ip": "10.0.0.1"})
+	event, err := client.CreateEvent(context.Background(), &eventpb.CreateEventRequest{
+		EventType:   eventType,
+		Severity:    severity,
+		Source:      "auth-service",
+		Description: "failed login",
+		EventData:   data,
+	})
+	if err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+	return event
+}
+
+func TestEventServerCreateAndGetEvent(t *testing.T) {
+	store := newMemoryEventStore()
+	eventServer := NewEventServer(store)
+	var published []string
+	eventServer.SetPublisher(func(ctx context.Context, event *models.Event) { published = append(published, event.EventID) })
+	client := dialTestServer(t, eventServer, "")
+
+	created := createTestEvent(t, client, "login_failure", models.SeverityHigh)
+	if created.GetEventId() == "" || created.GetCorrelationId() != created.GetEventId() {
+		t.Errorf("created event = %+v, want an event ID that doubles as correlation ID", created)
+	}
+	if len(published) != 1 || published[0] != created.GetEventId() {
+		t.Errorf("published %v, want the created event", published)
+	}
+
+	got, err := client.GetEvent(context.Background(), &eventpb.GetEventRequest{EventId: created.GetEventId()})
+	if err != nil {
+		t.Fatalf("GetEvent: %v", err)
+	}
+	if got.GetEventType() != "login_failure" || got.GetSeverity() != models.SeverityHigh || got.GetSource() != "auth-service" {
+		t.Errorf("GetEvent = %+v, want the created event", got)
+	}
+	if ip := got.GetEventData().GetFields()["ip"].GetStringValue(); ip != "10.0.0.1" {
+		t.Errorf("event_data ip = %q, want 10.0.0.1", ip)
+	}
+}
+
+func TestEventServerCreateEventValidation(t *testing.T) {
+	client := dialTestServer(t, NewEventServer(newMemoryEventStore()), "")
+
+	_, err := client.CreateEvent(context.Background(), &eventpb.CreateEventRequest{Severity: models.SeverityHigh, Source: "auth-service"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("CreateEvent without event_type returned %v, want InvalidArgument", err)
+	}
+}
+
+func TestEventServerGetEventNotFound(t *testing.T) {
+	client := dialTestServer(t, NewEventServer(newMemoryEventStore()), "")
+
+	_, err := client.GetEvent(context.Background(), &eventpb.GetEventRequest{EventId: "missing"})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetEvent returned %v, want NotFound", err)
+	}
+}
+
+// seedEvents stores events with fixed IDs directly in the store
+func seedEvents(t *testing.T, store *memoryEventStore, events ...*models.Event) {
+	t.Helper()
+	for _, event := range events {
+		if err := store.CreateEvent(context.Background(), event); err != nil {
+			t.Fatalf("seed %s: %v", event.EventID, err)
+		}
+	}
+}
+
+func TestEventServerListEvents(t *testing.T) {
+	store := newMemoryEventStore()
+	seedEvents(t, store,
+		&models.Event{EventID: "evt-1", EventType: "login_failure", Severity: models.SeverityHigh},
+		&models.Event{EventID: "evt-2", EventType: "port_scan", Severity: models.SeverityLow},
+	)
+	client := dialTestServer(t, NewEventServer(store), "")
+
+	resp, err := client.ListEvents(context.Background(), &eventpb.ListEventsRequest{})
+	if err != nil {
+		t.Fatalf("ListEvents: %v", err)
+	}
+	if resp.GetTotal() != 2 || len(resp.GetEvents()) != 2 {
+		t.Errorf("ListEvents returned %d of %d events, want 2", len(resp.GetEvents()), resp.GetTotal())
+	}
+}
+
+func TestEventServerUpdateEvent(t *testing.T) {
+	client := dialTestServer(t, NewEventServer(newMemoryEventStore()), "")
+	created := createTestEvent(t, client, "login_failure", models.SeverityHigh)
+
+	updated, err := client.UpdateEvent(context.Background(), &eventpb.UpdateEventRequest{
+		EventId:  created.GetEventId(),
+		Severity: models.SeverityCritical,
+	})
+	if err != nil {
+		t.Fatalf("UpdateEvent: %v", err)
+	}
+	if updated.GetSeverity() != models.SeverityCritical {
+		t.Errorf("severity = %q, want critical", updated.GetSeverity())
+	}
+
+	_, err = client.UpdateEvent(context.Background(), &eventpb.UpdateEventRequest{EventId: "missing", Severity: models.SeverityLow})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("UpdateEvent of a missing event returned %v, want NotFound", err)
+	}
+}
+
+func TestEventServerDeleteEvent(t *testing.T) {
+	client := dialTestServer(t, NewEventServer(newMemoryEventStore()), "")
+	created := createTestEvent(t, client, "login_failure", models.SeverityHigh)
+
+	resp, err := client.DeleteEvent(context.Background(), &eventpb.DeleteEventRequest{EventId: created.GetEventId()})
+	if err != nil {
+		t.Fatalf("DeleteEvent: %v", err)
+	}
+	if resp.GetEventId() != created.GetEventId() {
+		t.Errorf("DeleteEvent returned %q, want %q", resp.GetEventId(), created.GetEventId())
+	}
+
+	_, err = client.GetEvent(context.Background(), &eventpb.GetEventRequest{EventId: created.GetEventId()})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("GetEvent after delete returned %v, want NotFound", err)
+	}
+}
+
+func TestEventServerStreamEventsFilters(t *testing.T) {
+	store := newMemoryEventStore()
+	seedEvents(t, store,
+		&models.Event{EventID: "evt-1", EventType: "login_failure", Severity: models.SeverityHigh},
+		&models.Event{EventID: "evt-2", EventType: "port_scan", Severity: models.SeverityLow},
+		&models.Event{EventID: "evt-3", EventType: "port_scan", Severity: models.SeverityHigh},
+	)
+	client := dialTestServer(t, NewEventServer(store), "")
+
+	stream, err := client.StreamEvents(context.Background(), &eventpb.StreamEventsRequest{Severity: models.SeverityHigh, EventType: "port_scan"})
+	if err != nil {
+		t.Fatalf("StreamEvents: %v", err)
+	}
+	var received []string
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		received = append(received, event.GetEventId())
+	}
+	if len(received) != 1 || received[0] != "evt-3" {
+		t.Errorf("streamed %v, want only evt-3", received)
+	}
+}
+
+func TestEventServerRequiresAuthToken(t *testing.T) {
+	client := dialTestServer(t, NewEventServer(newMemoryEventStore()), "secret")
+
+	_, err := client.ListEvents(context.Background(), &eventpb.ListEventsRequest{})
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("ListEvents without a token returned %v, want Unauthenticated", err)
+	}
+
+	wrong := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wrong")
+	if _, err := client.ListEvents(wrong, &eventpb.ListEventsRequest{}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("ListEvents with a wrong token returned %v, want Unauthenticated", err)
+	}
+
+	authorized := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer secret")
+	if _, err := client.ListEvents(authorized, &eventpb.ListEventsRequest{}); err != nil {
+		t.Errorf("ListEvents with the token returned %v, want nil", err)
+	}
+
+	stream, err := client.StreamEvents(context.Background(), &eventpb.StreamEventsRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("StreamEvents without a token returned %v, want Unauthenticated", err)
+	}
+}
+
+// panickingStore panics on every read
+type panickingStore struct {
+	*memoryEventStore
+}
+
+func (panickingStore) GetEventByID(ctx context.Context, id string) (*models.Event, error) {
+	panic("boom")
+}
+
+func TestEventServerRecoversFromPanics(t *testing.T) {
+	client := dialTestServer(t, NewEventServer(panickingStore{newMemoryEventStore()}), "")
+
+	_, err := client.GetEvent(context.Background(), &eventpb.GetEventRequest{EventId: "evt-1"})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("GetEvent returned %v, want Internal", err)
+	}
+	// The server keeps serving after the panic
+	if _, err := client.ListEvents(context.Background(), &eventpb.ListEventsRequest{}); err != nil {
+		t.Errorf("ListEvents after a panic returned %v, want nil", err)
+	}
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/aggregation"
+	"skyhawk-security-microservice/internal/api"
+	apperrors "skyhawk-security-microservice/internal/errors"
+)
+
+// AggregationHandler handles event aggregation admin endpoints
+type AggregationHandler struct {
+	store *aggregation.Store
+}
+
+// NewAggregationHandler creates a new aggregation handler
+func NewAggregationHandler(store *aggregation.Store) *AggregationHandler {
+	return &AggregationHandler{store: store}
+}
+
+// GetConfig handles retrieving the aggregation settings
+func (h *AggregationHandler) GetConfig(c *gin.Context) {
+	config, err := h.store.Load()
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to load aggregation settings", err))
+		return
+	}
+
+	api.OK(c, config)
+}
+
+// UpdateConfig handles replacing the aggregation settings. Workers pick up
+// the new settings on their next refresh.
+func (h *AggregationHandler) UpdateConfig(c *gin.Context) {
+	config := aggregation.DefaultConfig()
+	if err := c.ShouldBindJSON(&config); err != nil {
+		api.Fail(c, apperrors.NewValidationError("Invalid request body", err.Error()))
+		return
+	}
+
+	if err := h.store.Save(config); err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to save aggregation settings", err))
+		return
+	}
+
+	config, err := h.store.Load()
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to load aggregation settings", err))
+		return
+	}
+
+	api.OK(c, config)
+}
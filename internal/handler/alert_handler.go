@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/alerting"
+	"skyhawk-security-microservice/internal/api"
+	apperrors "skyhawk-security-microservice/internal/errors"
+)
+
+// AlertHandler handles threshold alert rule admin endpoints
+type AlertHandler struct {
+	repo *alerting.Repository
+}
+
+// NewAlertHandler creates a new alert handler
+func NewAlertHandler(repo *alerting.Repository) *AlertHandler {
+	return &AlertHandler{repo: repo}
+}
+
+// CreateThresholdRule handles threshold rule creation
+func (h *AlertHandler) CreateThresholdRule(c *gin.Context) {
+	var req alerting.CreateThresholdRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.Fail(c, apperrors.NewValidationError("Invalid request body", err.Error()))
+		return
+	}
+
+	rule, err := req.ToRule()
+	if err != nil {
+		api.Fail(c, apperrors.NewValidationError("Invalid threshold rule", err.Error()))
+		return
+	}
+
+	if err := h.repo.CreateRule(rule); err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to create threshold rule", err))
+		return
+	}
+
+	api.Created(c, rule)
+}
+
+// GetThresholdRules handles threshold rule listing
+func (h *AlertHandler) GetThresholdRules(c *gin.Context) {
+	rules, err := h.repo.ListRules()
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve threshold rules", err))
+		return
+	}
+
+	api.OK(c, rules)
+}
+
+// DeleteThresholdRule handles threshold rule deletion
+func (h *AlertHandler) DeleteThresholdRule(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.repo.DeleteRule(id); err != nil {
+		if err.Error() == "alert rule not found" {
+			api.Fail(c, apperrors.NewNotFoundError("Threshold rule", id))
+			return
+		}
+		api.Fail(c, apperrors.NewInternalError("Failed to delete threshold rule", err))
+		return
+	}
+
+	api.OK(c, gin.H{"rule_id": id})
+}
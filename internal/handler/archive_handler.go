@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+	"skyhawk-security-microservice/internal/archival"
+	apperrors "skyhawk-security-microservice/internal/errors"
+)
+
+// ArchiveHandler handles the event archival admin endpoints
+type ArchiveHandler struct {
+	store *archival.StatusStore
+}
+
+// NewArchiveHandler creates a new archive handler
+func NewArchiveHandler(store *archival.StatusStore) *ArchiveHandler {
+	return &ArchiveHandler{store: store}
+}
+
+// GetStatus handles reporting the last archival run and the total rows archived
+func (h *ArchiveHandler) GetStatus(c *gin.Context) {
+	status, err := h.store.Status()
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve archive status", err))
+		return
+	}
+
+	api.OK(c, status)
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+	apperrors "skyhawk-security-microservice/internal/errors"
+	"skyhawk-security-microservice/internal/middleware"
+)
+
+// BruteForceHandler handles the authentication lockout admin endpoints
+type BruteForceHandler struct {
+	store    middleware.BruteForceStore
+	auditLog bool
+}
+
+// NewBruteForceHandler creates a new brute force handler that logs an audit entry for every
+// reset when auditLog is set
+func NewBruteForceHandler(store middleware.BruteForceStore, auditLog bool) *BruteForceHandler {
+	return &BruteForceHandler{store: store, auditLog: auditLog}
+}
+
+// Store returns the failure counter store to pass to middleware.BruteForceMiddleware
+func (h *BruteForceHandler) Store() middleware.BruteForceStore {
+	return h.store
+}
+
+// ResetLockoutRequest identifies the lockout to clear
+type ResetLockoutRequest struct {
+	IP       string `json:"ip" binding:"required,ip"`
+	Username string `json:"username"`
+}
+
+// ResetLockout handles clearing the failed attempt count of an IP and username
+func (h *BruteForceHandler) ResetLockout(c *gin.Context) {
+	var req ResetLockoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.Fail(c, apperrors.NewValidationError("Invalid request body", err.Error()))
+		return
+	}
+
+	if err := h.store.Reset(middleware.BruteForceKey(req.IP, req.Username)); err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to reset lockout", err))
+		return
+	}
+
+	auditf(c, h.auditLog, "authentication lockout of %s/%q reset by %s (request %s)", req.IP, req.Username, c.ClientIP(), c.GetString("request_id"))
+
+	api.OK(c, gin.H{
+		"ip":       req.IP,
+		"username": req.Username,
+	})
+}
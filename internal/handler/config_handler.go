@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+	"skyhawk-security-microservice/internal/config"
+	apperrors "skyhawk-security-microservice/internal/errors"
+)
+
+// ConfigHandler handles the configuration admin endpoints
+type ConfigHandler struct {
+	watcher *config.ConfigWatcher
+}
+
+// NewConfigHandler creates a new config handler; watcher is nil when no configuration file is used
+func NewConfigHandler(watcher *config.ConfigWatcher) *ConfigHandler {
+	return &ConfigHandler{watcher: watcher}
+}
+
+// Reload handles re-reading the configuration file without waiting for the file watcher
+func (h *ConfigHandler) Reload(c *gin.Context) {
+	if h.watcher == nil {
+		api.Fail(c, apperrors.NewUnavailableError("No configuration file is configured"))
+		return
+	}
+
+	if err := h.watcher.Reload(); err != nil {
+		api.Fail(c, apperrors.NewUnprocessableError("Failed to reload configuration", err.Error()))
+		return
+	}
+
+	api.OK(c, gin.H{
+		"reloaded": true,
+	})
+}
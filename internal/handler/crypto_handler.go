@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+	"skyhawk-security-microservice/internal/config"
+	"skyhawk-security-microservice/internal/crypto"
+)
+
+// CryptoHandler handles the field encryption admin endpoints
+type CryptoHandler struct {
+	encryption config.FieldEncryption
+}
+
+// NewCryptoHandler creates a new crypto handler
+func NewCryptoHandler(encryption config.FieldEncryption) *CryptoHandler {
+	return &CryptoHandler{encryption: encryption}
+}
+
+// GetStatus handles reporting whether event_data fields are encrypted at rest
+func (h *CryptoHandler) GetStatus(c *gin.Context) {
+	fields := []string{}
+	if h.encryption.Enabled() {
+		fields = h.encryption.Fields
+	}
+
+	api.OK(c, gin.H{
+		"enabled":          h.encryption.Enabled(),
+		"algorithm":        crypto.Algorithm,
+		"protected_fields": fields,
+	})
+}
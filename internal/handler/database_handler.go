@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+	"skyhawk-security-microservice/internal/database"
+	apperrors "skyhawk-security-microservice/internal/errors"
+)
+
+// DatabaseHandler handles the database maintenance admin endpoints
+type DatabaseHandler struct {
+	db *database.DB
+}
+
+// NewDatabaseHandler creates a new database handler
+func NewDatabaseHandler(db *database.DB) *DatabaseHandler {
+	return &DatabaseHandler{db: db}
+}
+
+// GetIndexBloat handles listing the estimated bloat of every index, most bloated first
+func (h *DatabaseHandler) GetIndexBloat(c *gin.Context) {
+	indexes, err := h.db.GetIndexBloat(c.Request.Context())
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to estimate index bloat", err))
+		return
+	}
+
+	api.OK(c, indexes)
+}
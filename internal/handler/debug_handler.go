@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"net/http"
+	"runtime"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v3"
+	"skyhawk-security-microservice/internal/api"
+	"skyhawk-security-microservice/internal/config"
+	apperrors "skyhawk-security-microservice/internal/errors"
+)
+
+// maxGoroutineDump caps the buffer used to capture the stacks of all goroutines
+const maxGoroutineDump = 64 << 20
+
+// DebugHandler handles the runtime inspection endpoints under /admin/debug
+type DebugHandler struct {
+	apiKey  string
+	watcher *config.ConfigWatcher
+}
+
+// NewDebugHandler creates a debug handler whose endpoints require apiKey. watcher is nil when
+// no configuration file is used.
+func NewDebugHandler(apiKey string, watcher *config.ConfigWatcher) *DebugHandler {
+	return &DebugHandler{apiKey: apiKey, watcher: watcher}
+}
+
+// APIKey returns the key to pass to middleware.AdminAPIKeyMiddleware
+func (h *DebugHandler) APIKey() string {
+	return h.apiKey
+}
+
+// MemStats is the subset of runtime.MemStats reported by GetMemStats
+type MemStats struct {
+	HeapInuse     uint64  `json:"HeapInuse"`
+	HeapIdle      uint64  `json:"HeapIdle"`
+	HeapSys       uint64  `json:"HeapSys"`
+	NumGC         uint32  `json:"NumGC"`
+	GCCPUFraction float64 `json:"GCCPUFraction"`
+	NextGC        uint64  `json:"NextGC"`
+}
+
+// GetGoroutines handles dumping the stack of every goroutine as text
+func (h *DebugHandler) GetGoroutines(c *gin.Context) {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= maxGoroutineDump {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", buf)
+}
+
+// GetMemStats handles reporting heap and garbage collector statistics
+func (h *DebugHandler) GetMemStats(c *gin.Context) {
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+
+	api.OK(c, MemStats{
+		HeapInuse:     stats.HeapInuse,
+		HeapIdle:      stats.HeapIdle,
+		HeapSys:       stats.HeapSys,
+		NumGC:         stats.NumGC,
+		GCCPUFraction: stats.GCCPUFraction,
+		NextGC:        stats.NextGC,
+	})
+}
+
+// GetConfig handles showing the configuration in effect with its secrets redacted. Keys
+// follow the configuration file.
+func (h *DebugHandler) GetConfig(c *gin.Context) {
+	if h.watcher == nil {
+		api.Fail(c, apperrors.NewUnavailableError("No configuration file is configured"))
+		return
+	}
+
+	// Round trip through YAML so the keys match the configuration file
+	data, err := yaml.Marshal(h.watcher.Config().Sanitized())
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to encode configuration", err))
+		return
+	}
+	var cfg map[string]interface{}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to encode configuration", err))
+		return
+	}
+
+	api.OK(c, cfg)
+}
@@ -1,195 +1,1404 @@
 package handler
 
 import (
-	"log"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/google/uuid"
+	"skyhawk-security-microservice/internal/api"
+	"skyhawk-security-microservice/internal/config"
+	"skyhawk-security-microservice/internal/enrichment"
+	apperrors "skyhawk-security-microservice/internal/errors"
+	"skyhawk-security-microservice/internal/format"
+	grpcserver "skyhawk-security-microservice/internal/grpc"
+	"skyhawk-security-microservice/internal/logger"
 	"skyhawk-security-microservice/internal/models"
+	"skyhawk-security-microservice/internal/normalization"
+	"skyhawk-security-microservice/internal/notifier"
+	"skyhawk-security-microservice/internal/pagination"
 	"skyhawk-security-microservice/internal/queue"
-	"skyhawk-security-microservice/internal/repository"
+	"skyhawk-security-microservice/internal/ratelimit"
+	"skyhawk-security-microservice/internal/routing"
+	"skyhawk-security-microservice/internal/validation"
+	"skyhawk-security-microservice/internal/webhook"
 )
 
+// EventStore is the event storage used by EventHandler, implemented by
+// repository.EventRepository and by repository.CachedEventRepository
+type EventStore interface {
+	CreateEvent(ctx context.Context, event *models.Event) error
+	GetEventByID(ctx context.Context, id string) (*models.Event, error)
+	ListEvents(ctx context.Context, filter models.EventFilter, page models.PaginationParams) ([]*models.Event, error)
+	FindEvents(ctx context.Context, filter models.EventFilter) ([]*models.Event, error)
+	GetEventSummaries(ctx context.Context, filter models.EventFilter, page models.PaginationParams) ([]models.EventSummary, int64, error)
+	GetRecentEventsBySource(ctx context.Context, source string, limit int, since time.Time) ([]*models.Event, error)
+	CountEventsByBucket(ctx context.Context, bucket time.Duration, from, to time.Time) ([]models.TimeSeriesBucket, error)
+	CountEventsBySeverity(ctx context.Context, filter models.EventFilter) (map[string]int64, error)
+	CountEventsByHour(ctx context.Context, from, to time.Time) ([]models.HourlyCount, error)
+	TopSources(ctx context.Context, limit int, filter models.EventFilter) ([]models.SourceCount, error)
+	GetEventFacets(ctx context.Context) (*models.EventFacets, error)
+	StreamEventsCreatedBetween(ctx context.Context, from, to time.Time, batchSize int, fn func(*models.Event) error) error
+	GetEventTransitions(ctx context.Context, eventID string) ([]models.EventTransition, error)
+	GetProcessingLog(ctx context.Context, eventID string) ([]models.ProcessingLogEntry, error)
+	ListTenantIDs(ctx context.Context) ([]string, error)
+	CheckDataFieldFilter(field string) string
+	UpdateEvent(ctx context.Context, eventID string, updates *models.UpdateEventRequest) (*models.Event, error)
+	PatchEventData(ctx context.Context, eventID string, patch models.EventData) (*models.Event, error)
+	AcknowledgeEvent(ctx context.Context, eventID string) (*models.Event, error)
+	BulkUpdateStatus(ctx context.Context, tenantID string, eventIDs []string, status, updatedBy, note string) models.BulkResult
+	DeleteEvent(ctx context.Context, eventID string) error
+	DeleteEvents(ctx context.Context, eventIDs []string) (*models.DeleteEventsResult, error)
+}
+
 // EventHandler handles security event-related endpoints
 type EventHandler struct {
-	eventRepo    *repository.EventRepository
+	eventRepo    EventStore
 	queueManager queue.QueueInterface
+	resolver     notifier.Resolver
+	webhookRepo  *webhook.Repository
+	cursorCodec  *pagination.CursorCodec
+	router       *routing.EventRouter
+	normalizer   *normalization.SeverityNormalizer
+	limiter      *ratelimit.SourceRateLimiter
+	enrichment   *enrichment.Pipeline
+	defaults     models.EventDefaults
+	decoders     *format.DecoderRegistry
+	validator    *validation.EventValidator
+	features     config.FeatureFlags
+	facets       facetCache
+	// rejectUnknownFields fails JSON create requests carrying fields CreateEventRequest lacks
+	rejectUnknownFields bool
 }
 
 // NewEventHandler creates a new event handler
-func NewEventHandler(eventRepo *repository.EventRepository, queueManager queue.QueueInterface) *EventHandler {
+func NewEventHandler(eventRepo EventStore, queueManager queue.QueueInterface) *EventHandler {
 	return &EventHandler{
 		eventRepo:    eventRepo,
 		queueManager: queueManager,
+		cursorCodec:  pagination.NewCursorCodecFromEnv(),
+		decoders:     format.NewDecoderRegistry(),
+		validator:    validation.NewEventValidator(),
+		features:     config.DefaultFeatureFlags(),
 	}
 }
 
-// CreateEvent handles security event creation
+// SetFeatures configures which optional features are enabled; all are by default
+func (h *EventHandler) SetFeatures(features config.FeatureFlags) {
+	h.features = features
+}
+
+// SetResolver configures the alert resolver used when events are acknowledged
+func (h *EventHandler) SetResolver(resolver notifier.Resolver) {
+	h.resolver = resolver
+}
+
+// SetRouter configures the router that selects the queue for new events
+func (h *EventHandler) SetRouter(router *routing.EventRouter) {
+	h.router = router
+}
+
+// SetNormalizer configures the normalizer that maps raw severity labels to canonical severities
+func (h *EventHandler) SetNormalizer(normalizer *normalization.SeverityNormalizer) {
+	h.normalizer = normalizer
+	h.validator.SetNormalizer(normalizer)
+}
+
+// SetSourceLimiter configures the per-source rate limiter applied to new events
+func (h *EventHandler) SetSourceLimiter(limiter *ratelimit.SourceRateLimiter) {
+	h.limiter = limiter
+}
+
+// SetEnrichmentPipeline configures the enrichers applied to new events before they are stored
+func (h *EventHandler) SetEnrichmentPipeline(pipeline *enrichment.Pipeline) {
+	h.enrichment = pipeline
+}
+
+// SetEventDefaults configures the severity and source given to events created without them
+func (h *EventHandler) SetEventDefaults(defaults models.EventDefaults) {
+	h.defaults = defaults
+	h.validator.SetEventDefaults(defaults)
+}
+
+// SetRejectUnknownFields configures whether JSON bodies of new events may carry fields that are
+// not part of the request, such as misspelled ones. They are ignored by default.
+func (h *EventHandler) SetRejectUnknownFields(reject bool) {
+	h.rejectUnknownFields = reject
+}
+
+// SetMaxDescriptionLength sets the limit on the length of event descriptions in characters
+func (h *EventHandler) SetMaxDescriptionLength(length int) {
+	h.validator.SetMaxDescriptionLength(length)
+}
+
+// RegisterDecoder accepts event bodies of another media type, parsed by decoder
+func (h *EventHandler) RegisterDecoder(mediaType string, decoder format.Decoder) {
+	h.decoders.Register(mediaType, decoder)
+}
+
+// SetWebhookRepository enables webhook dispatch for matching subscriptions
+func (h *EventHandler) SetWebhookRepository(repo *webhook.Repository) {
+	h.webhookRepo = repo
+}
+
+// CreateEvent handles security event creation. Every invalid field is reported at once.
 func (h *EventHandler) CreateEvent(c *gin.Context) {
+	var problems apperrors.ValidationErrors
+
 	var req models.CreateEventRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+	if !h.bindCreateEventRequest(c, &req, &problems) {
+		return
+	}
+
+	h.validator.Validate(&req, &problems)
+
+	if err := problems.Err(); err != nil {
+		api.Fail(c, err)
+		return
+	}
+
+	if h.limiter != nil && !h.limiter.Allow(req.Source) {
+		c.Header("X-RateLimit-Source", req.Source)
+		api.Fail(c, apperrors.NewRateLimitError("Rate limit exceeded for source", req.Source))
 		return
 	}
 
 	// Create event model
 	event := &models.Event{
-		EventID:     generateEventID(),
-		EventType:   req.EventType,
-		Severity:    req.Severity,
-		Source:      req.Source,
-		Description: req.Description,
-		EventData:   req.EventData,
+		EventID:          models.GenerateEventID(),
+		EventType:        req.EventType,
+		Severity:         req.Severity,
+		Source:           req.Source,
+		Description:      req.Description,
+		EventData:        req.EventData,
+		CorrelationID:    req.CorrelationID,
+		TenantID:         req.TenantID,
+		ATTACKTechniques: req.ATTACKTechniques,
+	}
+	if event.CorrelationID == "" {
+		event.CorrelationID = event.EventID
+	}
+
+	if h.enrichment != nil {
+		if err := h.enrichment.Enrich(event); err != nil {
+			logger.FromGinContext(c).Warn("Failed to enrich event", logger.Fields{"event_id": event.EventID, "error": err.Error()})
+		}
 	}
 
 	// Save to database
-	if err := h.eventRepo.CreateEvent(event); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to create event",
-		})
+	if err := h.eventRepo.CreateEvent(c.Request.Context(), event); err != nil {
+		if apperrors.IsValidation(err) || apperrors.IsConflict(err) {
+			api.Fail(c, err)
+			return
+		}
+		api.Fail(c, apperrors.NewInternalError("Failed to create event", err))
 		return
 	}
 
-			// Publish to queue for async processing
-		if h.queueManager != nil {
-			go func() {
-				if err := h.queueManager.PublishEvent(event, "security_events"); err != nil {
-					log.Printf("Failed to publish event to queue: %v", err)
-				} else {
-					log.Printf("Event %s published to queue", event.EventID)
-				}
-			}()
+	// Publish to queue for async processing. Carry the request ID so worker logs can be
+	// correlated with this request
+	h.queueEvent(c.Request.Context(), event, c.GetString("request_id"))
+
+	api.Created(c, event)
+}
+
+// queueEvent publishes a new event to its routed queue and dispatches matching webhooks in the
+// background. The trace context of ctx is kept, but not its cancellation, which ends with the
+// request, so the worker continues the trace.
+func (h *EventHandler) queueEvent(ctx context.Context, event *models.Event, requestID string) {
+	if !h.features.QueueEnabled || h.queueManager == nil {
+		return
+	}
+
+	targetQueue := h.routeEvent(event)
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		var opts []queue.PublishOption
+		if requestID != "" {
+			opts = append(opts, queue.WithHeader(queue.RequestIDHeader, requestID))
+		}
+		if err := queue.PublishEventContext(ctx, h.queueManager, event, targetQueue, opts...); err != nil {
+			logger.FromContext(ctx).Error("Failed to publish event to queue", err, logger.Fields{"event_id": event.EventID, "queue": targetQueue})
+		} else {
+			logger.FromContext(ctx).Info("Event published to queue", logger.Fields{"event_id": event.EventID, "queue": targetQueue})
+		}
+		h.dispatchWebhooks(ctx, event, requestID)
+	}()
+}
+
+// publishEvents publishes a batch of new events to their routed queues, waiting for the outcome
+// of each, and dispatches the webhooks of the published events in the background. It returns
+// nil when queueing is disabled.
+func (h *EventHandler) publishEvents(ctx context.Context, events []*models.Event) []queue.PublishResult {
+	if !h.features.QueueEnabled || h.queueManager == nil {
+		return nil
+	}
+
+	results := queue.PublishEvents(ctx, h.queueManager, events, h.routeEvent)
+	published := make([]*models.Event, 0, len(events))
+	for i, result := range results {
+		if !result.Published() {
+			logger.FromContext(ctx).Error("Failed to publish event to queue", result.Err, logger.Fields{"event_id": result.EventID, "queue": result.Queue})
+			continue
 		}
+		published = append(published, events[i])
+	}
+	logger.FromContext(ctx).Info("Event batch published to queues", logger.Fields{"events": len(events), "published": len(published)})
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "Event created successfully and queued for processing",
-		"event":   event,
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		for _, event := range published {
+			h.dispatchWebhooks(ctx, event, "")
+		}
+	}()
+	return results
+}
+
+// routeEvent returns the queue a new event is published to
+func (h *EventHandler) routeEvent(event *models.Event) string {
+	if h.router != nil {
+		return h.router.Route(event)
+	}
+	return routing.DefaultQueue
+}
+
+// ConfigureGRPC shares the validation, enrichment and queueing of new events with the gRPC
+// event service, so events created over gRPC are processed like those created over HTTP
+func (h *EventHandler) ConfigureGRPC(s *grpcserver.EventServer) {
+	s.SetValidator(h.validator)
+	s.SetEnrichmentPipeline(h.enrichment)
+	s.SetPublisher(func(ctx context.Context, event *models.Event) {
+		h.queueEvent(ctx, event, "")
 	})
+	s.SetBatchPublisher(h.publishEvents)
 }
 
-// GetEvents handles event retrieval
+// bindCreateEventRequest reads a JSON body, or a body in another format decoded by the decoder
+// registered for its Content-Type. Invalid fields are added to problems; when the body cannot
+// be read at all it responds with 400, or 415 for an unknown format, and returns false.
+func (h *EventHandler) bindCreateEventRequest(c *gin.Context, req *models.CreateEventRequest, problems *apperrors.ValidationErrors) bool {
+	mediaType := c.ContentType()
+	if mediaType == "" || mediaType == gin.MIMEJSON {
+		if err := h.bindJSON(c, req, problems); err != nil && !addBindingErrors(problems, err) {
+			api.Fail(c, apperrors.NewValidationError("Invalid request body", err.Error()))
+			return false
+		}
+		return true
+	}
+
+	decoder, ok := h.decoders.Lookup(mediaType)
+	if !ok {
+		supported := append([]string{gin.MIMEJSON}, h.decoders.MediaTypes()...)
+		api.Fail(c, apperrors.NewUnsupportedMediaTypeError("Unsupported content type: "+mediaType, "Supported: "+strings.Join(supported, ", ")))
+		return false
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		api.Fail(c, apperrors.NewValidationError("Invalid request body", err.Error()))
+		return false
+	}
+	decoded, err := decoder.Decode(body)
+	if err != nil {
+		api.Fail(c, apperrors.NewValidationError("Invalid request body", err.Error()))
+		return false
+	}
+
+	*req = *decoded
+	if err := binding.Validator.ValidateStruct(req); err != nil && !addBindingErrors(problems, err) {
+		api.Fail(c, apperrors.NewValidationError("Invalid request body", err.Error()))
+		return false
+	}
+	return true
+}
+
+// bindJSON binds a JSON body to req. When unknown fields are rejected, each one is added to
+// problems.
+func (h *EventHandler) bindJSON(c *gin.Context, req *models.CreateEventRequest, problems *apperrors.ValidationErrors) error {
+	if !h.rejectUnknownFields {
+		return c.ShouldBindJSON(req)
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		return err
+	}
+	addUnknownFields(problems, body, req)
+	return binding.JSON.BindBody(body, req)
+}
+
+// dispatchWebhooks queues a webhook_dispatch message for each matching subscription
+func (h *EventHandler) dispatchWebhooks(ctx context.Context, event *models.Event, requestID string) {
+	if h.webhookRepo == nil {
+		return
+	}
+
+	subs, err := h.webhookRepo.ListActiveSubscriptions(event.EventType)
+	if err != nil {
+		logger.FromContext(ctx).Error("Failed to load webhook subscriptions", err, logger.Fields{"event_id": event.EventID})
+		return
+	}
+
+	for _, sub := range subs {
+		message := queue.Message{
+			ID:   event.EventID + ":" + sub.ID,
+			Type: webhook.DispatchMessageType,
+			Data: map[string]interface{}{
+				"subscription_id": sub.ID,
+				"event":           event,
+			},
+			Timestamp: time.Now(),
+		}
+		if requestID != "" {
+			message.SetHeader(queue.RequestIDHeader, requestID)
+		}
+		if err := queue.PublishMessageContext(ctx, h.queueManager, message, webhook.DispatchQueue); err != nil {
+			logger.FromContext(ctx).Error("Failed to queue webhook dispatch", err, logger.Fields{"event_id": event.EventID, "subscription_id": sub.ID})
+		}
+	}
+}
+
+// Pagination limits for event listing
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// GetEvents handles paginated event retrieval
 func (h *EventHandler) GetEvents(c *gin.Context) {
-	events, err := h.eventRepo.GetAllEvents()
+	limit := defaultPageSize
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed <= 0 || parsed > maxPageSize {
+			api.Fail(c, apperrors.NewValidationError(fmt.Sprintf("limit must be between 1 and %d", maxPageSize), ""))
+			return
+		}
+		limit = parsed
+	}
+
+	var after *pagination.Cursor
+	if token := c.Query("cursor"); token != "" {
+		cursor, err := h.cursorCodec.Decode(token)
+		if err != nil {
+			api.Fail(c, err)
+			return
+		}
+		after = cursor
+	}
+
+	filter, ok := h.parseEventFilter(c)
+	if !ok {
+		return
+	}
+	eventSort, ok := parseEventSort(c)
+	if !ok {
+		return
+	}
+	if after != nil && after.Sort != eventSort.Token() {
+		api.Fail(c, apperrors.NewValidationError("Invalid cursor", "cursor was issued for a different sort order"))
+		return
+	}
+	page := models.PaginationParams{Limit: limit + 1, After: after, Sort: eventSort}
+
+	// Full events, including event_data, only on request
+	if c.Query("expand") == "event_data" {
+		// Fetch one extra row to know whether another page exists
+		events, err := h.eventRepo.ListEvents(c.Request.Context(), filter, page)
+		if err != nil {
+			api.Fail(c, apperrors.NewInternalError("Failed to retrieve events", err))
+			return
+		}
+
+		var nextCursor string
+		if len(events) > limit {
+			events = events[:limit]
+			last := events[len(events)-1]
+			nextCursor = h.cursorCodec.Encode(eventCursor(eventSort, last.CreatedAt, last.ID, last.Severity, last.EventType))
+		}
+
+		api.CursorPaginated(c, events, nil, limit, nextCursor)
+		return
+	}
+
+	summaries, totalCount, err := h.eventRepo.GetEventSummaries(c.Request.Context(), filter, page)
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve events", err))
+		return
+	}
+
+	var nextCursor string
+	if len(summaries) > limit {
+		summaries = summaries[:limit]
+		last := summaries[len(summaries)-1]
+		nextCursor = h.cursorCodec.Encode(eventCursor(eventSort, last.CreatedAt, last.ID, last.Severity, last.EventType))
+	}
+
+	api.CursorPaginated(c, summaries, &totalCount, limit, nextCursor)
+}
+
+// parseEventFilter reads the event filter query parameters, responding with 400 when one is
+// invalid. data.<key>=<value> parameters filter by indexed event_data keys.
+func (h *EventHandler) parseEventFilter(c *gin.Context) (models.EventFilter, bool) {
+	var filter models.EventFilter
+	if technique := c.Query("attack_technique"); technique != "" {
+		technique = strings.ToUpper(technique)
+		if !attackTechniquePattern.MatchString(technique) {
+			api.Fail(c, apperrors.NewValidationError("attack_technique must be a technique ID such as T1078 or T1110.001", ""))
+			return filter, false
+		}
+		filter.ATTACKTechnique = technique
+	}
+
+	var problems apperrors.ValidationErrors
+	query := c.Request.URL.Query()
+	params := make([]string, 0, len(query))
+	for param := range query {
+		params = append(params, param)
+	}
+	sort.Strings(params)
+	for _, param := range params {
+		field, ok := strings.CutPrefix(param, "data.")
+		if !ok {
+			continue
+		}
+		if problem := h.eventRepo.CheckDataFieldFilter(field); problem != "" {
+			problems.Add(param, problem)
+			continue
+		}
+		if filter.DataFields == nil {
+			filter.DataFields = make(map[string]string)
+		}
+		filter.DataFields[field] = query.Get(param)
+	}
+	if err := problems.Err(); err != nil {
+		api.Fail(c, err)
+		return filter, false
+	}
+
+	return filter, true
+}
+
+// parseEventSort reads the sort and order query parameters, responding with a validation error
+// when either is not supported. The default is created_at in descending order.
+func parseEventSort(c *gin.Context) (models.EventSort, bool) {
+	var eventSort models.EventSort
+	var problems apperrors.ValidationErrors
+
+	if field := c.Query("sort"); field != "" {
+		if slices.Contains(models.EventSortFields, field) {
+			eventSort.Field = field
+		} else {
+			problems.Add("sort", "must be one of "+strings.Join(models.EventSortFields, ", "))
+		}
+	}
+	switch strings.ToLower(c.DefaultQuery("order", "desc")) {
+	case "asc":
+		eventSort.Ascending = true
+	case "desc":
+	default:
+		problems.Add("order", "must be asc or desc")
+	}
+
+	if err := problems.Err(); err != nil {
+		api.Fail(c, err)
+		return eventSort, false
+	}
+	return eventSort, true
+}
+
+// eventCursor returns the position of an event in a listing ordered by sort
+func eventCursor(eventSort models.EventSort, createdAt time.Time, id, severity, eventType string) pagination.Cursor {
+	cursor := pagination.Cursor{CreatedAt: createdAt, ID: id, Sort: eventSort.Token()}
+	switch eventSort.Field {
+	case models.EventSortSeverity:
+		cursor.Key = severity
+	case models.EventSortEventType:
+		cursor.Key = eventType
+	}
+	return cursor
+}
+
+// attackTechniquePattern matches ATT&CK technique and sub-technique IDs
+var attackTechniquePattern = regexp.MustCompile(`^T\d{4}(\.\d{3})?$`)
+
+// timeSeriesBuckets lists the supported time series bucket sizes
+var timeSeriesBuckets = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+}
+
+// maxTimeSeriesBuckets caps the number of buckets a single request may produce
+const maxTimeSeriesBuckets = 10000
+
+// parseTimeRange reads the from and to query parameters, defaulting to the span before now,
+// and responds with 400 when either is invalid or from is not before to
+func parseTimeRange(c *gin.Context, span time.Duration) (time.Time, time.Time, bool) {
+	to := time.Now().UTC()
+	if rawTo := c.Query("to"); rawTo != "" {
+		parsed, err := time.Parse(time.RFC3339, rawTo)
+		if err != nil {
+			api.Fail(c, apperrors.NewValidationError("to must be an RFC3339 timestamp", ""))
+			return time.Time{}, time.Time{}, false
+		}
+		to = parsed
+	}
+
+	from := to.Add(-span)
+	if rawFrom := c.Query("from"); rawFrom != "" {
+		parsed, err := time.Parse(time.RFC3339, rawFrom)
+		if err != nil {
+			api.Fail(c, apperrors.NewValidationError("from must be an RFC3339 timestamp", ""))
+			return time.Time{}, time.Time{}, false
+		}
+		from = parsed
+	}
+
+	if !from.Before(to) {
+		api.Fail(c, apperrors.NewValidationError("from must be before to", ""))
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
+
+// GetEventTimeSeries handles event volume time series retrieval
+func (h *EventHandler) GetEventTimeSeries(c *gin.Context) {
+	bucketParam := c.DefaultQuery("bucket", "1m")
+	bucket, ok := timeSeriesBuckets[bucketParam]
+	if !ok {
+		api.Fail(c, apperrors.NewValidationError("bucket must be one of 1m, 5m, 1h", ""))
+		return
+	}
+
+	from, to, ok := parseTimeRange(c, time.Hour)
+	if !ok {
+		return
+	}
+
+	if to.Sub(from)/bucket > maxTimeSeriesBuckets {
+		api.Fail(c, apperrors.NewValidationError("Time range too large for bucket size", ""))
+		return
+	}
+
+	series, err := h.eventRepo.CountEventsByBucket(c.Request.Context(), bucket, from, to)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve events",
-		})
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve event time series", err))
 		return
 	}
 
-	// Get queue statistics if queue manager is available
-	var queueStats map[string]interface{}
-	if h.queueManager != nil {
-		queueStats = h.queueManager.GetQueueStats("security_events", "security_events_retry", "security_events_dead")
+	api.OK(c, gin.H{
+		"bucket": bucketParam,
+		"from":   from,
+		"to":     to,
+		"series": series,
+	})
+}
+
+// defaultTopSources and maxTopSources bound the number of sources of the event statistics
+const (
+	defaultTopSources = 10
+	maxTopSources     = 100
+)
+
+// GetEventStats handles the event statistics of dashboards: counts per severity and the
+// busiest sources of the events matching the listing filters, and counts per hour of the
+// events created between from and to, the last 24 hours by default
+func (h *EventHandler) GetEventStats(c *gin.Context) {
+	filter, ok := h.parseEventFilter(c)
+	if !ok {
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"events":     events,
-		"total":      len(events),
-		"queue_stats": queueStats,
+	from, to, ok := parseTimeRange(c, 24*time.Hour)
+	if !ok {
+		return
+	}
+	if to.Sub(from)/time.Hour > maxTimeSeriesBuckets {
+		api.Fail(c, apperrors.NewValidationError("Time range too large", ""))
+		return
+	}
+
+	limit := defaultTopSources
+	if rawLimit := c.Query("top"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed < 1 || parsed > maxTopSources {
+			api.Fail(c, apperrors.NewValidationError(fmt.Sprintf("top must be between 1 and %d", maxTopSources), ""))
+			return
+		}
+		limit = parsed
+	}
+
+	ctx := c.Request.Context()
+	bySeverity, err := h.eventRepo.CountEventsBySeverity(ctx, filter)
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve event statistics", err))
+		return
+	}
+	byHour, err := h.eventRepo.CountEventsByHour(ctx, from, to)
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve event statistics", err))
+		return
+	}
+	topSources, err := h.eventRepo.TopSources(ctx, limit, filter)
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve event statistics", err))
+		return
+	}
+
+	api.OK(c, gin.H{
+		"by_severity": bySeverity,
+		"by_hour":     byHour,
+		"top_sources": topSources,
+		"from":        from,
+		"to":          to,
 	})
 }
 
+// facetsCacheTTL is how long the event facets are served from memory; new values show up once
+// it passes
+const facetsCacheTTL = time.Minute
+
+// facetCache holds the last event facets read
+type facetCache struct {
+	mu      sync.Mutex
+	facets  *models.EventFacets
+	expires time.Time
+}
+
+// get returns the cached facets unless they expired
+func (fc *facetCache) get() (*models.EventFacets, bool) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if fc.facets == nil || time.Now().After(fc.expires) {
+		return nil, false
+	}
+	return fc.facets, true
+}
+
+// set caches facets for facetsCacheTTL
+func (fc *facetCache) set(facets *models.EventFacets) {
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	fc.facets = facets
+	fc.expires = time.Now().Add(facetsCacheTTL)
+}
+
+// GetEventFacets handles listing the distinct event types, severities and sources with their
+// event counts, for building filter controls. The result is cached for facetsCacheTTL.
+func (h *EventHandler) GetEventFacets(c *gin.Context) {
+	facets, ok := h.facets.get()
+	if !ok {
+		var err error
+		facets, err = h.eventRepo.GetEventFacets(c.Request.Context())
+		if err != nil {
+			api.Fail(c, apperrors.NewInternalError("Failed to retrieve event facets", err))
+			return
+		}
+		h.facets.set(facets)
+	}
+
+	api.OK(c, facets)
+}
+
+// GetEventSchema handles retrieval of the JSON Schema for event creation payloads. The
+// severity enumeration includes the labels accepted by the severity normalizer.
+func (h *EventHandler) GetEventSchema(c *gin.Context) {
+	var severities []string
+	if h.normalizer != nil {
+		seen := make(map[string]bool)
+		for _, severity := range models.Severities {
+			seen[severity] = true
+			severities = append(severities, severity)
+		}
+		for label := range h.normalizer.Mappings() {
+			if !seen[label] {
+				seen[label] = true
+				severities = append(severities, label)
+			}
+		}
+		sort.Strings(severities[len(models.Severities):])
+	}
+
+	c.Header("Content-Type", "application/schema+json")
+	c.JSON(http.StatusOK, models.CreateEventSchema(severities, h.defaults))
+}
+
+// ExportEvents handles event export in SIEM and threat intelligence formats
+func (h *EventHandler) ExportEvents(c *gin.Context) {
+	exportFormat := c.DefaultQuery("format", "cef")
+	if exportFormat != "cef" && exportFormat != "stix" {
+		api.Fail(c, apperrors.NewValidationError("Unsupported export format", ""))
+		return
+	}
+
+	filter, ok := h.parseEventFilter(c)
+	if !ok {
+		return
+	}
+
+	events, err := h.eventRepo.FindEvents(c.Request.Context(), filter)
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve events", err))
+		return
+	}
+
+	if exportFormat == "stix" {
+		writeSTIX(c, format.ToSTIXBundle(events))
+		return
+	}
+
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := bufio.NewWriter(c.Writer)
+	for _, event := range events {
+		if _, err := w.WriteString(format.ToCEF(event) + "\n"); err != nil {
+			logger.FromGinContext(c).Error("Failed to write CEF export", err)
+			return
+		}
+	}
+	if err := w.Flush(); err != nil {
+		logger.FromGinContext(c).Error("Failed to flush CEF export", err)
+	}
+}
+
+// GetEventSTIX handles single event retrieval as a STIX 2.1 bundle
+func (h *EventHandler) GetEventSTIX(c *gin.Context) {
+	eventID := c.Param("id")
+
+	event, err := h.eventRepo.GetEventByID(c.Request.Context(), eventID)
+	if err != nil {
+		if err.Error() == "event not found" {
+			api.Fail(c, apperrors.NewNotFoundError("Event", eventID))
+			return
+		}
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve event", err))
+		return
+	}
+
+	writeSTIX(c, format.ToSTIX(event))
+}
+
+// writeSTIX responds with a STIX bundle
+func writeSTIX(c *gin.Context, bundle *format.STIXBundle) {
+	data, err := format.MarshalSTIX(bundle)
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to encode STIX bundle", err))
+		return
+	}
+	c.Data(http.StatusOK, format.STIXContentType, data)
+}
+
 // GetEvent handles single event retrieval
 func (h *EventHandler) GetEvent(c *gin.Context) {
 	eventID := c.Param("id")
-	
-	event, err := h.eventRepo.GetEventByID(eventID)
+
+	event, err := h.eventRepo.GetEventByID(c.Request.Context(), eventID)
 	if err != nil {
 		if err.Error() == "event not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Event not found",
-			})
+			api.Fail(c, apperrors.NewNotFoundError("Event", eventID))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to retrieve event",
-		})
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve event", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"event": event,
-	})
+	api.OK(c, event)
 }
 
-// UpdateEvent handles event updates
+// UpdateEvent handles event updates. Every invalid field is reported at once.
 func (h *EventHandler) UpdateEvent(c *gin.Context) {
 	eventID := c.Param("id")
-	
+
+	var problems apperrors.ValidationErrors
+
 	var req models.UpdateEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !addBindingErrors(&problems, err) {
+		api.Fail(c, apperrors.NewValidationError("Invalid request body", err.Error()))
+		return
+	}
+	h.validator.CheckDescription(&problems, req.Description)
+
+	if h.normalizer != nil && req.Severity != "" {
+		severity, ok := h.normalizer.Normalize(req.Severity)
+		if ok {
+			req.Severity = severity
+		} else {
+			problems.Add("severity", "is not a known severity: "+req.Severity)
+		}
+	}
+
+	if err := problems.Err(); err != nil {
+		api.Fail(c, err)
+		return
+	}
+
+	event, err := h.eventRepo.UpdateEvent(c.Request.Context(), eventID, &req)
+	if err != nil {
+		if apperrors.IsValidation(err) {
+			api.Fail(c, err)
+			return
+		}
+		if err.Error() == "event not found" {
+			api.Fail(c, apperrors.NewNotFoundError("Event", eventID))
+			return
+		}
+		api.Fail(c, apperrors.NewInternalError("Failed to update event", err))
+		return
+	}
+
+	api.OK(c, event)
+}
+
+// PatchEvent handles merging keys into an event's data without resending the whole map
+func (h *EventHandler) PatchEvent(c *gin.Context) {
+	eventID := c.Param("id")
+
+	var req models.PatchEventRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid request body",
-		})
+		api.Fail(c, apperrors.NewValidationError("Invalid request body", err.Error()))
 		return
 	}
 
-	event, err := h.eventRepo.UpdateEvent(eventID, &req)
+	event, err := h.eventRepo.PatchEventData(c.Request.Context(), eventID, req.EventData)
 	if err != nil {
+		if apperrors.IsValidation(err) {
+			api.Fail(c, err)
+			return
+		}
 		if err.Error() == "event not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Event not found",
-			})
+			api.Fail(c, apperrors.NewNotFoundError("Event", eventID))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to update event",
-		})
+		api.Fail(c, apperrors.NewInternalError("Failed to update event", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Event updated successfully",
-		"event":   event,
-	})
+	api.OK(c, event)
 }
 
 // DeleteEvent handles event deletion
 func (h *EventHandler) DeleteEvent(c *gin.Context) {
 	eventID := c.Param("id")
-	
-	err := h.eventRepo.DeleteEvent(eventID)
+
+	err := h.eventRepo.DeleteEvent(c.Request.Context(), eventID)
 	if err != nil {
 		if err.Error() == "event not found" {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Event not found",
-			})
+			api.Fail(c, apperrors.NewNotFoundError("Event", eventID))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to delete event",
-		})
+		api.Fail(c, apperrors.NewInternalError("Failed to delete event", err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":  "Event deleted successfully",
+	api.OK(c, gin.H{
 		"event_id": eventID,
 	})
 }
 
-// generateEventID generates a unique event ID
-func generateEventID() string {
-	return "event-" + time.Now().Format("20060102150405") + "-" + time.Now().Format("000000000")
+// DeleteEvents handles deleting a batch of events by event ID
+func (h *EventHandler) DeleteEvents(c *gin.Context) {
+	var req models.DeleteEventsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.Fail(c, apperrors.NewValidationError(fmt.Sprintf("event_ids must contain between 1 and %d non-empty IDs", models.MaxDeleteBatchSize), ""))
+		return
+	}
+
+	result, err := h.eventRepo.DeleteEvents(c.Request.Context(), req.EventIDs)
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to delete events", err))
+		return
+	}
+
+	api.OK(c, gin.H{
+		"deleted":   result.Deleted,
+		"not_found": result.NotFound,
+	})
+}
+
+// Limits of the recent events of a source
+const (
+	defaultSourceEventsLimit = 50
+	maxSourceEventsLimit     = 500
+)
+
+// GetRecentEventsBySource handles retrieving the newest events of a source, optionally only
+// those created after the RFC3339 timestamp in since
+func (h *EventHandler) GetRecentEventsBySource(c *gin.Context) {
+	source := c.Param("source")
+
+	limit := defaultSourceEventsLimit
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed < 1 || parsed > maxSourceEventsLimit {
+			api.Fail(c, apperrors.NewValidationError(fmt.Sprintf("limit must be between 1 and %d", maxSourceEventsLimit), ""))
+			return
+		}
+		limit = parsed
+	}
+
+	var since time.Time
+	if rawSince := c.Query("since"); rawSince != "" {
+		parsed, err := time.Parse(time.RFC3339, rawSince)
+		if err != nil {
+			api.Fail(c, apperrors.NewValidationError("since must be an RFC3339 timestamp", ""))
+			return
+		}
+		since = parsed
+	}
+
+	events, err := h.eventRepo.GetRecentEventsBySource(c.Request.Context(), source, limit, since)
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve events", err))
+		return
+	}
+	if events == nil {
+		events = []*models.Event{}
+	}
+
+	api.OK(c, events)
+}
+
+// GetEventTimeline handles reconstructing the lifecycle of an event from its status
+// transitions and queue deliveries
+func (h *EventHandler) GetEventTimeline(c *gin.Context) {
+	eventID := c.Param("id")
+
+	event, err := h.eventRepo.GetEventByID(c.Request.Context(), eventID)
+	if err != nil {
+		if err.Error() == "event not found" {
+			api.Fail(c, apperrors.NewNotFoundError("Event", eventID))
+			return
+		}
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve event", err))
+		return
+	}
+
+	transitions, err := h.eventRepo.GetEventTransitions(c.Request.Context(), eventID)
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve event transitions", err))
+		return
+	}
+
+	processing, err := h.eventRepo.GetProcessingLog(c.Request.Context(), eventID)
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve event processing log", err))
+		return
+	}
+
+	api.OK(c, models.EventTimeline{
+		EventID:     event.EventID,
+		CreatedAt:   event.CreatedAt,
+		Status:      event.Status,
+		Transitions: transitions,
+		Processing:  processing,
+	})
+}
+
+// BulkUpdateStatus handles changing the status of a batch of events of the caller's tenant,
+// recording the caller's user ID. The outcome of each event is reported with 207
+// Multi-Status, and the updated events are announced on the queue.
+func (h *EventHandler) BulkUpdateStatus(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		api.Fail(c, apperrors.NewUnauthorizedError("Missing user identity"))
+		return
+	}
+	tenantID := c.GetString("tenant_id")
+
+	var problems apperrors.ValidationErrors
+
+	var req models.BulkStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !addBindingErrors(&problems, err) {
+		api.Fail(c, apperrors.NewValidationError("Invalid request body", err.Error()))
+		return
+	}
+	if req.Status != "" && !models.IsEventStatus(req.Status) {
+		problems.Add("status", "must be one of "+strings.Join(models.EventStatuses, ", "))
+	}
+	if err := problems.Err(); err != nil {
+		api.Fail(c, err)
+		return
+	}
+
+	result := h.eventRepo.BulkUpdateStatus(c.Request.Context(), tenantID, req.EventIDs, req.Status, userID, req.Note)
+	updated := result.UpdatedIDs()
+
+	auditf(c, h.features.AuditLogEnabled, "%d events set to %s by %s of tenant %q (request %s), %d failed", result.Updated, req.Status, userID, tenantID, c.GetString("request_id"), result.Failed)
+
+	if len(updated) > 0 {
+		requestID := c.GetString("request_id")
+		ctx := context.WithoutCancel(c.Request.Context())
+		go h.announceStatusUpdate(ctx, updated, req.Status, requestID)
+		if req.Status == models.EventStatusAcknowledged || req.Status == models.EventStatusResolved {
+			go h.resolveAlerts(ctx, updated)
+		}
+	}
+
+	api.Respond(c, http.StatusMultiStatus, result)
+}
+
+// announceStatusUpdate publishes one batch_status_updated message for downstream consumers
+func (h *EventHandler) announceStatusUpdate(ctx context.Context, eventIDs []string, status, requestID string) {
+	if h.queueManager == nil {
+		return
+	}
+
+	message := queue.Message{
+		ID:   uuid.NewString(),
+		Type: queue.StatusUpdateMessageType,
+		Data: map[string]interface{}{
+			"event_ids": eventIDs,
+			"status":    status,
+		},
+		Timestamp: time.Now(),
+	}
+	if requestID != "" {
+		message.SetHeader(queue.RequestIDHeader, requestID)
+	}
+
+	if err := queue.PublishMessageContext(ctx, h.queueManager, message, queue.StatusUpdateQueue); err != nil {
+		logger.FromContext(ctx).Error("Failed to publish status update", err, logger.Fields{"events": len(eventIDs), "status": status})
+	}
+}
+
+// resolveAlerts resolves the open alerts of acknowledged or resolved events, once per dedup key
+func (h *EventHandler) resolveAlerts(ctx context.Context, eventIDs []string) {
+	if h.resolver == nil {
+		return
+	}
+
+	resolved := make(map[string]bool, len(eventIDs))
+	for _, eventID := range eventIDs {
+		event, err := h.eventRepo.GetEventByID(ctx, eventID)
+		if err != nil {
+			logger.FromContext(ctx).Error("Failed to load acknowledged event", err, logger.Fields{"event_id": eventID})
+			continue
+		}
+
+		key := notifier.DedupKey(event)
+		if resolved[key] {
+			continue
+		}
+		resolved[key] = true
+
+		if err := h.resolver.Resolve(ctx, key); err != nil {
+			logger.FromContext(ctx).Error("Failed to resolve alert", err, logger.Fields{"event_id": eventID})
+		}
+	}
+}
+
+// AcknowledgeEvent handles event acknowledgement, resolving any open alert for it
+func (h *EventHandler) AcknowledgeEvent(c *gin.Context) {
+	eventID := c.Param("id")
+
+	event, err := h.eventRepo.GetEventByID(c.Request.Context(), eventID)
+	if err != nil {
+		if err.Error() == "event not found" {
+			api.Fail(c, apperrors.NewNotFoundError("Event", eventID))
+			return
+		}
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve event", err))
+		return
+	}
+
+	if h.resolver != nil {
+		if err := h.resolver.Resolve(c.Request.Context(), notifier.DedupKey(event)); err != nil {
+			api.Fail(c, apperrors.NewUpstreamError("Failed to resolve alert", err).WithContext("event_id", event.EventID))
+			return
+		}
+	}
+
+	event, err = h.eventRepo.AcknowledgeEvent(c.Request.Context(), eventID)
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to acknowledge event", err))
+		return
+	}
+
+	api.OK(c, event)
 }
 
 // GetQueueStats handles queue statistics requests
 func (h *EventHandler) GetQueueStats(c *gin.Context) {
 	if h.queueManager == nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{
-			"error": "Queue manager not available",
-		})
+		api.Fail(c, apperrors.NewUnavailableError("Queue manager not available"))
 		return
 	}
 
 	stats := h.queueManager.GetQueueStats("security_events", "security_events_retry", "security_events_dead")
-	
-	c.JSON(http.StatusOK, gin.H{
+
+	api.OK(c, gin.H{
 		"queue_stats": stats,
 		"timestamp":   time.Now(),
 	})
-} 
\ No newline at end of file
+}
+
+// GetTenantQueueStats handles reporting the depth of the tenant queue of every tenant with
+// stored events. The base queue defaults to the shared event queue and can be set with ?queue=.
+func (h *EventHandler) GetTenantQueueStats(c *gin.Context) {
+	naming, ok := h.queueManager.(interface{ TenantQueueNaming() bool })
+	if !ok || !naming.TenantQueueNaming() {
+		api.Fail(c, apperrors.NewUnavailableError("Tenant queues not enabled"))
+		return
+	}
+
+	tenantIDs, err := h.eventRepo.ListTenantIDs(c.Request.Context())
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to list tenants", err))
+		return
+	}
+
+	baseQueue := c.DefaultQuery("queue", routing.DefaultQueue)
+	tenants := make(map[string]interface{}, len(tenantIDs))
+	for _, tenantID := range tenantIDs {
+		queueName := queue.TenantQueueName(baseQueue, tenantID)
+		depth, err := h.queueManager.GetQueueLength(queueName)
+		if err != nil {
+			tenants[tenantID] = gin.H{"queue": queueName, "error": err.Error()}
+			continue
+		}
+		tenants[tenantID] = gin.H{"queue": queueName, "depth": depth}
+	}
+
+	api.OK(c, gin.H{
+		"queue":     baseQueue,
+		"tenants":   tenants,
+		"timestamp": time.Now(),
+	})
+}
+
+// replayBatchSize is how many events a replay reads from the database at a time, and how often
+// it reports progress
+const replayBatchSize = 500
+
+// ReplayEvents handles republishing the events created in [from, to) to a queue, oldest first,
+// so they are processed again. Progress is streamed as newline delimited JSON: a line after every
+// replayBatchSize events and a final line with the total. The replay stops when the request is
+// cancelled or its deadline passes; the final line then carries resume_from, the creation time
+// of the last event replayed, so the rest of the window can be replayed with a new request.
+func (h *EventHandler) ReplayEvents(c *gin.Context) {
+	if !h.features.QueueEnabled || h.queueManager == nil {
+		api.Fail(c, apperrors.NewUnavailableError("Queue manager not available"))
+		return
+	}
+
+	var problems apperrors.ValidationErrors
+	var from, to time.Time
+	for _, bound := range []struct {
+		name  string
+		value *time.Time
+	}{{"from", &from}, {"to", &to}} {
+		raw := c.Query(bound.name)
+		if raw == "" {
+			problems.Add(bound.name, "is required")
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			problems.Add(bound.name, "must be an RFC3339 timestamp")
+			continue
+		}
+		*bound.value = parsed
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		problems.Add("from", "must be before to")
+	}
+	if err := problems.Err(); err != nil {
+		api.Fail(c, err)
+		return
+	}
+
+	queueName := c.DefaultQuery("queue", routing.DefaultQueue)
+	replayID := uuid.New().String()
+	opts := []queue.PublishOption{queue.WithHeader(queue.ReplayHeader, replayID)}
+	if requestID := c.GetString("request_id"); requestID != "" {
+		opts = append(opts, queue.WithHeader(queue.RequestIDHeader, requestID))
+	}
+
+	// A replay can outlast the server's write timeout; keep the connection writable until the
+	// request deadline instead
+	ctx := c.Request.Context()
+	deadline, _ := ctx.Deadline()
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(deadline); err != nil {
+		logger.FromGinContext(c).Warn("Failed to extend the write deadline of replay", logger.Fields{"replay_id": replayID, "error": err.Error()})
+	}
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Status(http.StatusOK)
+	c.Writer.WriteHeaderNow()
+	progress := json.NewEncoder(c.Writer)
+
+	replayed := 0
+	var lastCreatedAt time.Time
+	err := h.eventRepo.StreamEventsCreatedBetween(ctx, from, to, replayBatchSize, func(event *models.Event) error {
+		if err := queue.PublishEventContext(ctx, h.queueManager, event, queueName, opts...); err != nil {
+			return fmt.Errorf("failed to publish event %s: %w", event.EventID, err)
+		}
+		replayed++
+		lastCreatedAt = event.CreatedAt
+		if replayed%replayBatchSize == 0 {
+			progress.Encode(gin.H{"replay_id": replayID, "replayed": replayed})
+			c.Writer.Flush()
+		}
+		return ctx.Err()
+	})
+
+	result := gin.H{"replay_id": replayID, "queue": queueName, "replayed": replayed, "done": err == nil}
+	if err != nil {
+		logger.FromGinContext(c).Error("Replay stopped", err, logger.Fields{"replay_id": replayID, "replayed": replayed})
+		result["error"] = "Replay stopped before all events were published"
+		resumeFrom := from
+		if replayed > 0 {
+			resumeFrom = lastCreatedAt
+		}
+		result["resume_from"] = resumeFrom.Format(time.RFC3339Nano)
+	}
+	progress.Encode(result)
+
+	auditf(c, h.features.AuditLogEnabled, "replayed %d events created between %s and %s to %s by %s (request %s)", replayed, from.Format(time.RFC3339), to.Format(time.RFC3339), queueName, c.ClientIP(), c.GetString("request_id"))
+}
+
+// PurgeQueue handles dropping every message waiting in a queue
+func (h *EventHandler) PurgeQueue(c *gin.Context) {
+	purger, ok := h.queueManager.(queue.Purger)
+	if !ok {
+		api.Fail(c, apperrors.NewUnavailableError("Queue purging not available"))
+		return
+	}
+
+	queueName := c.Param("name")
+	purged, err := purger.PurgeQueue(queueName)
+	if err != nil {
+		if errors.Is(err, queue.ErrQueueNotFound) {
+			api.Fail(c, apperrors.NewNotFoundError("Queue", queueName))
+			return
+		}
+		api.Fail(c, apperrors.NewInternalError("Failed to purge queue", err))
+		return
+	}
+
+	auditf(c, h.features.AuditLogEnabled, "queue %s purged of %d messages by %s (request %s)", queueName, purged, c.ClientIP(), c.GetString("request_id"))
+
+	api.OK(c, gin.H{
+		"queue":  queueName,
+		"purged": purged,
+	})
+}
+
+// PeekMessage handles showing the next message of a queue without consuming it. The message
+// is requeued, which can change the order in which consumers receive it.
+func (h *EventHandler) PeekMessage(c *gin.Context) {
+	peeker, ok := h.queueManager.(queue.Peeker)
+	if !ok {
+		api.Fail(c, apperrors.NewUnavailableError("Queue peeking not available"))
+		return
+	}
+
+	queueName := c.Param("name")
+	message, err := peeker.PeekMessage(queueName)
+	if err != nil {
+		if errors.Is(err, queue.ErrQueueNotFound) {
+			api.Fail(c, apperrors.NewNotFoundError("Queue", queueName))
+			return
+		}
+		api.Fail(c, apperrors.NewInternalError("Failed to peek at queue", err))
+		return
+	}
+
+	api.OK(c, gin.H{
+		"queue":   queueName,
+		"message": message,
+	})
+}
+
+// ReplayDeadLettersRequest selects the dead-lettered messages to move back for processing
+type ReplayDeadLettersRequest struct {
+	SourceQueue string `json:"source_queue"`
+	TargetQueue string `json:"target_queue"`
+	Limit       int    `json:"limit" binding:"omitempty,min=1,max=1000"`
+}
+
+// ReplayDeadLetters handles moving dead-lettered messages back to a queue for processing
+func (h *EventHandler) ReplayDeadLetters(c *gin.Context) {
+	replayer, ok := h.queueManager.(queue.DeadLetterReplayer)
+	if !ok {
+		api.Fail(c, apperrors.NewUnavailableError("Dead letter replay not available"))
+		return
+	}
+
+	var req ReplayDeadLettersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.Fail(c, apperrors.NewValidationError("Invalid request body", err.Error()))
+		return
+	}
+	if req.SourceQueue == "" {
+		req.SourceQueue = routing.DefaultQueue + "_dead"
+	}
+	if req.TargetQueue == "" {
+		req.TargetQueue = routing.DefaultQueue
+	}
+	if req.Limit == 0 {
+		req.Limit = 100
+	}
+	if req.SourceQueue == req.TargetQueue {
+		api.Fail(c, apperrors.NewValidationError("source_queue and target_queue must differ", ""))
+		return
+	}
+
+	replayed, err := replayer.ReplayDeadLetters(c.Request.Context(), req.SourceQueue, req.TargetQueue, req.Limit)
+	if err != nil {
+		if errors.Is(err, queue.ErrQueueNotFound) {
+			api.Fail(c, apperrors.NewNotFoundError("Queue", req.SourceQueue))
+			return
+		}
+		appErr := apperrors.NewInternalError("Failed to replay dead letters", err).
+			WithContext("source_queue", req.SourceQueue).
+			WithContext("target_queue", req.TargetQueue).
+			WithContext("replayed", replayed)
+		appErr.Details = fmt.Sprintf("%d messages were replayed before the replay stopped", replayed)
+		api.Fail(c, appErr)
+		return
+	}
+
+	auditf(c, h.features.AuditLogEnabled, "replayed %d messages from %s to %s by %s (request %s)", replayed, req.SourceQueue, req.TargetQueue, c.ClientIP(), c.GetString("request_id"))
+
+	api.OK(c, gin.H{
+		"source_queue": req.SourceQueue,
+		"target_queue": req.TargetQueue,
+		"replayed":     replayed,
+	})
+}
+
+// GetDeadLetterCount handles reporting the number of messages in a queue's dead letter queue
+func (h *EventHandler) GetDeadLetterCount(c *gin.Context) {
+	if h.queueManager == nil {
+		api.Fail(c, apperrors.NewUnavailableError("Queue manager not available"))
+		return
+	}
+
+	deadQueue := c.Param("name") + "_dead"
+	count, err := h.queueManager.GetQueueLength(deadQueue)
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to get dead letter count", err))
+		return
+	}
+
+	api.OK(c, gin.H{
+		"queue":             c.Param("name"),
+		"dead_letter_queue": deadQueue,
+		"dead_count":        count,
+	})
+}
@@ -0,0 +1,1571 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/config"
+	apperrors "skyhawk-security-microservice/internal/errors"
+	"skyhawk-security-microservice/internal/logger"
+	"skyhawk-security-microservice/internal/middleware"
+	"skyhawk-security-microservice/internal/models"
+	"skyhawk-security-microservice/internal/normalization"
+	"skyhawk-security-microservice/internal/queue"
+	"skyhawk-security-microservice/internal/ratelimit"
+	"skyhawk-security-microservice/internal/routing"
+)
+
+// fakeEventStore serves a fixed list of events and records created ones; EventStore methods a
+// test does not need panic
+type fakeEventStore struct {
+	EventStore
+	events  []*models.Event
+	created []*models.Event
+	// filter is the filter of the last listing
+	filter models.EventFilter
+	// afterEach runs after an event was handed to a stream callback
+	afterEach func(replayed int)
+}
+
+func (s *fakeEventStore) StreamEventsCreatedBetween(ctx context.Context, from, to time.Time, batchSize int, fn func(*models.Event) error) error {
+	for i, event := range s.events {
+		if event.CreatedAt.Before(from) || !event.CreatedAt.Before(to) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+		if s.afterEach != nil {
+			s.afterEach(i + 1)
+		}
+	}
+	return nil
+}
+
+func (s *fakeEventStore) CreateEvent(ctx context.Context, event *models.Event) error {
+	s.created = append(s.created, event)
+	return nil
+}
+
+func (s *fakeEventStore) ListEvents(ctx context.Context, filter models.EventFilter, page models.PaginationParams) ([]*models.Event, error) {
+	if len(s.events) > page.Limit {
+		return s.events[:page.Limit], nil
+	}
+	return s.events, nil
+}
+
+func (s *fakeEventStore) GetEventSummaries(ctx context.Context, filter models.EventFilter, page models.PaginationParams) ([]models.EventSummary, int64, error) {
+	s.filter = filter
+	events, _ := s.ListEvents(ctx, filter, page)
+	summaries := make([]models.EventSummary, len(events))
+	for i, event := range events {
+		summaries[i] = models.EventSummary{
+			ID:        event.ID,
+			EventID:   event.EventID,
+			EventType: event.EventType,
+			Severity:  event.Severity,
+			Source:    event.Source,
+			Status:    "open",
+			CreatedAt: event.CreatedAt,
+		}
+	}
+	return summaries, int64(len(s.events)), nil
+}
+
+func (s *fakeEventStore) CheckDataFieldFilter(field string) string {
+	if !slices.Contains(models.IndexedEventDataFields, field) {
+		return "is not filterable"
+	}
+	return ""
+}
+
+func newTestEvents(start time.Time, n int) []*models.Event {
+	events := make([]*models.Event, n)
+	for i := range events {
+		events[i] = &models.Event{
+			EventID:   "evt-" + string(rune('a'+i)),
+			EventType: "login_failure",
+			Severity:  "high",
+			Source:    "auth-service",
+			CreatedAt: start.Add(time.Duration(i) * time.Minute),
+		}
+	}
+	return events
+}
+
+// replayLines runs a replay request and returns its newline delimited JSON lines
+func replayLines(t *testing.T, h *EventHandler, req *http.Request) []map[string]interface{} {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/api/v1/events/replay", h.ReplayEvents)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var lines []map[string]interface{}
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		var line map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid progress line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		t.Fatal("replay wrote no progress")
+	}
+	return lines
+}
+
+func TestReplayEventsPublishesWindow(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := &fakeEventStore{events: newTestEvents(start, 5)}
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+	h := NewEventHandler(store, mq)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events/replay?from=2026-03-01T12:01:00Z&to=2026-03-01T12:04:00Z&queue=replayed", nil)
+	lines := replayLines(t, h, req)
+
+	result := lines[len(lines)-1]
+	if result["done"] != true || result["replayed"] != float64(3) {
+		t.Errorf("final line = %v, want 3 events replayed and done", result)
+	}
+	if _, ok := result["resume_from"]; ok {
+		t.Errorf("completed replay reported resume_from: %v", result)
+	}
+
+	published := mq.Published("replayed")
+	if len(published) != 3 {
+		t.Fatalf("published %d messages, want 3", len(published))
+	}
+	for _, message := range published {
+		if message.Headers[queue.ReplayHeader] != result["replay_id"] {
+			t.Errorf("message %s carries replay ID %q, want %v", message.ID, message.Headers[queue.ReplayHeader], result["replay_id"])
+		}
+	}
+}
+
+func TestReplayEventsReportsResumePointWhenStopped(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := &fakeEventStore{
+		events: newTestEvents(start, 5),
+		// The deadline passes after the second event
+		afterEach: func(replayed int) {
+			if replayed == 2 {
+				cancel()
+			}
+		},
+	}
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+	h := NewEventHandler(store, mq)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events/replay?from=2026-03-01T00:00:00Z&to=2026-03-02T00:00:00Z", nil).WithContext(ctx)
+	lines := replayLines(t, h, req)
+
+	result := lines[len(lines)-1]
+	if result["done"] != false || result["replayed"] != float64(2) {
+		t.Errorf("final line = %v, want 2 events replayed and not done", result)
+	}
+	if want := start.Add(time.Minute).Format(time.RFC3339Nano); result["resume_from"] != want {
+		t.Errorf("resume_from = %v, want %s", result["resume_from"], want)
+	}
+}
+
+func TestRequestTimeoutsGiveReplayItsOwnDeadline(t *testing.T) {
+	t.Setenv("REQUEST_TIMEOUT", "")
+	t.Setenv("ROUTE_TIMEOUTS", "")
+
+	config := requestTimeoutsFromEnv()
+	if timeout := config.Routes["POST /api/v1/events/replay"]; timeout != replayRequestTimeout {
+		t.Errorf("replay deadline = %s, want %s", timeout, replayRequestTimeout)
+	}
+	if config.Default != defaultRequestTimeout {
+		t.Errorf("default deadline = %s, want %s", config.Default, defaultRequestTimeout)
+	}
+
+	t.Setenv("ROUTE_TIMEOUTS", "POST /api/v1/events/replay=0")
+	if timeout, ok := requestTimeoutsFromEnv().Routes["POST /api/v1/events/replay"]; !ok || timeout != 0 {
+		t.Errorf("ROUTE_TIMEOUTS override gave replay deadline %s, want 0", timeout)
+	}
+}
+
+// serve runs a request against a router with the given route registered
+func serve(method, route string, handler gin.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("request_id", c.GetHeader("X-Request-ID"))
+	})
+	router.Handle(method, route, handler)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGetEventsSummariesAreSmallerThanFullEvents(t *testing.T) {
+	events := make([]*models.Event, 100)
+	for i := range events {
+		events[i] = &models.Event{
+			ID:            fmt.Sprint(i + 1),
+			EventID:       fmt.Sprintf("event-%03d", i),
+			EventType:     "login_failure",
+			Severity:      "high",
+			Source:        "auth-service",
+			Description:   "Repeated failed login attempts for a privileged account",
+			EventData:     models.EventData{"ip": "203.0.113.7", "user": "admin", "attempts": 5, "user_agent": "Mozilla/5.0 (X11; Linux x86_64)"},
+			CorrelationID: fmt.Sprintf("event-%03d", i),
+			CreatedAt:     time.Date(2026, 1, 1, 0, i, 0, 0, time.UTC),
+			UpdatedAt:     time.Date(2026, 1, 1, 0, i, 0, 0, time.UTC),
+		}
+	}
+	h := NewEventHandler(&fakeEventStore{events: events}, nil)
+
+	summaries := serve(http.MethodGet, "/api/v1/events", h.GetEvents, httptest.NewRequest(http.MethodGet, "/api/v1/events?limit=100", nil))
+	full := serve(http.MethodGet, "/api/v1/events", h.GetEvents, httptest.NewRequest(http.MethodGet, "/api/v1/events?limit=100&expand=event_data", nil))
+	if summaries.Code != http.StatusOK || full.Code != http.StatusOK {
+		t.Fatalf("status = %d and %d, want 200: %s %s", summaries.Code, full.Code, summaries.Body.String(), full.Body.String())
+	}
+
+	var page struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(summaries.Body.Bytes(), &page); err != nil {
+		t.Fatalf("decode summaries: %v", err)
+	}
+	if len(page.Data) != 100 {
+		t.Fatalf("summary page has %d events, want 100", len(page.Data))
+	}
+	if _, ok := page.Data[0]["event_data"]; ok {
+		t.Error("summary includes event_data")
+	}
+	if page.Data[0]["status"] != "open" {
+		t.Errorf("summary status = %v, want open", page.Data[0]["status"])
+	}
+
+	if summarySize, fullSize := summaries.Body.Len(), full.Body.Len(); summarySize*2 > fullSize {
+		t.Errorf("summary response is %d bytes and full response %d bytes, want at most half", summarySize, fullSize)
+	}
+}
+
+func TestGetEventsRejectsUnindexedDataField(t *testing.T) {
+	store := &fakeEventStore{}
+	h := NewEventHandler(store, nil)
+
+	rec := serve(http.MethodGet, "/api/v1/events", h.GetEvents, httptest.NewRequest(http.MethodGet, "/api/v1/events?data.password=hunter2", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "data.password") {
+		t.Errorf("response %s does not name the rejected field", rec.Body.String())
+	}
+
+	rec = serve(http.MethodGet, "/api/v1/events", h.GetEvents, httptest.NewRequest(http.MethodGet, "/api/v1/events?data.username=alice", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if got := store.filter.DataFields; len(got) != 1 || got["username"] != "alice" {
+		t.Errorf("listing filtered by %v, want username=alice", got)
+	}
+}
+
+func TestCreateEventPublishesToQueue(t *testing.T) {
+	store := &fakeEventStore{}
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+	h := NewEventHandler(store, mq)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events", strings.NewReader(`{"event_type":"login_failure","severity":"high","source":"auth-service"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "req-1")
+	rec := serve(http.MethodPost, "/api/v1/events", h.CreateEvent, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if len(store.created) != 1 {
+		t.Fatalf("stored %d events, want 1", len(store.created))
+	}
+
+	// The event is published in the background
+	message, err := mq.ConsumeMessage("security_events", 2*time.Second)
+	if err != nil {
+		t.Fatalf("no message published: %v", err)
+	}
+	if message.ID != store.created[0].EventID {
+		t.Errorf("published message %s, want the stored event %s", message.ID, store.created[0].EventID)
+	}
+	if message.Headers[queue.RequestIDHeader] != "req-1" {
+		t.Errorf("request ID header = %q, want req-1", message.Headers[queue.RequestIDHeader])
+	}
+}
+
+// recordingHandler keeps every log entry
+type recordingHandler struct {
+	mu      sync.Mutex
+	entries []logger.Entry
+}
+
+func (h *recordingHandler) Handle(entry logger.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, entry)
+	return nil
+}
+
+func (h *recordingHandler) Entries() []logger.Entry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]logger.Entry(nil), h.entries...)
+}
+
+var (
+	globalLogOnce    sync.Once
+	globalLogEntries *recordingHandler
+)
+
+// recordGlobalLog returns a handler keeping every entry logged through the global logger,
+// which queues and workers log through, from now on. It is shared by the tests of the
+// package, which tell their entries apart by field values.
+func recordGlobalLog() *recordingHandler {
+	globalLogOnce.Do(func() {
+		globalLogEntries = &recordingHandler{}
+		logger.GetLogger().AddHandler(globalLogEntries)
+	})
+	return globalLogEntries
+}
+
+func TestRequestIDReachesWorkerLogs(t *testing.T) {
+	entries := recordGlobalLog()
+	store := &fakeEventStore{}
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+	h := NewEventHandler(store, mq)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestIDMiddleware())
+	router.POST("/api/v1/events", h.CreateEvent)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events", strings.NewReader(`{"event_type":"login_failure","severity":"high","source":"auth-service"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Request-ID", "req-trace-1")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if got := rec.Header().Get("X-Request-ID"); got != "req-trace-1" {
+		t.Fatalf("X-Request-ID response header = %q, want req-trace-1", got)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go mq.StartConsumer("security_events", 1, queue.ConsumerConfig{Stop: stop})
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, entry := range entries.Entries() {
+			if entry.Message == "Processing event" && entry.Fields["request_id"] == "req-trace-1" {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("no worker log entry carries request_id req-trace-1; got %d entries", len(entries.Entries()))
+}
+
+func TestCreateEventPublishesToRoutedQueue(t *testing.T) {
+	store := &fakeEventStore{}
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+	h := NewEventHandler(store, mq)
+	router := routing.NewEventRouter("")
+	router.AddRule(10, func(event *models.Event) bool { return event.Severity == models.SeverityCritical }, "security_events_critical")
+	h.SetRouter(router)
+
+	for _, severity := range []string{"critical", "low"} {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/events", strings.NewReader(`{"event_type":"login_failure","severity":"`+severity+`","source":"auth-service"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if rec := serve(http.MethodPost, "/api/v1/events", h.CreateEvent, req); rec.Code != http.StatusCreated {
+			t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+		}
+	}
+
+	critical, err := mq.ConsumeMessage("security_events_critical", 2*time.Second)
+	if err != nil {
+		t.Fatalf("no message on security_events_critical: %v", err)
+	}
+	if event, _ := critical.Data["event"].(map[string]interface{}); event["severity"] != models.SeverityCritical {
+		t.Errorf("security_events_critical got %+v, want the critical event", critical.Data["event"])
+	}
+	low, err := mq.ConsumeMessage("security_events", 2*time.Second)
+	if err != nil {
+		t.Fatalf("no message on security_events: %v", err)
+	}
+	if event, _ := low.Data["event"].(map[string]interface{}); event["severity"] != models.SeverityLow {
+		t.Errorf("security_events got %+v, want the low event", low.Data["event"])
+	}
+}
+
+func TestCreateEventNormalizesSeverity(t *testing.T) {
+	store := &fakeEventStore{}
+	h := NewEventHandler(store, nil)
+	h.SetNormalizer(normalization.NewSeverityNormalizer())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events", strings.NewReader(`{"event_type":"login_failure","severity":"P1","source":"auth-service"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if rec := serve(http.MethodPost, "/api/v1/events", h.CreateEvent, req); rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if len(store.created) != 1 || store.created[0].Severity != models.SeverityCritical {
+		t.Fatalf("stored %+v, want one critical event", store.created)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/events", strings.NewReader(`{"event_type":"login_failure","severity":"urgent-ish","source":"auth-service"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := serve(http.MethodPost, "/api/v1/events", h.CreateEvent, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "severity") {
+		t.Errorf("body = %s, want the severity field reported", rec.Body.String())
+	}
+	if len(store.created) != 1 {
+		t.Error("event with an unknown severity was stored")
+	}
+}
+
+func TestCreateEventRateLimitsSource(t *testing.T) {
+	store := &fakeEventStore{}
+	h := NewEventHandler(store, nil)
+	h.SetSourceLimiter(ratelimit.NewSourceRateLimiter(ratelimit.Limit{Rate: 0.01, BurstSize: 10}))
+
+	var limited int
+	for i := 0; i < 30; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/events", strings.NewReader(`{"event_type":"port_scan","severity":"low","source":"firewall"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := serve(http.MethodPost, "/api/v1/events", h.CreateEvent, req)
+		switch rec.Code {
+		case http.StatusCreated:
+		case http.StatusTooManyRequests:
+			limited++
+			if source := rec.Header().Get("X-RateLimit-Source"); source != "firewall" {
+				t.Errorf("X-RateLimit-Source = %q, want firewall", source)
+			}
+		default:
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+		}
+	}
+
+	if limited != 20 {
+		t.Errorf("%d events were rate limited, want the 20 above the burst", limited)
+	}
+	if len(store.created) != 10 {
+		t.Errorf("stored %d events, want 10", len(store.created))
+	}
+}
+
+func TestCreateEventSkipsQueueWhenDisabled(t *testing.T) {
+	store := &fakeEventStore{}
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+	h := NewEventHandler(store, mq)
+	features := h.features
+	features.QueueEnabled = false
+	h.SetFeatures(features)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events", strings.NewReader(`{"event_type":"login_failure","severity":"high","source":"auth-service"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := serve(http.MethodPost, "/api/v1/events", h.CreateEvent, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+
+	if _, err := mq.ConsumeMessage("security_events", 100*time.Millisecond); err == nil {
+		t.Error("event published with the queue disabled")
+	}
+}
+
+// publishRecorder records every publish attempt; other QueueInterface methods panic
+type publishRecorder struct {
+	queue.QueueInterface
+	mu     sync.Mutex
+	events []string
+}
+
+func (q *publishRecorder) PublishEvent(event *models.Event, queueName string, opts ...queue.PublishOption) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.events = append(q.events, event.EventID)
+	return nil
+}
+
+func (q *publishRecorder) PublishMessage(message queue.Message, queueName string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.events = append(q.events, message.ID)
+	return nil
+}
+
+func (q *publishRecorder) Attempts() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.events)
+}
+
+func TestCreateEventAttemptsNoPublishWithQueueFeatureDisabled(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		store := &fakeEventStore{}
+		publisher := &publishRecorder{}
+		h := NewEventHandler(store, publisher)
+		features := config.DefaultFeatureFlags()
+		features.QueueEnabled = enabled
+		h.SetFeatures(features)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/events", strings.NewReader(`{"event_type":"login_failure","severity":"high","source":"auth-service"}`))
+		req.Header.Set("Content-Type", "application/json")
+		rec := serve(http.MethodPost, "/api/v1/events", h.CreateEvent, req)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("QueueEnabled=%v: status = %d, want %d: %s", enabled, rec.Code, http.StatusCreated, rec.Body.String())
+		}
+		if len(store.created) != 1 {
+			t.Errorf("QueueEnabled=%v: %d events stored, want 1", enabled, len(store.created))
+		}
+
+		// Publishing happens in the background, so give it the time the enabled case needs
+		deadline := time.Now().Add(500 * time.Millisecond)
+		for publisher.Attempts() == 0 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if want := map[bool]int{true: 1, false: 0}[enabled]; publisher.Attempts() != want {
+			t.Errorf("QueueEnabled=%v: %d publish attempts, want %d", enabled, publisher.Attempts(), want)
+		}
+	}
+}
+
+func TestCreateEventRejectsInvalidEventWithoutPublishing(t *testing.T) {
+	store := &fakeEventStore{}
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+	h := NewEventHandler(store, mq)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events", strings.NewReader(`{"severity":"high"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := serve(http.MethodPost, "/api/v1/events", h.CreateEvent, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+	if len(store.created) != 0 || len(mq.Published("security_events")) != 0 {
+		t.Error("invalid event was stored or published")
+	}
+}
+
+func TestCreateEventAcceptsStringifiedEventData(t *testing.T) {
+	store := &fakeEventStore{}
+	h := NewEventHandler(store, nil)
+
+	body := `{"event_type":"login_failure","severity":"high","source":"auth-service","event_data":"{\"ip\":\"203.0.113.7\"}"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := serve(http.MethodPost, "/api/v1/events", h.CreateEvent, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if len(store.created) != 1 || store.created[0].EventData["ip"] != "203.0.113.7" {
+		t.Errorf("stored %+v, want event data with ip 203.0.113.7", store.created)
+	}
+}
+
+func TestCreateEventRejectsEventDataThatIsNotAnObject(t *testing.T) {
+	store := &fakeEventStore{}
+	h := NewEventHandler(store, nil)
+
+	body := `{"event_type":"login_failure","severity":"high","source":"auth-service","event_data":[1,2]}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := serve(http.MethodPost, "/api/v1/events", h.CreateEvent, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), `"field":"event_data"`) {
+		t.Errorf("response %s does not report event_data", rec.Body.String())
+	}
+	if len(store.created) != 0 {
+		t.Error("event with invalid event data was stored")
+	}
+}
+
+func TestCreateEventResponsesUseEnvelopes(t *testing.T) {
+	h := NewEventHandler(&fakeEventStore{}, nil)
+	post := func(body string) map[string]json.RawMessage {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/events", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Request-ID", "req-1")
+		rec := serve(http.MethodPost, "/api/v1/events", h.CreateEvent, req)
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(rec.Body.Bytes(), &fields); err != nil {
+			t.Fatalf("body %s is not a JSON object: %v", rec.Body.String(), err)
+		}
+		return fields
+	}
+
+	created := post(`{"event_type":"login_failure","severity":"high","source":"auth-service"}`)
+	if len(created) != 2 || created["data"] == nil || string(created["meta"]) != `{"request_id":"req-1"}` {
+		t.Errorf("created response has fields %v, want data and meta", created)
+	}
+
+	failed := post(`{"severity":"high"}`)
+	for _, key := range []string{"type", "message", "code", "fields", "request_id"} {
+		if failed[key] == nil {
+			t.Errorf("error response lacks %s: %v", key, failed)
+		}
+	}
+	if failed["data"] != nil {
+		t.Errorf("error response has data: %s", failed["data"])
+	}
+}
+
+func TestReplayDeadLettersMovesMessages(t *testing.T) {
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+	h := NewEventHandler(nil, mq)
+
+	for _, id := range []string{"evt-1", "evt-2", "evt-3"} {
+		message := queue.NewEventMessage(&models.Event{EventID: id, EventType: "login_failure", Severity: "high"})
+		message.Retries = 3
+		if err := mq.PublishMessage(message, "security_events_dead"); err != nil {
+			t.Fatalf("PublishMessage: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/queue/replay", strings.NewReader(`{"limit":2}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := serve(http.MethodPost, "/api/v1/admin/queue/replay", h.ReplayDeadLetters, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	replayed := mq.Published("security_events")
+	if len(replayed) != 2 || replayed[0].ID != "evt-1" || replayed[1].ID != "evt-2" {
+		t.Fatalf("replayed %+v, want evt-1 and evt-2", replayed)
+	}
+	if replayed[0].Retries != 0 {
+		t.Errorf("replayed message kept %d retries, want 0", replayed[0].Retries)
+	}
+	if length, _ := mq.GetQueueLength("security_events_dead"); length != 1 {
+		t.Errorf("dead letter queue holds %d messages, want 1", length)
+	}
+}
+
+// recordingNotifier records the IDs of the events it is notified of
+type recordingNotifier struct {
+	mu       sync.Mutex
+	eventIDs []string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, event *models.Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.eventIDs = append(n.eventIDs, event.EventID)
+	return nil
+}
+
+func (n *recordingNotifier) EventIDs() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.eventIDs...)
+}
+
+func TestReplayedDeadLetterIsProcessedByConsumer(t *testing.T) {
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+	processed := &recordingNotifier{}
+	mq.AddBlockingNotifier(processed)
+	h := NewEventHandler(nil, mq)
+
+	message := queue.NewEventMessage(&models.Event{EventID: "evt-1", EventType: "login_failure", Severity: "high"})
+	message.Retries = 3
+	if err := mq.PublishMessage(message, "security_events_dead"); err != nil {
+		t.Fatalf("PublishMessage: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/queue/replay", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := serve(http.MethodPost, "/api/v1/admin/queue/replay", h.ReplayDeadLetters, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go mq.StartConsumer("security_events", 1, queue.ConsumerConfig{Stop: stop})
+
+	deadline := time.Now().Add(3 * time.Second)
+	for len(processed.EventIDs()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("replayed message was not processed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if ids := processed.EventIDs(); len(ids) != 1 || ids[0] != "evt-1" {
+		t.Errorf("processed %v, want [evt-1]", ids)
+	}
+	for _, name := range []string{"security_events", "security_events_retry", "security_events_dead"} {
+		if length, _ := mq.GetQueueLength(name); length != 0 {
+			t.Errorf("%s holds %d messages after processing, want 0", name, length)
+		}
+	}
+}
+
+// timelineStore keeps created events and records their status transitions, as the database
+// triggers do, and the processing log written by workers
+type timelineStore struct {
+	fakeEventStore
+	mu          sync.Mutex
+	transitions map[string][]models.EventTransition
+	processing  map[string][]models.ProcessingLogEntry
+}
+
+func newTimelineStore() *timelineStore {
+	return &timelineStore{
+		transitions: make(map[string][]models.EventTransition),
+		processing:  make(map[string][]models.ProcessingLogEntry),
+	}
+}
+
+func (s *timelineStore) CreateEvent(ctx context.Context, event *models.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event.CreatedAt = time.Now()
+	event.Status = models.EventStatusOpen
+	s.created = append(s.created, event)
+	s.transitions[event.EventID] = append(s.transitions[event.EventID], models.EventTransition{ToStatus: models.EventStatusOpen, ChangedAt: event.CreatedAt})
+	return nil
+}
+
+func (s *timelineStore) GetEventByID(ctx context.Context, id string) (*models.Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, event := range s.created {
+		if event.EventID == id {
+			return event, nil
+		}
+	}
+	return nil, fmt.Errorf("event not found")
+}
+
+func (s *timelineStore) AcknowledgeEvent(ctx context.Context, eventID string) (*models.Event, error) {
+	event, err := s.GetEventByID(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	event.AcknowledgedAt = &now
+	s.setStatus(event, models.EventStatusAcknowledged, now)
+	return event, nil
+}
+
+func (s *timelineStore) BulkUpdateStatus(ctx context.Context, tenantID string, eventIDs []string, status, updatedBy, note string) models.BulkResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := models.BulkResult{Status: status}
+	for _, event := range s.created {
+		if slices.Contains(eventIDs, event.EventID) && models.CanTransitionStatus(event.Status, status) {
+			s.setStatus(event, status, time.Now())
+			result.Updated++
+			result.Results = append(result.Results, models.BulkEventResult{EventID: event.EventID, Success: true})
+		}
+	}
+	return result
+}
+
+// setStatus moves event to status, recording the transition as the status trigger does
+func (s *timelineStore) setStatus(event *models.Event, status string, at time.Time) {
+	from := event.Status
+	event.Status = status
+	if from != status {
+		s.transitions[event.EventID] = append(s.transitions[event.EventID], models.EventTransition{FromStatus: &from, ToStatus: status, ChangedAt: at})
+	}
+}
+
+func (s *timelineStore) GetEventTransitions(ctx context.Context, eventID string) ([]models.EventTransition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.EventTransition{}, s.transitions[eventID]...), nil
+}
+
+func (s *timelineStore) RecordProcessing(ctx context.Context, entry models.ProcessingLogEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.processing[entry.EventID] = append(s.processing[entry.EventID], entry)
+	return nil
+}
+
+func (s *timelineStore) GetProcessingLog(ctx context.Context, eventID string) ([]models.ProcessingLogEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.ProcessingLogEntry{}, s.processing[eventID]...), nil
+}
+
+func TestGetEventTimelineReconstructsLifecycle(t *testing.T) {
+	store := newTimelineStore()
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+	mq.SetProcessingRecorder(store)
+	h := NewEventHandler(store, mq)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events", strings.NewReader(`{"event_type":"login_failure","severity":"high","source":"auth-service"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if rec := serve(http.MethodPost, "/api/v1/events", h.CreateEvent, req); rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d: %s", rec.Code, rec.Body.String())
+	}
+	eventID := store.created[0].EventID
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go mq.StartConsumer("security_events", 1, queue.ConsumerConfig{Stop: stop})
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if log, _ := store.GetProcessingLog(context.Background(), eventID); len(log) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the worker did not record processing the event")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/events/"+eventID+"/acknowledge", nil)
+	if rec := serve(http.MethodPost, "/api/v1/events/:id/acknowledge", h.AcknowledgeEvent, req); rec.Code != http.StatusOK {
+		t.Fatalf("acknowledge status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if rec := patchBatch(h, `{"event_ids":["`+eventID+`"],"status":"resolved"}`, "user-7", ""); rec.Code != http.StatusMultiStatus {
+		t.Fatalf("resolve status = %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/events/"+eventID+"/timeline", nil)
+	rec := serve(http.MethodGet, "/api/v1/events/:id/timeline", h.GetEventTimeline, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("timeline status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Data models.EventTimeline `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode timeline: %v", err)
+	}
+	timeline := body.Data
+
+	if timeline.EventID != eventID || timeline.Status != models.EventStatusResolved {
+		t.Errorf("timeline of %s is %s, want %s resolved", timeline.EventID, timeline.Status, eventID)
+	}
+	if len(timeline.Transitions) != 3 {
+		t.Fatalf("timeline has %d transitions, want 3: %+v", len(timeline.Transitions), timeline.Transitions)
+	}
+	created, acknowledged, resolved := timeline.Transitions[0], timeline.Transitions[1], timeline.Transitions[2]
+	if created.FromStatus != nil || created.ToStatus != models.EventStatusOpen {
+		t.Errorf("first transition = %+v, want the creation as open", created)
+	}
+	if acknowledged.FromStatus == nil || *acknowledged.FromStatus != models.EventStatusOpen || acknowledged.ToStatus != models.EventStatusAcknowledged {
+		t.Errorf("second transition = %+v, want open to acknowledged", acknowledged)
+	}
+	if resolved.FromStatus == nil || *resolved.FromStatus != models.EventStatusAcknowledged || resolved.ToStatus != models.EventStatusResolved {
+		t.Errorf("third transition = %+v, want acknowledged to resolved", resolved)
+	}
+	if acknowledged.ChangedAt.Before(created.ChangedAt) || resolved.ChangedAt.Before(acknowledged.ChangedAt) {
+		t.Error("transitions are out of order")
+	}
+	if len(timeline.Processing) != 1 || timeline.Processing[0].Outcome != models.ProcessingOutcomeProcessed || timeline.Processing[0].Queue != "security_events" {
+		t.Errorf("processing = %+v, want one processed delivery from security_events", timeline.Processing)
+	}
+}
+
+func TestReplayDeadLettersRejectsSameQueue(t *testing.T) {
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+	h := NewEventHandler(nil, mq)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/queue/replay", strings.NewReader(`{"source_queue":"security_events","target_queue":"security_events"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := serve(http.MethodPost, "/api/v1/admin/queue/replay", h.ReplayDeadLetters, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
+
+func TestGetDeadLetterCount(t *testing.T) {
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+	h := NewEventHandler(nil, mq)
+	if err := mq.PublishMessage(queue.Message{ID: "evt-1"}, "security_events_dead"); err != nil {
+		t.Fatalf("PublishMessage: %v", err)
+	}
+
+	rec := serve(http.MethodGet, "/api/v1/admin/queue/:name/dead-count", h.GetDeadLetterCount,
+		httptest.NewRequest(http.MethodGet, "/api/v1/admin/queue/security_events/dead-count", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var body struct {
+		Data struct {
+			DeadLetterQueue string `json:"dead_letter_queue"`
+			DeadCount       int64  `json:"dead_count"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if body.Data.DeadLetterQueue != "security_events_dead" || body.Data.DeadCount != 1 {
+		t.Errorf("response = %+v, want 1 message in security_events_dead", body.Data)
+	}
+}
+
+func TestGetQueueStatsWithoutQueue(t *testing.T) {
+	h := NewEventHandler(nil, nil)
+
+	rec := serve(http.MethodGet, "/api/v1/queue/stats", h.GetQueueStats, httptest.NewRequest(http.MethodGet, "/api/v1/queue/stats", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
+
+// bulkStatusStore keeps the status and tenant of events and applies bulk status updates like
+// the repository does, recording the caller of each
+type bulkStatusStore struct {
+	fakeEventStore
+	statuses  map[string]string
+	tenants   map[string]string
+	updatedBy []string
+}
+
+func (s *bulkStatusStore) BulkUpdateStatus(ctx context.Context, tenantID string, eventIDs []string, status, updatedBy, note string) models.BulkResult {
+	s.updatedBy = append(s.updatedBy, updatedBy)
+	result := models.BulkResult{Status: status}
+	for _, eventID := range eventIDs {
+		item := models.BulkEventResult{EventID: eventID}
+		from, found := s.statuses[eventID]
+		switch {
+		case !found || s.tenants[eventID] != tenantID:
+			item.Error = "event not found"
+		case !models.CanTransitionStatus(from, status):
+			item.Error = fmt.Sprintf("cannot change status from %s to %s", from, status)
+		default:
+			item.Success = true
+			s.statuses[eventID] = status
+		}
+		if item.Success {
+			result.Updated++
+		} else {
+			result.Failed++
+		}
+		result.Results = append(result.Results, item)
+	}
+	return result
+}
+
+// patchBatch sends a bulk status update as userID of tenantID, either of which may be empty
+func patchBatch(h *EventHandler, body, userID, tenantID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/events/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if userID != "" {
+		req.Header.Set(middleware.UserIDHeader, userID)
+	}
+	if tenantID != "" {
+		req.Header.Set(middleware.TenantIDHeader, tenantID)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.IdentityMiddleware())
+	router.PATCH("/api/v1/events/batch", h.BulkUpdateStatus)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestBulkUpdateStatusReportsEachEventAndPublishesOnce(t *testing.T) {
+	store := &bulkStatusStore{
+		statuses: map[string]string{"evt-1": models.EventStatusOpen, "evt-2": models.EventStatusAcknowledged, "evt-3": models.EventStatusOpen},
+		tenants:  map[string]string{"evt-1": "acme", "evt-2": "acme", "evt-3": "globex"},
+	}
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+	h := NewEventHandler(store, mq)
+
+	rec := patchBatch(h, `{"event_ids":["evt-1","evt-2","evt-3","evt-missing"],"status":"acknowledged","note":"triage"}`, "user-7", "acme")
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusMultiStatus, rec.Body.String())
+	}
+	var body struct {
+		Data models.BulkResult `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	result := body.Data
+	if result.Updated != 2 || result.Failed != 2 || len(result.Results) != 4 {
+		t.Fatalf("result = %+v, want 2 updated and 2 failed of 4", result)
+	}
+	want := []models.BulkEventResult{
+		{EventID: "evt-1", Success: true},
+		{EventID: "evt-2", Success: true},
+		{EventID: "evt-3", Error: "event not found"},
+		{EventID: "evt-missing", Error: "event not found"},
+	}
+	if !slices.Equal(result.Results, want) {
+		t.Errorf("results = %+v, want %+v", result.Results, want)
+	}
+	if !slices.Equal(store.updatedBy, []string{"user-7"}) {
+		t.Errorf("updated by %v, want the caller's user ID", store.updatedBy)
+	}
+
+	message, err := mq.ConsumeMessage(queue.StatusUpdateQueue, 2*time.Second)
+	if err != nil {
+		t.Fatalf("no status update published: %v", err)
+	}
+	if message.Type != queue.StatusUpdateMessageType || message.Data["status"] != models.EventStatusAcknowledged {
+		t.Errorf("published %s with status %v, want %s acknowledged", message.Type, message.Data["status"], queue.StatusUpdateMessageType)
+	}
+	if ids := fmt.Sprint(message.Data["event_ids"]); ids != "[evt-1 evt-2]" {
+		t.Errorf("announced event_ids %s, want the updated [evt-1 evt-2]", ids)
+	}
+	if _, err := mq.ConsumeMessage(queue.StatusUpdateQueue, 100*time.Millisecond); err == nil {
+		t.Error("a second status update was published for one batch")
+	}
+}
+
+func TestBulkUpdateStatusRejectsTransitionsOfTheStateMachine(t *testing.T) {
+	store := &bulkStatusStore{
+		statuses: map[string]string{"evt-1": models.EventStatusAcknowledged},
+		tenants:  map[string]string{"evt-1": ""},
+	}
+	publisher := &publishRecorder{}
+	h := NewEventHandler(store, publisher)
+
+	rec := patchBatch(h, `{"event_ids":["evt-1"],"status":"open"}`, "user-7", "")
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusMultiStatus, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "cannot change status from acknowledged to open") {
+		t.Errorf("response %s does not report the rejected transition", rec.Body.String())
+	}
+	if store.statuses["evt-1"] != models.EventStatusAcknowledged {
+		t.Errorf("evt-1 moved to %s", store.statuses["evt-1"])
+	}
+
+	rec = patchBatch(h, `{"event_ids":["evt-1"],"status":"archived"}`, "user-7", "")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "status") {
+		t.Errorf("unknown status: %d %s, want a validation error naming status", rec.Code, rec.Body.String())
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if got := publisher.Attempts(); got != 0 {
+		t.Errorf("%d status updates published without an updated event", got)
+	}
+}
+
+func TestBulkUpdateStatusCapsBatchAt200Events(t *testing.T) {
+	store := &bulkStatusStore{statuses: map[string]string{}, tenants: map[string]string{}}
+	h := NewEventHandler(store, nil)
+	batch := func(n int) string {
+		ids := make([]string, n)
+		for i := range ids {
+			ids[i] = fmt.Sprintf("%q", fmt.Sprintf("evt-%d", i))
+			store.statuses[fmt.Sprintf("evt-%d", i)] = models.EventStatusOpen
+		}
+		return `{"event_ids":[` + strings.Join(ids, ",") + `],"status":"acknowledged"}`
+	}
+
+	if rec := patchBatch(h, batch(200), "user-7", ""); rec.Code != http.StatusMultiStatus {
+		t.Errorf("200 events: status = %d, want %d: %s", rec.Code, http.StatusMultiStatus, rec.Body.String())
+	}
+	rec := patchBatch(h, batch(201), "user-7", "")
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "event_ids") {
+		t.Errorf("201 events: %d %s, want a validation error naming event_ids", rec.Code, rec.Body.String())
+	}
+	if len(store.updatedBy) != 1 {
+		t.Errorf("the store was called %d times, want only for the batch within the cap", len(store.updatedBy))
+	}
+}
+
+func TestBulkUpdateStatusRequiresUserIdentity(t *testing.T) {
+	store := &bulkStatusStore{statuses: map[string]string{"evt-1": models.EventStatusOpen}, tenants: map[string]string{}}
+	h := NewEventHandler(store, nil)
+
+	rec := patchBatch(h, `{"event_ids":["evt-1"],"status":"acknowledged"}`, "", "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d: %s", rec.Code, http.StatusUnauthorized, rec.Body.String())
+	}
+	if len(store.updatedBy) != 0 {
+		t.Error("events were updated without a user identity")
+	}
+}
+
+// sourceEventsStore records the limit of the last by-source lookup
+type sourceEventsStore struct {
+	fakeEventStore
+	limit int
+	calls int
+}
+
+func (s *sourceEventsStore) GetRecentEventsBySource(ctx context.Context, source string, limit int, since time.Time) ([]*models.Event, error) {
+	s.limit = limit
+	s.calls++
+	return nil, nil
+}
+
+func TestGetRecentEventsBySourceBoundsTheLimit(t *testing.T) {
+	tests := []struct {
+		query     string
+		status    int
+		wantLimit int
+	}{
+		{query: "", status: http.StatusOK, wantLimit: defaultSourceEventsLimit},
+		{query: "?limit=500", status: http.StatusOK, wantLimit: 500},
+		{query: "?limit=501", status: http.StatusBadRequest},
+		{query: "?limit=0", status: http.StatusBadRequest},
+		{query: "?limit=ten", status: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		store := &sourceEventsStore{}
+		h := NewEventHandler(store, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/events/by-source/auth-service"+tt.query, nil)
+		rec := serve(http.MethodGet, "/api/v1/events/by-source/:source", h.GetRecentEventsBySource, req)
+		if rec.Code != tt.status {
+			t.Errorf("%q: status = %d, want %d: %s", tt.query, rec.Code, tt.status, rec.Body.String())
+			continue
+		}
+		if tt.status != http.StatusOK {
+			if store.calls != 0 {
+				t.Errorf("%q: rejected limit still queried the store", tt.query)
+			}
+			if !strings.Contains(rec.Body.String(), "limit must be between 1 and 500") {
+				t.Errorf("%q: body %s does not name the bounds", tt.query, rec.Body.String())
+			}
+			continue
+		}
+		if store.limit != tt.wantLimit {
+			t.Errorf("%q: queried with limit %d, want %d", tt.query, store.limit, tt.wantLimit)
+		}
+	}
+}
+
+// sortRecordingStore records the page of every summary listing
+type sortRecordingStore struct {
+	fakeEventStore
+	pages []models.PaginationParams
+}
+
+func (s *sortRecordingStore) GetEventSummaries(ctx context.Context, filter models.EventFilter, page models.PaginationParams) ([]models.EventSummary, int64, error) {
+	s.pages = append(s.pages, page)
+	return s.fakeEventStore.GetEventSummaries(ctx, filter, page)
+}
+
+// getEvents lists events with the query and returns the response and its next cursor
+func getEvents(t *testing.T, h *EventHandler, query string) (*httptest.ResponseRecorder, string) {
+	t.Helper()
+	rec := serve(http.MethodGet, "/api/v1/events", h.GetEvents, httptest.NewRequest(http.MethodGet, "/api/v1/events"+query, nil))
+	var page struct {
+		Meta struct {
+			NextCursor string `json:"next_cursor"`
+		} `json:"meta"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &page)
+	return rec, page.Meta.NextCursor
+}
+
+func TestGetEventsSortsByEachAllowlistedFieldAndDirection(t *testing.T) {
+	events := newTestEvents(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), 3)
+	events[0].ID, events[0].Severity, events[0].EventType = "id-a", "critical", "malware_detected"
+
+	for _, field := range models.EventSortFields {
+		for _, order := range []string{"asc", "desc"} {
+			name := field + " " + order
+			store := &sortRecordingStore{fakeEventStore: fakeEventStore{events: events}}
+			h := NewEventHandler(store, nil)
+
+			rec, next := getEvents(t, h, "?limit=1&sort="+field+"&order="+order)
+			if rec.Code != http.StatusOK {
+				t.Errorf("%s: status = %d, want %d: %s", name, rec.Code, http.StatusOK, rec.Body.String())
+				continue
+			}
+			want := models.EventSort{Field: field, Ascending: order == "asc"}
+			if got := store.pages[0].Sort; got != want {
+				t.Errorf("%s: listed with sort %+v, want %+v", name, got, want)
+			}
+
+			cursor, err := h.cursorCodec.Decode(next)
+			if err != nil {
+				t.Fatalf("%s: decode next cursor %q: %v", name, next, err)
+			}
+			if cursor.Sort != want.Token() || cursor.ID != "id-a" {
+				t.Errorf("%s: cursor = %+v, want sort %q at id-a", name, cursor, want.Token())
+			}
+			wantKey := map[string]string{models.EventSortSeverity: "critical", models.EventSortEventType: "malware_detected"}[field]
+			if cursor.Key != wantKey {
+				t.Errorf("%s: cursor key = %q, want %q", name, cursor.Key, wantKey)
+			}
+
+			// The cursor resumes the listing it was issued for, and no other
+			rec, _ = getEvents(t, h, "?limit=1&sort="+field+"&order="+order+"&cursor="+next)
+			if rec.Code != http.StatusOK {
+				t.Errorf("%s: next page status = %d, want %d: %s", name, rec.Code, http.StatusOK, rec.Body.String())
+			} else if after := store.pages[1].After; after == nil || *after != *cursor {
+				t.Errorf("%s: next page listed after %+v, want %+v", name, after, cursor)
+			}
+			flipped := map[string]string{"asc": "desc", "desc": "asc"}[order]
+			rec, _ = getEvents(t, h, "?limit=1&sort="+field+"&order="+flipped+"&cursor="+next)
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "different sort order") {
+				t.Errorf("%s: cursor reused with order %s gave %d: %s", name, flipped, rec.Code, rec.Body.String())
+			}
+			if len(store.pages) != 2 {
+				t.Errorf("%s: %d listings, want the mismatched cursor rejected before listing", name, len(store.pages))
+			}
+		}
+	}
+}
+
+func TestGetEventsCursorsAreBoundToTheirSortField(t *testing.T) {
+	events := newTestEvents(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC), 3)
+	for i, event := range events {
+		event.ID = fmt.Sprint(i + 1)
+	}
+	h := NewEventHandler(&sortRecordingStore{fakeEventStore: fakeEventStore{events: events}}, nil)
+
+	// Cursors of the default order omit sort and order, so those issued before sorting stay valid
+	_, next := getEvents(t, h, "?limit=1")
+	if rec, _ := getEvents(t, h, "?limit=1&sort=created_at&order=desc&cursor="+next); rec.Code != http.StatusOK {
+		t.Errorf("default cursor with explicit created_at desc gave %d: %s", rec.Code, rec.Body.String())
+	}
+
+	for _, field := range []string{models.EventSortSeverity, models.EventSortEventType} {
+		if rec, _ := getEvents(t, h, "?limit=1&sort="+field+"&cursor="+next); rec.Code != http.StatusBadRequest {
+			t.Errorf("created_at cursor with sort=%s gave %d, want %d", field, rec.Code, http.StatusBadRequest)
+		}
+		_, sorted := getEvents(t, h, "?limit=1&sort="+field)
+		if rec, _ := getEvents(t, h, "?limit=1&cursor="+sorted); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s cursor without sort gave %d, want %d", field, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestGetEventsRejectsUnknownSortOrOrder(t *testing.T) {
+	tests := []struct {
+		query string
+		param string
+	}{
+		{"?sort=description", "sort"},
+		{"?sort=created_at%20DESC%3B%20DROP%20TABLE%20security_events", "sort"},
+		{"?sort=Severity", "sort"},
+		{"?order=sideways", "order"},
+		{"?sort=severity&order=descending", "order"},
+	}
+
+	for _, tt := range tests {
+		store := &sortRecordingStore{}
+		h := NewEventHandler(store, nil)
+
+		rec, _ := getEvents(t, h, tt.query)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", tt.query, rec.Code, http.StatusBadRequest)
+			continue
+		}
+		if !strings.Contains(rec.Body.String(), `"`+tt.param+`"`) {
+			t.Errorf("%s: response %s does not name %s", tt.query, rec.Body.String(), tt.param)
+		}
+		if len(store.pages) != 0 {
+			t.Errorf("%s: rejected sort still listed events", tt.query)
+		}
+	}
+}
+
+// patchStore merges patches into its events like the repository does
+type patchStore struct {
+	fakeEventStore
+	events map[string]*models.Event
+}
+
+func (s *patchStore) PatchEventData(ctx context.Context, eventID string, patch models.EventData) (*models.Event, error) {
+	event, ok := s.events[eventID]
+	if !ok {
+		return nil, errors.New("event not found")
+	}
+	event.EventData = models.MergeEventData(event.EventData, patch)
+	return event, nil
+}
+
+// patchEvent sends body to PATCH /api/v1/events/:id
+func patchEvent(h *EventHandler, eventID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/events/"+eventID, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return serve(http.MethodPatch, "/api/v1/events/:id", h.PatchEvent, req)
+}
+
+func TestPatchEventDeepMergesAndDeletesNullKeys(t *testing.T) {
+	store := &patchStore{events: map[string]*models.Event{"evt-1": {
+		EventID: "evt-1",
+		EventData: models.EventData{
+			"ip":     "203.0.113.7",
+			"user":   "alice",
+			"device": map[string]interface{}{"os": "linux", "agent": map[string]interface{}{"version": "1.2", "mode": "audit"}},
+		},
+	}}}
+	h := NewEventHandler(store, nil)
+
+	rec := patchEvent(h, "evt-1", `{"event_data": {"device": {"agent": {"version": "1.3", "mode": null}, "host": "web-1"}, "user": null}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var response struct {
+		Data models.Event `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	want := models.EventData{
+		"ip":     "203.0.113.7",
+		"device": map[string]interface{}{"os": "linux", "host": "web-1", "agent": map[string]interface{}{"version": "1.3"}},
+	}
+	if !reflect.DeepEqual(response.Data.EventData, want) {
+		t.Errorf("patched event_data = %v, want %v", response.Data.EventData, want)
+	}
+}
+
+func TestPatchEventReportsMissingEventsAndBodies(t *testing.T) {
+	h := NewEventHandler(&patchStore{events: map[string]*models.Event{"evt-1": {EventID: "evt-1"}}}, nil)
+
+	rec := patchEvent(h, "evt-404", `{"event_data": {"user": null}}`)
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), "evt-404") {
+		t.Errorf("patching a missing event gave %d: %s, want %d naming it", rec.Code, rec.Body.String(), http.StatusNotFound)
+	}
+
+	for _, body := range []string{`{}`, `{"event_data": [1]}`, `not json`} {
+		if rec := patchEvent(h, "evt-1", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("patch %s gave %d, want %d", body, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+// deleteStore deletes from a set of event IDs like the repository does
+type deleteStore struct {
+	fakeEventStore
+	existing map[string]bool
+	calls    int
+}
+
+func (s *deleteStore) DeleteEvents(ctx context.Context, eventIDs []string) (*models.DeleteEventsResult, error) {
+	s.calls++
+	result := &models.DeleteEventsResult{NotFound: []string{}}
+	reported := map[string]bool{}
+	for _, eventID := range eventIDs {
+		switch {
+		case s.existing[eventID]:
+			delete(s.existing, eventID)
+			result.Deleted++
+		case !reported[eventID]:
+			result.NotFound = append(result.NotFound, eventID)
+		}
+		reported[eventID] = true
+	}
+	return result, nil
+}
+
+// deleteBatch sends body to DELETE /api/v1/events
+func deleteBatch(h *EventHandler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return serve(http.MethodDelete, "/api/v1/events", h.DeleteEvents, req)
+}
+
+// eventIDsBody returns a delete request body for n distinct event IDs
+func eventIDsBody(n int) string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("evt-%d", i)
+	}
+	body, _ := json.Marshal(models.DeleteEventsRequest{EventIDs: ids})
+	return string(body)
+}
+
+func TestDeleteEventsReportsDeletedCountAndMissingIDs(t *testing.T) {
+	store := &deleteStore{existing: map[string]bool{"evt-1": true, "evt-2": true, "evt-3": true}}
+	h := NewEventHandler(store, nil)
+
+	rec := deleteBatch(h, `{"event_ids": ["evt-1", "evt-404", "evt-2", "evt-404", "evt-1"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var response struct {
+		Data models.DeleteEventsResult `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.Data.Deleted != 2 || !slices.Equal(response.Data.NotFound, []string{"evt-404"}) {
+		t.Errorf("result = %+v, want 2 deleted and evt-404 not found once", response.Data)
+	}
+	if !store.existing["evt-3"] {
+		t.Error("an event outside the batch was deleted")
+	}
+
+	rec = deleteBatch(h, `{"event_ids": ["evt-404"]}`)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"not_found":["evt-404"]`) || !strings.Contains(rec.Body.String(), `"deleted":0`) {
+		t.Errorf("deleting only a missing event gave %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDeleteEventsCapsBatchAt1000IDs(t *testing.T) {
+	store := &deleteStore{}
+	h := NewEventHandler(store, nil)
+
+	if rec := deleteBatch(h, eventIDsBody(models.MaxDeleteBatchSize)); rec.Code != http.StatusOK {
+		t.Fatalf("deleting %d events gave %d: %s", models.MaxDeleteBatchSize, rec.Code, rec.Body.String())
+	}
+
+	for name, body := range map[string]string{
+		"over the cap": eventIDsBody(models.MaxDeleteBatchSize + 1),
+		"empty":        `{"event_ids": []}`,
+		"blank ID":     `{"event_ids": ["evt-1", ""]}`,
+		"missing":      `{}`,
+	} {
+		rec := deleteBatch(h, body)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "between 1 and 1000") {
+			t.Errorf("%s: status = %d: %s, want %d naming the bounds", name, rec.Code, rec.Body.String(), http.StatusBadRequest)
+		}
+	}
+	if store.calls != 1 {
+		t.Errorf("store deleted %d batches, want only the one within the cap", store.calls)
+	}
+}
+
+// postEvent sends a JSON body to POST /api/v1/events
+func postEvent(h *EventHandler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	return serve(http.MethodPost, "/api/v1/events", h.CreateEvent, req)
+}
+
+func TestCreateEventRejectsUnknownFieldsWhenStrict(t *testing.T) {
+	store := &fakeEventStore{}
+	h := NewEventHandler(store, nil)
+	h.SetRejectUnknownFields(true)
+
+	rec := postEvent(h, `{"event_type":"login_failure","severity":"high","severty":"high","source":"auth-service","sourc_ip":"203.0.113.7"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusBadRequest, rec.Body.String())
+	}
+	var response apperrors.AppError
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	want := []apperrors.FieldError{
+		{Field: "severty", Message: "is not a known field"},
+		{Field: "sourc_ip", Message: "is not a known field"},
+	}
+	if response.Type != apperrors.ErrorTypeValidation || !reflect.DeepEqual(response.Fields, want) {
+		t.Errorf("response = %s, want a VALIDATION_ERROR naming severty and sourc_ip", rec.Body.String())
+	}
+	if len(store.created) != 0 {
+		t.Error("event with unknown fields was stored")
+	}
+
+	// Keys match the request fields case-insensitively, like encoding/json
+	if rec := postEvent(h, `{"Event_Type":"login_failure","SEVERITY":"high","source":"auth-service"}`); rec.Code != http.StatusCreated {
+		t.Errorf("differently cased known fields gave %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCreateEventIgnoresUnknownFieldsByDefault(t *testing.T) {
+	store := &fakeEventStore{}
+	h := NewEventHandler(store, nil)
+
+	rec := postEvent(h, `{"event_type":"login_failure","severity":"high","severty":"low","source":"auth-service"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	if len(store.created) != 1 || store.created[0].Severity != "high" {
+		t.Errorf("stored %+v, want the event with the unknown field dropped", store.created)
+	}
+}
+
+// updateStore applies updates to a single stored event
+type updateStore struct {
+	fakeEventStore
+	updates []*models.UpdateEventRequest
+}
+
+func (s *updateStore) UpdateEvent(ctx context.Context, eventID string, updates *models.UpdateEventRequest) (*models.Event, error) {
+	s.updates = append(s.updates, updates)
+	return &models.Event{EventID: eventID, Description: updates.Description}, nil
+}
+
+func TestDescriptionLengthIsLimitedOnCreateAndUpdate(t *testing.T) {
+	store := &updateStore{}
+	h := NewEventHandler(store, nil)
+	h.SetMaxDescriptionLength(10)
+
+	// The limit counts characters, not bytes
+	atLimit, overLimit := strings.Repeat("é", 10), strings.Repeat("é", 11)
+	create := func(description string) *httptest.ResponseRecorder {
+		return postEvent(h, `{"event_type":"login_failure","severity":"high","source":"auth-service","description":"`+description+`"}`)
+	}
+	update := func(description string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/events/evt-1", strings.NewReader(`{"description":"`+description+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		return serve(http.MethodPut, "/api/v1/events/:id", h.UpdateEvent, req)
+	}
+
+	for name, send := range map[string]func(string) *httptest.ResponseRecorder{"create": create, "update": update} {
+		rec := send(overLimit)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: over-limit description gave %d: %s", name, rec.Code, rec.Body.String())
+			continue
+		}
+		var response apperrors.AppError
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Fatalf("%s: decode response: %v", name, err)
+		}
+		want := []apperrors.FieldError{{Field: "description", Message: "must be at most 10 characters"}}
+		if response.Type != apperrors.ErrorTypeValidation || !reflect.DeepEqual(response.Fields, want) {
+			t.Errorf("%s: response = %s, want a VALIDATION_ERROR stating the limit", name, rec.Body.String())
+		}
+
+		if rec := send(atLimit); rec.Code != http.StatusCreated && rec.Code != http.StatusOK {
+			t.Errorf("%s: description at the limit gave %d: %s", name, rec.Code, rec.Body.String())
+		}
+	}
+	if len(store.created) != 1 || len(store.updates) != 1 {
+		t.Errorf("stored %d events and %d updates, want only those at the limit", len(store.created), len(store.updates))
+	}
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+	"skyhawk-security-microservice/internal/config"
+	"skyhawk-security-microservice/internal/logger"
+)
+
+// FeatureHandler handles the feature flag admin endpoint
+type FeatureHandler struct {
+	features config.FeatureFlags
+}
+
+// NewFeatureHandler creates a new feature handler reporting features
+func NewFeatureHandler(features config.FeatureFlags) *FeatureHandler {
+	return &FeatureHandler{features: features}
+}
+
+// GetFeatures handles reporting which optional features are enabled
+func (h *FeatureHandler) GetFeatures(c *gin.Context) {
+	api.OK(c, h.features)
+}
+
+// auditf logs an audit entry of an administrative action unless audit logging is disabled
+func auditf(c *gin.Context, enabled bool, format string, args ...interface{}) {
+	if enabled {
+		logger.FromGinContext(c).Info("Audit: "+fmt.Sprintf(format, args...), logger.Fields{"audit": true})
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+	"skyhawk-security-microservice/internal/routing"
+)
+
+func TestConfigWatcherReroutesEventsWhenFileChanges(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	initial := "routing:\n  - match: {severity: critical}\n    target: security_events_critical\n"
+	if err := os.WriteFile(path, []byte(initial), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+
+	router := routing.NewEventRouter("security_events")
+	watcher := newConfigWatcher(router)
+	defer watcher.Close()
+
+	event := &models.Event{EventType: "login_failure", Severity: models.SeverityCritical, Source: "auth-service"}
+	if got := router.Route(event); got != "security_events_critical" {
+		t.Fatalf("routed to %s before the change, want security_events_critical", got)
+	}
+
+	updated := "routing:\n  - match: {severity: critical}\n    target: security_events_pager\n"
+	if err := os.WriteFile(path, []byte(updated), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for router.Route(event) != "security_events_pager" {
+		if time.Now().After(deadline) {
+			t.Fatalf("still routed to %s 2s after the change, want security_events_pager", router.Route(event))
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestMaxDescriptionLengthFromEnv(t *testing.T) {
+	t.Setenv("MAX_DESCRIPTION_LENGTH", "")
+	if got := maxDescriptionLengthFromEnv(); got != models.DefaultMaxDescriptionLength {
+		t.Errorf("unset MAX_DESCRIPTION_LENGTH gave %d, want %d", got, models.DefaultMaxDescriptionLength)
+	}
+
+	t.Setenv("MAX_DESCRIPTION_LENGTH", "280")
+	if got := maxDescriptionLengthFromEnv(); got != 280 {
+		t.Errorf("MAX_DESCRIPTION_LENGTH=280 gave %d", got)
+	}
+}
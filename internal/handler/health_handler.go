@@ -1,39 +1,93 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+	apperrors "skyhawk-security-microservice/internal/errors"
+	"skyhawk-security-microservice/internal/health"
 )
 
-type HealthHandler struct{}
+type HealthHandler struct {
+	checker *health.HealthChecker
+}
 
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+func NewHealthHandler(checker *health.HealthChecker) *HealthHandler {
+	return &HealthHandler{checker: checker}
 }
 
+// HealthCheck reports the result of every check. Only a failing critical check answers 503; a
+// degraded service, including one whose non-critical checks fail, still answers 200.
 func (h *HealthHandler) HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status":    "healthy",
-		"timestamp": time.Now().Format(time.RFC3339),
+	status := h.checker.CheckHealth(c.Request.Context())
+
+	statusCode := http.StatusOK
+	if status.Status == "unhealthy" {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	api.Respond(c, statusCode, gin.H{
+		"status":    status.Status,
+		"timestamp": status.Timestamp.Format(time.RFC3339),
 		"service":   "skyhawk-security-microservice",
-		"version":   "1.0.0",
+		"version":   status.Version,
+		"uptime":    status.Uptime,
+		"checks":    status.Checks,
 	})
 }
 
+// defaultHistoryLimit is the number of results GetHistory returns unless limit is set
+const defaultHistoryLimit = 20
+
+// GetHistory handles listing the recent results of one check with its uptime over the retained
+// results and when it last changed status
+func (h *HealthHandler) GetHistory(c *gin.Context) {
+	check := c.Query("check")
+	if check == "" {
+		api.Fail(c, apperrors.NewValidationError("check is required", ""))
+		return
+	}
+
+	limit := defaultHistoryLimit
+	if rawLimit := c.Query("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed < 1 || parsed > health.DefaultHistorySize {
+			api.Fail(c, apperrors.NewValidationError(fmt.Sprintf("limit must be between 1 and %d", health.DefaultHistorySize), ""))
+			return
+		}
+		limit = parsed
+	}
+
+	history, ok := h.checker.History(check, limit)
+	if !ok {
+		appErr := apperrors.NewNotFoundError("History of check", check)
+		appErr.Details = "Checks with history: " + strings.Join(h.checker.HistoryChecks(), ", ")
+		api.Fail(c, appErr)
+		return
+	}
+
+	api.OK(c, history)
+}
+
+// GetStatus reports the service status along with the database connection pool statistics
 func (h *HealthHandler) GetStatus(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
+	api.OK(c, gin.H{
 		"status":    "operational",
 		"uptime":    "running",
 		"timestamp": time.Now().Format(time.RFC3339),
+		"database":  h.checker.DatabasePoolStats(),
 	})
 }
 
 func (h *HealthHandler) GetRoot(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
+	api.OK(c, gin.H{
 		"service": "Skyhawk Security Microservice",
 		"version": "1.0.0",
 		"status":  "running",
 	})
-} 
\ No newline at end of file
+}
@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+	"skyhawk-security-microservice/internal/mitre"
+)
+
+// MITREHandler handles the MITRE ATT&CK admin endpoints
+type MITREHandler struct {
+	lookup *mitre.ATTACKLookup
+}
+
+// NewMITREHandler creates a new MITRE handler
+func NewMITREHandler(lookup *mitre.ATTACKLookup) *MITREHandler {
+	return &MITREHandler{lookup: lookup}
+}
+
+// GetTechniques handles listing the loaded ATT&CK techniques
+func (h *MITREHandler) GetTechniques(c *gin.Context) {
+	api.OK(c, h.lookup.Techniques())
+}
@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+	"skyhawk-security-microservice/internal/scheduler"
+)
+
+// SchedulerHandler handles the scheduled job admin endpoints
+type SchedulerHandler struct {
+	scheduler *scheduler.Scheduler
+}
+
+// NewSchedulerHandler creates a new scheduler handler
+func NewSchedulerHandler(scheduler *scheduler.Scheduler) *SchedulerHandler {
+	return &SchedulerHandler{scheduler: scheduler}
+}
+
+// GetStatus handles listing the scheduled jobs with their last and next runs
+func (h *SchedulerHandler) GetStatus(c *gin.Context) {
+	jobs := []scheduler.JobStatus{}
+	if h.scheduler != nil {
+		jobs = h.scheduler.Status()
+	}
+
+	api.OK(c, jobs)
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+	apperrors "skyhawk-security-microservice/internal/errors"
+	"skyhawk-security-microservice/internal/normalization"
+)
+
+// SeverityMapHandler handles the severity normalization admin endpoints
+type SeverityMapHandler struct {
+	normalizer *normalization.SeverityNormalizer
+}
+
+// NewSeverityMapHandler creates a new severity map handler
+func NewSeverityMapHandler(normalizer *normalization.SeverityNormalizer) *SeverityMapHandler {
+	return &SeverityMapHandler{normalizer: normalizer}
+}
+
+// UpdateSeverityMapRequest represents the request to add severity mappings
+type UpdateSeverityMapRequest struct {
+	Mappings map[string]string `json:"mappings" binding:"required"`
+}
+
+// GetSeverityMap handles listing the current severity mappings
+func (h *SeverityMapHandler) GetSeverityMap(c *gin.Context) {
+	api.OK(c, h.normalizer.Mappings())
+}
+
+// AddSeverityMappings handles adding severity mappings. Mappings are held in
+// memory and apply to this instance only.
+func (h *SeverityMapHandler) AddSeverityMappings(c *gin.Context) {
+	var req UpdateSeverityMapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.Fail(c, apperrors.NewValidationError("Invalid request body", err.Error()))
+		return
+	}
+
+	if err := h.normalizer.Add(req.Mappings); err != nil {
+		api.Fail(c, apperrors.NewValidationError(err.Error(), ""))
+		return
+	}
+
+	api.OK(c, h.normalizer.Mappings())
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+	apperrors "skyhawk-security-microservice/internal/errors"
+	"skyhawk-security-microservice/internal/ratelimit"
+)
+
+// SourceLimitHandler handles the per-source rate limit admin endpoints
+type SourceLimitHandler struct {
+	limiter *ratelimit.SourceRateLimiter
+}
+
+// NewSourceLimitHandler creates a new source limit handler
+func NewSourceLimitHandler(limiter *ratelimit.SourceRateLimiter) *SourceLimitHandler {
+	return &SourceLimitHandler{limiter: limiter}
+}
+
+// SetSourceLimitRequest represents the request to override a source's rate limit
+type SetSourceLimitRequest struct {
+	Source    string  `json:"source" binding:"required"`
+	Rate      float64 `json:"rate" binding:"min=0"`
+	BurstSize int     `json:"burst_size" binding:"min=0"`
+}
+
+// GetSourceLimits handles listing the default limit, overrides and active source buckets
+func (h *SourceLimitHandler) GetSourceLimits(c *gin.Context) {
+	sources := h.limiter.Status()
+	if sources == nil {
+		sources = []ratelimit.SourceStatus{}
+	}
+
+	api.OK(c, gin.H{
+		"default":   h.limiter.Defaults(),
+		"overrides": h.limiter.Overrides(),
+		"sources":   sources,
+	})
+}
+
+// SetSourceLimit handles setting a per-source override. Overrides are held in
+// memory and apply to this instance only.
+func (h *SourceLimitHandler) SetSourceLimit(c *gin.Context) {
+	var req SetSourceLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.Fail(c, apperrors.NewValidationError("Invalid request body", err.Error()))
+		return
+	}
+
+	limit := ratelimit.Limit{Rate: req.Rate, BurstSize: req.BurstSize}
+	if err := h.limiter.SetOverride(req.Source, limit); err != nil {
+		api.Fail(c, apperrors.NewValidationError(err.Error(), ""))
+		return
+	}
+
+	api.OK(c, gin.H{
+		"source": req.Source,
+		"limit":  limit,
+	})
+}
+
+// DeleteSourceLimit handles removing a per-source override
+func (h *SourceLimitHandler) DeleteSourceLimit(c *gin.Context) {
+	source := c.Param("source")
+
+	if !h.limiter.RemoveOverride(source) {
+		api.Fail(c, apperrors.NewNotFoundError("Source limit", source))
+		return
+	}
+
+	api.OK(c, gin.H{
+		"source": source,
+	})
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	apperrors "skyhawk-security-microservice/internal/errors"
+	"skyhawk-security-microservice/internal/models"
+)
+
+func init() {
+	// Name invalid fields as they appear in the request body
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			return name
+		})
+	}
+}
+
+// addBindingErrors records the field problems behind a failed ShouldBindJSON. It returns
+// false when the error is not about individual fields, such as a malformed body.
+func addBindingErrors(v *apperrors.ValidationErrors, err error) bool {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		v.Add(typeErr.Field, "must not be a "+typeErr.Value)
+		return true
+	}
+
+	var dataErr *models.EventDataError
+	if errors.As(err, &dataErr) {
+		v.Add("event_data", dataErr.Message())
+		return true
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return false
+	}
+	for _, fe := range fieldErrs {
+		// Drop the request struct name from the namespace, e.g. CreateEventRequest.attack_techniques[0]
+		field := fe.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+		v.Add(field, bindingMessage(fe))
+	}
+	return true
+}
+
+// addUnknownFields records every top-level key of a JSON object body that matches no field of
+// the struct v points to. Keys match case-insensitively, as encoding/json does. A body that is
+// not an object is left to the binding to report.
+func addUnknownFields(v *apperrors.ValidationErrors, body []byte, dst interface{}) {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		return
+	}
+
+	known := jsonFieldNames(reflect.TypeOf(dst))
+	unknown := make([]string, 0, len(fields))
+	for name := range fields {
+		if !known[strings.ToLower(name)] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		v.Add(name, "is not a known field")
+	}
+}
+
+// jsonFieldNames returns the lowercased JSON names of the fields of a struct, or of the struct a
+// pointer points to
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[strings.ToLower(name)] = true
+	}
+	return names
+}
+
+// bindingMessage describes a failed binding tag
+func bindingMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "oneof":
+		return "must be one of " + fe.Param()
+	case "min", "gte":
+		return "must be at least " + fe.Param()
+	case "max", "lte":
+		return "must be at most " + fe.Param()
+	default:
+		return "failed the " + fe.Tag() + " check"
+	}
+}
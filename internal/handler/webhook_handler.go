@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+	apperrors "skyhawk-security-microservice/internal/errors"
+	"skyhawk-security-microservice/internal/webhook"
+)
+
+// WebhookHandler handles webhook subscription admin endpoints
+type WebhookHandler struct {
+	repo *webhook.Repository
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(repo *webhook.Repository) *WebhookHandler {
+	return &WebhookHandler{repo: repo}
+}
+
+// CreateSubscription handles subscription creation
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var req webhook.CreateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.Fail(c, apperrors.NewValidationError("Invalid request body", err.Error()))
+		return
+	}
+
+	sub := &webhook.Subscription{
+		TargetURL:  req.TargetURL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		Active:     true,
+	}
+	if sub.EventTypes == nil {
+		sub.EventTypes = []string{}
+	}
+	if req.Active != nil {
+		sub.Active = *req.Active
+	}
+
+	if err := h.repo.CreateSubscription(sub); err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to create subscription", err))
+		return
+	}
+
+	api.Created(c, sub)
+}
+
+// GetSubscriptions handles subscription listing
+func (h *WebhookHandler) GetSubscriptions(c *gin.Context) {
+	subs, err := h.repo.ListSubscriptions()
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve subscriptions", err))
+		return
+	}
+
+	api.OK(c, subs)
+}
+
+// GetSubscription handles single subscription retrieval
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	sub, err := h.repo.GetSubscription(c.Param("id"))
+	if err != nil {
+		h.respondError(c, err, "Failed to retrieve subscription")
+		return
+	}
+
+	api.OK(c, sub)
+}
+
+// UpdateSubscription handles subscription updates
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	var req webhook.UpdateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		api.Fail(c, apperrors.NewValidationError("Invalid request body", err.Error()))
+		return
+	}
+
+	sub, err := h.repo.UpdateSubscription(c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err, "Failed to update subscription")
+		return
+	}
+
+	api.OK(c, sub)
+}
+
+// DeleteSubscription handles subscription deletion
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	id := c.Param("id")
+
+	if err := h.repo.DeleteSubscription(id); err != nil {
+		h.respondError(c, err, "Failed to delete subscription")
+		return
+	}
+
+	api.OK(c, gin.H{
+		"subscription_id": id,
+	})
+}
+
+// RotateSecret handles replacing a subscription's signing secret. The new secret
+// is only returned in this response; afterwards only its hash is shown.
+func (h *WebhookHandler) RotateSecret(c *gin.Context) {
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to generate secret", err))
+		return
+	}
+
+	sub, err := h.repo.RotateSecret(c.Param("id"), secret)
+	if err != nil {
+		h.respondError(c, err, "Failed to rotate secret")
+		return
+	}
+
+	api.OK(c, gin.H{
+		"secret":       secret,
+		"subscription": sub,
+	})
+}
+
+// GetDeliveries handles delivery history retrieval for a subscription
+func (h *WebhookHandler) GetDeliveries(c *gin.Context) {
+	id := c.Param("id")
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 500 {
+		api.Fail(c, apperrors.NewValidationError("limit must be between 1 and 500", ""))
+		return
+	}
+
+	if _, err := h.repo.GetSubscription(id); err != nil {
+		h.respondError(c, err, "Failed to retrieve subscription")
+		return
+	}
+
+	deliveries, err := h.repo.ListDeliveries(id, limit)
+	if err != nil {
+		api.Fail(c, apperrors.NewInternalError("Failed to retrieve deliveries", err))
+		return
+	}
+
+	api.OK(c, deliveries)
+}
+
+// respondError maps repository errors to HTTP responses
+func (h *WebhookHandler) respondError(c *gin.Context, err error, message string) {
+	if err.Error() == "subscription not found" {
+		api.Fail(c, apperrors.NewNotFoundError("Subscription", c.Param("id")))
+		return
+	}
+	api.Fail(c, apperrors.NewInternalError(message, err))
+}
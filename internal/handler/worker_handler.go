@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+	apperrors "skyhawk-security-microservice/internal/errors"
+	"skyhawk-security-microservice/internal/queue"
+)
+
+// WorkerHandler handles the queue worker admin endpoints
+type WorkerHandler struct {
+	queueManager queue.QueueInterface
+}
+
+// NewWorkerHandler creates a new worker handler
+func NewWorkerHandler(queueManager queue.QueueInterface) *WorkerHandler {
+	return &WorkerHandler{queueManager: queueManager}
+}
+
+// GetWorkers handles listing the tags of the consumers started by this process's queue manager
+func (h *WorkerHandler) GetWorkers(c *gin.Context) {
+	if h.queueManager == nil {
+		api.Fail(c, apperrors.NewUnavailableError("Queue manager not available"))
+		return
+	}
+
+	tags := []string{}
+	if lister, ok := h.queueManager.(queue.ConsumerLister); ok {
+		tags = lister.ConsumerTags()
+	}
+
+	api.OK(c, tags)
+}
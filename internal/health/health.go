@@ -2,12 +2,12 @@ package health
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"sync"
 	"time"
 
 	"skyhawk-security-microservice/internal/database"
+	"skyhawk-security-microservice/internal/routing"
 )
 
 // HealthStatus represents the overall health status
@@ -21,19 +21,49 @@ type HealthStatus struct {
 
 // CheckResult represents the result of a health check
 type CheckResult struct {
-	Status    string    `json:"status"`
-	Message   string    `json:"message,omitempty"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	// Critical checks make the service unhealthy when they fail; other failing checks only
+	// degrade it
+	Critical  bool      `json:"critical"`
 	Timestamp time.Time `json:"timestamp"`
 	Duration  string    `json:"duration"`
 }
 
+// Checks lists every health check, including the queue checks enabled by SetQueue
+var Checks = []string{"database", "memory", "disk", "queue", "dead_letter_queue"}
+
+// DefaultCriticalChecks are the checks whose failure makes the service unhealthy unless
+// SetCriticalChecks says otherwise. A queue backlog only degrades the service by default,
+// since the API keeps accepting events while workers catch up.
+var DefaultCriticalChecks = []string{"database"}
+
+// QueueLengthGetter reports the number of messages waiting in a queue
+type QueueLengthGetter interface {
+	GetQueueLength(queueName string) (int64, error)
+}
+
+// QueueDepthConfig sets the backlog of the main queue above which the service is degraded
+// (WarnThreshold) or unhealthy (CriticalThreshold)
+type QueueDepthConfig struct {
+	WarnThreshold     int64
+	CriticalThreshold int64
+}
+
+// DefaultQueueDepthConfig is used unless QUEUE_WARN_THRESHOLD or QUEUE_CRITICAL_THRESHOLD are set
+var DefaultQueueDepthConfig = QueueDepthConfig{WarnThreshold: 1000, CriticalThreshold: 10000}
+
 // HealthChecker manages all health checks
 type HealthChecker struct {
 	db           *database.DB
+	queue        QueueLengthGetter
+	queueDepth   QueueDepthConfig
 	startTime    time.Time
 	version      string
 	mu           sync.RWMutex
 	checkResults map[string]CheckResult
+	critical     map[string]bool
+	history      *HealthHistory
 }
 
 // NewHealthChecker creates a new health checker
@@ -43,34 +73,103 @@ func NewHealthChecker(db *database.DB) *HealthChecker {
 		startTime:    time.Now(),
 		version:      "1.0.0",
 		checkResults: make(map[string]CheckResult),
+		critical:     criticalSet(DefaultCriticalChecks),
+		history:      NewHealthHistory(DefaultHistorySize),
 	}
 }
 
-// CheckHealth performs all health checks
-func (hc *HealthChecker) CheckHealth(ctx context.Context) HealthStatus {
+// SetCriticalChecks replaces the checks whose failure makes the service unhealthy; a failure
+// of any other check only degrades it
+func (hc *HealthChecker) SetCriticalChecks(checks []string) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+
+	hc.critical = criticalSet(checks)
+}
+
+// criticalSet indexes the names of critical checks
+func criticalSet(checks []string) map[string]bool {
+	critical := make(map[string]bool, len(checks))
+	for _, check := range checks {
+		critical[check] = true
+	}
+	return critical
+}
+
+// SetQueue enables the queue backlog and dead-letter checks
+func (hc *HealthChecker) SetQueue(queue QueueLengthGetter, depth QueueDepthConfig) {
 	hc.mu.Lock()
 	defer hc.mu.Unlock()
 
+	hc.queue = queue
+	hc.queueDepth = depth
+}
+
+// checkConfig is the configuration a round of checks runs with, copied under hc.mu so the
+// checks themselves run without holding it
+type checkConfig struct {
+	queue      QueueLengthGetter
+	queueDepth QueueDepthConfig
+	critical   map[string]bool
+}
+
+// config copies the current check configuration
+func (hc *HealthChecker) config() checkConfig {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+
+	return checkConfig{queue: hc.queue, queueDepth: hc.queueDepth, critical: hc.critical}
+}
+
+// CheckHealth performs all health checks. The status is "unhealthy" if any critical check is,
+// otherwise "degraded" if any check is not healthy, otherwise "healthy".
+func (hc *HealthChecker) CheckHealth(ctx context.Context) HealthStatus {
+	cfg := hc.config()
+
 	// Perform all health checks concurrently
 	var wg sync.WaitGroup
 	checks := []string{"database", "memory", "disk"}
+	if cfg.queue != nil {
+		checks = append(checks, "queue", "dead_letter_queue")
+	}
+	results := make([]CheckResult, len(checks))
 
-	for _, check := range checks {
+	for i, check := range checks {
 		wg.Add(1)
-		go func(checkName string) {
+		go func(i int, checkName string) {
 			defer wg.Done()
-			hc.performCheck(ctx, checkName)
-		}(check)
+			result := hc.performCheck(ctx, checkName, cfg)
+			results[i] = result
+
+			hc.history.Record(checkName, HistoryEntry{
+				Timestamp: result.Timestamp,
+				Status:    result.Status,
+				Duration:  result.Duration,
+			})
+		}(i, check)
 	}
 
 	wg.Wait()
 
-	// Determine overall status
+	// Store the latest results, then determine the overall status from a copy so callers
+	// never share the map
+	hc.mu.Lock()
+	for i, check := range checks {
+		hc.checkResults[check] = results[i]
+	}
+	latest := make(map[string]CheckResult, len(hc.checkResults))
+	for name, result := range hc.checkResults {
+		latest[name] = result
+	}
+	hc.mu.Unlock()
+
 	overallStatus := "healthy"
-	for _, result := range hc.checkResults {
-		if result.Status == "unhealthy" {
+	for _, result := range latest {
+		switch {
+		case result.Status == "unhealthy" && result.Critical:
 			overallStatus = "unhealthy"
-			break
+		case result.Status != "healthy" && overallStatus == "healthy":
+			overallStatus = "degraded"
 		}
 	}
 
@@ -79,12 +178,23 @@ func (hc *HealthChecker) CheckHealth(ctx context.Context) HealthStatus {
 		Timestamp: time.Now(),
 		Uptime:    time.Since(hc.startTime).String(),
 		Version:   hc.version,
-		Checks:    hc.checkResults,
+		Checks:    latest,
 	}
 }
 
+// History returns up to limit of the most recent results of a check, and false when the
+// check has not run yet
+func (hc *HealthChecker) History(check string, limit int) (CheckHistory, bool) {
+	return hc.history.History(check, limit)
+}
+
+// HistoryChecks returns the names of the checks with a history, sorted
+func (hc *HealthChecker) HistoryChecks() []string {
+	return hc.history.Checks()
+}
+
 // performCheck performs a specific health check
-func (hc *HealthChecker) performCheck(ctx context.Context, checkName string) {
+func (hc *HealthChecker) performCheck(ctx context.Context, checkName string, cfg checkConfig) CheckResult {
 	start := time.Now()
 	var result CheckResult
 
@@ -95,6 +205,10 @@ func (hc *HealthChecker) performCheck(ctx context.Context, checkName string) {
 		result = hc.checkMemory()
 	case "disk":
 		result = hc.checkDisk()
+	case "queue":
+		result = checkQueueDepth(cfg.queue, cfg.queueDepth)
+	case "dead_letter_queue":
+		result = checkDeadLetters(cfg.queue)
 	default:
 		result = CheckResult{
 			Status:    "unknown",
@@ -103,8 +217,9 @@ func (hc *HealthChecker) performCheck(ctx context.Context, checkName string) {
 		}
 	}
 
+	result.Critical = cfg.critical[checkName]
 	result.Duration = time.Since(start).String()
-	hc.checkResults[checkName] = result
+	return result
 }
 
 // checkDatabase checks database connectivity
@@ -161,16 +276,69 @@ func (hc *HealthChecker) checkDisk() CheckResult {
 	}
 }
 
+// checkQueueDepth compares the backlog of the main queue with the configured thresholds
+func checkQueueDepth(queue QueueLengthGetter, thresholds QueueDepthConfig) CheckResult {
+	depth, err := queue.GetQueueLength(routing.DefaultQueue)
+	if err != nil {
+		return CheckResult{
+			Status:    "degraded",
+			Message:   fmt.Sprintf("Queue length unavailable: %v", err),
+			Timestamp: time.Now(),
+		}
+	}
+
+	switch {
+	case depth > thresholds.CriticalThreshold:
+		return CheckResult{
+			Status:    "unhealthy",
+			Message:   fmt.Sprintf("Queue backlog critical: %d messages", depth),
+			Timestamp: time.Now(),
+		}
+	case depth > thresholds.WarnThreshold:
+		return CheckResult{
+			Status:    "degraded",
+			Message:   "Queue backlog building up",
+			Timestamp: time.Now(),
+		}
+	}
+
+	return CheckResult{
+		Status:    "healthy",
+		Message:   fmt.Sprintf("Queue backlog: %d messages", depth),
+		Timestamp: time.Now(),
+	}
+}
+
+// checkDeadLetters reports any message in the dead-letter queue as degraded
+func checkDeadLetters(queue QueueLengthGetter) CheckResult {
+	depth, err := queue.GetQueueLength(routing.DefaultQueue + "_dead")
+	if err != nil {
+		return CheckResult{
+			Status:    "degraded",
+			Message:   fmt.Sprintf("Dead-letter queue length unavailable: %v", err),
+			Timestamp: time.Now(),
+		}
+	}
+
+	if depth > 0 {
+		return CheckResult{
+			Status:    "degraded",
+			Message:   fmt.Sprintf("%d messages in the dead-letter queue", depth),
+			Timestamp: time.Now(),
+		}
+	}
+
+	return CheckResult{
+		Status:    "healthy",
+		Message:   "Dead-letter queue empty",
+		Timestamp: time.Now(),
+	}
+}
+
 // GetReadinessStatus checks if the service is ready to handle requests
 func (hc *HealthChecker) GetReadinessStatus(ctx context.Context) HealthStatus {
 	// For readiness, we only check critical dependencies
-	hc.mu.Lock()
-	defer hc.mu.Unlock()
-
-	// Check database readiness
-	start := time.Now()
-	dbResult := hc.checkDatabase(ctx)
-	dbResult.Duration = time.Since(start).String()
+	dbResult := hc.performCheck(ctx, "database", hc.config())
 
 	checks := map[string]CheckResult{
 		"database": dbResult,
@@ -188,4 +356,9 @@ func (hc *HealthChecker) GetReadinessStatus(ctx context.Context) HealthStatus {
 		Version:   hc.version,
 		Checks:    checks,
 	}
-} 
\ No newline at end of file
+}
+
+// DatabasePoolStats returns the connection pool statistics of the database
+func (hc *HealthChecker) DatabasePoolStats() database.PoolStats {
+	return hc.db.PoolStats()
+}
@@ -0,0 +1,162 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"skyhawk-security-microservice/internal/database"
+	"skyhawk-security-microservice/internal/queue"
+	"skyhawk-security-microservice/internal/routing"
+)
+
+// newUnreachableDB returns a database whose connections are refused, so its check fails fast
+func newUnreachableDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 connect_timeout=1 sslmode=disable")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &database.DB{DB: db}
+}
+
+// fakeQueue reports fixed queue lengths, optionally blocking until release is closed
+type fakeQueue struct {
+	lengths map[string]int64
+	release chan struct{}
+}
+
+func (q *fakeQueue) GetQueueLength(queueName string) (int64, error) {
+	if q.release != nil {
+		<-q.release
+	}
+	return q.lengths[queueName], nil
+}
+
+func TestCheckHealthQueueBacklogOnlyDegradesByDefault(t *testing.T) {
+	hc := NewHealthChecker(newUnreachableDB(t))
+	hc.SetCriticalChecks([]string{})
+	hc.SetQueue(&fakeQueue{lengths: map[string]int64{routing.DefaultQueue: 20000}}, DefaultQueueDepthConfig)
+
+	status := hc.CheckHealth(context.Background())
+
+	if status.Checks["queue"].Status != "unhealthy" {
+		t.Errorf("queue check = %+v, want unhealthy above the critical threshold", status.Checks["queue"])
+	}
+	if status.Status != "degraded" {
+		t.Errorf("status = %s, want degraded while no check is critical", status.Status)
+	}
+}
+
+func TestDefaultCriticalChecks(t *testing.T) {
+	hc := NewHealthChecker(newUnreachableDB(t))
+	hc.SetQueue(&fakeQueue{lengths: map[string]int64{routing.DefaultQueue: 20000}}, DefaultQueueDepthConfig)
+
+	status := hc.CheckHealth(context.Background())
+
+	if !status.Checks["database"].Critical {
+		t.Error("database check is not critical by default")
+	}
+	if status.Checks["queue"].Critical {
+		t.Error("queue check is critical by default")
+	}
+	if status.Status != "unhealthy" {
+		t.Errorf("status = %s, want unhealthy with the database down", status.Status)
+	}
+}
+
+func TestCheckHealthDoesNotHoldLockWhileChecking(t *testing.T) {
+	hc := NewHealthChecker(newUnreachableDB(t))
+	queue := &fakeQueue{release: make(chan struct{})}
+	hc.SetQueue(queue, DefaultQueueDepthConfig)
+
+	done := make(chan HealthStatus)
+	go func() { done <- hc.CheckHealth(context.Background()) }()
+
+	// While the queue check blocks, configuration and readiness must not wait for it
+	configured := make(chan struct{})
+	go func() {
+		hc.SetCriticalChecks([]string{"database"})
+		hc.GetReadinessStatus(context.Background())
+		close(configured)
+	}()
+	select {
+	case <-configured:
+	case <-time.After(5 * time.Second):
+		t.Fatal("SetCriticalChecks blocked behind a running health check")
+	}
+
+	close(queue.release)
+	status := <-done
+	if status.Checks["dead_letter_queue"].Status != "healthy" {
+		t.Errorf("dead letter check = %+v, want healthy", status.Checks["dead_letter_queue"])
+	}
+}
+
+func TestCheckQueueDepthThresholds(t *testing.T) {
+	thresholds := QueueDepthConfig{WarnThreshold: 10, CriticalThreshold: 100}
+	for depth, want := range map[int64]string{5: "healthy", 50: "degraded", 500: "unhealthy"} {
+		queue := &fakeQueue{lengths: map[string]int64{routing.DefaultQueue: depth}}
+		if got := checkQueueDepth(queue, thresholds).Status; got != want {
+			t.Errorf("depth %d: status = %s, want %s", depth, got, want)
+		}
+	}
+}
+
+// publishMessages publishes n messages to queueName
+func publishMessages(t *testing.T, mq *queue.MemoryQueue, queueName string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := mq.PublishMessage(queue.Message{ID: fmt.Sprintf("msg-%d", i), Type: "security_event"}, queueName); err != nil {
+			t.Fatalf("PublishMessage: %v", err)
+		}
+	}
+}
+
+func TestCheckHealthDegradedAboveWarnThreshold(t *testing.T) {
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+	publishMessages(t, mq, routing.DefaultQueue, 1001)
+
+	hc := NewHealthChecker(newUnreachableDB(t))
+	hc.SetCriticalChecks([]string{})
+	hc.SetQueue(mq, DefaultQueueDepthConfig)
+
+	status := hc.CheckHealth(context.Background())
+
+	if check := status.Checks["queue"]; check.Status != "degraded" || check.Message != "Queue backlog building up" {
+		t.Errorf("queue check = %+v, want degraded with 1001 messages", check)
+	}
+	if status.Status != "degraded" {
+		t.Errorf("status = %s, want degraded", status.Status)
+	}
+}
+
+func TestCheckQueueDepthAtWarnThresholdIsHealthy(t *testing.T) {
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+	publishMessages(t, mq, routing.DefaultQueue, 1000)
+
+	if got := checkQueueDepth(mq, DefaultQueueDepthConfig).Status; got != "healthy" {
+		t.Errorf("status = %s with 1000 messages, want healthy", got)
+	}
+}
+
+func TestCheckDeadLettersReportsCount(t *testing.T) {
+	mq := queue.NewMemoryQueue()
+	defer mq.Close()
+
+	if got := checkDeadLetters(mq).Status; got != "healthy" {
+		t.Errorf("status = %s with no dead letters, want healthy", got)
+	}
+
+	publishMessages(t, mq, routing.DefaultQueue+"_dead", 3)
+	result := checkDeadLetters(mq)
+	if result.Status != "degraded" || !strings.HasPrefix(result.Message, "3 messages") {
+		t.Errorf("dead letter check = %+v, want degraded with the count 3", result)
+	}
+}
@@ -0,0 +1,127 @@
+package health
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultHistorySize is the number of results kept for each check
+const DefaultHistorySize = 100
+
+// HistoryEntry records one result of a check
+type HistoryEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Status    string    `json:"status"`
+	Duration  string    `json:"duration"`
+}
+
+// CheckHistory is the recent history of a check
+type CheckHistory struct {
+	Check string `json:"check"`
+	// Entries are the most recent results, oldest first
+	Entries []HistoryEntry `json:"entries"`
+	// UptimePercent is the share of healthy results among all retained results
+	UptimePercent float64 `json:"uptime_percent"`
+	// LastStateChange is when the check entered its current status, or its oldest retained
+	// result when the status has not changed since
+	LastStateChange time.Time `json:"last_state_change"`
+}
+
+// HealthHistory keeps the most recent results of each check in a fixed-size ring buffer
+type HealthHistory struct {
+	mu    sync.RWMutex
+	size  int
+	rings map[string]*historyRing
+}
+
+// historyRing holds up to len(entries) results; next is where the following result is written
+type historyRing struct {
+	entries []HistoryEntry
+	next    int
+	full    bool
+}
+
+// NewHealthHistory creates a history keeping the last size results of each check
+func NewHealthHistory(size int) *HealthHistory {
+	if size < 1 {
+		size = DefaultHistorySize
+	}
+	return &HealthHistory{
+		size:  size,
+		rings: make(map[string]*historyRing),
+	}
+}
+
+// Record appends a result of check, overwriting its oldest result when the buffer is full
+func (h *HealthHistory) Record(check string, entry HistoryEntry) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ring, ok := h.rings[check]
+	if !ok {
+		ring = &historyRing{entries: make([]HistoryEntry, h.size)}
+		h.rings[check] = ring
+	}
+
+	ring.entries[ring.next] = entry
+	ring.next = (ring.next + 1) % len(ring.entries)
+	if ring.next == 0 {
+		ring.full = true
+	}
+}
+
+// Checks returns the names of the checks with recorded results, sorted
+func (h *HealthHistory) Checks() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	checks := make([]string, 0, len(h.rings))
+	for check := range h.rings {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+	return checks
+}
+
+// History returns up to limit of the most recent results of check. It returns false when no
+// result of check was recorded.
+func (h *HealthHistory) History(check string, limit int) (CheckHistory, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	ring, ok := h.rings[check]
+	if !ok {
+		return CheckHistory{}, false
+	}
+	entries := ring.ordered()
+
+	history := CheckHistory{Check: check}
+	healthy := 0
+	for i, entry := range entries {
+		if entry.Status == "healthy" {
+			healthy++
+		}
+		if i == 0 || entry.Status != entries[i-1].Status {
+			history.LastStateChange = entry.Timestamp
+		}
+	}
+	history.UptimePercent = float64(healthy) / float64(len(entries)) * 100
+
+	if limit > 0 && limit < len(entries) {
+		entries = entries[len(entries)-limit:]
+	}
+	history.Entries = entries
+
+	return history, true
+}
+
+// ordered copies the retained results, oldest first
+func (r *historyRing) ordered() []HistoryEntry {
+	if !r.full {
+		return append([]HistoryEntry(nil), r.entries[:r.next]...)
+	}
+	entries := make([]HistoryEntry, 0, len(r.entries))
+	entries = append(entries, r.entries[r.next:]...)
+	return append(entries, r.entries[:r.next]...)
+}
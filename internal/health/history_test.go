@@ -0,0 +1,89 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"skyhawk-security-microservice/internal/routing"
+)
+
+// recordStatuses records one result per status, a second apart from start
+func recordStatuses(h *HealthHistory, check string, start time.Time, statuses ...string) {
+	for i, status := range statuses {
+		h.Record(check, HistoryEntry{Timestamp: start.Add(time.Duration(i) * time.Second), Status: status})
+	}
+}
+
+func TestHistoryUptimePercent(t *testing.T) {
+	h := NewHealthHistory(DefaultHistorySize)
+	start := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	recordStatuses(h, "database", start, "healthy", "healthy", "healthy", "unhealthy", "unhealthy")
+
+	history, ok := h.History("database", 0)
+	if !ok {
+		t.Fatal("History found no results for database")
+	}
+	if history.UptimePercent != 60 {
+		t.Errorf("UptimePercent = %v, want 60", history.UptimePercent)
+	}
+	if want := start.Add(3 * time.Second); !history.LastStateChange.Equal(want) {
+		t.Errorf("LastStateChange = %s, want the first unhealthy result at %s", history.LastStateChange, want)
+	}
+	if len(history.Entries) != 5 || history.Entries[0].Status != "healthy" || history.Entries[4].Status != "unhealthy" {
+		t.Errorf("entries = %+v, want the 5 results oldest first", history.Entries)
+	}
+}
+
+func TestHistoryKeepsMostRecentResults(t *testing.T) {
+	h := NewHealthHistory(3)
+	start := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	recordStatuses(h, "disk", start, "unhealthy", "unhealthy", "healthy", "healthy", "degraded")
+
+	history, _ := h.History("disk", 0)
+	if len(history.Entries) != 3 {
+		t.Fatalf("kept %d results, want 3", len(history.Entries))
+	}
+	if !history.Entries[0].Timestamp.Equal(start.Add(2*time.Second)) || history.Entries[2].Status != "degraded" {
+		t.Errorf("entries = %+v, want the last 3 results oldest first", history.Entries)
+	}
+	if history.UptimePercent != float64(2)/3*100 {
+		t.Errorf("UptimePercent = %v, want the share of the retained results", history.UptimePercent)
+	}
+
+	limited, _ := h.History("disk", 1)
+	if len(limited.Entries) != 1 || limited.Entries[0].Status != "degraded" {
+		t.Errorf("limited entries = %+v, want the latest result", limited.Entries)
+	}
+	if limited.UptimePercent != history.UptimePercent {
+		t.Errorf("limit changed UptimePercent to %v", limited.UptimePercent)
+	}
+}
+
+func TestHistoryUnknownCheck(t *testing.T) {
+	if _, ok := NewHealthHistory(DefaultHistorySize).History("database", 0); ok {
+		t.Error("History reported results for a check never recorded")
+	}
+}
+
+func TestCheckHealthRecordsHistory(t *testing.T) {
+	hc := NewHealthChecker(newUnreachableDB(t))
+	queue := &fakeQueue{lengths: map[string]int64{}}
+	hc.SetQueue(queue, DefaultQueueDepthConfig)
+
+	for _, depth := range []int64{0, 0, 0, 20000, 20000} {
+		queue.lengths[routing.DefaultQueue] = depth
+		hc.CheckHealth(context.Background())
+	}
+
+	history, ok := hc.History("queue", 0)
+	if !ok {
+		t.Fatal("no history recorded for the queue check")
+	}
+	if history.UptimePercent != 60 {
+		t.Errorf("queue UptimePercent = %v after 3 healthy and 2 unhealthy checks, want 60", history.UptimePercent)
+	}
+	if checks := hc.HistoryChecks(); len(checks) != 5 {
+		t.Errorf("HistoryChecks() = %v, want every check run", checks)
+	}
+}
@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKey is the gin context key of the request logger set by the structured log middleware
+const ContextKey = "logger"
+
+// contextKey is the request context key of the request logger
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying logger, for code that only receives the request
+// context
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or the global logger
+func FromContext(ctx context.Context) *Logger {
+	if logger, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return logger
+	}
+	return GetLogger()
+}
+
+// FromGinContext returns the logger of the request, or the global logger when the request has
+// none
+func FromGinContext(c *gin.Context) *Logger {
+	if value, ok := c.Get(ContextKey); ok {
+		if logger, ok := value.(*Logger); ok {
+			return logger
+		}
+	}
+	if c.Request != nil {
+		return FromContext(c.Request.Context())
+	}
+	return GetLogger()
+}
@@ -7,8 +7,9 @@ import (
 	"log"
 	"os"
 	"runtime"
-	"time"
+	"strings"
 	"sync"
+	"time"
 )
 
 // Level represents log levels
@@ -40,20 +41,38 @@ func (l Level) String() string {
 	}
 }
 
+// ParseLevel parses a level name such as "info" or "ERROR"
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return DEBUG, nil
+	case "INFO":
+		return INFO, nil
+	case "WARN", "WARNING":
+		return WARN, nil
+	case "ERROR":
+		return ERROR, nil
+	case "FATAL":
+		return FATAL, nil
+	default:
+		return INFO, fmt.Errorf("unknown log level: %s", name)
+	}
+}
+
 // Fields represents structured log fields
 type Fields map[string]interface{}
 
 // Entry represents a log entry
 type Entry struct {
-	Level     Level                 `json:"level"`
-	Message   string                `json:"message"`
-	Timestamp time.Time             `json:"timestamp"`
-	Fields    Fields                `json:"fields,omitempty"`
-	Caller    string                `json:"caller,omitempty"`
-	RequestID string                `json:"request_id,omitempty"`
-	UserID    string                `json:"user_id,omitempty"`
-	Duration  time.Duration         `json:"duration,omitempty"`
-	Error     error                 `json:"error,omitempty"`
+	Level     Level                  `json:"level"`
+	Message   string                 `json:"message"`
+	Timestamp time.Time              `json:"timestamp"`
+	Fields    Fields                 `json:"fields,omitempty"`
+	Caller    string                 `json:"caller,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	UserID    string                 `json:"user_id,omitempty"`
+	Duration  time.Duration          `json:"duration,omitempty"`
+	Error     error                  `json:"error,omitempty"`
 	Context   map[string]interface{} `json:"context,omitempty"`
 }
 
@@ -92,13 +111,13 @@ func (h *JSONHandler) Handle(entry Entry) error {
 		entry.Timestamp.Format(time.RFC3339),
 		entry.Caller,
 	)
-	
+
 	if len(entry.Fields) > 0 {
 		logLine += fmt.Sprintf(`,"fields":%v`, entry.Fields)
 	}
-	
+
 	logLine += "\n"
-	
+
 	_, err := h.output.Write([]byte(logLine))
 	return err
 }
@@ -238,6 +257,18 @@ func (l *Logger) log(level Level, message string, fields Fields) {
 	}
 }
 
+// Log logs a message at the given level. FATAL is logged as ERROR since Log never exits.
+func (l *Logger) Log(level Level, message string, fields ...Fields) {
+	if level > ERROR {
+		level = ERROR
+	}
+	var f Fields
+	if len(fields) > 0 {
+		f = fields[0]
+	}
+	l.log(level, message, f)
+}
+
 // Debug logs a debug message
 func (l *Logger) Debug(message string, fields ...Fields) {
 	var f Fields
@@ -301,15 +332,22 @@ func NewRequestLogger(logger *Logger) *RequestLogger {
 	return &RequestLogger{logger: logger}
 }
 
-// LogRequest logs an HTTP request
-func (rl *RequestLogger) LogRequest(ctx context.Context, method, path, remoteAddr string, statusCode int, duration time.Duration) {
+// LogRequest logs an HTTP request with the bytes of response body written, merging any extra
+// fields such as queue_time
+func (rl *RequestLogger) LogRequest(ctx context.Context, method, path, remoteAddr string, statusCode int, bytes int64, duration time.Duration, extra ...Fields) {
 	fields := Fields{
 		"method":      method,
 		"path":        path,
 		"remote_addr": remoteAddr,
 		"status_code": statusCode,
+		"bytes":       bytes,
 		"duration":    duration.String(),
 	}
+	for _, f := range extra {
+		for k, v := range f {
+			fields[k] = v
+		}
+	}
 
 	level := INFO
 	if statusCode >= 400 {
@@ -361,4 +399,4 @@ func Error(message string, err error, fields ...Fields) {
 // Fatal logs a fatal message using the global logger
 func Fatal(message string, fields ...Fields) {
 	GetLogger().Fatal(message, fields...)
-} 
\ No newline at end of file
+}
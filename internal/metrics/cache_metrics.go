@@ -0,0 +1,19 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Results of a cache lookup, used as the result label of EventCacheLookups
+const (
+	CacheResultHit  = "hit"
+	CacheResultMiss = "miss"
+)
+
+// EventCacheLookups counts the GetEventByID lookups of the event cache by result (hit, miss).
+// The API server serves it from Registry.
+var EventCacheLookups = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+	Name: "event_cache_lookups_total",
+	Help: "Event cache lookups by result (hit, miss).",
+}, []string{"result"})
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"skyhawk-security-microservice/internal/database"
+)
+
+// poolMetrics mirrors the database connection pool statistics
+type poolMetrics struct {
+	openConnections   *prometheus.GaugeVec
+	waited            prometheus.Gauge
+	waitDuration      prometheus.Gauge
+	maxIdleClosed     prometheus.Gauge
+	maxLifetimeClosed prometheus.Gauge
+}
+
+func newPoolMetrics(reg prometheus.Registerer) *poolMetrics {
+	factory := promauto.With(reg)
+	return &poolMetrics{
+		openConnections: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "db_open_connections",
+			Help: "Open database connections by state (in_use, idle).",
+		}, []string{"state"}),
+		waited: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "db_connections_waited_total",
+			Help: "Connections waited for because the pool was exhausted.",
+		}),
+		waitDuration: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "db_wait_duration_seconds_total",
+			Help: "Time spent waiting for a free connection.",
+		}),
+		maxIdleClosed: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "db_connections_max_idle_closed_total",
+			Help: "Connections closed because the idle pool was full.",
+		}),
+		maxLifetimeClosed: factory.NewGauge(prometheus.GaugeOpts{
+			Name: "db_connections_max_lifetime_closed_total",
+			Help: "Connections closed because they reached their maximum lifetime.",
+		}),
+	}
+}
+
+// update copies the current pool statistics of db into the gauges
+func (m *poolMetrics) update(db *database.DB) {
+	stats := db.DB.Stats()
+	m.openConnections.WithLabelValues("in_use").Set(float64(stats.InUse))
+	m.openConnections.WithLabelValues("idle").Set(float64(stats.Idle))
+	m.waited.Set(float64(stats.WaitCount))
+	m.waitDuration.Set(stats.WaitDuration.Seconds())
+	m.maxIdleClosed.Set(float64(stats.MaxIdleClosed))
+	m.maxLifetimeClosed.Set(float64(stats.MaxLifetimeClosed))
+}
+
+// StartPoolMetricsExporter registers the connection pool gauges with reg and refreshes
+// them immediately and then on every interval until the returned function is called
+func StartPoolMetricsExporter(db *database.DB, reg prometheus.Registerer, interval time.Duration) (stop func()) {
+	m := newPoolMetrics(reg)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			m.update(db)
+
+			select {
+			case <-ticker.C:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"skyhawk-security-microservice/internal/database"
+)
+
+// poolTestDriver opens connections that only count toward the pool statistics
+type poolTestDriver struct{}
+
+func (poolTestDriver) Open(name string) (driver.Conn, error) { return poolTestConn{}, nil }
+
+type poolTestConn struct{}
+
+func (poolTestConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (poolTestConn) Close() error              { return nil }
+func (poolTestConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func init() {
+	sql.Register("pooltest", poolTestDriver{})
+}
+
+func newPoolTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := sql.Open("pooltest", "")
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &database.DB{DB: db}
+}
+
+// checkOut takes n connections from the pool, returned to it at the end of the test
+func checkOut(t *testing.T, db *database.DB, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			t.Fatalf("Conn: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+	}
+}
+
+func TestPoolMetricsInUseGaugeFollowsCheckedOutConnections(t *testing.T) {
+	db := newPoolTestDB(t)
+	m := newPoolMetrics(prometheus.NewRegistry())
+
+	m.update(db)
+	if got := testutil.ToFloat64(m.openConnections.WithLabelValues("in_use")); got != 0 {
+		t.Fatalf("in_use = %v before any connection, want 0", got)
+	}
+
+	checkOut(t, db, 3)
+	m.update(db)
+	if got := testutil.ToFloat64(m.openConnections.WithLabelValues("in_use")); got != 3 {
+		t.Errorf("in_use = %v with 3 connections checked out, want 3", got)
+	}
+	if got := testutil.ToFloat64(m.openConnections.WithLabelValues("idle")); got != 0 {
+		t.Errorf("idle = %v, want 0", got)
+	}
+}
+
+func TestStartPoolMetricsExporterRefreshesGauges(t *testing.T) {
+	db := newPoolTestDB(t)
+	reg := prometheus.NewRegistry()
+	stop := StartPoolMetricsExporter(db, reg, 10*time.Millisecond)
+	defer stop()
+
+	checkOut(t, db, 2)
+
+	want := `
+# HELP db_open_connections Open database connections by state (in_use, idle).
+# TYPE db_open_connections gauge
+db_open_connections{state="idle"} 0
+db_open_connections{state="in_use"} 2
+`
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		err := testutil.GatherAndCompare(reg, strings.NewReader(want), "db_open_connections")
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("gauges were not refreshed: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
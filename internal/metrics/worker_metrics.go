@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	_ "embed"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Outcomes of processing an event, used as the status label of the worker metrics
+const (
+	ProcessingStatusSuccess = "success"
+	ProcessingStatusFailure = "failure"
+	ProcessingStatusTimeout = "timeout"
+)
+
+// ProcessingDuration observes how long workers take to process an event, by event type and
+// outcome. Workers serve it from the default registry on -metrics-addr.
+var ProcessingDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "event_processing_duration_seconds",
+	Help:    "Time taken to process an event by event type and outcome (success, failure, timeout).",
+	Buckets: prometheus.DefBuckets,
+}, []string{"event_type", "status"})
+
+// EventsProcessedTotal counts the events workers processed, by event type and outcome
+var EventsProcessedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "events_processed_total",
+	Help: "Events processed by event type and outcome (success, failure, timeout).",
+}, []string{"event_type", "status"})
+
+// ObserveProcessing records one processed event in ProcessingDuration and EventsProcessedTotal
+func ObserveProcessing(eventType, status string, elapsed time.Duration) {
+	ProcessingDuration.WithLabelValues(eventType, status).Observe(elapsed.Seconds())
+	EventsProcessedTotal.WithLabelValues(eventType, status).Inc()
+}
+
+// WorkerDashboard is a Grafana dashboard charting the worker processing metrics, importable
+// through the Grafana UI or provisioning
+//
+//go:embed dashboards/worker.json
+var WorkerDashboard []byte
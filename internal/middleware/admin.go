@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+	apperrors "skyhawk-security-microservice/internal/errors"
+)
+
+// AdminAPIKeyHeader carries the admin API key
+const AdminAPIKeyHeader = "X-Admin-API-Key"
+
+// AdminAPIKeyMiddleware rejects requests whose X-Admin-API-Key header does not match apiKey.
+// Every request is rejected when apiKey is empty, so a missing key never opens the routes.
+func AdminAPIKeyMiddleware(apiKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if apiKey == "" {
+			api.Fail(c, apperrors.NewForbiddenError("Admin API key is not configured"))
+			return
+		}
+
+		provided := c.GetHeader(AdminAPIKeyHeader)
+		if subtle.ConstantTimeCompare([]byte(provided), []byte(apiKey)) != 1 {
+			api.Fail(c, apperrors.NewUnauthorizedError("Invalid or missing admin API key"))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"skyhawk-security-microservice/internal/api"
+	apperrors "skyhawk-security-microservice/internal/errors"
+)
+
+// RemainingAttemptsHeader reports how many more failed attempts are allowed before a lockout
+const RemainingAttemptsHeader = "X-Remaining-Attempts"
+
+// BruteForceStore counts failed authentication attempts per key
+type BruteForceStore interface {
+	// Failures returns the number of failed attempts recorded for key
+	Failures(key string) (int, error)
+	// RecordFailure increments the failure count for key and returns it. The count
+	// expires ttl after the latest failure.
+	RecordFailure(key string, ttl time.Duration) (int, error)
+	// Reset clears the failure count for key
+	Reset(key string) error
+}
+
+// maxCredentialsBodySize bounds the request body read for the username of an authentication
+// attempt, so a huge body cannot exhaust memory before the handler runs
+const maxCredentialsBodySize = 64 << 10
+
+// BruteForceKey builds the store key for a client IP and username
+func BruteForceKey(ip, username string) string {
+	return "bruteforce:" + ip + ":" + username
+}
+
+// BruteForceMiddleware locks out an IP and username pair after maxAttempts failed
+// authentication attempts. A failure is a 401 response from the handler. Locked out
+// clients get 429 without reaching the handler until lockoutDuration has passed since
+// their last failure.
+func BruteForceMiddleware(store BruteForceStore, maxAttempts int, lockoutDuration time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, err := bruteForceUsername(c)
+		if err != nil {
+			api.Fail(c, apperrors.NewValidationError("Request body too large", err.Error()))
+			return
+		}
+		key := BruteForceKey(c.ClientIP(), username)
+
+		failures, err := store.Failures(key)
+		if err != nil {
+			// Fail open; an unavailable store should not lock everyone out
+			log.Printf("Warning: failed to read brute force counter: %v", err)
+			c.Next()
+			return
+		}
+
+		if failures >= maxAttempts {
+			c.Header(RemainingAttemptsHeader, "0")
+			c.Header("Retry-After", strconv.Itoa(int(lockoutDuration.Seconds())))
+			api.Fail(c, apperrors.NewRateLimitError("Too many failed authentication attempts", ""))
+			return
+		}
+
+		// Attempts left counting this one; set before the handler writes the response
+		c.Header(RemainingAttemptsHeader, strconv.Itoa(maxAttempts-failures))
+
+		c.Next()
+
+		if c.Writer.Status() != http.StatusUnauthorized {
+			return
+		}
+		if _, err := store.RecordFailure(key, lockoutDuration); err != nil {
+			log.Printf("Warning: failed to record authentication failure: %v", err)
+		}
+	}
+}
+
+// bruteForceUsername returns the username of an authentication attempt from basic auth
+// or a JSON body's "username" field. The body is restored for the handler. It returns an
+// error when the body exceeds maxCredentialsBodySize.
+func bruteForceUsername(c *gin.Context) (string, error) {
+	if username, _, ok := c.Request.BasicAuth(); ok {
+		return username, nil
+	}
+	if c.Request.Body == nil {
+		return "", nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxCredentialsBodySize))
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return "", fmt.Errorf("the body exceeds %d bytes", tooLarge.Limit)
+		}
+		return "", nil
+	}
+
+	var credentials struct {
+		Username string `json:"username"`
+	}
+	if err := json.Unmarshal(body, &credentials); err != nil {
+		return "", nil
+	}
+	return credentials.Username, nil
+}
+
+// bruteForceSweepInterval is how often MemoryBruteForceStore drops expired entries
+const bruteForceSweepInterval = time.Minute
+
+// MemoryBruteForceStore keeps failure counts in memory, so lockouts apply to one instance only.
+// Expired entries are swept at most every bruteForceSweepInterval when failures are recorded, so
+// attempts with ever new usernames do not grow the store without bound.
+type MemoryBruteForceStore struct {
+	mu        sync.Mutex
+	entries   map[string]bruteForceEntry
+	nextSweep time.Time
+}
+
+type bruteForceEntry struct {
+	failures int
+	expires  time.Time
+}
+
+// NewMemoryBruteForceStore creates an empty in-memory store
+func NewMemoryBruteForceStore() *MemoryBruteForceStore {
+	return &MemoryBruteForceStore{entries: make(map[string]bruteForceEntry)}
+}
+
+// sweep drops expired entries once the sweep interval has passed; s.mu must be held
+func (s *MemoryBruteForceStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+	for key, entry := range s.entries {
+		if now.After(entry.expires) {
+			delete(s.entries, key)
+		}
+	}
+	s.nextSweep = now.Add(bruteForceSweepInterval)
+}
+
+// Failures returns the unexpired failure count for key
+func (s *MemoryBruteForceStore) Failures(key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		delete(s.entries, key)
+		return 0, nil
+	}
+	return entry.failures, nil
+}
+
+// RecordFailure increments the failure count for key and extends its expiry
+func (s *MemoryBruteForceStore) RecordFailure(key string, ttl time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	entry := s.entries[key]
+	if now.After(entry.expires) {
+		entry.failures = 0
+	}
+	entry.failures++
+	entry.expires = now.Add(ttl)
+	s.entries[key] = entry
+	return entry.failures, nil
+}
+
+// Reset clears the failure count for key
+func (s *MemoryBruteForceStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// RedisBruteForceStore keeps failure counts in Redis so lockouts are shared by all instances
+type RedisBruteForceStore struct {
+	client *redis.Client
+}
+
+// NewRedisBruteForceStore creates a store backed by the given Redis client
+func NewRedisBruteForceStore(client *redis.Client) *RedisBruteForceStore {
+	return &RedisBruteForceStore{client: client}
+}
+
+// Failures returns the failure count for key; expired keys are removed by Redis
+func (s *RedisBruteForceStore) Failures(key string) (int, error) {
+	failures, err := s.client.Get(context.Background(), key).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return failures, err
+}
+
+// RecordFailure increments the failure count for key and extends its expiry in one transaction
+func (s *RedisBruteForceStore) RecordFailure(key string, ttl time.Duration) (int, error) {
+	var incr *redis.IntCmd
+	_, err := s.client.TxPipelined(context.Background(), func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(context.Background(), key)
+		pipe.Expire(context.Background(), key, ttl)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(incr.Val()), nil
+}
+
+// Reset clears the failure count for key
+func (s *RedisBruteForceStore) Reset(key string) error {
+	return s.client.Del(context.Background(), key).Err()
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newBruteForceRouter serves POST /login, which answers 401 unless the password is "right"
+func newBruteForceRouter(store BruteForceStore, maxAttempts int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/login", BruteForceMiddleware(store, maxAttempts, time.Minute), func(c *gin.Context) {
+		var credentials struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if err := c.ShouldBindJSON(&credentials); err != nil || credentials.Password != "right" {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func login(router *gin.Engine, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestBruteForceMiddlewareLocksOutAfterMaxAttempts(t *testing.T) {
+	router := newBruteForceRouter(NewMemoryBruteForceStore(), 3)
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		rec := login(router, `{"username":"alice","password":"wrong"}`)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d: status = %d, want 401", attempt, rec.Code)
+		}
+		if got := rec.Header().Get(RemainingAttemptsHeader); got != strconv.Itoa(4-attempt) {
+			t.Errorf("attempt %d: %s = %s, want %d", attempt, RemainingAttemptsHeader, got, 4-attempt)
+		}
+	}
+
+	// Locked out even with the right password; the handler, which restores the body, is not reached
+	if rec := login(router, `{"username":"alice","password":"right"}`); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("status after lockout = %d, want 429", rec.Code)
+	}
+	// Other usernames are not affected, and the handler still sees the body
+	if rec := login(router, `{"username":"bob","password":"right"}`); rec.Code != http.StatusOK {
+		t.Errorf("status for another user = %d, want 200", rec.Code)
+	}
+}
+
+func TestBruteForceMiddlewareRejectsOversizedBody(t *testing.T) {
+	router := newBruteForceRouter(NewMemoryBruteForceStore(), 3)
+
+	body := `{"username":"alice","password":"` + strings.Repeat("x", maxCredentialsBodySize) + `"}`
+	if rec := login(router, body); rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400 for a body over %d bytes", rec.Code, maxCredentialsBodySize)
+	}
+}
+
+func TestMemoryBruteForceStoreSweepsExpiredEntries(t *testing.T) {
+	store := NewMemoryBruteForceStore()
+	for i := 0; i < 100; i++ {
+		if _, err := store.RecordFailure(BruteForceKey("10.0.0.1", "user"+strconv.Itoa(i)), time.Millisecond); err != nil {
+			t.Fatalf("RecordFailure: %v", err)
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	// The next failure after the sweep interval drops the expired entries
+	store.mu.Lock()
+	store.nextSweep = time.Now()
+	store.mu.Unlock()
+	if _, err := store.RecordFailure(BruteForceKey("10.0.0.1", "alice"), time.Minute); err != nil {
+		t.Fatalf("RecordFailure: %v", err)
+	}
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.entries) != 1 {
+		t.Errorf("store holds %d entries after the sweep, want 1", len(store.entries))
+	}
+}
+
+func TestMemoryBruteForceStoreExpiresFailures(t *testing.T) {
+	store := NewMemoryBruteForceStore()
+	key := BruteForceKey("10.0.0.1", "alice")
+	store.RecordFailure(key, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	if failures, _ := store.Failures(key); failures != 0 {
+		t.Errorf("Failures = %d after expiry, want 0", failures)
+	}
+	if failures, _ := store.RecordFailure(key, time.Minute); failures != 1 {
+		t.Errorf("RecordFailure = %d after expiry, want a fresh count of 1", failures)
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// UserIDHeader and TenantIDHeader carry the identity of the caller, set by the authenticating
+// gateway in front of the service
+const (
+	UserIDHeader   = "X-User-ID"
+	TenantIDHeader = "X-Tenant-ID"
+)
+
+// IdentityMiddleware stores the user ID and tenant ID forwarded by the gateway in the gin
+// context as user_id and tenant_id. A request without them carries no identity.
+func IdentityMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userID := c.GetHeader(UserIDHeader); userID != "" {
+			c.Set("user_id", userID)
+		}
+		if tenantID := c.GetHeader(TenantIDHeader); tenantID != "" {
+			c.Set("tenant_id", tenantID)
+		}
+		c.Next()
+	}
+}
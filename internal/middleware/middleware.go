@@ -1,10 +1,24 @@
 package middleware
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"skyhawk-security-microservice/internal/api"
+	apperrors "skyhawk-security-microservice/internal/errors"
+	"skyhawk-security-microservice/internal/logger"
 )
 
 // CORSMiddleware adds CORS headers
@@ -12,7 +26,7 @@ func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-User-ID, X-Tenant-ID")
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusNoContent)
@@ -23,7 +37,8 @@ func CORSMiddleware() gin.HandlerFunc {
 	}
 }
 
-// RequestIDMiddleware adds a request ID to each request
+// RequestIDMiddleware adds a request ID to each request, keeping one sent by the client. The ID
+// is stored in the gin context and returned in the X-Request-ID response header.
 func RequestIDMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requestID := c.GetHeader("X-Request-ID")
@@ -36,7 +51,235 @@ func RequestIDMiddleware() gin.HandlerFunc {
 	}
 }
 
-// generateRequestID generates a unique request ID
+// requestLogFields are the gin context keys copied into the request logger when they are set
+var requestLogFields = []string{"request_id", "user_id", "tenant_id"}
+
+// StructuredLogMiddleware stores a child of l enriched with the method, path, request ID, user
+// ID and tenant ID of the request in the gin context and the request context, for handlers to
+// log through logger.FromGinContext. It must run after the middleware setting those IDs. Once
+// the request completes, a DEBUG entry records its status_code and duration_ms; the INFO
+// access log stays with RequestLoggerMiddleware.
+func StructuredLogMiddleware(l *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		fields := logger.Fields{
+			"method": c.Request.Method,
+			"path":   c.Request.URL.Path,
+		}
+		for _, key := range requestLogFields {
+			if value := c.GetString(key); value != "" {
+				fields[key] = value
+			}
+		}
+		requestLogger := l.WithFields(fields)
+		c.Set(logger.ContextKey, requestLogger)
+		c.Request = c.Request.WithContext(logger.NewContext(c.Request.Context(), requestLogger))
+
+		defer func() {
+			requestLogger.Debug("Request completed", logger.Fields{
+				"status_code": c.Writer.Status(),
+				"duration_ms": time.Since(start).Milliseconds(),
+			})
+		}()
+		c.Next()
+	}
+}
+
+// generateRequestID generates a random UUID v4 request ID
 func generateRequestID() string {
-	return "req-" + time.Now().Format("20060102150405") + "-" + time.Now().Format("000000000")
-} 
\ No newline at end of file
+	return uuid.NewString()
+}
+
+// TracingMiddleware continues the trace of an incoming traceparent header, or starts one, in a
+// server span per request. The span context is stored in the request context, so events
+// published by handlers carry it to the workers.
+func TracingMiddleware() gin.HandlerFunc {
+	tracer := otel.Tracer("skyhawk-security-microservice/internal/middleware")
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", c.Request.Method),
+				attribute.String("http.route", route),
+			),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// RequestLoggerMiddleware logs each request through the structured RequestLogger, including
+// the bytes of response body written. When a load balancer sets X-Request-Start, the time spent
+// before reaching the handler is logged as queue_time.
+func RequestLoggerMiddleware(rl *logger.RequestLogger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		queueTime, hasQueueTime := parseRequestStart(c.GetHeader("X-Request-Start"), start)
+
+		writer := &countingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		var extra logger.Fields
+		if hasQueueTime {
+			extra = logger.Fields{"queue_time": queueTime.String()}
+		}
+
+		rl.LogRequest(c, c.Request.Method, c.Request.URL.Path, c.ClientIP(), c.Writer.Status(), writer.bytes.Load(), time.Since(start), extra)
+	}
+}
+
+// countingWriter counts the bytes of response body written, accumulating across the writes
+// of a streamed response
+type countingWriter struct {
+	gin.ResponseWriter
+	// bytes is atomic because a timed out request is answered from another goroutine
+	bytes atomic.Int64
+}
+
+// Write writes the body and counts the bytes written
+func (w *countingWriter) Write(data []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(data)
+	w.bytes.Add(int64(n))
+	return n, err
+}
+
+// WriteString writes the body and counts the bytes written
+func (w *countingWriter) WriteString(s string) (int, error) {
+	n, err := w.ResponseWriter.WriteString(s)
+	w.bytes.Add(int64(n))
+	return n, err
+}
+
+// Unwrap returns the wrapped writer, so http.ResponseController reaches the connection
+func (w *countingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// parseRequestStart parses an X-Request-Start header (epoch millis, optionally prefixed
+// with "t=") and returns how long the request waited before now. Absent, malformed or
+// future timestamps are ignored.
+func parseRequestStart(header string, now time.Time) (time.Duration, bool) {
+	header = strings.TrimPrefix(strings.TrimSpace(header), "t=")
+	if header == "" {
+		return 0, false
+	}
+
+	value, err := strconv.ParseInt(header, 10, 64)
+	if err != nil || value <= 0 {
+		return 0, false
+	}
+
+	// Some proxies send microseconds or nanoseconds instead of milliseconds
+	var start time.Time
+	switch {
+	case value > 1e17:
+		start = time.Unix(0, value)
+	case value > 1e14:
+		start = time.UnixMicro(value)
+	default:
+		start = time.UnixMilli(value)
+	}
+
+	queueTime := now.Sub(start)
+	if queueTime < 0 {
+		return 0, false
+	}
+	return queueTime, true
+}
+
+// ErrorHandlerMiddleware logs the errors handlers attach with c.Error. Application errors are
+// logged with their structured fields, and answered with their status code when the handler
+// did not write a response.
+func ErrorHandlerMiddleware(l *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		for _, ginErr := range c.Errors {
+			fields := logger.Fields{
+				"method": c.Request.Method,
+				"path":   c.Request.URL.Path,
+			}
+			if requestID, ok := c.Get("request_id"); ok {
+				fields["request_id"] = requestID
+			}
+
+			var appErr *apperrors.AppError
+			if !errors.As(ginErr.Err, &appErr) {
+				l.Error("Request failed", ginErr.Err, fields)
+				continue
+			}
+
+			for k, v := range appErr.LogFields() {
+				fields[k] = v
+			}
+			level := logger.WARN
+			if appErr.StatusCode >= http.StatusInternalServerError {
+				level = logger.ERROR
+			}
+			l.Log(level, "Request failed", fields)
+
+			if !c.Writer.Written() {
+				c.JSON(appErr.StatusCode, api.NewErrorResponse(appErr, c.GetString("request_id")))
+			}
+		}
+	}
+}
+
+// RecoveryMiddleware recovers panics in later handlers, logs them with the stack of the
+// panic and answers with an internal error carrying the request ID
+func RecoveryMiddleware(l *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// net/http uses this panic to abort a response silently
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			err, ok := recovered.(error)
+			if !ok {
+				err = fmt.Errorf("%v", recovered)
+			}
+			// Created while panicking, so the recorded stack includes the panic site
+			appErr := apperrors.NewInternalError("Internal server error", err)
+
+			requestID := c.GetString("request_id")
+			fields := appErr.LogFields()
+			fields["method"] = c.Request.Method
+			fields["path"] = c.Request.URL.Path
+			fields["panic"] = fmt.Sprint(recovered)
+			if requestID != "" {
+				fields["request_id"] = requestID
+			}
+			l.Log(logger.ERROR, "Panic recovered", fields)
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			c.AbortWithStatusJSON(appErr.StatusCode, api.NewErrorResponse(appErr, requestID))
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,265 @@
+package middleware
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"skyhawk-security-microservice/internal/api"
+	apperrors "skyhawk-security-microservice/internal/errors"
+	"skyhawk-security-microservice/internal/logger"
+)
+
+// recordingHandler keeps every log entry
+type recordingHandler struct {
+	mu      sync.Mutex
+	entries []logger.Entry
+}
+
+func (h *recordingHandler) Handle(entry logger.Entry) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, entry)
+	return nil
+}
+
+func (h *recordingHandler) Entries() []logger.Entry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]logger.Entry(nil), h.entries...)
+}
+
+// newRecordingLogger returns a logger whose entries are kept by the returned handler
+func newRecordingLogger() (*logger.Logger, *recordingHandler) {
+	l := logger.NewLogger(logger.DEBUG, io.Discard)
+	handler := &recordingHandler{}
+	l.AddHandler(handler)
+	return l, handler
+}
+
+// newErrorHandlerRouter serves GET /fail, which attaches err to the request
+func newErrorHandlerRouter(l *logger.Logger, err error) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("request_id", "req-1") })
+	router.Use(ErrorHandlerMiddleware(l))
+	router.GET("/fail", func(c *gin.Context) { c.Error(err) })
+	return router
+}
+
+func TestErrorHandlerMiddlewareLogsAppErrorFields(t *testing.T) {
+	l, entries := newRecordingLogger()
+	router := newErrorHandlerRouter(l, apperrors.NewNotFoundError("event", "abc").WithContext("event_id", "abc"))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if body["type"] != string(apperrors.ErrorTypeNotFound) || body["request_id"] != "req-1" {
+		t.Errorf("body = %v, want the not found error with the request ID", body)
+	}
+
+	logged := entries.Entries()
+	if len(logged) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(logged))
+	}
+	entry := logged[0]
+	if entry.Level != logger.WARN {
+		t.Errorf("level = %s, want WARN for a client error", entry.Level)
+	}
+	for key, want := range map[string]interface{}{
+		"type":       string(apperrors.ErrorTypeNotFound),
+		"event_id":   "abc",
+		"code":       http.StatusNotFound,
+		"request_id": "req-1",
+		"method":     http.MethodGet,
+		"path":       "/fail",
+	} {
+		if entry.Fields[key] != want {
+			t.Errorf("field %s = %v, want %v", key, entry.Fields[key], want)
+		}
+	}
+	if entry.Fields["stack"] == nil {
+		t.Error("entry has no stack field")
+	}
+}
+
+func TestErrorHandlerMiddlewareLogsServerErrorsAsErrors(t *testing.T) {
+	l, entries := newRecordingLogger()
+	router := newErrorHandlerRouter(l, apperrors.NewInternalError("failed to store event", fmt.Errorf("connection refused")))
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/fail", nil))
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	logged := entries.Entries()
+	if len(logged) != 1 || logged[0].Level != logger.ERROR || logged[0].Fields["error"] != "connection refused" {
+		t.Errorf("logged %+v, want one ERROR entry with the cause", logged)
+	}
+}
+
+// servePanic serves GET /panic, which panics with value, behind RecoveryMiddleware
+func servePanic(l *logger.Logger, value interface{}) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set("request_id", "req-1") })
+	router.Use(RecoveryMiddleware(l))
+	router.GET("/panic", func(c *gin.Context) { panic(value) })
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	return rec
+}
+
+func TestRecoveryMiddlewareRespondsWithInternalError(t *testing.T) {
+	l, _ := newRecordingLogger()
+	rec := servePanic(l, "boom")
+
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusInternalServerError)
+	}
+	var body api.ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response %s: %v", rec.Body.String(), err)
+	}
+	if body.Type != string(apperrors.ErrorTypeInternal) || body.Code != http.StatusInternalServerError || body.RequestID != "req-1" {
+		t.Errorf("response = %+v, want an internal error for request req-1", body)
+	}
+}
+
+func TestRecoveryMiddlewareLogsPanicWithStack(t *testing.T) {
+	l, entries := newRecordingLogger()
+	servePanic(l, "boom")
+
+	logged := entries.Entries()
+	if len(logged) != 1 {
+		t.Fatalf("logged %d entries, want 1", len(logged))
+	}
+	entry := logged[0]
+	if entry.Level != logger.ERROR {
+		t.Errorf("level = %v, want ERROR", entry.Level)
+	}
+	if fields := entry.Fields; fields["panic"] != "boom" || fields["request_id"] != "req-1" || fields["type"] != string(apperrors.ErrorTypeInternal) {
+		t.Errorf("fields = %v, want the panic, request ID and error type", fields)
+	}
+	if stack, _ := entry.Fields["stack"].(string); stack == "" {
+		t.Errorf("fields = %v, want the stack of the panic", entry.Fields)
+	}
+}
+
+func TestRecoveryMiddlewareRepanicsAbortHandler(t *testing.T) {
+	l, _ := newRecordingLogger()
+	defer func() {
+		if recovered := recover(); recovered != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", recovered)
+		}
+	}()
+	servePanic(l, http.ErrAbortHandler)
+}
+
+var (
+	spanExporterOnce sync.Once
+	spanExporter     *tracetest.InMemoryExporter
+)
+
+// recordSpans registers an in-memory span exporter with otel once per test binary and
+// returns it emptied
+func recordSpans() *tracetest.InMemoryExporter {
+	spanExporterOnce.Do(func() {
+		spanExporter = tracetest.NewInMemoryExporter()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spanExporter)))
+		otel.SetTextMapPropagator(propagation.TraceContext{})
+	})
+	spanExporter.Reset()
+	return spanExporter
+}
+
+func TestTracingMiddlewareContinuesIncomingTrace(t *testing.T) {
+	exporter := recordSpans()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(TracingMiddleware())
+	var handlerSpan trace.SpanContext
+	router.POST("/api/v1/events", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "POST /api/v1/events" || span.SpanKind != trace.SpanKindServer {
+		t.Errorf("span = %s (%s), want the server span of the route", span.Name, span.SpanKind)
+	}
+	if span.SpanContext.TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || span.Parent.SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("span %s has parent %s, want a child of the incoming traceparent", span.SpanContext.TraceID(), span.Parent.SpanID())
+	}
+	if handlerSpan.SpanID() != span.SpanContext.SpanID() {
+		t.Errorf("handler saw span %s, want the server span %s", handlerSpan.SpanID(), span.SpanContext.SpanID())
+	}
+	if span.Status.Code != codes.Error {
+		t.Errorf("span status = %v, want an error for a 500", span.Status)
+	}
+}
+
+func TestStructuredLogMiddlewareAddsRequestFieldsToHandlerLogs(t *testing.T) {
+	l, logs := newRecordingLogger()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware())
+	router.Use(IdentityMiddleware())
+	router.Use(StructuredLogMiddleware(l))
+	router.GET("/events/:id", func(c *gin.Context) {
+		logger.FromGinContext(c).Info("From gin context")
+		logger.FromContext(c.Request.Context()).Info("From request context")
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/events/evt-1", nil)
+	req.Header.Set("X-Request-ID", "req-42")
+	req.Header.Set(UserIDHeader, "user-7")
+	req.Header.Set(TenantIDHeader, "acme")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	want := logger.Fields{"method": "GET", "path": "/events/evt-1", "request_id": "req-42", "user_id": "user-7", "tenant_id": "acme"}
+	found := 0
+	for _, entry := range logs.Entries() {
+		if entry.Message != "From gin context" && entry.Message != "From request context" {
+			continue
+		}
+		found++
+		for key, value := range want {
+			if entry.Fields[key] != value {
+				t.Errorf("%q logged %s = %v, want %v", entry.Message, key, entry.Fields[key], value)
+			}
+		}
+	}
+	if found != 2 {
+		t.Errorf("found %d handler log entries, want 2", found)
+	}
+}
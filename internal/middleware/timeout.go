@@ -0,0 +1,281 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+	apperrors "skyhawk-security-microservice/internal/errors"
+)
+
+// TimeoutConfig sets the deadline of each request
+type TimeoutConfig struct {
+	// Default applies to routes without an override; 0 disables the deadline
+	Default time.Duration
+	// Routes overrides the deadline of a route, keyed by "METHOD /route/:param" or by
+	// "/route/:param" for every method. A zero duration disables the deadline.
+	Routes map[string]time.Duration
+}
+
+// timeoutFor returns the deadline of a request to route
+func (tc TimeoutConfig) timeoutFor(method, route string) time.Duration {
+	if timeout, ok := tc.Routes[method+" "+route]; ok {
+		return timeout
+	}
+	if timeout, ok := tc.Routes[route]; ok {
+		return timeout
+	}
+	return tc.Default
+}
+
+// ParseRouteTimeouts parses comma separated route=duration overrides such as
+// "GET /api/v1/events/export=2m,/api/v1/events/timeseries=10s"
+func ParseRouteTimeouts(spec string) (map[string]time.Duration, error) {
+	routes := make(map[string]time.Duration)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		i := strings.LastIndex(entry, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid route timeout %q: expected route=duration", entry)
+		}
+		route := strings.Join(strings.Fields(entry[:i]), " ")
+		timeout, err := time.ParseDuration(strings.TrimSpace(entry[i+1:]))
+		if err != nil || timeout < 0 {
+			return nil, fmt.Errorf("invalid route timeout %q: bad duration", entry)
+		}
+		if route == "" {
+			return nil, fmt.Errorf("invalid route timeout %q: missing route", entry)
+		}
+		routes[route] = timeout
+	}
+	return routes, nil
+}
+
+// timeoutRetryAfter is the Retry-After, in seconds, of a request answered because it timed out
+const timeoutRetryAfter = "5"
+
+// TimeoutMiddleware gives every request context a deadline of d, so context-aware repository
+// calls are cancelled once it passes. A handler that has not started its response by then is
+// answered with 503, a Retry-After header and a TIMEOUT error right away, even when it ignores
+// its context: the response is complete once written, and the connection is closed when the
+// handler eventually returns. Whatever the handler writes afterwards is discarded. Responses
+// already being written, such as streamed exports, are left alone. A d of 0 disables the deadline.
+func TimeoutMiddleware(d time.Duration) gin.HandlerFunc {
+	return RouteTimeoutMiddleware(TimeoutConfig{Default: d})
+}
+
+// RouteTimeoutMiddleware is TimeoutMiddleware with the deadline of each route taken from config
+func RouteTimeoutMiddleware(config TimeoutConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := config.timeoutFor(c.Request.Method, c.FullPath())
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		appErr := apperrors.NewTimeoutError("Request timed out",
+			fmt.Sprintf("the request did not complete within %s", timeout))
+		requestID := c.GetString("request_id")
+		writer := newTimeoutWriter(c.Writer)
+		c.Writer = writer
+
+		// Answer from a watcher once the deadline passes, while the handler may still be running
+		handlerDone := make(chan struct{})
+		watcherDone := make(chan struct{})
+		go func() {
+			defer close(watcherDone)
+			select {
+			case <-ctx.Done():
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					writer.timeout(appErr, requestID)
+				}
+			case <-handlerDone:
+			}
+		}()
+
+		c.Next()
+		close(handlerDone)
+		<-watcherDone
+
+		// A handler that returned at the deadline without answering is answered here instead
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			writer.timeout(appErr, requestID)
+		}
+		c.Writer = writer.ResponseWriter
+
+		if writer.finish() {
+			c.Error(appErr)
+			c.Abort()
+		}
+	}
+}
+
+// timeoutWriter lets the middleware answer a request that ran past its deadline while the
+// handler is still running. The handler's status and headers are held back until it writes,
+// so the timeout response never mixes with them, and writes are serialized with the timeout.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	mu     sync.Mutex
+	header http.Header
+	status int
+	// started is set once the handler's status and headers were sent
+	started  bool
+	timedOut bool
+}
+
+// newTimeoutWriter wraps w, starting with a copy of the headers set before the handler ran
+func newTimeoutWriter(w gin.ResponseWriter) *timeoutWriter {
+	return &timeoutWriter{
+		ResponseWriter: w,
+		header:         w.Header().Clone(),
+		status:         w.Status(),
+	}
+}
+
+// timeout answers with appErr unless the handler already started its response
+func (w *timeoutWriter) timeout(appErr *apperrors.AppError, requestID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started || w.ResponseWriter.Written() {
+		return
+	}
+	w.timedOut = true
+
+	// With a Content-Length the client has the whole response once it is flushed, rather than
+	// waiting for the handler to return and end a chunked body. The connection is closed
+	// afterwards, since it stays busy until the handler returns.
+	body, _ := json.Marshal(api.NewErrorResponse(appErr, requestID))
+	header := w.ResponseWriter.Header()
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	header.Set("Connection", "close")
+	header.Set("Retry-After", timeoutRetryAfter)
+	w.ResponseWriter.WriteHeader(appErr.StatusCode)
+	w.ResponseWriter.Write(body)
+	w.ResponseWriter.Flush()
+}
+
+// finish hands the handler's status and headers to the wrapped writer when the handler wrote
+// nothing, so they are sent once the request completes. It reports whether the request timed out.
+func (w *timeoutWriter) finish() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.timedOut {
+		w.start()
+	}
+	return w.timedOut
+}
+
+// start sends the handler's status and headers to the wrapped writer; w.mu must be held
+func (w *timeoutWriter) start() {
+	if w.started {
+		return
+	}
+	w.started = true
+	header := w.ResponseWriter.Header()
+	for key, values := range w.header {
+		header[key] = values
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+}
+
+// Header returns the handler's headers
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+// WriteHeader records the handler's status
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if code > 0 && !w.started {
+		w.status = code
+	}
+}
+
+// WriteHeaderNow sends the status unless the request timed out
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.timedOut {
+		w.start()
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// Write writes the body unless the request timed out
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return len(data), nil
+	}
+	w.start()
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString writes the body unless the request timed out
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut {
+		return len(s), nil
+	}
+	w.start()
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Status returns the handler's status, or the timeout status once the request timed out
+func (w *timeoutWriter) Status() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.timedOut || w.started {
+		return w.ResponseWriter.Status()
+	}
+	return w.status
+}
+
+// Size returns the number of body bytes written
+func (w *timeoutWriter) Size() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.ResponseWriter.Size()
+}
+
+// Written reports whether the response was started, counting the timeout response
+func (w *timeoutWriter) Written() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.timedOut || w.ResponseWriter.Written()
+}
+
+// Unwrap returns the wrapped writer, so http.ResponseController reaches the connection
+func (w *timeoutWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Flush flushes the response unless the request timed out
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.timedOut {
+		w.start()
+		w.ResponseWriter.Flush()
+	}
+}
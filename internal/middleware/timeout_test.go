@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/api"
+)
+
+// newTimeoutServer serves GET /slow, which ignores its context and blocks until the test ends,
+// and GET /fast, behind TimeoutMiddleware with the given deadline
+func newTimeoutServer(t *testing.T, timeout time.Duration) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	release := make(chan struct{})
+	router := gin.New()
+	router.Use(TimeoutMiddleware(timeout))
+	router.GET("/slow", func(c *gin.Context) {
+		<-release
+		c.JSON(http.StatusOK, gin.H{"late": true})
+	})
+	router.GET("/fast", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	})
+
+	server := httptest.NewServer(router)
+	// Cleanups run last first: release the handler, then close the server
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+	return server
+}
+
+func TestTimeoutMiddlewareAnswersWithoutWaitingForHandler(t *testing.T) {
+	server := newTimeoutServer(t, 50*time.Millisecond)
+	client := &http.Client{Timeout: 2 * time.Second}
+
+	start := time.Now()
+	resp, err := client.Get(server.URL + "/slow")
+	if err != nil {
+		t.Fatalf("GET /slow: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("response took %s, want it right after the deadline", elapsed)
+	}
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", resp.StatusCode)
+	}
+	if resp.Header.Get("Retry-After") != timeoutRetryAfter {
+		t.Errorf("Retry-After = %q, want %q", resp.Header.Get("Retry-After"), timeoutRetryAfter)
+	}
+	if resp.ContentLength != int64(len(body)) {
+		t.Errorf("Content-Length = %d, body is %d bytes", resp.ContentLength, len(body))
+	}
+
+	var errResp api.ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		t.Fatalf("body %q is not an error response: %v", body, err)
+	}
+	if errResp.Type != "TIMEOUT" || errResp.Code != http.StatusServiceUnavailable {
+		t.Errorf("body = %s, want a TIMEOUT error", body)
+	}
+}
+
+func TestTimeoutMiddlewareLeavesFastHandlerAlone(t *testing.T) {
+	server := newTimeoutServer(t, time.Second)
+
+	resp, err := http.Get(server.URL + "/fast")
+	if err != nil {
+		t.Fatalf("GET /fast: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if string(body) != `{"ok":true}` {
+		t.Errorf("body = %s, want the handler's response", body)
+	}
+}
+
+func TestRouteTimeoutMiddlewareAppliesRouteDeadlines(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RouteTimeoutMiddleware(TimeoutConfig{
+		Default: 30 * time.Second,
+		Routes: map[string]time.Duration{
+			"/health":             5 * time.Second,
+			"POST /events/replay": 0,
+		},
+	}))
+	deadline := func(c *gin.Context) {
+		remaining := "none"
+		if deadline, ok := c.Request.Context().Deadline(); ok {
+			remaining = time.Until(deadline).Round(time.Second).String()
+		}
+		c.String(http.StatusOK, remaining)
+	}
+	router.GET("/health", deadline)
+	router.GET("/events/replay", deadline)
+	router.POST("/events/replay", deadline)
+
+	for _, tt := range []struct {
+		method, path, want string
+	}{
+		{http.MethodGet, "/health", "5s"},
+		{http.MethodPost, "/events/replay", "none"},
+		{http.MethodGet, "/events/replay", "30s"},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if got := rec.Body.String(); got != tt.want {
+			t.Errorf("%s %s deadline = %s, want %s", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestParseRouteTimeouts(t *testing.T) {
+	routes, err := ParseRouteTimeouts("GET /api/v1/events/export=2m, /health = 2s")
+	if err != nil {
+		t.Fatalf("ParseRouteTimeouts: %v", err)
+	}
+	if routes["GET /api/v1/events/export"] != 2*time.Minute || routes["/health"] != 2*time.Second {
+		t.Errorf("routes = %v", routes)
+	}
+
+	if _, err := ParseRouteTimeouts("/health=soon"); err == nil {
+		t.Error("ParseRouteTimeouts accepted an invalid duration")
+	}
+}
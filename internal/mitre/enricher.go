@@ -0,0 +1,104 @@
+package mitre
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// DefaultTechniqueMapping maps common event types to the ATT&CK techniques they indicate
+var DefaultTechniqueMapping = map[string][]string{
+	"login":                {"T1078"},
+	"login_failed":         {"T1110"},
+	"failed_login":         {"T1110"},
+	"brute_force":          {"T1110"},
+	"login_brute_force":    {"T1110", "T1110.001"},
+	"password_spray":       {"T1110.003"},
+	"credential_stuffing":  {"T1110.004"},
+	"credential_dump":      {"T1003"},
+	"data_access":          {"T1005"},
+	"file_access":          {"T1083"},
+	"port_scan":            {"T1046"},
+	"phishing":             {"T1566"},
+	"malware":              {"T1204"},
+	"privilege_escalation": {"T1548"},
+	"account_created":      {"T1136"},
+	"data_exfiltration":    {"T1048"},
+	"ransomware":           {"T1486"},
+	"ddos":                 {"T1498"},
+}
+
+// ATTACKEnricher tags events with the ATT&CK techniques mapped to their event type
+type ATTACKEnricher struct {
+	mapping map[string][]string
+}
+
+// NewATTACKEnricher creates an enricher for the given event type mapping. Every mapped
+// technique must exist in the lookup.
+func NewATTACKEnricher(lookup *ATTACKLookup, mapping map[string][]string) (*ATTACKEnricher, error) {
+	normalized := make(map[string][]string, len(mapping))
+	for eventType, ids := range mapping {
+		techniques := make([]string, 0, len(ids))
+		for _, id := range ids {
+			technique, err := lookup.LookupTechnique(id)
+			if err != nil {
+				return nil, fmt.Errorf("event type %s: %v", eventType, err)
+			}
+			techniques = append(techniques, technique.ID)
+		}
+		normalized[strings.ToLower(eventType)] = techniques
+	}
+
+	return &ATTACKEnricher{mapping: normalized}, nil
+}
+
+// LoadTechniqueMapping reads a YAML map of event types to technique IDs, e.g.
+//
+//	login_failed: [T1110]
+//	port_scan: [T1046]
+func LoadTechniqueMapping(path string) (map[string][]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ATT&CK mapping: %v", err)
+	}
+
+	var mapping map[string][]string
+	if err := yaml.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse ATT&CK mapping: %v", err)
+	}
+
+	return mapping, nil
+}
+
+// Name identifies the enricher
+func (e *ATTACKEnricher) Name() string {
+	return "mitre_attack"
+}
+
+// Techniques returns the technique IDs mapped to an event type
+func (e *ATTACKEnricher) Techniques(eventType string) []string {
+	return e.mapping[strings.ToLower(eventType)]
+}
+
+// Enrich adds the mapped techniques to the event, keeping any it already has
+func (e *ATTACKEnricher) Enrich(event *models.Event) error {
+	for _, id := range e.Techniques(event.EventType) {
+		if !containsTechnique(event.ATTACKTechniques, id) {
+			event.ATTACKTechniques = append(event.ATTACKTechniques, id)
+		}
+	}
+	return nil
+}
+
+// containsTechnique reports whether ids contains id
+func containsTechnique(ids []string, id string) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
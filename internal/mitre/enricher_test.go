@@ -0,0 +1,60 @@
+package mitre
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+func TestDefaultMappingTechniquesExist(t *testing.T) {
+	if _, err := NewATTACKEnricher(newTestLookup(t), DefaultTechniqueMapping); err != nil {
+		t.Errorf("default mapping references a technique missing from the embedded bundle: %v", err)
+	}
+}
+
+func TestEnricherMapsBruteForceToT1110(t *testing.T) {
+	enricher, err := NewATTACKEnricher(newTestLookup(t), DefaultTechniqueMapping)
+	if err != nil {
+		t.Fatalf("NewATTACKEnricher: %v", err)
+	}
+
+	event := &models.Event{EventType: "Login_Brute_Force", ATTACKTechniques: []string{"T1110"}}
+	if err := enricher.Enrich(event); err != nil {
+		t.Fatalf("Enrich: %v", err)
+	}
+	if want := []string{"T1110", "T1110.001"}; !reflect.DeepEqual(event.ATTACKTechniques, want) {
+		t.Errorf("techniques = %v, want %v without duplicates", event.ATTACKTechniques, want)
+	}
+
+	unmapped := &models.Event{EventType: "heartbeat"}
+	enricher.Enrich(unmapped)
+	if len(unmapped.ATTACKTechniques) != 0 {
+		t.Errorf("unmapped event tagged with %v", unmapped.ATTACKTechniques)
+	}
+}
+
+func TestNewATTACKEnricherRejectsUnknownTechnique(t *testing.T) {
+	_, err := NewATTACKEnricher(newTestLookup(t), map[string][]string{"login": {"T9999"}})
+	if err == nil {
+		t.Error("NewATTACKEnricher accepted an unknown technique")
+	}
+}
+
+func TestLoadTechniqueMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "attack.yaml")
+	if err := os.WriteFile(path, []byte("login_failed: [T1110]\nport_scan: [T1046]\n"), 0o600); err != nil {
+		t.Fatalf("write mapping: %v", err)
+	}
+
+	mapping, err := LoadTechniqueMapping(path)
+	if err != nil {
+		t.Fatalf("LoadTechniqueMapping: %v", err)
+	}
+	want := map[string][]string{"login_failed": {"T1110"}, "port_scan": {"T1046"}}
+	if !reflect.DeepEqual(mapping, want) {
+		t.Errorf("mapping = %v, want %v", mapping, want)
+	}
+}
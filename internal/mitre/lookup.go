@@ -0,0 +1,132 @@
+package mitre
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// embeddedBundle is a subset of the MITRE ATT&CK Enterprise STIX 2.1 bundle covering
+// the techniques referenced by the default event type mapping
+//
+//go:embed data/enterprise-attack.json
+var embeddedBundle []byte
+
+// Technique describes a MITRE ATT&CK technique or sub-technique
+type Technique struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Tactics     []string `json:"tactics"`
+	Description string   `json:"description"`
+	URL         string   `json:"url,omitempty"`
+}
+
+// ATTACKLookup indexes ATT&CK techniques by ID
+type ATTACKLookup struct {
+	techniques map[string]*Technique
+}
+
+// stixBundle is the subset of a STIX 2.x bundle read by the lookup
+type stixBundle struct {
+	Objects []stixObject `json:"objects"`
+}
+
+type stixObject struct {
+	Type            string `json:"type"`
+	Name            string `json:"name"`
+	Description     string `json:"description"`
+	Revoked         bool   `json:"revoked"`
+	Deprecated      bool   `json:"x_mitre_deprecated"`
+	KillChainPhases []struct {
+		KillChainName string `json:"kill_chain_name"`
+		PhaseName     string `json:"phase_name"`
+	} `json:"kill_chain_phases"`
+	ExternalReferences []struct {
+		SourceName string `json:"source_name"`
+		ExternalID string `json:"external_id"`
+		URL        string `json:"url"`
+	} `json:"external_references"`
+}
+
+// NewATTACKLookup loads the embedded ATT&CK bundle
+func NewATTACKLookup() (*ATTACKLookup, error) {
+	return ParseATTACKBundle(embeddedBundle)
+}
+
+// LoadATTACKLookup loads an ATT&CK STIX bundle from a file, e.g. the full enterprise-attack.json
+func LoadATTACKLookup(path string) (*ATTACKLookup, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ATT&CK bundle: %v", err)
+	}
+	return ParseATTACKBundle(data)
+}
+
+// ParseATTACKBundle indexes the attack-pattern objects of a STIX bundle, skipping
+// revoked and deprecated techniques
+func ParseATTACKBundle(data []byte) (*ATTACKLookup, error) {
+	var bundle stixBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse ATT&CK bundle: %v", err)
+	}
+
+	lookup := &ATTACKLookup{techniques: make(map[string]*Technique)}
+	for _, obj := range bundle.Objects {
+		if obj.Type != "attack-pattern" || obj.Revoked || obj.Deprecated {
+			continue
+		}
+
+		technique := &Technique{
+			Name:        obj.Name,
+			Description: obj.Description,
+			Tactics:     []string{},
+		}
+		for _, ref := range obj.ExternalReferences {
+			if ref.SourceName == "mitre-attack" {
+				technique.ID = ref.ExternalID
+				technique.URL = ref.URL
+				break
+			}
+		}
+		if technique.ID == "" {
+			continue
+		}
+		for _, phase := range obj.KillChainPhases {
+			if phase.KillChainName == "mitre-attack" {
+				technique.Tactics = append(technique.Tactics, phase.PhaseName)
+			}
+		}
+
+		lookup.techniques[technique.ID] = technique
+	}
+
+	if len(lookup.techniques) == 0 {
+		return nil, fmt.Errorf("ATT&CK bundle contains no techniques")
+	}
+
+	return lookup, nil
+}
+
+// LookupTechnique returns the technique with the given ID, e.g. "T1110" or "T1110.001"
+func (l *ATTACKLookup) LookupTechnique(id string) (*Technique, error) {
+	technique, ok := l.techniques[strings.ToUpper(strings.TrimSpace(id))]
+	if !ok {
+		return nil, fmt.Errorf("technique not found: %s", id)
+	}
+	return technique, nil
+}
+
+// Techniques returns every loaded technique ordered by ID
+func (l *ATTACKLookup) Techniques() []*Technique {
+	techniques := make([]*Technique, 0, len(l.techniques))
+	for _, technique := range l.techniques {
+		techniques = append(techniques, technique)
+	}
+	sort.Slice(techniques, func(i, j int) bool {
+		return techniques[i].ID < techniques[j].ID
+	})
+	return techniques
+}
@@ -0,0 +1,59 @@
+package mitre
+
+import (
+	"reflect"
+	"testing"
+)
+
+func newTestLookup(t *testing.T) *ATTACKLookup {
+	t.Helper()
+	lookup, err := NewATTACKLookup()
+	if err != nil {
+		t.Fatalf("NewATTACKLookup: %v", err)
+	}
+	return lookup
+}
+
+func TestLookupTechnique(t *testing.T) {
+	lookup := newTestLookup(t)
+
+	technique, err := lookup.LookupTechnique(" t1110 ")
+	if err != nil {
+		t.Fatalf("LookupTechnique: %v", err)
+	}
+	if technique.ID != "T1110" || technique.Name != "Brute Force" {
+		t.Errorf("technique = %+v, want T1110 Brute Force", technique)
+	}
+	if !reflect.DeepEqual(technique.Tactics, []string{"credential-access"}) {
+		t.Errorf("tactics = %v, want [credential-access]", technique.Tactics)
+	}
+	if technique.Description == "" || technique.URL != "https://attack.mitre.org/techniques/T1110/" {
+		t.Errorf("technique = %+v, want its description and URL", technique)
+	}
+
+	if _, err := lookup.LookupTechnique("T9999"); err == nil {
+		t.Error("LookupTechnique returned nil error for an unknown technique")
+	}
+}
+
+func TestParseATTACKBundleSkipsRevokedAndDeprecated(t *testing.T) {
+	bundle := `{"objects": [
+		{"type": "attack-pattern", "name": "Kept", "external_references": [{"source_name": "mitre-attack", "external_id": "T0001"}]},
+		{"type": "attack-pattern", "name": "Revoked", "revoked": true, "external_references": [{"source_name": "mitre-attack", "external_id": "T0002"}]},
+		{"type": "attack-pattern", "name": "Deprecated", "x_mitre_deprecated": true, "external_references": [{"source_name": "mitre-attack", "external_id": "T0003"}]},
+		{"type": "intrusion-set", "name": "Group", "external_references": [{"source_name": "mitre-attack", "external_id": "G0001"}]}
+	]}`
+
+	lookup, err := ParseATTACKBundle([]byte(bundle))
+	if err != nil {
+		t.Fatalf("ParseATTACKBundle: %v", err)
+	}
+	techniques := lookup.Techniques()
+	if len(techniques) != 1 || techniques[0].ID != "T0001" {
+		t.Errorf("techniques = %+v, want only T0001", techniques)
+	}
+
+	if _, err := ParseATTACKBundle([]byte(`{"objects": []}`)); err == nil {
+		t.Error("ParseATTACKBundle accepted a bundle without techniques")
+	}
+}
@@ -1,22 +1,118 @@
 package models
 
 import (
+	"bytes"
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
+
+	"skyhawk-security-microservice/internal/crypto"
+	"skyhawk-security-microservice/internal/pagination"
 )
 
 // Event represents a security event
 type Event struct {
-	ID          string    `json:"id" db:"id"`
-	EventID     string    `json:"event_id" db:"event_id"`
-	EventType   string    `json:"event_type" db:"event_type"`
-	Severity    string    `json:"severity" db:"severity"`
-	Source      string    `json:"source" db:"source"`
-	Description string    `json:"description" db:"description"`
-	EventData   EventData `json:"event_data" db:"event_data"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID            string    `json:"id" db:"id"`
+	EventID       string    `json:"event_id" db:"event_id"`
+	EventType     string    `json:"event_type" db:"event_type"`
+	Severity      string    `json:"severity" db:"severity"`
+	Source        string    `json:"source" db:"source"`
+	Description   string    `json:"description" db:"description"`
+	EventData     EventData `json:"event_data" db:"event_data"`
+	CorrelationID string    `json:"correlation_id" db:"correlation_id"`
+	// TenantID is the tenant that owns the event, empty for single-tenant deployments
+	TenantID string `json:"tenant_id,omitempty" db:"tenant_id"`
+	// ATTACKTechniques holds MITRE ATT&CK technique IDs such as "T1078" or "T1110.001"
+	ATTACKTechniques []string `json:"attack_techniques" db:"attack_techniques"`
+	// Status is open, acknowledged or resolved; see CanTransitionStatus
+	Status         string     `json:"status" db:"status"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at" db:"acknowledged_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at" db:"updated_at"`
+	// AcknowledgedBy and AcknowledgementNote are set by bulk status updates
+	AcknowledgedBy      string `json:"acknowledged_by,omitempty" db:"acknowledged_by"`
+	AcknowledgementNote string `json:"acknowledgement_note,omitempty" db:"acknowledgement_note"`
+}
+
+// Event statuses
+const (
+	EventStatusOpen         = "open"
+	EventStatusAcknowledged = "acknowledged"
+	EventStatusResolved     = "resolved"
+)
+
+// EventStatuses lists the event statuses in lifecycle order
+var EventStatuses = []string{EventStatusOpen, EventStatusAcknowledged, EventStatusResolved}
+
+// IsEventStatus reports whether status is a known event status
+func IsEventStatus(status string) bool {
+	return status == EventStatusOpen || status == EventStatusAcknowledged || status == EventStatusResolved
+}
+
+// CanTransitionStatus reports whether an event may move from one status to another. Open
+// events can be acknowledged, and acknowledging again keeps the original acknowledgement.
+// Open and acknowledged events can be resolved, and only resolved events can be reopened.
+func CanTransitionStatus(from, to string) bool {
+	switch to {
+	case EventStatusAcknowledged:
+		return from == EventStatusOpen || from == EventStatusAcknowledged
+	case EventStatusResolved:
+		return from == EventStatusOpen || from == EventStatusAcknowledged
+	case EventStatusOpen:
+		return from == EventStatusResolved
+	}
+	return false
+}
+
+// EventSummary is the compact form of an event returned by list endpoints. It leaves out
+// event_data, which can be large.
+type EventSummary struct {
+	// ID is only used to build pagination cursors
+	ID             string     `json:"-"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Severity       string     `json:"severity"`
+	Source         string     `json:"source"`
+	Status         string     `json:"status"`
+	CreatedAt      time.Time  `json:"created_at"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	// ChildrenCount is the number of other events correlated to this one
+	ChildrenCount int `json:"children_count"`
+}
+
+// Severity levels accepted for security events
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// Severities lists the canonical severities from least to most severe
+var Severities = []string{SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical}
+
+// SeverityLevel returns the numeric rank of a severity (higher is more severe),
+// or 0 if the severity is not recognized
+func SeverityLevel(severity string) int {
+	switch strings.ToLower(severity) {
+	case SeverityLow:
+		return 1
+	case SeverityMedium:
+		return 2
+	case SeverityHigh:
+		return 3
+	case SeverityCritical:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// GenerateEventID generates a unique event ID
+func GenerateEventID() string {
+	return "event-" + time.Now().Format("20060102150405") + "-" + time.Now().Format("000000000")
 }
 
 // EventData represents the JSON data for an event
@@ -30,28 +126,254 @@ func (e EventData) Value() (driver.Value, error) {
 	return json.Marshal(e)
 }
 
-// Scan implements the sql.Scanner interface for JSONB
+// Scan implements the sql.Scanner interface for JSONB. Drivers may return the
+// document as []byte or string; any other type is reported as an error.
 func (e *EventData) Scan(value interface{}) error {
 	if value == nil {
 		*e = nil
 		return nil
 	}
 
-	bytes, ok := value.([]byte)
-	if !ok {
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into EventData", value)
+	}
+
+	// Decode into a fresh map; unmarshaling into a reused one would merge in stale keys
+	var data EventData
+	if err := json.Unmarshal(bytes, &data); err != nil {
+		return fmt.Errorf("invalid event_data JSON: %w", err)
+	}
+	*e = data
+	return nil
+}
+
+// UnmarshalJSON accepts a JSON object, or a string holding a JSON object as sent by producers
+// that encode event_data twice, and decodes either into a map. null leaves the data nil; any
+// other value is rejected with an EventDataError.
+func (e *EventData) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		*e = nil
 		return nil
 	}
 
-	return json.Unmarshal(bytes, e)
+	if len(data) > 0 && data[0] == '"' {
+		var encoded string
+		if err := json.Unmarshal(data, &encoded); err != nil {
+			return err
+		}
+		inner := bytes.TrimSpace([]byte(encoded))
+		if !json.Valid(inner) {
+			return &EventDataError{Got: "string"}
+		}
+		if kind := jsonKind(inner); kind != "object" {
+			return &EventDataError{Got: "string holding " + kind}
+		}
+		data = inner
+	} else if kind := jsonKind(data); kind != "object" {
+		return &EventDataError{Got: kind}
+	}
+
+	// Decode into a fresh map; unmarshaling into a reused one would merge in stale keys
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*e = decoded
+	return nil
+}
+
+// EventDataError reports event_data that is neither a JSON object nor a string holding one
+type EventDataError struct {
+	// Got describes the value received, such as "array" or "string holding number"
+	Got string
+}
+
+func (e *EventDataError) Error() string {
+	return "event_data " + e.Message()
+}
+
+// Message describes the problem without naming the field
+func (e *EventDataError) Message() string {
+	return "must be a JSON object or a string holding one, got " + e.Got
+}
+
+// jsonKind names the type of a valid JSON value from its first byte
+func jsonKind(data []byte) string {
+	if len(data) == 0 {
+		return "nothing"
+	}
+	switch data[0] {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return "null"
+	default:
+		return "number"
+	}
+}
+
+// Encrypt replaces the top-level fields named in fields with their encrypted JSON encoding,
+// bound to the field name so a ciphertext cannot be moved to another field. Missing fields are
+// left out. Values are always encrypted, so a plaintext that looks like a ciphertext is kept.
+func (e EventData) Encrypt(fields []string, enc *crypto.FieldEncrypter) error {
+	for _, field := range fields {
+		value, ok := e[field]
+		if !ok {
+			continue
+		}
+
+		plaintext, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", field, err)
+		}
+		ciphertext, err := enc.Encrypt(plaintext, []byte(field))
+		if err != nil {
+			return fmt.Errorf("failed to encrypt %s: %w", field, err)
+		}
+		e[field] = ciphertext
+	}
+	return nil
+}
+
+// Decrypt restores the top-level fields named in fields that were encrypted by Encrypt.
+// Fields stored before encryption was enabled are left as they are.
+func (e EventData) Decrypt(fields []string, enc *crypto.FieldEncrypter) error {
+	for _, field := range fields {
+		ciphertext, ok := e[field].(string)
+		if !ok || !crypto.IsEncrypted(ciphertext) {
+			continue
+		}
+
+		plaintext, err := enc.Decrypt(ciphertext, []byte(field))
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", field, err)
+		}
+		var value interface{}
+		if err := json.Unmarshal(plaintext, &value); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", field, err)
+		}
+		e[field] = value
+	}
+	return nil
+}
+
+// CiphertextFields returns the top-level fields named in fields whose value is a string that
+// looks like a ciphertext. Such values cannot be told apart from encrypted ones once stored.
+func (e EventData) CiphertextFields(fields []string) []string {
+	var found []string
+	for _, field := range fields {
+		if s, ok := e[field].(string); ok && crypto.IsEncrypted(s) {
+			found = append(found, field)
+		}
+	}
+	return found
+}
+
+// MergeEventData deep-merges patch into data following JSON Merge Patch (RFC 7386): nested
+// objects are merged key by key, a null value deletes the key and any other value replaces
+// it. data is not modified.
+func MergeEventData(data, patch EventData) EventData {
+	return EventData(mergeObjects(data, patch))
+}
+
+// mergeObjects applies a merge patch object to a copy of target
+func mergeObjects(target, patch map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(target)+len(patch))
+	for key, value := range target {
+		merged[key] = value
+	}
+
+	for key, value := range patch {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		patchObject, ok := value.(map[string]interface{})
+		if !ok {
+			merged[key] = value
+			continue
+		}
+		targetObject, _ := merged[key].(map[string]interface{})
+		merged[key] = mergeObjects(targetObject, patchObject)
+	}
+
+	return merged
 }
 
+// DefaultMaxEventDataSize is the default limit on the serialized size of event_data in bytes
+const DefaultMaxEventDataSize = 1 << 20
+
+// DefaultMaxDescriptionLength is the default limit on the length of a description in characters
+const DefaultMaxDescriptionLength = 4096
+
 // CreateEventRequest represents the request to create an event
 type CreateEventRequest struct {
-	EventType   string    `json:"event_type" binding:"required"`
-	Severity    string    `json:"severity" binding:"required"`
-	Source      string    `json:"source" binding:"required"`
-	Description string    `json:"description"`
-	EventData   EventData `json:"event_data"`
+	EventType string `json:"event_type" binding:"required"`
+	// Severity and Source are required unless EventDefaults provides them
+	Severity      string    `json:"severity"`
+	Source        string    `json:"source"`
+	Description   string    `json:"description"`
+	EventData     EventData `json:"event_data"`
+	CorrelationID string    `json:"correlation_id"`
+	TenantID      string    `json:"tenant_id"`
+	// ATTACKTechniques are added to the techniques derived from the event type
+	ATTACKTechniques []string `json:"attack_techniques" binding:"omitempty,dive,required"`
+}
+
+// EventDefaults fills in the severity and source of events created without them
+type EventDefaults struct {
+	Severity string
+	Source   string
+	// Strict rejects events without a severity or source even when defaults are set
+	Strict bool
+}
+
+// Apply fills a missing severity or source with its default. A value sent in the request
+// always wins over the default; fields that remain empty, or every empty field in strict
+// mode, are returned by JSON name.
+func (d EventDefaults) Apply(req *CreateEventRequest) (missing []string) {
+	apply := func(value *string, name, fallback string) {
+		if strings.TrimSpace(*value) != "" {
+			return
+		}
+		if d.Strict || fallback == "" {
+			missing = append(missing, name)
+			return
+		}
+		*value = fallback
+	}
+
+	apply(&req.Severity, "severity", d.Severity)
+	apply(&req.Source, "source", d.Source)
+	return missing
+}
+
+// defaultFor returns the default applied to a CreateEventRequest field, or "" when the
+// field is required
+func (d EventDefaults) defaultFor(name string) string {
+	if d.Strict {
+		return ""
+	}
+	switch name {
+	case "severity":
+		return d.Severity
+	case "source":
+		return d.Source
+	}
+	return ""
 }
 
 // UpdateEventRequest represents the request to update an event
@@ -61,4 +383,145 @@ type UpdateEventRequest struct {
 	Source      string    `json:"source"`
 	Description string    `json:"description"`
 	EventData   EventData `json:"event_data"`
-} 
\ No newline at end of file
+}
+
+// PatchEventRequest represents the request to merge keys into an event's data. A null
+// value removes the key.
+type PatchEventRequest struct {
+	EventData EventData `json:"event_data" binding:"required"`
+}
+
+// MaxDeleteBatchSize is the maximum number of events a batch delete may remove
+const MaxDeleteBatchSize = 1000
+
+// DeleteEventsRequest represents the request to delete a batch of events
+type DeleteEventsRequest struct {
+	EventIDs []string `json:"event_ids" binding:"required,min=1,max=1000,dive,required"`
+}
+
+// DeleteEventsResult reports the outcome of a batch delete
+type DeleteEventsResult struct {
+	Deleted  int      `json:"deleted"`
+	NotFound []string `json:"not_found"`
+}
+
+// MaxStatusBatchSize is the maximum number of events a bulk status update may change
+const MaxStatusBatchSize = 200
+
+// BulkStatusRequest represents the request to change the status of a batch of events
+type BulkStatusRequest struct {
+	EventIDs []string `json:"event_ids" binding:"required,min=1,max=200,dive,required"`
+	Status   string   `json:"status" binding:"required"`
+	Note     string   `json:"note"`
+}
+
+// BulkResult reports the outcome of a bulk status update for each requested event
+type BulkResult struct {
+	Status  string            `json:"status"`
+	Updated int               `json:"updated"`
+	Failed  int               `json:"failed"`
+	Results []BulkEventResult `json:"results"`
+}
+
+// BulkEventResult is the outcome of a bulk status update for one event
+type BulkEventResult struct {
+	EventID string `json:"event_id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// UpdatedIDs returns the IDs of the events that were updated, in request order
+func (r BulkResult) UpdatedIDs() []string {
+	ids := []string{}
+	for _, result := range r.Results {
+		if result.Success {
+			ids = append(ids, result.EventID)
+		}
+	}
+	return ids
+}
+
+// PaginationParams selects a page of a keyset paginated listing
+type PaginationParams struct {
+	Limit int
+	// After is the position of the last row of the previous page; nil starts at the first row
+	After *pagination.Cursor
+	Sort  EventSort
+}
+
+// Fields event listings can be sorted by
+const (
+	EventSortCreatedAt = "created_at"
+	EventSortSeverity  = "severity"
+	EventSortEventType = "event_type"
+)
+
+// EventSortFields lists the fields event listings can be sorted by
+var EventSortFields = []string{EventSortCreatedAt, EventSortSeverity, EventSortEventType}
+
+// EventSort orders an event listing by Field, breaking ties by creation time and ID in the same
+// direction. Severities sort by level rather than by name. The zero value lists newest first.
+type EventSort struct {
+	Field     string
+	Ascending bool
+}
+
+// Token identifies the order in pagination cursors. It is empty for created_at descending,
+// so cursors issued before listings could be sorted stay valid.
+func (s EventSort) Token() string {
+	field := s.Field
+	if field == "" {
+		field = EventSortCreatedAt
+	}
+	if field == EventSortCreatedAt && !s.Ascending {
+		return ""
+	}
+	if s.Ascending {
+		return field + ":asc"
+	}
+	return field + ":desc"
+}
+
+// EventFilter narrows an event listing; zero values match every event
+type EventFilter struct {
+	ATTACKTechnique string
+	// DataFields matches events whose event_data has each key set to the given value. Only
+	// IndexedEventDataFields can be filtered.
+	DataFields map[string]string
+}
+
+// IndexedEventDataFields lists the event_data keys with an index, which event listings can be
+// filtered by
+var IndexedEventDataFields = []string{"source_ip", "username", "session_id", "file_path"}
+
+// TimeSeriesBucket represents the number of events within a time bucket
+type TimeSeriesBucket struct {
+	Timestamp time.Time `json:"timestamp"`
+	Count     int64     `json:"count"`
+}
+
+// HourlyCount is the number of events created within an hour
+type HourlyCount struct {
+	Hour  time.Time `json:"hour"`
+	Count int64     `json:"count"`
+}
+
+// SourceCount is the number of events reported by a source
+type SourceCount struct {
+	Source string `json:"source"`
+	Count  int64  `json:"count"`
+}
+
+// FacetValue is a distinct value of an event field and the number of events with it
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// EventFacets lists the distinct values of the fields event listings are filtered by, most
+// common first
+type EventFacets struct {
+	EventTypes []FacetValue `json:"event_type"`
+	Severities []FacetValue `json:"severity"`
+	Sources    []FacetValue `json:"source"`
+}
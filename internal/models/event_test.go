@@ -0,0 +1,254 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"skyhawk-security-microservice/internal/crypto"
+)
+
+func newTestEncrypter(t *testing.T) *crypto.FieldEncrypter {
+	t.Helper()
+	enc, err := crypto.NewFieldEncrypterFromHex(strings.Repeat("01", 32))
+	if err != nil {
+		t.Fatalf("NewFieldEncrypterFromHex: %v", err)
+	}
+	return enc
+}
+
+func TestEventDataEncryptDecryptRoundTrip(t *testing.T) {
+	enc := newTestEncrypter(t)
+	fields := []string{"email", "ssn", "phone_number"}
+	original := EventData{
+		"email":    "alice@example.com",
+		"ssn":      map[string]interface{}{"last4": "6789"},
+		"username": "alice",
+	}
+
+	data := EventData{}
+	for key, value := range original {
+		data[key] = value
+	}
+	if err := data.Encrypt(fields, enc); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	for _, field := range []string{"email", "ssn"} {
+		if s, ok := data[field].(string); !ok || !crypto.IsEncrypted(s) {
+			t.Errorf("%s = %v, want a ciphertext", field, data[field])
+		}
+	}
+	if data["username"] != "alice" {
+		t.Errorf("unprotected field changed to %v", data["username"])
+	}
+	if _, ok := data["phone_number"]; ok {
+		t.Error("missing protected field was added")
+	}
+
+	if err := data.Decrypt(fields, enc); err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if !reflect.DeepEqual(data, original) {
+		t.Errorf("round trip = %v, want %v", data, original)
+	}
+}
+
+func TestEventDataDecryptRejectsSwappedFields(t *testing.T) {
+	enc := newTestEncrypter(t)
+	fields := []string{"email", "ssn"}
+
+	data := EventData{"email": "alice@example.com", "ssn": "123-45-6789"}
+	if err := data.Encrypt(fields, enc); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	data["email"], data["ssn"] = data["ssn"], data["email"]
+
+	if err := data.Decrypt(fields, enc); err == nil {
+		t.Error("Decrypt of swapped ciphertexts succeeded, want an error")
+	}
+}
+
+func TestEventDataEncryptDoesNotSkipCiphertextLookalikes(t *testing.T) {
+	enc := newTestEncrypter(t)
+	fields := []string{"email"}
+
+	data := EventData{"email": "enc:v1:garbage"}
+	if err := data.Encrypt(fields, enc); err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if data["email"] == "enc:v1:garbage" {
+		t.Fatal("value that looks like a ciphertext was stored unencrypted")
+	}
+	if err := data.Decrypt(fields, enc); err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if data["email"] != "enc:v1:garbage" {
+		t.Errorf("email = %v, want the original value", data["email"])
+	}
+}
+
+func TestEventDataCiphertextFields(t *testing.T) {
+	data := EventData{
+		"email":    "enc:v2:abc",
+		"ssn":      "enc:v1:abc",
+		"phone":    "555-0100",
+		"username": "enc:v2:abc",
+	}
+
+	got := data.CiphertextFields([]string{"email", "ssn", "phone", "missing"})
+	if want := []string{"email", "ssn"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("CiphertextFields = %v, want %v", got, want)
+	}
+}
+
+func TestEventDataScan(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  EventData
+	}{
+		{name: "bytes", value: []byte(`{"ip":"10.0.0.1","attempts":3}`), want: EventData{"ip": "10.0.0.1", "attempts": float64(3)}},
+		{name: "string", value: `{"ip":"10.0.0.1"}`, want: EventData{"ip": "10.0.0.1"}},
+		{name: "nil", value: nil, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := EventData{"stale": true}
+			if err := data.Scan(tt.value); err != nil {
+				t.Fatalf("Scan: %v", err)
+			}
+			if !reflect.DeepEqual(data, tt.want) {
+				t.Errorf("Scan produced %v, want %v", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventDataScanRejectsUnexpectedTypes(t *testing.T) {
+	var data EventData
+	if err := data.Scan(42); err == nil || !strings.Contains(err.Error(), "int") {
+		t.Errorf("Scan(42) returned %v, want an error naming the type", err)
+	}
+	if err := data.Scan([]byte("not json")); err == nil {
+		t.Error("Scan accepted invalid JSON")
+	}
+}
+
+func TestEventDataUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    EventData
+		wantGot string
+	}{
+		{name: "object", input: `{"ip":"203.0.113.7","attempts":5}`, want: EventData{"ip": "203.0.113.7", "attempts": float64(5)}},
+		{name: "stringified object", input: `"{\"ip\":\"203.0.113.7\",\"attempts\":5}"`, want: EventData{"ip": "203.0.113.7", "attempts": float64(5)}},
+		{name: "null", input: `null`, want: nil},
+		{name: "array", input: `[1,2]`, wantGot: "array"},
+		{name: "number", input: `42`, wantGot: "number"},
+		{name: "string holding array", input: `"[1,2]"`, wantGot: "string holding array"},
+		{name: "string holding no JSON", input: `"ip=203.0.113.7"`, wantGot: "string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data EventData
+			err := json.Unmarshal([]byte(tt.input), &data)
+			if tt.wantGot != "" {
+				var dataErr *EventDataError
+				if !errors.As(err, &dataErr) || dataErr.Got != tt.wantGot {
+					t.Errorf("Unmarshal(%s) returned %v, want an EventDataError for %s", tt.input, err, tt.wantGot)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal(%s): %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(data, tt.want) {
+				t.Errorf("Unmarshal(%s) = %v, want %v", tt.input, data, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventDataUnmarshalJSONReplacesExistingKeys(t *testing.T) {
+	data := EventData{"stale": true}
+	if err := json.Unmarshal([]byte(`{"ip":"203.0.113.7"}`), &data); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if _, ok := data["stale"]; ok {
+		t.Errorf("decoded event data %v kept a key of the previous value", data)
+	}
+}
+
+func TestCanTransitionStatus(t *testing.T) {
+	allowed := map[[2]string]bool{
+		{EventStatusOpen, EventStatusAcknowledged}:         true,
+		{EventStatusAcknowledged, EventStatusAcknowledged}: true,
+		{EventStatusOpen, EventStatusResolved}:             true,
+		{EventStatusAcknowledged, EventStatusResolved}:     true,
+		{EventStatusResolved, EventStatusOpen}:             true,
+	}
+
+	for _, from := range append(EventStatuses, "archived") {
+		for _, to := range append(EventStatuses, "archived") {
+			if got := CanTransitionStatus(from, to); got != allowed[[2]string{from, to}] {
+				t.Errorf("CanTransitionStatus(%s, %s) = %t", from, to, got)
+			}
+		}
+	}
+}
+
+func TestEventSortTokenDistinguishesEveryOrder(t *testing.T) {
+	tokens := map[string]EventSort{}
+	for _, field := range EventSortFields {
+		for _, ascending := range []bool{false, true} {
+			sort := EventSort{Field: field, Ascending: ascending}
+			token := sort.Token()
+			if other, ok := tokens[token]; ok {
+				t.Errorf("%+v and %+v share the token %q", sort, other, token)
+			}
+			tokens[token] = sort
+		}
+	}
+
+	// Cursors issued before listings could be sorted carry no token
+	if token := (EventSort{}).Token(); token != "" {
+		t.Errorf("default order token = %q, want empty", token)
+	}
+	if token := (EventSort{Field: EventSortCreatedAt}).Token(); token != "" {
+		t.Errorf("created_at descending token = %q, want empty like the default order", token)
+	}
+}
+
+func TestMergeEventDataFollowsMergePatch(t *testing.T) {
+	data := EventData{
+		"ip":     "203.0.113.7",
+		"tags":   []interface{}{"brute-force"},
+		"device": map[string]interface{}{"os": "linux", "agent": map[string]interface{}{"version": "1.2", "mode": "audit"}},
+		"user":   "alice",
+	}
+	patch := EventData{
+		"device": map[string]interface{}{"agent": map[string]interface{}{"version": "1.3", "mode": nil}, "host": "web-1"},
+		"user":   nil,
+		"tags":   []interface{}{"credential-stuffing"},
+		"ip":     map[string]interface{}{"v4": "203.0.113.7"},
+		"absent": nil,
+	}
+
+	want := EventData{
+		"ip":     map[string]interface{}{"v4": "203.0.113.7"},
+		"tags":   []interface{}{"credential-stuffing"},
+		"device": map[string]interface{}{"os": "linux", "host": "web-1", "agent": map[string]interface{}{"version": "1.3"}},
+	}
+	if got := MergeEventData(data, patch); !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeEventData = %v, want %v", got, want)
+	}
+	if data["user"] != "alice" || data["device"].(map[string]interface{})["agent"].(map[string]interface{})["mode"] != "audit" {
+		t.Errorf("MergeEventData modified its input: %v", data)
+	}
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect of the generated schemas
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// CreateEventSchema returns a JSON Schema for event creation payloads. Properties and
+// required fields are derived from the json and binding tags of CreateEventRequest, the
+// same tags request binding validates against; severity and source are required unless
+// defaults provides them. severities lists the accepted severity labels; nil means the
+// canonical severities.
+func CreateEventSchema(severities []string, defaults EventDefaults) map[string]interface{} {
+	if severities == nil {
+		severities = Severities
+	}
+
+	properties := make(map[string]interface{})
+	required := []string{}
+
+	t := reflect.TypeOf(CreateEventRequest{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		rules := strings.Split(field.Tag.Get("binding"), ",")
+		property := fieldSchema(field.Type, rules)
+		if hasRule(rules, "required") {
+			required = append(required, name)
+			if field.Type.Kind() == reflect.String {
+				property["minLength"] = 1
+			}
+		}
+		properties[name] = property
+	}
+
+	for _, name := range []string{"severity", "source"} {
+		property := properties[name].(map[string]interface{})
+		if value := defaults.defaultFor(name); value != "" {
+			property["default"] = value
+			continue
+		}
+		required = append(required, name)
+		property["minLength"] = 1
+	}
+
+	severity := properties["severity"].(map[string]interface{})
+	severity["enum"] = severities
+	severity["description"] = "Event severity; labels are matched case-insensitively and stored as one of " + strings.Join(Severities, ", ")
+
+	if techniques, ok := properties["attack_techniques"].(map[string]interface{}); ok {
+		techniques["description"] = "MITRE ATT&CK technique IDs added to those derived from the event type"
+	}
+	if eventData, ok := properties["event_data"].(map[string]interface{}); ok {
+		eventData["description"] = "Free-form event details; its serialized size is limited by the server"
+	}
+	if correlationID, ok := properties["correlation_id"].(map[string]interface{}); ok {
+		correlationID["description"] = "Groups related events; defaults to the generated event ID"
+	}
+
+	return map[string]interface{}{
+		"$schema":    JSONSchemaDraft,
+		"title":      "CreateEventRequest",
+		"type":       "object",
+		"properties": properties,
+		"required":   required,
+	}
+}
+
+// fieldSchema maps a Go field type onto a JSON Schema type
+func fieldSchema(t reflect.Type, rules []string) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object"}
+	case reflect.Slice:
+		items := fieldSchema(t.Elem(), nil)
+		// Rules after "dive" apply to the elements
+		for i, rule := range rules {
+			if rule == "dive" && hasRule(rules[i+1:], "required") && t.Elem().Kind() == reflect.String {
+				items["minLength"] = 1
+			}
+		}
+		return map[string]interface{}{"type": "array", "items": items}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Int32:
+		return map[string]interface{}{"type": "integer"}
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// hasRule reports whether a binding rule is set before any "dive"
+func hasRule(rules []string, name string) bool {
+	for _, rule := range rules {
+		if rule == "dive" {
+			return false
+		}
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// EventTransition is a status change of an event. FromStatus is nil for the creation of
+// the event.
+type EventTransition struct {
+	FromStatus *string   `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  string    `json:"changed_by,omitempty"`
+	Note       string    `json:"note,omitempty"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+// Outcomes of processing a queued event
+const (
+	ProcessingOutcomeProcessed = "processed"
+	ProcessingOutcomeFailed    = "failed"
+	ProcessingOutcomeTimedOut  = "timed_out"
+	ProcessingOutcomeCancelled = "cancelled"
+	// ProcessingOutcomeDuplicate is a redelivery skipped because the event was processed before
+	ProcessingOutcomeDuplicate = "duplicate"
+)
+
+// ProcessingLogEntry records one delivery of a queued event to a worker
+type ProcessingLogEntry struct {
+	EventID string `json:"-"`
+	Queue   string `json:"queue"`
+	// Attempt is 1 for the first delivery and grows with every retry
+	Attempt     int        `json:"attempt"`
+	PublishedAt *time.Time `json:"published_at"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  time.Time  `json:"finished_at"`
+	Outcome     string     `json:"outcome"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// EventTimeline is the lifecycle of an event: its status transitions and queue processing,
+// each in chronological order
+type EventTimeline struct {
+	EventID     string               `json:"event_id"`
+	CreatedAt   time.Time            `json:"created_at"`
+	Status      string               `json:"status"`
+	Transitions []EventTransition    `json:"transitions"`
+	Processing  []ProcessingLogEntry `json:"processing"`
+}
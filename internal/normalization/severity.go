@@ -0,0 +1,138 @@
+package normalization
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// DefaultSeverityMap maps common severity labels used by event sources to canonical severities
+var DefaultSeverityMap = map[string]string{
+	"critical":  models.SeverityCritical,
+	"crit":      models.SeverityCritical,
+	"p1":        models.SeverityCritical,
+	"1":         models.SeverityCritical,
+	"sev1":      models.SeverityCritical,
+	"fatal":     models.SeverityCritical,
+	"emergency": models.SeverityCritical,
+	"emerg":     models.SeverityCritical,
+	"alert":     models.SeverityCritical,
+
+	"high":  models.SeverityHigh,
+	"p2":    models.SeverityHigh,
+	"2":     models.SeverityHigh,
+	"sev2":  models.SeverityHigh,
+	"major": models.SeverityHigh,
+	"error": models.SeverityHigh,
+	"err":   models.SeverityHigh,
+
+	"medium":   models.SeverityMedium,
+	"med":      models.SeverityMedium,
+	"moderate": models.SeverityMedium,
+	"p3":       models.SeverityMedium,
+	"3":        models.SeverityMedium,
+	"sev3":     models.SeverityMedium,
+	"warning":  models.SeverityMedium,
+	"warn":     models.SeverityMedium,
+
+	"low":           models.SeverityLow,
+	"minor":         models.SeverityLow,
+	"p4":            models.SeverityLow,
+	"4":             models.SeverityLow,
+	"sev4":          models.SeverityLow,
+	"p5":            models.SeverityLow,
+	"5":             models.SeverityLow,
+	"info":          models.SeverityLow,
+	"informational": models.SeverityLow,
+	"notice":        models.SeverityLow,
+}
+
+// SeverityNormalizer maps raw severity labels to canonical severities.
+// Lookups ignore case and surrounding whitespace.
+type SeverityNormalizer struct {
+	mu       sync.RWMutex
+	mappings map[string]string
+}
+
+// NewSeverityNormalizer creates a normalizer with the default mappings
+func NewSeverityNormalizer() *SeverityNormalizer {
+	n := &SeverityNormalizer{mappings: make(map[string]string, len(DefaultSeverityMap))}
+	for raw, canonical := range DefaultSeverityMap {
+		n.mappings[raw] = canonical
+	}
+	return n
+}
+
+// LoadFile reads a YAML map of raw labels to canonical severities, e.g.
+//
+//	P0: critical
+//	SEV-2: high
+//
+// Entries are added on top of the existing mappings.
+func (n *SeverityNormalizer) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read severity map: %v", err)
+	}
+
+	var mappings map[string]string
+	if err := yaml.Unmarshal(data, &mappings); err != nil {
+		return fmt.Errorf("failed to parse severity map: %v", err)
+	}
+
+	return n.Add(mappings)
+}
+
+// Add registers mappings from raw labels to canonical severities. No mappings are
+// added if any target is not a canonical severity.
+func (n *SeverityNormalizer) Add(mappings map[string]string) error {
+	normalized := make(map[string]string, len(mappings))
+	for raw, canonical := range mappings {
+		key := normalizeKey(raw)
+		if key == "" {
+			return fmt.Errorf("severity label must not be empty")
+		}
+		canonical = strings.ToLower(strings.TrimSpace(canonical))
+		if models.SeverityLevel(canonical) == 0 {
+			return fmt.Errorf("unknown canonical severity %q for label %q", canonical, raw)
+		}
+		normalized[key] = canonical
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for raw, canonical := range normalized {
+		n.mappings[raw] = canonical
+	}
+	return nil
+}
+
+// Normalize returns the canonical severity for a raw label and whether it was recognized
+func (n *SeverityNormalizer) Normalize(raw string) (string, bool) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	canonical, ok := n.mappings[normalizeKey(raw)]
+	return canonical, ok
+}
+
+// Mappings returns a copy of the current mappings
+func (n *SeverityNormalizer) Mappings() map[string]string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	mappings := make(map[string]string, len(n.mappings))
+	for raw, canonical := range n.mappings {
+		mappings[raw] = canonical
+	}
+	return mappings
+}
+
+// normalizeKey folds a raw label into its lookup form
+func normalizeKey(raw string) string {
+	return strings.ToLower(strings.TrimSpace(raw))
+}
@@ -0,0 +1,91 @@
+package normalization
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+func TestNormalizeCriticalVariants(t *testing.T) {
+	normalizer := NewSeverityNormalizer()
+
+	for _, raw := range []string{"P1", "CRIT", "1", "CRITICAL", " critical "} {
+		severity, ok := normalizer.Normalize(raw)
+		if !ok || severity != models.SeverityCritical {
+			t.Errorf("Normalize(%q) = %q, %v; want critical, true", raw, severity, ok)
+		}
+	}
+}
+
+func TestNormalizeDefaultMapping(t *testing.T) {
+	normalizer := NewSeverityNormalizer()
+
+	tests := map[string]string{
+		"sev2":    models.SeverityHigh,
+		"Error":   models.SeverityHigh,
+		"warning": models.SeverityMedium,
+		"P3":      models.SeverityMedium,
+		"info":    models.SeverityLow,
+		"5":       models.SeverityLow,
+	}
+	for raw, want := range tests {
+		if severity, ok := normalizer.Normalize(raw); !ok || severity != want {
+			t.Errorf("Normalize(%q) = %q, %v; want %q, true", raw, severity, ok, want)
+		}
+	}
+}
+
+func TestNormalizeUnknownSeverity(t *testing.T) {
+	if severity, ok := NewSeverityNormalizer().Normalize("urgent-ish"); ok {
+		t.Errorf("Normalize returned %q, true for an unknown label", severity)
+	}
+}
+
+func TestAddMappings(t *testing.T) {
+	normalizer := NewSeverityNormalizer()
+
+	if err := normalizer.Add(map[string]string{"P0": "Critical", "SEV-2": "high"}); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	if severity, ok := normalizer.Normalize("p0"); !ok || severity != models.SeverityCritical {
+		t.Errorf("Normalize(p0) = %q, %v; want critical, true", severity, ok)
+	}
+	if mappings := normalizer.Mappings(); mappings["sev-2"] != models.SeverityHigh {
+		t.Errorf("Mappings()[sev-2] = %q, want high", mappings["sev-2"])
+	}
+}
+
+func TestAddRejectsInvalidMappingsAtomically(t *testing.T) {
+	normalizer := NewSeverityNormalizer()
+
+	err := normalizer.Add(map[string]string{"P0": "critical", "SEV-9": "apocalyptic"})
+	if err == nil {
+		t.Fatal("Add accepted a mapping to an unknown canonical severity")
+	}
+	if _, ok := normalizer.Normalize("P0"); ok {
+		t.Error("valid mappings were added alongside an invalid one")
+	}
+	if err := normalizer.Add(map[string]string{" ": "low"}); err == nil {
+		t.Error("Add accepted an empty label")
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "severity.yaml")
+	if err := os.WriteFile(path, []byte("P0: critical\nSEV-2: high\n"), 0o600); err != nil {
+		t.Fatalf("write severity map: %v", err)
+	}
+
+	normalizer := NewSeverityNormalizer()
+	if err := normalizer.LoadFile(path); err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if severity, _ := normalizer.Normalize("sev-2"); severity != models.SeverityHigh {
+		t.Errorf("Normalize(sev-2) = %q, want high", severity)
+	}
+	if severity, _ := normalizer.Normalize("crit"); severity != models.SeverityCritical {
+		t.Errorf("Normalize(crit) = %q, want the default mappings kept", severity)
+	}
+}
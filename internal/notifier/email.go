@@ -0,0 +1,289 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+const (
+	emailTimeout        = 10 * time.Second
+	emailSuppressWindow = 15 * time.Minute
+)
+
+// EmailConfig holds the SMTP settings for EmailNotifier
+type EmailConfig struct {
+	SMTPHost string
+	SMTPPort int
+	From     string
+	To       []string
+	Username string
+	Password string
+	// TLS connects with implicit TLS (usually port 465). Otherwise STARTTLS is used
+	// when the server offers it.
+	TLS         bool
+	MinSeverity string
+	// BaseURL is the public URL of the API, used to build event deep-links
+	BaseURL string
+}
+
+// EmailNotifier emails security alerts over SMTP. Alerts sharing a correlation ID are
+// sent once per suppress window.
+type EmailNotifier struct {
+	config   EmailConfig
+	timeout  time.Duration
+	suppress time.Duration
+
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+// NewEmailNotifier creates a new email notifier
+func NewEmailNotifier(config EmailConfig) *EmailNotifier {
+	if config.MinSeverity == "" {
+		config.MinSeverity = models.SeverityCritical
+	}
+	if config.SMTPPort == 0 {
+		config.SMTPPort = 587
+	}
+	return &EmailNotifier{
+		config:   config,
+		timeout:  emailTimeout,
+		suppress: emailSuppressWindow,
+		sent:     make(map[string]time.Time),
+	}
+}
+
+// Notify emails the event if it meets the severity threshold and no alert with the
+// same correlation ID was emailed within the suppress window
+func (n *EmailNotifier) Notify(ctx context.Context, event *models.Event) error {
+	if !MeetsThreshold(event, n.config.MinSeverity) {
+		return nil
+	}
+
+	key := DedupKey(event)
+	if !n.claim(key, time.Now()) {
+		return nil
+	}
+
+	message, err := n.buildMessage(event)
+	if err != nil {
+		n.release(key)
+		return err
+	}
+
+	if err := n.send(ctx, message); err != nil {
+		// Let a retry of the same alert through
+		n.release(key)
+		return fmt.Errorf("failed to send alert email: %w", err)
+	}
+
+	return nil
+}
+
+// claim records that an alert for key is being sent, returning false when one was
+// already sent within the suppress window
+func (n *EmailNotifier) claim(key string, now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for k, sentAt := range n.sent {
+		if now.Sub(sentAt) >= n.suppress {
+			delete(n.sent, k)
+		}
+	}
+
+	if _, ok := n.sent[key]; ok {
+		return false
+	}
+	n.sent[key] = now
+	return true
+}
+
+// release forgets a claimed alert so it can be sent again
+func (n *EmailNotifier) release(key string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.sent, key)
+}
+
+// send delivers a message to all recipients, giving up after the notifier timeout
+func (n *EmailNotifier) send(ctx context.Context, message []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(n.config.SMTPHost, strconv.Itoa(n.config.SMTPPort))
+	dialer := &net.Dialer{}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	tlsConfig := &tls.Config{ServerName: n.config.SMTPHost}
+	if n.config.TLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+
+	client, err := smtp.NewClient(conn, n.config.SMTPHost)
+	if err != nil {
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if !n.config.TLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				return fmt.Errorf("failed to start TLS: %w", err)
+			}
+		}
+	}
+
+	if n.config.Username != "" {
+		auth := smtp.PlainAuth("", n.config.Username, n.config.Password, n.config.SMTPHost)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := client.Mail(n.config.From); err != nil {
+		return fmt.Errorf("failed to set sender: %w", err)
+	}
+	for _, to := range n.config.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("failed to add recipient %s: %w", to, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message data: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// EmailSubject returns the email subject for an event
+func EmailSubject(event *models.Event) string {
+	subject := fmt.Sprintf("[SECURITY ALERT] %s: %s", strings.ToUpper(event.Severity), event.EventType)
+	// Event fields must not be able to inject headers
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(subject)
+}
+
+var emailHTMLTemplate = template.Must(template.New("email").Parse(`<html>
+<body>
+<h2 style="color: {{.Color}}">{{.Severity}} security event</h2>
+<table>
+<tr><th align="left">Event ID</th><td>{{.Event.EventID}}</td></tr>
+<tr><th align="left">Type</th><td>{{.Event.EventType}}</td></tr>
+<tr><th align="left">Source</th><td>{{.Event.Source}}</td></tr>
+<tr><th align="left">Severity</th><td>{{.Severity}}</td></tr>
+<tr><th align="left">Time</th><td>{{.Time}}</td></tr>
+<tr><th align="left">Correlation ID</th><td>{{.Event.CorrelationID}}</td></tr>
+</table>
+<p>{{.Event.Description}}</p>
+{{if .URL}}<p><a href="{{.URL}}">View event {{.Event.EventID}}</a></p>{{end}}
+</body>
+</html>
+`))
+
+// buildMessage formats the event as a multipart/alternative email with plain text and HTML bodies
+func (n *EmailNotifier) buildMessage(event *models.Event) ([]byte, error) {
+	severity := strings.ToUpper(event.Severity)
+	timestamp := event.CreatedAt.UTC().Format(time.RFC3339)
+	var eventURL string
+	if n.config.BaseURL != "" {
+		eventURL = fmt.Sprintf("%s/api/v1/events/%s", strings.TrimRight(n.config.BaseURL, "/"), event.EventID)
+	}
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "%s security event\n\n", severity)
+	fmt.Fprintf(&text, "Event ID:       %s\n", event.EventID)
+	fmt.Fprintf(&text, "Type:           %s\n", event.EventType)
+	fmt.Fprintf(&text, "Source:         %s\n", event.Source)
+	fmt.Fprintf(&text, "Severity:       %s\n", severity)
+	fmt.Fprintf(&text, "Time:           %s\n", timestamp)
+	fmt.Fprintf(&text, "Correlation ID: %s\n", event.CorrelationID)
+	if event.Description != "" {
+		fmt.Fprintf(&text, "\n%s\n", event.Description)
+	}
+	if eventURL != "" {
+		fmt.Fprintf(&text, "\nView event: %s\n", eventURL)
+	}
+
+	var html bytes.Buffer
+	err := emailHTMLTemplate.Execute(&html, map[string]interface{}{
+		"Event":    event,
+		"Severity": severity,
+		"Color":    severityColor(event.Severity),
+		"Time":     timestamp,
+		"URL":      eventURL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render alert email: %w", err)
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", text.String()},
+		{"text/html; charset=UTF-8", html.String()},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create email part: %w", err)
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, fmt.Errorf("failed to write email part: %w", err)
+		}
+		if err := qp.Close(); err != nil {
+			return nil, fmt.Errorf("failed to write email part: %w", err)
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish email body: %w", err)
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", n.config.From)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(n.config.To, ", "))
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", EmailSubject(event)))
+	fmt.Fprintf(&message, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&message, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&message, "Content-Type: multipart/alternative; boundary=%q\r\n", parts.Boundary())
+	fmt.Fprintf(&message, "\r\n")
+	message.Write(body.Bytes())
+
+	return message.Bytes(), nil
+}
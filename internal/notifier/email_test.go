@@ -0,0 +1,253 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/emersion/go-smtp"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// receivedMail is a message accepted by the fake SMTP server
+type receivedMail struct {
+	from       string
+	recipients []string
+	data       string
+}
+
+// fakeSMTPServer is an SMTP server on a local port that accepts every message
+type fakeSMTPServer struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	messages []receivedMail
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := &fakeSMTPServer{listener: listener}
+
+	smtpServer := smtp.NewServer(smtp.BackendFunc(func(c *smtp.Conn) (smtp.Session, error) {
+		return &fakeSMTPSession{server: server}, nil
+	}))
+	smtpServer.Domain = "localhost"
+	go smtpServer.Serve(listener)
+	t.Cleanup(func() { smtpServer.Close() })
+	return server
+}
+
+// fakeSMTPSession records the message of one SMTP transaction on its server
+type fakeSMTPSession struct {
+	server  *fakeSMTPServer
+	current receivedMail
+}
+
+func (s *fakeSMTPSession) Mail(from string, opts *smtp.MailOptions) error {
+	s.current = receivedMail{from: from}
+	return nil
+}
+
+func (s *fakeSMTPSession) Rcpt(to string, opts *smtp.RcptOptions) error {
+	s.current.recipients = append(s.current.recipients, to)
+	return nil
+}
+
+func (s *fakeSMTPSession) Data(r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.current.data = string(data)
+	s.server.mu.Lock()
+	s.server.messages = append(s.server.messages, s.current)
+	s.server.mu.Unlock()
+	return nil
+}
+
+func (s *fakeSMTPSession) Reset() { s.current = receivedMail{} }
+
+func (s *fakeSMTPSession) Logout() error { return nil }
+
+func (s *fakeSMTPServer) Messages() []receivedMail {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]receivedMail(nil), s.messages...)
+}
+
+func (s *fakeSMTPServer) config() EmailConfig {
+	host, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	portNumber, _ := strconv.Atoi(port)
+	return EmailConfig{
+		SMTPHost: host,
+		SMTPPort: portNumber,
+		From:     "alerts@skyhawk.example.com",
+		To:       []string{"soc@example.com", "oncall@example.com"},
+		BaseURL:  "https://skyhawk.example.com",
+	}
+}
+
+func criticalEvent(id, correlationID string) *models.Event {
+	return &models.Event{
+		EventID:       id,
+		EventType:     "privilege_escalation",
+		Severity:      models.SeverityCritical,
+		Source:        "iam-service",
+		Description:   "role changed to admin",
+		CorrelationID: correlationID,
+		CreatedAt:     time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+}
+
+// mailBodies returns the decoded plain text and HTML parts of a message
+func mailBodies(t *testing.T, message *mail.Message) (string, string) {
+	t.Helper()
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", message.Header.Get("Content-Type"))
+	}
+
+	bodies := make(map[string]string)
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		content, err := io.ReadAll(quotedprintable.NewReader(part))
+		if err != nil {
+			t.Fatalf("decode part: %v", err)
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[partType] = string(content)
+	}
+	return bodies["text/plain"], bodies["text/html"]
+}
+
+func TestEmailNotifierSendsAlert(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notifier := NewEmailNotifier(server.config())
+
+	if err := notifier.Notify(context.Background(), criticalEvent("evt-1", "corr-1")); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("server received %d messages, want 1", len(messages))
+	}
+	received := messages[0]
+	if received.from != "alerts@skyhawk.example.com" {
+		t.Errorf("MAIL FROM = %q, want alerts@skyhawk.example.com", received.from)
+	}
+	if strings.Join(received.recipients, ",") != "soc@example.com,oncall@example.com" {
+		t.Errorf("recipients = %v, want soc@ and oncall@", received.recipients)
+	}
+
+	message, err := mail.ReadMessage(bufio.NewReader(strings.NewReader(received.data)))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decode subject: %v", err)
+	}
+	if subject != "[SECURITY ALERT] CRITICAL: privilege_escalation" {
+		t.Errorf("subject = %q, want [SECURITY ALERT] CRITICAL: privilege_escalation", subject)
+	}
+	if to := message.Header.Get("To"); to != "soc@example.com, oncall@example.com" {
+		t.Errorf("To = %q, want both recipients", to)
+	}
+
+	text, html := mailBodies(t, message)
+	for _, want := range []string{"Event ID:       evt-1", "Source:         iam-service", "Time:           2026-01-02T03:04:05Z", "role changed to admin", "https://skyhawk.example.com/api/v1/events/evt-1"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text body %q does not contain %q", text, want)
+		}
+	}
+	if !strings.Contains(html, `<a href="https://skyhawk.example.com/api/v1/events/evt-1">`) || !strings.Contains(html, "#d00000") {
+		t.Errorf("html body %q does not link the event in the critical color", html)
+	}
+}
+
+func TestEmailNotifierSuppressesDuplicateAlerts(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notifier := NewEmailNotifier(server.config())
+
+	for _, event := range []*models.Event{criticalEvent("evt-1", "corr-1"), criticalEvent("evt-2", "corr-1"), criticalEvent("evt-3", "corr-2")} {
+		if err := notifier.Notify(context.Background(), event); err != nil {
+			t.Fatalf("Notify(%s): %v", event.EventID, err)
+		}
+	}
+	if messages := server.Messages(); len(messages) != 2 {
+		t.Errorf("server received %d messages, want 2 with corr-1 suppressed once", len(messages))
+	}
+
+	// Once the suppress window has passed the alert is sent again
+	notifier.mu.Lock()
+	for key := range notifier.sent {
+		notifier.sent[key] = time.Now().Add(-emailSuppressWindow)
+	}
+	notifier.mu.Unlock()
+	if err := notifier.Notify(context.Background(), criticalEvent("evt-4", "corr-1")); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if messages := server.Messages(); len(messages) != 3 {
+		t.Errorf("server received %d messages, want 3 after the suppress window", len(messages))
+	}
+}
+
+func TestEmailNotifierSkipsEventsBelowThreshold(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notifier := NewEmailNotifier(server.config())
+
+	event := criticalEvent("evt-1", "corr-1")
+	event.Severity = models.SeverityHigh
+	if err := notifier.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if messages := server.Messages(); len(messages) != 0 {
+		t.Errorf("server received %d messages for a high event, want 0 with the default critical threshold", len(messages))
+	}
+}
+
+func TestEmailNotifierRetriesAfterFailedSend(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+	portNumber, _ := strconv.Atoi(port)
+
+	notifier := NewEmailNotifier(EmailConfig{SMTPHost: host, SMTPPort: portNumber, From: "alerts@example.com", To: []string{"soc@example.com"}})
+	for i := 0; i < 2; i++ {
+		if err := notifier.Notify(context.Background(), criticalEvent("evt-1", "corr-1")); err == nil {
+			t.Fatalf("attempt %d: Notify returned nil without a server, want an error instead of suppression", i+1)
+		}
+	}
+}
+
+func TestEmailSubjectStripsLineBreaks(t *testing.T) {
+	event := &models.Event{Severity: models.SeverityCritical, EventType: "x\r\nBcc: attacker@example.com"}
+	if subject := EmailSubject(event); strings.ContainsAny(subject, "\r\n") {
+		t.Errorf("subject %q contains a line break", subject)
+	}
+}
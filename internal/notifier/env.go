@@ -0,0 +1,78 @@
+package notifier
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// SlackConfigFromEnv reads the Slack settings from SLACK_WEBHOOK_URL, SLACK_CHANNEL,
+// SLACK_MIN_SEVERITY and API_BASE_URL. WebhookURL is empty when Slack is not configured.
+func SlackConfigFromEnv() SlackConfig {
+	return SlackConfig{
+		WebhookURL:  os.Getenv("SLACK_WEBHOOK_URL"),
+		Channel:     os.Getenv("SLACK_CHANNEL"),
+		MinSeverity: os.Getenv("SLACK_MIN_SEVERITY"),
+		BaseURL:     os.Getenv("API_BASE_URL"),
+	}
+}
+
+// EmailConfigFromEnv reads the SMTP settings from the SMTP_* variables, EMAIL_MIN_SEVERITY and
+// API_BASE_URL. It returns nil when SMTP_HOST is not set.
+func EmailConfigFromEnv() (*EmailConfig, error) {
+	smtpHost := os.Getenv("SMTP_HOST")
+	if smtpHost == "" {
+		return nil, nil
+	}
+
+	smtpPort := 587
+	if value := os.Getenv("SMTP_PORT"); value != "" {
+		port, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SMTP_PORT: %s", value)
+		}
+		smtpPort = port
+	}
+	recipients := strings.Split(os.Getenv("SMTP_TO"), ",")
+	for i := range recipients {
+		recipients[i] = strings.TrimSpace(recipients[i])
+	}
+	if os.Getenv("SMTP_FROM") == "" || recipients[0] == "" {
+		return nil, fmt.Errorf("SMTP_FROM and SMTP_TO must be set for email alerts")
+	}
+
+	return &EmailConfig{
+		SMTPHost:    smtpHost,
+		SMTPPort:    smtpPort,
+		From:        os.Getenv("SMTP_FROM"),
+		To:          recipients,
+		Username:    os.Getenv("SMTP_USERNAME"),
+		Password:    os.Getenv("SMTP_PASSWORD"),
+		TLS:         os.Getenv("SMTP_TLS") == "true",
+		MinSeverity: os.Getenv("EMAIL_MIN_SEVERITY"),
+		BaseURL:     os.Getenv("API_BASE_URL"),
+	}, nil
+}
+
+// FromEnv creates the notifiers configured in the environment: Slack when SLACK_WEBHOOK_URL
+// is set, PagerDuty when PAGERDUTY_ROUTING_KEY is set and email when SMTP_HOST is set
+func FromEnv() ([]Notifier, error) {
+	var notifiers []Notifier
+
+	if slackConfig := SlackConfigFromEnv(); slackConfig.WebhookURL != "" {
+		notifiers = append(notifiers, NewSlackNotifier(slackConfig))
+	}
+	if routingKey := os.Getenv("PAGERDUTY_ROUTING_KEY"); routingKey != "" {
+		notifiers = append(notifiers, NewPagerDutyNotifier(routingKey, os.Getenv("PAGERDUTY_MIN_SEVERITY")))
+	}
+	emailConfig, err := EmailConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if emailConfig != nil {
+		notifiers = append(notifiers, NewEmailNotifier(*emailConfig))
+	}
+
+	return notifiers, nil
+}
@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// Notifier delivers alerts about security events to an external system
+type Notifier interface {
+	Notify(ctx context.Context, event *models.Event) error
+}
+
+// NotifierConfig holds the configuration shared by webhook based notifiers
+type NotifierConfig struct {
+	WebhookURL  string `yaml:"webhook_url"`
+	Channel     string `yaml:"channel"`
+	MinSeverity string `yaml:"min_severity"`
+	// BaseURL is the public URL of the API, used to build event deep-links
+	BaseURL string `yaml:"base_url"`
+}
+
+// MeetsThreshold reports whether the event severity is at or above the minimum severity
+func MeetsThreshold(event *models.Event, minSeverity string) bool {
+	if minSeverity == "" {
+		return true
+	}
+	return models.SeverityLevel(event.Severity) >= models.SeverityLevel(minSeverity)
+}
+
+const (
+	maxAttempts    = 3
+	initialBackoff = 500 * time.Millisecond
+)
+
+// postJSON POSTs a JSON payload, retrying with exponential backoff on transient failures
+func postJSON(ctx context.Context, client *http.Client, url string, payload []byte) error {
+	backoff := initialBackoff
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to send request: %w", err)
+		} else {
+			resp.Body.Close()
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				return nil
+			}
+			lastErr = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+			if !isTransient(resp.StatusCode) {
+				return lastErr
+			}
+		}
+
+		if attempt == maxAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return fmt.Errorf("giving up after %d attempts: %w", maxAttempts, lastErr)
+}
+
+// isTransient reports whether a status code is worth retrying
+func isTransient(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable
+}
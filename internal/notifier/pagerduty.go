@@ -0,0 +1,128 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// PagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// Resolver resolves a previously triggered alert
+type Resolver interface {
+	Resolve(ctx context.Context, dedupKey string) error
+}
+
+// PagerDutyNotifier triggers and resolves PagerDuty incidents via the Events API v2
+type PagerDutyNotifier struct {
+	routingKey  string
+	minSeverity string
+	eventsURL   string
+	client      *http.Client
+}
+
+// NewPagerDutyNotifier creates a new PagerDuty notifier
+func NewPagerDutyNotifier(routingKey, minSeverity string) *PagerDutyNotifier {
+	if minSeverity == "" {
+		minSeverity = models.SeverityCritical
+	}
+	return &PagerDutyNotifier{
+		routingKey:  routingKey,
+		minSeverity: minSeverity,
+		eventsURL:   PagerDutyEventsURL,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// pagerDutyEvent is the Events API v2 request body
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key,omitempty"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Component     string                 `json:"component,omitempty"`
+	Class         string                 `json:"class,omitempty"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+// Notify triggers an incident if the event meets the configured severity threshold
+func (n *PagerDutyNotifier) Notify(ctx context.Context, event *models.Event) error {
+	if !MeetsThreshold(event, n.minSeverity) {
+		return nil
+	}
+
+	return n.send(ctx, pagerDutyEvent{
+		RoutingKey:  n.routingKey,
+		EventAction: "trigger",
+		DedupKey:    DedupKey(event),
+		Payload: &pagerDutyPayload{
+			Summary:   fmt.Sprintf("[%s] %s event from %s", strings.ToUpper(event.Severity), event.EventType, event.Source),
+			Source:    event.Source,
+			Severity:  pagerDutySeverity(event.Severity),
+			Component: "skyhawk-security-microservice",
+			Class:     event.EventType,
+			CustomDetails: map[string]interface{}{
+				"event_id":    event.EventID,
+				"source":      event.Source,
+				"description": event.Description,
+			},
+		},
+	})
+}
+
+// Resolve resolves the incident identified by the dedup key
+func (n *PagerDutyNotifier) Resolve(ctx context.Context, dedupKey string) error {
+	return n.send(ctx, pagerDutyEvent{
+		RoutingKey:  n.routingKey,
+		EventAction: "resolve",
+		DedupKey:    dedupKey,
+	})
+}
+
+// send delivers an Events API v2 request
+func (n *PagerDutyNotifier) send(ctx context.Context, event pagerDutyEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pagerduty event: %w", err)
+	}
+
+	if err := postJSON(ctx, n.client, n.eventsURL, payload); err != nil {
+		return fmt.Errorf("failed to deliver pagerduty %s: %w", event.EventAction, err)
+	}
+
+	return nil
+}
+
+// DedupKey returns the key PagerDuty uses to group alerts for an event
+func DedupKey(event *models.Event) string {
+	if event.CorrelationID != "" {
+		return event.CorrelationID
+	}
+	return event.EventID
+}
+
+// pagerDutySeverity maps event severities onto PagerDuty severities
+func pagerDutySeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case models.SeverityCritical:
+		return "critical"
+	case models.SeverityHigh:
+		return "error"
+	case models.SeverityMedium:
+		return "warning"
+	default:
+		return "info"
+	}
+}
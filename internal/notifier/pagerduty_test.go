@@ -0,0 +1,114 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// newPagerDutyTestServer returns a notifier that talks to a mock HTTPS Events API,
+// and the events that API received
+func newPagerDutyTestServer(t *testing.T) (*PagerDutyNotifier, *[]pagerDutyEvent) {
+	t.Helper()
+	var received []pagerDutyEvent
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event pagerDutyEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			t.Errorf("events API received invalid JSON: %v", err)
+		}
+		received = append(received, event)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	t.Cleanup(server.Close)
+
+	notifier := NewPagerDutyNotifier("routing-key", "")
+	notifier.eventsURL = server.URL
+	notifier.client = server.Client()
+	return notifier, &received
+}
+
+func TestPagerDutyNotifierTriggersIncident(t *testing.T) {
+	notifier, received := newPagerDutyTestServer(t)
+	event := &models.Event{
+		EventID:       "evt-1",
+		EventType:     "privilege_escalation",
+		Severity:      models.SeverityCritical,
+		Source:        "iam-service",
+		Description:   "role changed to admin",
+		CorrelationID: "corr-1",
+	}
+
+	if err := notifier.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(*received) != 1 {
+		t.Fatalf("events API received %d events, want 1", len(*received))
+	}
+
+	sent := (*received)[0]
+	if sent.RoutingKey != "routing-key" || sent.EventAction != "trigger" || sent.DedupKey != "corr-1" {
+		t.Errorf("event = %+v, want a trigger keyed by the correlation ID", sent)
+	}
+	if sent.Payload == nil {
+		t.Fatal("trigger has no payload")
+	}
+	if sent.Payload.Severity != "critical" || sent.Payload.Source != "iam-service" {
+		t.Errorf("payload = %+v, want critical from iam-service", sent.Payload)
+	}
+	for key, want := range map[string]string{"event_id": "evt-1", "source": "iam-service", "description": "role changed to admin"} {
+		if got := sent.Payload.CustomDetails[key]; got != want {
+			t.Errorf("custom_details[%s] = %v, want %q", key, got, want)
+		}
+	}
+}
+
+func TestPagerDutyNotifierSkipsEventsBelowThreshold(t *testing.T) {
+	notifier, received := newPagerDutyTestServer(t)
+
+	event := &models.Event{EventID: "evt-1", Severity: models.SeverityHigh}
+	if err := notifier.Notify(context.Background(), event); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(*received) != 0 {
+		t.Errorf("events API received %d events for a high event, want 0 with the default critical threshold", len(*received))
+	}
+}
+
+func TestPagerDutyNotifierResolve(t *testing.T) {
+	notifier, received := newPagerDutyTestServer(t)
+
+	if err := notifier.Resolve(context.Background(), "corr-1"); err != nil {
+		t.Fatalf("Resolve: %v", err)
+	}
+	if len(*received) != 1 {
+		t.Fatalf("events API received %d events, want 1", len(*received))
+	}
+	if sent := (*received)[0]; sent.EventAction != "resolve" || sent.DedupKey != "corr-1" || sent.Payload != nil {
+		t.Errorf("event = %+v, want a resolve for corr-1 without payload", sent)
+	}
+}
+
+func TestDedupKeyFallsBackToEventID(t *testing.T) {
+	if key := DedupKey(&models.Event{EventID: "evt-1"}); key != "evt-1" {
+		t.Errorf("DedupKey = %q, want evt-1 without a correlation ID", key)
+	}
+}
+
+func TestPagerDutySeverity(t *testing.T) {
+	tests := map[string]string{
+		models.SeverityCritical: "critical",
+		models.SeverityHigh:     "error",
+		models.SeverityMedium:   "warning",
+		models.SeverityLow:      "info",
+		"HIGH":                  "error",
+	}
+	for severity, want := range tests {
+		if got := pagerDutySeverity(severity); got != want {
+			t.Errorf("pagerDutySeverity(%q) = %q, want %q", severity, got, want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// SlackConfig configures the Slack notifier
+type SlackConfig = NotifierConfig
+
+// SlackNotifier posts security events to a Slack Incoming Webhook
+type SlackNotifier struct {
+	mu     sync.RWMutex
+	config SlackConfig
+	client *http.Client
+}
+
+// NewSlackNotifier creates a new Slack notifier
+func NewSlackNotifier(config SlackConfig) *SlackNotifier {
+	return &SlackNotifier{
+		config: withSlackDefaults(config),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Reload replaces the notifier configuration, e.g. to rotate the webhook URL.
+// Notifications already in flight finish with the previous configuration.
+func (n *SlackNotifier) Reload(config SlackConfig) error {
+	if config.WebhookURL == "" {
+		return fmt.Errorf("slack webhook URL is required")
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.config = withSlackDefaults(config)
+	return nil
+}
+
+// withSlackDefaults alerts on high severity events unless configured otherwise
+func withSlackDefaults(config SlackConfig) SlackConfig {
+	if config.MinSeverity == "" {
+		config.MinSeverity = models.SeverityHigh
+	}
+	return config
+}
+
+// slackMessage is the payload accepted by Slack Incoming Webhooks
+type slackMessage struct {
+	Channel     string            `json:"channel,omitempty"`
+	Text        string            `json:"text"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type   string      `json:"type"`
+	Text   *slackText  `json:"text,omitempty"`
+	Fields []slackText `json:"fields,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Notify sends the event to Slack if it meets the configured severity threshold
+func (n *SlackNotifier) Notify(ctx context.Context, event *models.Event) error {
+	n.mu.RLock()
+	config := n.config
+	n.mu.RUnlock()
+
+	if !MeetsThreshold(event, config.MinSeverity) {
+		return nil
+	}
+
+	payload, err := json.Marshal(buildSlackMessage(config, event))
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack message: %w", err)
+	}
+
+	if err := postJSON(ctx, n.client, config.WebhookURL, payload); err != nil {
+		return fmt.Errorf("failed to deliver slack notification: %w", err)
+	}
+
+	return nil
+}
+
+// buildSlackMessage formats the event as a Block Kit attachment
+func buildSlackMessage(config SlackConfig, event *models.Event) slackMessage {
+	severity := strings.ToUpper(event.Severity)
+	eventURL := fmt.Sprintf("%s/api/v1/events/%s", strings.TrimRight(config.BaseURL, "/"), event.EventID)
+
+	return slackMessage{
+		Channel: config.Channel,
+		Text:    fmt.Sprintf("[%s] %s event from %s", severity, event.EventType, event.Source),
+		Attachments: []slackAttachment{
+			{
+				Color: severityColor(event.Severity),
+				Blocks: []slackBlock{
+					{
+						Type: "header",
+						Text: &slackText{Type: "plain_text", Text: fmt.Sprintf("%s security event", severity)},
+					},
+					{
+						Type: "section",
+						Fields: []slackText{
+							{Type: "mrkdwn", Text: fmt.Sprintf("*Type:*\n%s", event.EventType)},
+							{Type: "mrkdwn", Text: fmt.Sprintf("*Source:*\n%s", event.Source)},
+						},
+					},
+					{
+						Type: "section",
+						Text: &slackText{Type: "mrkdwn", Text: fmt.Sprintf("*Description:*\n%s", event.Description)},
+					},
+					{
+						Type: "section",
+						Text: &slackText{Type: "mrkdwn", Text: fmt.Sprintf("<%s|View event %s>", eventURL, event.EventID)},
+					},
+				},
+			},
+		},
+	}
+}
+
+// severityColor returns the attachment color for a severity
+func severityColor(severity string) string {
+	switch strings.ToLower(severity) {
+	case models.SeverityCritical:
+		return "#d00000"
+	case models.SeverityHigh:
+		return "#ff8c00"
+	case models.SeverityMedium:
+		return "#ffd700"
+	default:
+		return "#808080"
+	}
+}
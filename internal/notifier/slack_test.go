@@ -0,0 +1,141 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// webhookRecorder is a mock webhook server that records the requests it receives
+type webhookRecorder struct {
+	server   *httptest.Server
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func newWebhookRecorder(t *testing.T, status int) *webhookRecorder {
+	t.Helper()
+	recorder := &webhookRecorder{}
+	recorder.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("webhook received invalid JSON: %v", err)
+		}
+		recorder.requests = append(recorder.requests, r)
+		recorder.bodies = append(recorder.bodies, body)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(recorder.server.Close)
+	return recorder
+}
+
+func slackTestEvent(severity string) *models.Event {
+	return &models.Event{
+		EventID:     "evt-1",
+		EventType:   "login_failure",
+		Severity:    severity,
+		Source:      "auth-service",
+		Description: "5 failed logins for admin",
+	}
+}
+
+func TestSlackNotifierPostsPayload(t *testing.T) {
+	webhook := newWebhookRecorder(t, http.StatusOK)
+	notifier := NewSlackNotifier(SlackConfig{
+		WebhookURL: webhook.server.URL,
+		Channel:    "#security",
+		BaseURL:    "https://skyhawk.example.com/",
+	})
+
+	if err := notifier.Notify(context.Background(), slackTestEvent(models.SeverityCritical)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(webhook.requests) != 1 {
+		t.Fatalf("webhook received %d requests, want 1", len(webhook.requests))
+	}
+	request := webhook.requests[0]
+	if request.Method != http.MethodPost {
+		t.Errorf("method = %s, want POST", request.Method)
+	}
+	if contentType := request.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+
+	var message slackMessage
+	if err := json.Unmarshal(webhook.bodies[0], &message); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if message.Channel != "#security" {
+		t.Errorf("channel = %q, want #security", message.Channel)
+	}
+	if want := "[CRITICAL] login_failure event from auth-service"; message.Text != want {
+		t.Errorf("text = %q, want %q", message.Text, want)
+	}
+	if len(message.Attachments) != 1 {
+		t.Fatalf("payload has %d attachments, want 1", len(message.Attachments))
+	}
+	attachment := message.Attachments[0]
+	if attachment.Color != "#d00000" {
+		t.Errorf("color = %q, want #d00000", attachment.Color)
+	}
+	if len(attachment.Blocks) != 4 {
+		t.Fatalf("attachment has %d blocks, want 4", len(attachment.Blocks))
+	}
+	if header := attachment.Blocks[0]; header.Type != "header" || header.Text.Text != "CRITICAL security event" {
+		t.Errorf("header block = %+v, want the severity heading", header)
+	}
+	if fields := attachment.Blocks[1].Fields; len(fields) != 2 || fields[0].Text != "*Type:*\nlogin_failure" || fields[1].Text != "*Source:*\nauth-service" {
+		t.Errorf("fields = %+v, want type and source", fields)
+	}
+	if link := attachment.Blocks[3].Text.Text; !strings.Contains(link, "https://skyhawk.example.com/api/v1/events/evt-1") {
+		t.Errorf("link block = %q, want the event deep-link", link)
+	}
+}
+
+func TestSlackNotifierSkipsEventsBelowThreshold(t *testing.T) {
+	webhook := newWebhookRecorder(t, http.StatusOK)
+	notifier := NewSlackNotifier(SlackConfig{WebhookURL: webhook.server.URL})
+
+	if err := notifier.Notify(context.Background(), slackTestEvent(models.SeverityMedium)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(webhook.requests) != 0 {
+		t.Errorf("webhook received %d requests for a medium event, want 0 with the default high threshold", len(webhook.requests))
+	}
+}
+
+func TestSlackNotifierReportsRejectedWebhook(t *testing.T) {
+	webhook := newWebhookRecorder(t, http.StatusBadRequest)
+	notifier := NewSlackNotifier(SlackConfig{WebhookURL: webhook.server.URL})
+
+	if err := notifier.Notify(context.Background(), slackTestEvent(models.SeverityHigh)); err == nil {
+		t.Error("Notify returned nil for a 400 response")
+	}
+	if len(webhook.requests) != 1 {
+		t.Errorf("webhook received %d requests, want 1 since 400 is not retried", len(webhook.requests))
+	}
+}
+
+func TestSlackNotifierReload(t *testing.T) {
+	first := newWebhookRecorder(t, http.StatusOK)
+	second := newWebhookRecorder(t, http.StatusOK)
+	notifier := NewSlackNotifier(SlackConfig{WebhookURL: first.server.URL})
+
+	if err := notifier.Reload(SlackConfig{}); err == nil {
+		t.Error("Reload accepted an empty webhook URL")
+	}
+	if err := notifier.Reload(SlackConfig{WebhookURL: second.server.URL}); err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if err := notifier.Notify(context.Background(), slackTestEvent(models.SeverityHigh)); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(first.requests) != 0 || len(second.requests) != 1 {
+		t.Errorf("old webhook got %d requests and new webhook %d, want 0 and 1", len(first.requests), len(second.requests))
+	}
+}
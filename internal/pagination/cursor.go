@@ -0,0 +1,95 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	apperrors "skyhawk-security-microservice/internal/errors"
+)
+
+// Cursor identifies a position in a list ordered by (created_at DESC, id DESC), or by a sort
+// key with created_at and id breaking ties
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+	// Key is the sort key of the position when the list is not ordered by created_at
+	Key string `json:"k,omitempty"`
+	// Sort names the order the cursor was issued for; empty for (created_at DESC, id DESC)
+	Sort string `json:"s,omitempty"`
+}
+
+// CursorCodec encodes cursors as opaque, HMAC-signed tokens so clients cannot forge positions
+type CursorCodec struct {
+	secret []byte
+}
+
+// NewCursorCodec creates a codec signing cursors with the given secret
+func NewCursorCodec(secret []byte) *CursorCodec {
+	return &CursorCodec{secret: secret}
+}
+
+// NewCursorCodecFromEnv creates a codec using CURSOR_SECRET. When unset, a random secret is
+// generated, which means cursors are only valid on the instance that issued them.
+func NewCursorCodecFromEnv() *CursorCodec {
+	if secret := os.Getenv("CURSOR_SECRET"); secret != "" {
+		return NewCursorCodec([]byte(secret))
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		log.Fatalf("Failed to generate cursor secret: %v", err)
+	}
+	log.Printf("Warning: CURSOR_SECRET not set, pagination cursors will not be valid across instances")
+	return NewCursorCodec(secret)
+}
+
+// Encode returns the opaque token for a cursor
+func (c *CursorCodec) Encode(cursor Cursor) string {
+	payload, _ := json.Marshal(cursor)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(c.sign(encoded))
+}
+
+// Decode validates and decodes an opaque cursor token
+func (c *CursorCodec) Decode(token string) (*Cursor, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, invalidCursor("malformed cursor")
+	}
+
+	providedSig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(providedSig, c.sign(encoded)) {
+		return nil, invalidCursor("cursor signature mismatch")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, invalidCursor("malformed cursor encoding")
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(payload, &cursor); err != nil || cursor.ID == "" || cursor.CreatedAt.IsZero() {
+		return nil, invalidCursor("malformed cursor payload")
+	}
+
+	return &cursor, nil
+}
+
+// sign computes the HMAC-SHA256 of the encoded payload
+func (c *CursorCodec) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+// invalidCursor builds the validation error returned for bad cursors
+func invalidCursor(details string) error {
+	return apperrors.NewValidationError("Invalid cursor", details)
+}
@@ -0,0 +1,159 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"skyhawk-security-microservice/internal/logger"
+)
+
+// ScalingConfig bounds the number of consumers run by a WorkerScaler
+type ScalingConfig struct {
+	MinWorkers int
+	MaxWorkers int
+	// HighWatermark adds a consumer when the queue holds more messages than this
+	HighWatermark int64
+	// LowWatermark removes a consumer when the queue holds fewer messages than this
+	LowWatermark int64
+	// Interval is how often the queue depth is sampled
+	Interval time.Duration
+}
+
+// Validate checks that the bounds and watermarks are consistent
+func (c ScalingConfig) Validate() error {
+	if c.MinWorkers < 1 || c.MaxWorkers < c.MinWorkers {
+		return fmt.Errorf("worker bounds must satisfy 1 <= min (%d) <= max (%d)", c.MinWorkers, c.MaxWorkers)
+	}
+	if c.LowWatermark < 0 || c.HighWatermark <= c.LowWatermark {
+		return fmt.Errorf("high watermark (%d) must exceed low watermark (%d)", c.HighWatermark, c.LowWatermark)
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("scaling interval must be positive")
+	}
+	return nil
+}
+
+// QueueLengthReader reports the number of messages waiting in a queue
+type QueueLengthReader interface {
+	GetQueueLength(queueName string) (int64, error)
+}
+
+// ConsumerFunc runs one consumer until stop is closed or the queue shuts down
+type ConsumerFunc func(workerID int, stop <-chan struct{})
+
+// WorkerScaler adjusts the number of consumers of a queue between the configured bounds,
+// one consumer per sample, based on the queue depth
+type WorkerScaler struct {
+	config    ScalingConfig
+	queue     QueueLengthReader
+	queueName string
+	consume   ConsumerFunc
+	logger    *logger.Logger
+
+	mu      sync.Mutex
+	stops   []chan struct{}
+	started int
+	wg      sync.WaitGroup
+}
+
+// NewWorkerScaler creates a scaler for queueName that runs consumers with consume
+func NewWorkerScaler(config ScalingConfig, queue QueueLengthReader, queueName string, consume ConsumerFunc) *WorkerScaler {
+	return &WorkerScaler{
+		config:    config,
+		queue:     queue,
+		queueName: queueName,
+		consume:   consume,
+		logger:    logger.GetLogger().WithField("queue", queueName),
+	}
+}
+
+// Run starts the minimum number of consumers and rescales on every interval until ctx is done.
+// Consumers still running at that point stop when the queue is closed or StopAll is called.
+func (s *WorkerScaler) Run(ctx context.Context) {
+	for i := 0; i < s.config.MinWorkers; i++ {
+		s.scaleUp()
+	}
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.rescale()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Workers returns the number of running consumers
+func (s *WorkerScaler) Workers() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.stops)
+}
+
+// Wait blocks until every consumer started by the scaler has returned
+func (s *WorkerScaler) Wait() {
+	s.wg.Wait()
+}
+
+// rescale samples the queue depth and adds or removes one consumer
+func (s *WorkerScaler) rescale() {
+	depth, err := s.queue.GetQueueLength(s.queueName)
+	if err != nil {
+		s.logger.Error("Failed to sample queue depth", err)
+		return
+	}
+
+	workers := s.Workers()
+	switch {
+	case depth > s.config.HighWatermark && workers < s.config.MaxWorkers:
+		s.logger.Info("Scaling up consumers", logger.Fields{"depth": depth, "workers": workers + 1})
+		s.scaleUp()
+	case depth < s.config.LowWatermark && workers > s.config.MinWorkers:
+		s.logger.Info("Scaling down consumers", logger.Fields{"depth": depth, "workers": workers - 1})
+		s.scaleDown()
+	}
+}
+
+// scaleUp starts another consumer
+func (s *WorkerScaler) scaleUp() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stop := make(chan struct{})
+	s.stops = append(s.stops, stop)
+	s.started++
+	workerID := s.started
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.consume(workerID, stop)
+	}()
+}
+
+// StopAll signals every running consumer to stop; each finishes its in-flight messages first
+func (s *WorkerScaler) StopAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stop := range s.stops {
+		close(stop)
+	}
+	s.stops = nil
+}
+
+// scaleDown signals the newest consumer to stop; it finishes its in-flight messages first
+func (s *WorkerScaler) scaleDown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last := len(s.stops) - 1
+	close(s.stops[last])
+	s.stops = s.stops[:last]
+}
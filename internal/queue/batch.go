@@ -0,0 +1,38 @@
+package queue
+
+import (
+	"context"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// PublishResult is the outcome of publishing one event of a batch
+type PublishResult struct {
+	EventID string
+	// Queue is the queue the event was published, or meant to be published, to
+	Queue string
+	// Err is why the event was not published; nil when it was
+	Err error
+}
+
+// Published reports whether the event was published
+func (r PublishResult) Published() bool {
+	return r.Err == nil
+}
+
+// PublishEvents publishes every event to the queue route selects for it, propagating the trace
+// context of ctx. A failure does not stop the batch: one result is returned per event, in
+// order, so callers can tell which events were published. Once ctx is done the remaining
+// events fail with its error.
+func PublishEvents(ctx context.Context, q QueueInterface, events []*models.Event, route func(*models.Event) string, opts ...PublishOption) []PublishResult {
+	results := make([]PublishResult, len(events))
+	for i, event := range events {
+		results[i] = PublishResult{EventID: event.EventID, Queue: route(event)}
+		if err := ctx.Err(); err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Err = PublishEventContext(ctx, q, event, results[i].Queue, opts...)
+	}
+	return results
+}
@@ -0,0 +1,110 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// flakyQueue fails the failAt-th publish and calls afterPublish after every attempt, counting
+// from 1
+type flakyQueue struct {
+	*MemoryQueue
+	failAt       int
+	afterPublish func(attempt int)
+	attempts     int
+}
+
+func (q *flakyQueue) PublishEvent(event *models.Event, queueName string, opts ...PublishOption) error {
+	q.attempts++
+	if q.afterPublish != nil {
+		defer q.afterPublish(q.attempts)
+	}
+	if q.attempts == q.failAt {
+		return errors.New("broker unavailable")
+	}
+	return q.MemoryQueue.PublishEvent(event, queueName, opts...)
+}
+
+// batchEvents returns n events; even ones are low severity and odd ones high
+func batchEvents(n int) []*models.Event {
+	events := make([]*models.Event, n)
+	for i := range events {
+		events[i] = testEvent(fmt.Sprintf("evt-%d", i))
+		if i%2 == 0 {
+			events[i].Severity = "low"
+		}
+	}
+	return events
+}
+
+// routeBySeverity sends high severity events to their own queue
+func routeBySeverity(event *models.Event) string {
+	if event.Severity == "high" {
+		return "high_severity_events"
+	}
+	return "security_events"
+}
+
+func TestPublishEventsContinuesPastAFailedEvent(t *testing.T) {
+	mq := NewMemoryQueue()
+	defer mq.Close()
+	q := &flakyQueue{MemoryQueue: mq, failAt: 3}
+	events := batchEvents(5)
+
+	results := PublishEvents(context.Background(), q, events, routeBySeverity)
+
+	if len(results) != len(events) {
+		t.Fatalf("got %d results for %d events", len(results), len(events))
+	}
+	for i, result := range results {
+		if result.EventID != events[i].EventID || result.Queue != routeBySeverity(events[i]) {
+			t.Errorf("result %d = %s on %s, want %s on %s in input order", i, result.EventID, result.Queue, events[i].EventID, routeBySeverity(events[i]))
+		}
+		if wantPublished := i != 2; result.Published() != wantPublished {
+			t.Errorf("%s published = %t, want %t (%v)", result.EventID, result.Published(), wantPublished, result.Err)
+		}
+	}
+	if q.attempts != len(events) {
+		t.Errorf("attempted %d publishes, want every event attempted", q.attempts)
+	}
+	if got := len(mq.Published("security_events")) + len(mq.Published("high_severity_events")); got != 4 {
+		t.Errorf("%d events reached the queues, want 4", got)
+	}
+}
+
+func TestPublishEventsFailsTheRestOnceCancelled(t *testing.T) {
+	mq := NewMemoryQueue()
+	defer mq.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	q := &flakyQueue{MemoryQueue: mq, afterPublish: func(attempt int) {
+		if attempt == 2 {
+			cancel()
+		}
+	}}
+	events := batchEvents(5)
+
+	results := PublishEvents(ctx, q, events, routeBySeverity)
+
+	for i, result := range results {
+		if result.EventID != events[i].EventID {
+			t.Errorf("result %d is for %s, want %s", i, result.EventID, events[i].EventID)
+		}
+		if i < 2 {
+			if !result.Published() {
+				t.Errorf("%s published before cancelling failed: %v", result.EventID, result.Err)
+			}
+			continue
+		}
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("%s after cancelling failed with %v, want context.Canceled", result.EventID, result.Err)
+		}
+	}
+	if q.attempts != 2 {
+		t.Errorf("attempted %d publishes, want none after cancelling", q.attempts)
+	}
+}
@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"skyhawk-security-microservice/internal/logger"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// ExchangeMode selects how events are published to RabbitMQ
+type ExchangeMode string
+
+const (
+	// ExchangeModeDefault publishes to the default exchange using the queue name as routing key
+	ExchangeModeDefault ExchangeMode = "default"
+	// ExchangeModeTopic publishes events to a topic exchange using EventRoutingKey
+	ExchangeModeTopic ExchangeMode = "topic"
+)
+
+const (
+	defaultTopicExchange = "security_events_topic"
+	// allEventsPattern matches every routing key produced by EventRoutingKey
+	allEventsPattern = "event.#"
+)
+
+// EventRoutingKey builds the topic routing key for an event: event.<type>.<severity>
+func EventRoutingKey(event *models.Event) string {
+	return fmt.Sprintf("event.%s.%s", routingKeyWord(event.EventType), routingKeyWord(event.Severity))
+}
+
+// TenantRoutingKey builds the topic routing key for an event of a tenant:
+// tenant.<tenant_id>.event.<type>.<severity>. Bindings to "event.#" do not match it, so the
+// events of a tenant only reach the queues bound to that tenant.
+func TenantRoutingKey(event *models.Event) string {
+	return "tenant." + event.TenantID + "." + EventRoutingKey(event)
+}
+
+// TenantPattern matches every routing key produced by TenantRoutingKey for a tenant
+func TenantPattern(tenantID string) string {
+	return "tenant." + tenantID + ".#"
+}
+
+// routingKeyWord normalizes a value into a single routing key word
+func routingKeyWord(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return "unknown"
+	}
+	return strings.NewReplacer(".", "_", "*", "_", "#", "_", " ", "_").Replace(value)
+}
+
+// declareTopicExchange declares the durable topic exchange used in topic mode
+func (rq *RabbitMQQueue) declareTopicExchange() error {
+	err := rq.currentChannel().ExchangeDeclare(
+		rq.exchange, // name
+		"topic",     // type
+		true,        // durable
+		false,       // auto-deleted
+		false,       // internal
+		false,       // no-wait
+		nil,         // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare exchange: %w", err)
+	}
+	return nil
+}
+
+// BindQueue declares a queue and binds it to the topic exchange with a routing pattern
+// such as "event.login.*" or "event.*.critical"
+func (rq *RabbitMQQueue) BindQueue(queueName, pattern string) error {
+	if rq.exchangeMode != ExchangeModeTopic {
+		return fmt.Errorf("queue binding requires %s exchange mode", ExchangeModeTopic)
+	}
+
+	_, err := rq.declareQueue(queueName)
+	if err != nil {
+		return fmt.Errorf("failed to declare queue: %w", err)
+	}
+
+	if err := rq.currentChannel().QueueBind(queueName, pattern, rq.exchange, false, nil); err != nil {
+		return fmt.Errorf("failed to bind queue: %w", err)
+	}
+
+	rq.logger.Info("Bound queue to exchange", logger.Fields{"queue": queueName, "exchange": rq.exchange, "pattern": pattern})
+	return nil
+}
+
+// publishToTopic publishes an event message to the topic exchange. The target queue is
+// bound to every event so existing consumers keep receiving all events. With tenant queue
+// naming enabled, the events of a tenant use TenantRoutingKey and their tenant queue is bound
+// to the events of that tenant only.
+func (rq *RabbitMQQueue) publishToTopic(ctx context.Context, message Message, event *models.Event, queueName string) (err error) {
+	routingKey, pattern := EventRoutingKey(event), allEventsPattern
+	if rq.tenantQueues && event.TenantID != "" {
+		routingKey, pattern = TenantRoutingKey(event), TenantPattern(event.TenantID)
+	}
+
+	if _, bound := rq.boundQueues.Load(queueName); !bound {
+		if err := rq.BindQueue(queueName, pattern); err != nil {
+			return err
+		}
+		rq.boundQueues.Store(queueName, true)
+	}
+
+	ctx, span := startPublishSpan(ctx, message, rq.exchange)
+	defer func() { endSpan(span, err) }()
+
+	if err := rq.publish(ctx, rq.exchange, routingKey, message); err != nil {
+		return err
+	}
+
+	rq.logger.Info("Published message", logger.Fields{"message_id": message.ID, "exchange": rq.exchange, "routing_key": routingKey})
+	return nil
+}
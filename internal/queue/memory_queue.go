@@ -0,0 +1,325 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"skyhawk-security-microservice/internal/logger"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// ErrQueueClosed is returned by MemoryQueue operations after Close
+var ErrQueueClosed = errors.New("queue is closed")
+
+// MemoryQueue implements QueueInterface in process, for tests and local runs without a broker.
+// Queues are FIFO, and every published message and purge is also recorded for assertions.
+type MemoryQueue struct {
+	mu        sync.Mutex
+	queues    map[string][]Message
+	published map[string][]Message
+	purged    []string
+	// changed is closed and replaced whenever a message is published or the queue closes
+	changed chan struct{}
+	closed  bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	*EventProcessor
+}
+
+// NewMemoryQueue creates an empty in-memory queue
+func NewMemoryQueue() *MemoryQueue {
+	ctx, cancel := context.WithCancel(context.Background())
+	queue := &MemoryQueue{
+		queues:         make(map[string][]Message),
+		published:      make(map[string][]Message),
+		changed:        make(chan struct{}),
+		ctx:            ctx,
+		cancel:         cancel,
+		EventProcessor: NewEventProcessor(ctx),
+	}
+	queue.EventProcessor.publisher = queue
+	return queue
+}
+
+// PublishMessage appends a message to a queue. The message goes through a JSON round trip
+// so consumers see the same shape a broker would deliver.
+func (mq *MemoryQueue) PublishMessage(message Message, queueName string) error {
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+	var stored Message
+	if err := json.Unmarshal(messageBytes, &stored); err != nil {
+		return fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	if mq.closed {
+		return ErrQueueClosed
+	}
+
+	mq.queues[queueName] = append(mq.queues[queueName], stored)
+	mq.published[queueName] = append(mq.published[queueName], stored)
+	close(mq.changed)
+	mq.changed = make(chan struct{})
+
+	mq.logger.Debug("Published message", logger.Fields{"message_id": message.ID, "queue": queueName})
+	return nil
+}
+
+// PublishEvent publishes an event to the queue
+func (mq *MemoryQueue) PublishEvent(event *models.Event, queueName string, opts ...PublishOption) error {
+	return mq.PublishMessage(NewEventMessage(event, opts...), queueName)
+}
+
+// ConsumeMessage removes and returns the oldest message of a queue, waiting up to timeout
+// for one to be published
+func (mq *MemoryQueue) ConsumeMessage(queueName string, timeout time.Duration) (*Message, error) {
+	return mq.consumeMessage(queueName, timeout, nil)
+}
+
+// consumeMessage is ConsumeMessage giving up early, without a message, when interrupt is closed
+func (mq *MemoryQueue) consumeMessage(queueName string, timeout time.Duration, interrupt <-chan struct{}) (*Message, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		mq.mu.Lock()
+		if mq.closed {
+			mq.mu.Unlock()
+			return nil, ErrQueueClosed
+		}
+		if messages := mq.queues[queueName]; len(messages) > 0 {
+			message := messages[0]
+			mq.queues[queueName] = messages[1:]
+			mq.mu.Unlock()
+			return &message, nil
+		}
+		changed := mq.changed
+		mq.mu.Unlock()
+
+		select {
+		case <-changed:
+		case <-interrupt:
+			return nil, fmt.Errorf("interrupted waiting for message")
+		case <-deadline.C:
+			return nil, fmt.Errorf("timeout waiting for message")
+		}
+	}
+}
+
+// StartConsumer processes messages one at a time until the queue is closed or config.Stop
+// is closed. Failed messages
+// move to the retry queue, and to the dead letter queue after three attempts.
+func (mq *MemoryQueue) StartConsumer(queueName string, workerID int, config ConsumerConfig) {
+	workerLogger := mq.logger.WithFields(logger.Fields{"worker_id": workerID, "queue": queueName})
+	workerLogger.Info("Starting in-memory consumer worker")
+	defer config.Pause.register(queueName, workerID)()
+
+	for {
+		select {
+		case <-config.Stop:
+			workerLogger.Info("Consumer worker stopped")
+			return
+		default:
+		}
+
+		// Wait out a pause; a stop or close is handled on the next iteration
+		if !config.Pause.wait(config.Stop, mq.ctx.Done()) {
+			continue
+		}
+
+		// Stop waiting for a message when paused meanwhile, so none is taken during the pause
+		paused, pauseChanged := config.Pause.state()
+		if paused {
+			continue
+		}
+		message, err := mq.consumeMessage(queueName, time.Second, pauseChanged)
+		if errors.Is(err, ErrQueueClosed) {
+			workerLogger.Info("Consumer worker stopping")
+			return
+		}
+		if err != nil {
+			continue
+		}
+
+		msgLogger := messageLogger(workerLogger, message)
+
+		if !meetsMinSeverity(message, config.MinSeverity) {
+			msgLogger.Debug("Skipping message below minimum severity", logger.Fields{"message_id": message.ID, "min_severity": config.MinSeverity})
+			continue
+		}
+
+		start := time.Now()
+		err = mq.processWithTimeout(mq.ctx, queueName, message, config)
+		if errors.Is(err, context.Canceled) {
+			// Only Close cancels the context, and queued messages do not outlive it
+			msgLogger.Warn("Processing cancelled, dropping message", logger.Fields{"message_id": message.ID})
+			continue
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			recordTimeout(msgLogger, queueName, message, time.Since(start))
+			message.markFailed(timeoutError(config, err))
+			if err := mq.PublishMessage(*message, queueName+"_dead"); err != nil {
+				msgLogger.Error("Failed to move message to dead letter queue", err, logger.Fields{"message_id": message.ID})
+			}
+			continue
+		}
+		if err != nil {
+			msgLogger.Error("Error processing message", err, logger.Fields{"message_id": message.ID})
+
+			message.Retries++
+			target := queueName + "_retry"
+			if message.Retries >= 3 {
+				target = queueName + "_dead"
+				message.markFailed(err)
+			}
+			if err := mq.PublishMessage(*message, target); err != nil {
+				msgLogger.Error("Failed to requeue message", err, logger.Fields{"message_id": message.ID})
+			}
+		}
+	}
+}
+
+// Published returns every message published to a queue, including consumed ones, in publish order
+func (mq *MemoryQueue) Published(queueName string) []Message {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	return append([]Message(nil), mq.published[queueName]...)
+}
+
+// Reset drops all queued messages and recorded calls
+func (mq *MemoryQueue) Reset() {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	mq.queues = make(map[string][]Message)
+	mq.published = make(map[string][]Message)
+	mq.purged = nil
+}
+
+// Purges returns the names of the queues purged, in call order
+func (mq *MemoryQueue) Purges() []string {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	return append([]string(nil), mq.purged...)
+}
+
+// PurgeQueue drops the messages waiting in a queue; Published still records them
+func (mq *MemoryQueue) PurgeQueue(queueName string) (int, error) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	if mq.closed {
+		return 0, ErrQueueClosed
+	}
+	purged := len(mq.queues[queueName])
+	delete(mq.queues, queueName)
+	mq.purged = append(mq.purged, queueName)
+	return purged, nil
+}
+
+// PeekMessage returns the next message waiting in a queue without removing it
+func (mq *MemoryQueue) PeekMessage(queueName string) (*Message, error) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	if mq.closed {
+		return nil, ErrQueueClosed
+	}
+	messages := mq.queues[queueName]
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	message := messages[0]
+	return &message, nil
+}
+
+// ReplayDeadLetters moves up to limit messages from srcQueue to dstQueue with their retry count reset
+func (mq *MemoryQueue) ReplayDeadLetters(ctx context.Context, srcQueue, dstQueue string, limit int) (int, error) {
+	replayed := 0
+	for replayed < limit {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+
+		mq.mu.Lock()
+		if mq.closed {
+			mq.mu.Unlock()
+			return replayed, ErrQueueClosed
+		}
+		messages := mq.queues[srcQueue]
+		if len(messages) == 0 {
+			mq.mu.Unlock()
+			break
+		}
+		message := messages[0]
+		mq.queues[srcQueue] = messages[1:]
+		mq.mu.Unlock()
+
+		message.Retries = 0
+		message.clearFailure()
+		if err := mq.PublishMessage(message, dstQueue); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+
+	return replayed, nil
+}
+
+// GetQueueLength returns the number of messages waiting in a queue
+func (mq *MemoryQueue) GetQueueLength(queueName string) (int64, error) {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	if mq.closed {
+		return 0, ErrQueueClosed
+	}
+	return int64(len(mq.queues[queueName])), nil
+}
+
+// GetQueueStats returns statistics about queues
+func (mq *MemoryQueue) GetQueueStats(queueNames ...string) map[string]interface{} {
+	stats := make(map[string]interface{})
+
+	for _, queueName := range queueNames {
+		length, err := mq.GetQueueLength(queueName)
+		if err != nil {
+			stats[queueName] = map[string]interface{}{
+				"error": err.Error(),
+			}
+			continue
+		}
+
+		stats[queueName] = map[string]interface{}{
+			"length": length,
+			"type":   "memory",
+		}
+	}
+
+	return stats
+}
+
+// Close stops consumers; later publishes and consumes return ErrQueueClosed
+func (mq *MemoryQueue) Close() error {
+	mq.mu.Lock()
+	defer mq.mu.Unlock()
+
+	if mq.closed {
+		return nil
+	}
+	mq.closed = true
+	mq.cancel()
+	close(mq.changed)
+	return nil
+}
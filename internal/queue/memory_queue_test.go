@@ -0,0 +1,91 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// stallingNotifier holds up event processing until its context is done
+type stallingNotifier struct{}
+
+func (stallingNotifier) Notify(ctx context.Context, event *models.Event) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestMemoryQueuePurgeQueue(t *testing.T) {
+	mq := NewMemoryQueue()
+	defer mq.Close()
+
+	for i := 0; i < 10; i++ {
+		message := Message{ID: fmt.Sprintf("msg-%d", i), Type: "security_event"}
+		if err := mq.PublishMessage(message, "security_events"); err != nil {
+			t.Fatalf("PublishMessage: %v", err)
+		}
+	}
+
+	purged, err := mq.PurgeQueue("security_events")
+	if err != nil {
+		t.Fatalf("PurgeQueue: %v", err)
+	}
+	if purged != 10 {
+		t.Errorf("purged = %d, want 10", purged)
+	}
+
+	length, err := mq.GetQueueLength("security_events")
+	if err != nil {
+		t.Fatalf("GetQueueLength: %v", err)
+	}
+	if length != 0 {
+		t.Errorf("queue length after purge = %d, want 0", length)
+	}
+
+	if purges := mq.Purges(); len(purges) != 1 || purges[0] != "security_events" {
+		t.Errorf("Purges() = %v, want [security_events]", purges)
+	}
+	if published := mq.Published("security_events"); len(published) != 10 {
+		t.Errorf("Published() recorded %d messages, want 10", len(published))
+	}
+}
+
+func TestMemoryQueuePurgeQueueAfterClose(t *testing.T) {
+	mq := NewMemoryQueue()
+	mq.Close()
+
+	if _, err := mq.PurgeQueue("security_events"); err != ErrQueueClosed {
+		t.Errorf("PurgeQueue after Close returned %v, want ErrQueueClosed", err)
+	}
+}
+
+func TestMemoryQueueDeadLettersMessageExceedingProcessingTimeout(t *testing.T) {
+	mq := NewMemoryQueue()
+	defer mq.Close()
+	mq.AddBlockingNotifier(stallingNotifier{})
+	timeouts := testutil.ToFloat64(processTimeouts.WithLabelValues("timeout_events"))
+
+	if err := mq.PublishMessage(NewEventMessage(testEvent("evt-1")), "timeout_events"); err != nil {
+		t.Fatalf("PublishMessage: %v", err)
+	}
+	stop := make(chan struct{})
+	defer close(stop)
+	go mq.StartConsumer("timeout_events", 1, ConsumerConfig{Stop: stop, ProcessingTimeout: 200 * time.Millisecond})
+
+	message, err := mq.ConsumeMessage("timeout_events_dead", 3*time.Second)
+	if err != nil {
+		t.Fatalf("no message dead-lettered: %v", err)
+	}
+	if message.ID != "evt-1" || message.Retries != 0 {
+		t.Errorf("dead-lettered %+v, want evt-1 without retries", message)
+	}
+	if got := testutil.ToFloat64(processTimeouts.WithLabelValues("timeout_events")); got != timeouts+1 {
+		t.Errorf("process_timeout_total = %v, want %v", got, timeouts+1)
+	}
+	if retried := mq.Published("timeout_events_retry"); len(retried) != 0 {
+		t.Errorf("retry queue received %d messages, want none", len(retried))
+	}
+}
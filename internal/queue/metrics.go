@@ -0,0 +1,12 @@
+package queue
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// processTimeouts counts messages abandoned because processing exceeded ConsumerConfig.ProcessingTimeout
+var processTimeouts = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "process_timeout_total",
+	Help: "Messages dead-lettered because processing exceeded the timeout.",
+}, []string{"queue"})
@@ -0,0 +1,193 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"skyhawk-security-microservice/internal/logger"
+)
+
+// orderedMaxAttempts is how many times an ordered consumer processes a failing message before
+// moving it to <queue>_dead. Failures are retried in place, since a retry queue would reorder them.
+const orderedMaxAttempts = 3
+
+// NATSOrderedConsumer consumes a NATS JetStream queue with ordered push subscriptions, which
+// deliver the messages of a subject strictly in stream order. Unlike the durable consumers of
+// NATSQueue, an ordered consumer is ephemeral, unshared and never acks: every ordered consumer
+// receives every message, so one process should run a single consumer per queue. It starts
+// at StartSequence, or with the messages published after it subscribes when that is 0.
+type NATSOrderedConsumer struct {
+	*NATSQueue
+	// StartSequence is the stream sequence ordered consumers start from; 0 delivers only new
+	// messages
+	StartSequence uint64
+
+	subsMu sync.Mutex
+	subs   []*nats.Subscription
+	// queueSubs holds the subscription of each queue consumed by StartConsumer
+	queueSubs map[string]*nats.Subscription
+}
+
+// NewNATSOrderedConsumer connects to NATS and ensures the JetStream stream exists
+func NewNATSOrderedConsumer(config NATSConfig, startSequence uint64) (*NATSOrderedConsumer, error) {
+	nq, err := NewNATSQueue(config)
+	if err != nil {
+		return nil, err
+	}
+	oc := &NATSOrderedConsumer{NATSQueue: nq, StartSequence: startSequence, queueSubs: make(map[string]*nats.Subscription)}
+	nq.queueLength = oc.pendingMessages
+	return oc, nil
+}
+
+// StartOrderedConsumer subscribes handler to subject with an ordered consumer. Messages are
+// handed to handler one at a time in stream order; the subscription ends when the consumer is
+// closed.
+func (oc *NATSOrderedConsumer) StartOrderedConsumer(subject string, handler func(msg *nats.Msg)) error {
+	sub, err := oc.subscribeOrdered(subject, handler)
+	if err != nil {
+		return err
+	}
+
+	oc.subsMu.Lock()
+	defer oc.subsMu.Unlock()
+	oc.subs = append(oc.subs, sub)
+	return nil
+}
+
+// subscribeOrdered creates an ordered push subscription on subject
+func (oc *NATSOrderedConsumer) subscribeOrdered(subject string, handler func(msg *nats.Msg)) (*nats.Subscription, error) {
+	opts := []nats.SubOpt{nats.OrderedConsumer(), nats.DeliverNew()}
+	if oc.StartSequence > 0 {
+		opts = []nats.SubOpt{nats.OrderedConsumer(), nats.StartSequence(oc.StartSequence)}
+	}
+
+	sub, err := oc.js.Subscribe(subject, handler, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start ordered consumer: %w", err)
+	}
+	return sub, nil
+}
+
+// StartConsumer processes a queue in stream order until the queue is closed or config.Stop is
+// closed. Messages are processed one at a time, so PoolSize and AckBuffer are ignored. A
+// failing message is retried in place and moved to <queue>_dead after orderedMaxAttempts.
+func (oc *NATSOrderedConsumer) StartConsumer(queueName string, workerID int, config ConsumerConfig) {
+	workerLogger := oc.logger.WithFields(logger.Fields{"worker_id": workerID, "queue": queueName, "ordered": true})
+	workerLogger.Info("Starting NATS ordered consumer worker")
+	defer config.Pause.register(queueName, workerID)()
+
+	sub, err := oc.subscribeOrdered(oc.subject(queueName), func(msg *nats.Msg) {
+		// Blocking the callback holds back later messages, which keeps them in order
+		if !config.Pause.wait(config.Stop, oc.ctx.Done()) {
+			return
+		}
+		oc.handleOrdered(msg, queueName, config, workerLogger)
+	})
+	if err != nil {
+		workerLogger.Error("Failed to start consuming", err)
+		return
+	}
+	oc.subsMu.Lock()
+	oc.queueSubs[queueName] = sub
+	oc.subsMu.Unlock()
+	defer func() {
+		oc.subsMu.Lock()
+		if oc.queueSubs[queueName] == sub {
+			delete(oc.queueSubs, queueName)
+		}
+		oc.subsMu.Unlock()
+	}()
+
+	select {
+	case <-oc.ctx.Done():
+	case <-config.Stop:
+	}
+	sub.Drain()
+	// Drain completes asynchronously; wait for the pending callback to finish
+	for sub.IsValid() {
+		time.Sleep(50 * time.Millisecond)
+	}
+	workerLogger.Info("Consumer worker stopping")
+}
+
+// handleOrdered processes one message of an ordered consumer, logging its stream sequence so
+// a consumer can be restarted from it
+func (oc *NATSOrderedConsumer) handleOrdered(msg *nats.Msg, queueName string, config ConsumerConfig, workerLogger *logger.Logger) {
+	var message Message
+	if err := json.Unmarshal(msg.Data, &message); err != nil {
+		workerLogger.Error("Failed to unmarshal message", err)
+		return // Malformed payloads will never succeed
+	}
+	msgLogger := messageLogger(workerLogger, &message)
+	if meta, err := msg.Metadata(); err == nil {
+		msgLogger = msgLogger.WithField("stream_sequence", meta.Sequence.Stream)
+	}
+
+	if !meetsMinSeverity(&message, config.MinSeverity) {
+		msgLogger.Debug("Skipping message below minimum severity", logger.Fields{"message_id": message.ID, "min_severity": config.MinSeverity})
+		return
+	}
+
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		err := oc.processWithTimeout(oc.ctx, queueName, &message, config)
+		if err == nil {
+			return
+		}
+		if errors.Is(err, context.Canceled) {
+			msgLogger.Warn("Processing cancelled, message not processed", logger.Fields{"message_id": message.ID})
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			recordTimeout(msgLogger, queueName, &message, time.Since(start))
+			message.markFailed(timeoutError(config, err))
+		} else {
+			msgLogger.Error("Error processing message", err, logger.Fields{"message_id": message.ID, "attempt": attempt})
+			if attempt < orderedMaxAttempts {
+				message.Retries++
+				continue
+			}
+			message.markFailed(err)
+		}
+
+		if err := oc.PublishMessage(message, queueName+"_dead"); err != nil {
+			msgLogger.Error("Failed to move message to dead letter queue", err, logger.Fields{"message_id": message.ID})
+		}
+		return
+	}
+}
+
+// pendingMessages returns the number of messages the ordered consumer of a queue started by
+// StartConsumer has not received yet. Ordered consumers keep no shared position, so a process
+// that does not consume the queue, such as the API, reports 0.
+func (oc *NATSOrderedConsumer) pendingMessages(queueName string) (int64, error) {
+	oc.subsMu.Lock()
+	sub := oc.queueSubs[queueName]
+	oc.subsMu.Unlock()
+	if sub == nil {
+		return 0, nil
+	}
+
+	info, err := sub.ConsumerInfo()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get consumer info: %w", err)
+	}
+	return int64(info.NumPending), nil
+}
+
+// Close ends the ordered subscriptions and drains the NATS connection
+func (oc *NATSOrderedConsumer) Close() error {
+	oc.subsMu.Lock()
+	for _, sub := range oc.subs {
+		sub.Unsubscribe()
+	}
+	oc.subs = nil
+	oc.subsMu.Unlock()
+
+	return oc.NATSQueue.Close()
+}
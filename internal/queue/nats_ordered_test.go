@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// recordingNotifier records the IDs of the events it is notified about, in call order
+type recordingNotifier struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, event *models.Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.ids = append(n.ids, event.EventID)
+	return nil
+}
+
+func (n *recordingNotifier) IDs() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string(nil), n.ids...)
+}
+
+func TestNATSOrderedConsumerProcessesInStreamOrder(t *testing.T) {
+	url := startJetStream(t)
+	publisher, err := NewNATSQueue(NATSConfig{URL: url})
+	if err != nil {
+		t.Fatalf("NewNATSQueue: %v", err)
+	}
+	t.Cleanup(func() { publisher.Close() })
+
+	want := []string{"evt-1", "evt-2", "evt-3", "evt-4", "evt-5"}
+	for _, id := range want {
+		if err := publisher.PublishEvent(testEvent(id), "security_events"); err != nil {
+			t.Fatalf("PublishEvent: %v", err)
+		}
+	}
+
+	oc, err := NewNATSOrderedConsumer(NATSConfig{URL: url}, 1)
+	if err != nil {
+		t.Fatalf("NewNATSOrderedConsumer: %v", err)
+	}
+	t.Cleanup(func() { oc.Close() })
+	processed := &recordingNotifier{}
+	oc.AddBlockingNotifier(processed)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		oc.StartConsumer("security_events", 1, ConsumerConfig{Stop: stop})
+	}()
+
+	waitFor(t, 5*time.Second, "ordered consumer to process every message", func() bool {
+		return len(processed.IDs()) >= len(want)
+	})
+	close(stop)
+	<-done
+
+	got := processed.IDs()
+	if len(got) != len(want) {
+		t.Fatalf("processed %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("processed %v, want %v", got, want)
+		}
+	}
+}
+
+func TestNATSOrderedConsumerDeadLettersAfterRetriesInPlace(t *testing.T) {
+	url := startJetStream(t)
+	// The ordered consumer only reports the queues it consumes, so read the dead letter
+	// queue through a durable consumer
+	inspector, err := NewNATSQueue(NATSConfig{URL: url})
+	if err != nil {
+		t.Fatalf("NewNATSQueue: %v", err)
+	}
+	t.Cleanup(func() { inspector.Close() })
+
+	oc, err := NewNATSOrderedConsumer(NATSConfig{URL: url}, 1)
+	if err != nil {
+		t.Fatalf("NewNATSOrderedConsumer: %v", err)
+	}
+	t.Cleanup(func() { oc.Close() })
+	failing := &failingNotifier{failures: -1}
+	oc.AddBlockingNotifier(failing)
+
+	if err := oc.PublishEvent(testEvent("evt-1"), "security_events"); err != nil {
+		t.Fatalf("PublishEvent: %v", err)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		oc.StartConsumer("security_events", 1, ConsumerConfig{Stop: stop})
+	}()
+
+	waitFor(t, 5*time.Second, "message to be dead-lettered", func() bool {
+		length, err := inspector.GetQueueLength("security_events_dead")
+		return err == nil && length == 1
+	})
+	close(stop)
+	<-done
+
+	if calls := failing.Calls(); calls != orderedMaxAttempts {
+		t.Errorf("processed %d times, want %d", calls, orderedMaxAttempts)
+	}
+}
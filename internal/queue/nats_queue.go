@@ -0,0 +1,407 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"skyhawk-security-microservice/internal/logger"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// NATSConfig holds the JetStream settings for NATSQueue
+type NATSConfig struct {
+	URL     string
+	Stream  string
+	Subject string
+	Durable string
+}
+
+// natsMaxAttempts is how many times a failing message is processed before it moves to
+// <queue>_dead, matching the retry budget of the RabbitMQ consumers
+const natsMaxAttempts = 3
+
+// natsMaxDeliver bounds the deliveries of a message past natsMaxAttempts, so a message whose
+// dead letter publish keeps failing stops being redelivered rather than looping forever
+const natsMaxDeliver = natsMaxAttempts + 5
+
+// natsRetryBackoff delays the redelivery of a failed message, indexed by its attempt; later
+// attempts use the last delay
+var natsRetryBackoff = []time.Duration{time.Second, 10 * time.Second, 30 * time.Second}
+
+// natsFetchWait is how long a worker waits for a message before checking whether to stop
+const natsFetchWait = time.Second
+
+// NATSQueue implements queue using NATS JetStream. Each queue name maps to the
+// subject <Subject>.<queueName> within a single stream, consumed through one durable pull
+// consumer per queue that every worker and ConsumeMessage share.
+type NATSQueue struct {
+	nc     *nats.Conn
+	js     nats.JetStreamContext
+	config NATSConfig
+	ctx    context.Context
+	cancel context.CancelFunc
+	// queueLength reports the depth of a queue, for GetQueueLength, GetQueueStats and PurgeQueue
+	queueLength func(queueName string) (int64, error)
+
+	*EventProcessor
+}
+
+// NewNATSQueue connects to NATS and ensures the JetStream stream exists
+func NewNATSQueue(config NATSConfig) (*NATSQueue, error) {
+	if config.URL == "" {
+		config.URL = nats.DefaultURL
+	}
+	if config.Stream == "" {
+		config.Stream = "SECURITY_EVENTS"
+	}
+	if config.Subject == "" {
+		config.Subject = "security"
+	}
+	if config.Durable == "" {
+		config.Durable = "skyhawk-worker"
+	}
+
+	nc, err := nats.Connect(config.URL,
+		nats.Name("skyhawk-security-microservice"),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(2*time.Second),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	js, err := nc.JetStream()
+	if err != nil {
+		nc.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	if _, err := js.StreamInfo(config.Stream); err != nil {
+		if !errors.Is(err, nats.ErrStreamNotFound) {
+			nc.Close()
+			return nil, fmt.Errorf("failed to get stream info: %w", err)
+		}
+		_, err = js.AddStream(&nats.StreamConfig{
+			Name:     config.Stream,
+			Subjects: []string{config.Subject + ".>"},
+			Storage:  nats.FileStorage,
+		})
+		if err != nil {
+			nc.Close()
+			return nil, fmt.Errorf("failed to create stream: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	queue := &NATSQueue{
+		nc:             nc,
+		js:             js,
+		config:         config,
+		ctx:            ctx,
+		cancel:         cancel,
+		EventProcessor: NewEventProcessor(ctx),
+	}
+	queue.EventProcessor.publisher = queue
+	queue.queueLength = queue.pendingMessages
+
+	queue.logger.Info("Connected to NATS JetStream successfully")
+	return queue, nil
+}
+
+// subject returns the subject a queue name is published on
+func (nq *NATSQueue) subject(queueName string) string {
+	return nq.config.Subject + "." + queueName
+}
+
+// durableName returns the durable consumer name for a queue
+func (nq *NATSQueue) durableName(queueName string) string {
+	// Durable names may not contain dots
+	return strings.ReplaceAll(nq.config.Durable+"_"+queueName, ".", "_")
+}
+
+// PublishMessage publishes a message to a queue. The Nats-Msg-Id header lets
+// JetStream drop duplicate publishes of the same message attempt.
+func (nq *NATSQueue) PublishMessage(message Message, queueName string) error {
+	messageBytes, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	msg := nats.NewMsg(nq.subject(queueName))
+	msg.Data = messageBytes
+	msg.Header.Set("Content-Type", "application/json")
+
+	msgID := fmt.Sprintf("%s:%s:%d", message.ID, queueName, message.Retries)
+	if _, err := nq.js.PublishMsg(msg, nats.MsgId(msgID)); err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	nq.logger.Info("Published message", logger.Fields{"message_id": message.ID, "subject": msg.Subject})
+	return nil
+}
+
+// PublishEvent publishes an event to the queue
+func (nq *NATSQueue) PublishEvent(event *models.Event, queueName string, opts ...PublishOption) error {
+	return nq.PublishMessage(NewEventMessage(event, opts...), queueName)
+}
+
+// ensureConsumer creates the durable pull consumer of a queue unless it exists and returns
+// its info. It starts at the beginning of the stream, so messages published before the first
+// worker started are consumed too.
+func (nq *NATSQueue) ensureConsumer(queueName string) (*nats.ConsumerInfo, error) {
+	durable := nq.durableName(queueName)
+	info, err := nq.js.ConsumerInfo(nq.config.Stream, durable)
+	if err == nil {
+		return info, nil
+	}
+	if !errors.Is(err, nats.ErrConsumerNotFound) {
+		return nil, fmt.Errorf("failed to get consumer info: %w", err)
+	}
+
+	info, err = nq.js.AddConsumer(nq.config.Stream, &nats.ConsumerConfig{
+		Durable:       durable,
+		FilterSubject: nq.subject(queueName),
+		AckPolicy:     nats.AckExplicitPolicy,
+		DeliverPolicy: nats.DeliverAllPolicy,
+		MaxDeliver:    natsMaxDeliver,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer: %w", err)
+	}
+	return info, nil
+}
+
+// pullSubscribe binds a pull subscription to the durable consumer of a queue. Unsubscribing a
+// bound subscription leaves the consumer, and its position, in place.
+func (nq *NATSQueue) pullSubscribe(queueName string) (*nats.Subscription, error) {
+	info, err := nq.ensureConsumer(queueName)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := nq.js.PullSubscribe(nq.subject(queueName), info.Name, nats.Bind(nq.config.Stream, info.Name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe: %w", err)
+	}
+	return sub, nil
+}
+
+// ConsumeMessage consumes a single message from a queue, through the durable consumer the
+// workers share
+func (nq *NATSQueue) ConsumeMessage(queueName string, timeout time.Duration) (*Message, error) {
+	sub, err := nq.pullSubscribe(queueName)
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Unsubscribe()
+
+	msgs, err := sub.Fetch(1, nats.MaxWait(timeout))
+	if err != nil {
+		if errors.Is(err, nats.ErrTimeout) {
+			return nil, fmt.Errorf("timeout waiting for message")
+		}
+		return nil, fmt.Errorf("failed to fetch message: %w", err)
+	}
+
+	msg := msgs[0]
+	var message Message
+	if err := json.Unmarshal(msg.Data, &message); err != nil {
+		msg.Term() // Malformed payloads will never succeed
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+
+	msg.Ack()
+
+	nq.logger.Info("Consumed message", logger.Fields{"message_id": message.ID, "subject": msg.Subject})
+	return &message, nil
+}
+
+// StartConsumer fetches messages from the queue's durable pull consumer and processes them
+// until the queue is closed or config.Stop is closed, finishing the messages in flight first.
+// Workers share the consumer, and up to PoolSize messages of a worker are processed at once.
+// A failed message is redelivered after natsRetryBackoff and moved to <queue>_dead once it
+// failed natsMaxAttempts times; messages that time out are dead-lettered right away.
+func (nq *NATSQueue) StartConsumer(queueName string, workerID int, config ConsumerConfig) {
+	workerLogger := nq.logger.WithFields(logger.Fields{"worker_id": workerID, "queue": queueName})
+	workerLogger.Info("Starting NATS consumer worker")
+	defer config.Pause.register(queueName, workerID)()
+
+	sub, err := nq.pullSubscribe(queueName)
+	if err != nil {
+		workerLogger.Error("Failed to start consuming", err)
+		return
+	}
+	defer sub.Unsubscribe()
+
+	// A slot is taken before fetching, so no message waits unprocessed while its AckWait runs
+	slots := make(chan struct{}, config.poolSize())
+	var inFlight sync.WaitGroup
+	defer inFlight.Wait()
+
+	for {
+		// Wait out a pause; a stop or close is handled below
+		config.Pause.wait(config.Stop, nq.ctx.Done())
+
+		select {
+		case <-nq.ctx.Done():
+			workerLogger.Info("Consumer worker stopping")
+			return
+		case <-config.Stop:
+			workerLogger.Info("Consumer worker stopped")
+			return
+		case slots <- struct{}{}:
+		}
+
+		msgs, err := sub.Fetch(1, nats.MaxWait(natsFetchWait))
+		if err != nil || len(msgs) == 0 {
+			<-slots
+			if err != nil && !errors.Is(err, nats.ErrTimeout) && !errors.Is(err, context.DeadlineExceeded) {
+				workerLogger.Error("Failed to fetch message", err)
+				sleepContext(nq.ctx, natsFetchWait)
+			}
+			continue
+		}
+
+		inFlight.Add(1)
+		go func(msg *nats.Msg) {
+			defer func() {
+				<-slots
+				inFlight.Done()
+			}()
+			nq.handleMessage(msg, queueName, config, workerLogger)
+		}(msgs[0])
+	}
+}
+
+// handleMessage processes one delivery and settles it. A message is only acked once it was
+// processed or moved to the dead letter queue; when that move fails it is redelivered.
+func (nq *NATSQueue) handleMessage(msg *nats.Msg, queueName string, config ConsumerConfig, workerLogger *logger.Logger) {
+	var message Message
+	if err := json.Unmarshal(msg.Data, &message); err != nil {
+		workerLogger.Error("Failed to unmarshal message", err)
+		msg.Term() // Malformed payloads will never succeed
+		return
+	}
+	msgLogger := messageLogger(workerLogger, &message)
+
+	attempt := 1
+	if meta, err := msg.Metadata(); err == nil {
+		attempt = int(meta.NumDelivered)
+	}
+	message.Retries = attempt - 1
+
+	if !meetsMinSeverity(&message, config.MinSeverity) {
+		msgLogger.Debug("Skipping message below minimum severity", logger.Fields{"message_id": message.ID, "min_severity": config.MinSeverity})
+		msg.Ack()
+		return
+	}
+
+	start := time.Now()
+	err := nq.processWithTimeout(nq.ctx, queueName, &message, config)
+	switch {
+	case err == nil:
+		msg.Ack()
+	case errors.Is(err, context.Canceled):
+		msgLogger.Warn("Processing cancelled, requeuing message", logger.Fields{"message_id": message.ID})
+		msg.Nak() // Redeliver; the queue is shutting down
+	case errors.Is(err, context.DeadlineExceeded):
+		recordTimeout(msgLogger, queueName, &message, time.Since(start))
+		message.markFailed(timeoutError(config, err))
+		nq.deadLetter(msg, &message, queueName, msgLogger)
+	case attempt >= natsMaxAttempts:
+		msgLogger.Error("Error processing message", err, logger.Fields{"message_id": message.ID, "attempt": attempt})
+		message.markFailed(err)
+		nq.deadLetter(msg, &message, queueName, msgLogger)
+	default:
+		msgLogger.Error("Error processing message", err, logger.Fields{"message_id": message.ID, "attempt": attempt})
+		msg.NakWithDelay(natsRetryDelay(attempt))
+	}
+}
+
+// deadLetter moves a message to <queue>_dead and acks the delivery, or redelivers it when the
+// move fails so the message is not lost
+func (nq *NATSQueue) deadLetter(msg *nats.Msg, message *Message, queueName string, msgLogger *logger.Logger) {
+	if err := nq.PublishMessage(*message, queueName+"_dead"); err != nil {
+		msgLogger.Error("Failed to move message to dead letter queue", err, logger.Fields{"message_id": message.ID})
+		msg.NakWithDelay(natsRetryDelay(natsMaxAttempts))
+		return
+	}
+	msg.Ack()
+}
+
+// natsRetryDelay returns how long to wait before redelivering a message that failed attempt
+func natsRetryDelay(attempt int) time.Duration {
+	if attempt > len(natsRetryBackoff) {
+		attempt = len(natsRetryBackoff)
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+	return natsRetryBackoff[attempt-1]
+}
+
+// GetQueueLength returns the number of messages waiting in a queue
+func (nq *NATSQueue) GetQueueLength(queueName string) (int64, error) {
+	return nq.queueLength(queueName)
+}
+
+// pendingMessages returns the number of messages of a queue its consumer has not handed out
+// yet. Messages stay in the stream after they are acked, so the stream's message count only
+// grows and is not the depth of the queue.
+func (nq *NATSQueue) pendingMessages(queueName string) (int64, error) {
+	info, err := nq.ensureConsumer(queueName)
+	if err != nil {
+		return 0, err
+	}
+	return int64(info.NumPending), nil
+}
+
+// PurgeQueue removes the messages stored for a queue's subject from the stream
+func (nq *NATSQueue) PurgeQueue(queueName string) (int, error) {
+	length, err := nq.GetQueueLength(queueName)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := nq.js.PurgeStream(nq.config.Stream, &nats.StreamPurgeRequest{Subject: nq.subject(queueName)}); err != nil {
+		return 0, fmt.Errorf("failed to purge stream: %w", err)
+	}
+
+	nq.logger.Warn("Purged queue", logger.Fields{"queue": queueName, "purged": length})
+	return int(length), nil
+}
+
+// GetQueueStats returns statistics about queues
+func (nq *NATSQueue) GetQueueStats(queueNames ...string) map[string]interface{} {
+	stats := make(map[string]interface{})
+
+	for _, queueName := range queueNames {
+		length, err := nq.GetQueueLength(queueName)
+		if err != nil {
+			stats[queueName] = map[string]interface{}{
+				"error": err.Error(),
+			}
+			continue
+		}
+
+		stats[queueName] = map[string]interface{}{
+			"length": length,
+			"type":   "nats",
+		}
+	}
+
+	return stats
+}
+
+// Close drains the NATS connection, letting in-flight messages finish
+func (nq *NATSQueue) Close() error {
+	nq.cancel()
+	return nq.nc.Drain()
+}
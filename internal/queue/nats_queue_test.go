@@ -0,0 +1,224 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// startJetStream runs an in-process NATS server with JetStream enabled for the test
+func startJetStream(t *testing.T) string {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		t.Fatalf("failed to create NATS server: %v", err)
+	}
+	go srv.Start()
+	if !srv.ReadyForConnections(10 * time.Second) {
+		t.Fatal("NATS server did not start")
+	}
+	t.Cleanup(srv.Shutdown)
+	return srv.ClientURL()
+}
+
+func newTestNATSQueue(t *testing.T) *NATSQueue {
+	t.Helper()
+	nq, err := NewNATSQueue(NATSConfig{URL: startJetStream(t)})
+	if err != nil {
+		t.Fatalf("NewNATSQueue: %v", err)
+	}
+	t.Cleanup(func() { nq.Close() })
+	return nq
+}
+
+// shortNATSBackoff shortens the redelivery delays for the duration of the test
+func shortNATSBackoff(t *testing.T) {
+	t.Helper()
+	saved := natsRetryBackoff
+	natsRetryBackoff = []time.Duration{10 * time.Millisecond}
+	t.Cleanup(func() { natsRetryBackoff = saved })
+}
+
+// failingNotifier fails the first failures notifications and records every event it sees
+type failingNotifier struct {
+	mu       sync.Mutex
+	failures int
+	calls    int
+}
+
+func (n *failingNotifier) Notify(ctx context.Context, event *models.Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.calls++
+	if n.failures < 0 || n.calls <= n.failures {
+		return errors.New("notification failed")
+	}
+	return nil
+}
+
+func (n *failingNotifier) Calls() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.calls
+}
+
+func testEvent(id string) *models.Event {
+	return &models.Event{EventID: id, EventType: "login_failure", Severity: "high", Source: "auth-service"}
+}
+
+// waitFor polls cond until it holds or the timeout passes
+func waitFor(t *testing.T, timeout time.Duration, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestNATSQueuePublishConsumeRoundTrip(t *testing.T) {
+	nq := newTestNATSQueue(t)
+
+	if err := nq.PublishEvent(testEvent("evt-1"), "security_events"); err != nil {
+		t.Fatalf("PublishEvent: %v", err)
+	}
+	if length, err := nq.GetQueueLength("security_events"); err != nil || length != 1 {
+		t.Fatalf("GetQueueLength = %d, %v; want 1", length, err)
+	}
+
+	message, err := nq.ConsumeMessage("security_events", 2*time.Second)
+	if err != nil {
+		t.Fatalf("ConsumeMessage: %v", err)
+	}
+	if message.ID != "evt-1" {
+		t.Errorf("consumed message %s, want evt-1", message.ID)
+	}
+
+	// Acked messages stay in the stream but no longer count as waiting
+	if length, err := nq.GetQueueLength("security_events"); err != nil || length != 0 {
+		t.Errorf("GetQueueLength after ack = %d, %v; want 0", length, err)
+	}
+	if _, err := nq.ConsumeMessage("security_events", 200*time.Millisecond); err == nil {
+		t.Error("ConsumeMessage returned the acked message again")
+	}
+}
+
+func TestNATSQueueConsumeMessageSharesWorkerConsumer(t *testing.T) {
+	nq := newTestNATSQueue(t)
+	processed := &failingNotifier{}
+	nq.AddBlockingNotifier(processed)
+
+	for _, id := range []string{"evt-1", "evt-2"} {
+		if err := nq.PublishEvent(testEvent(id), "security_events"); err != nil {
+			t.Fatalf("PublishEvent: %v", err)
+		}
+	}
+
+	// ConsumeMessage takes the first message, so the worker only processes the second
+	if _, err := nq.ConsumeMessage("security_events", 2*time.Second); err != nil {
+		t.Fatalf("ConsumeMessage: %v", err)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		nq.StartConsumer("security_events", 1, ConsumerConfig{Stop: stop})
+	}()
+	waitFor(t, 5*time.Second, "the worker to process a message", func() bool { return processed.Calls() == 1 })
+	time.Sleep(300 * time.Millisecond)
+	close(stop)
+	<-done
+
+	if calls := processed.Calls(); calls != 1 {
+		t.Errorf("worker processed %d messages, want 1", calls)
+	}
+	if _, err := nq.js.ConsumerInfo(nq.config.Stream, nq.durableName("security_events")); err != nil {
+		t.Errorf("durable consumer was removed: %v", err)
+	}
+}
+
+func TestNATSQueueRedeliversFailedMessage(t *testing.T) {
+	shortNATSBackoff(t)
+	nq := newTestNATSQueue(t)
+	flaky := &failingNotifier{failures: 1}
+	nq.AddBlockingNotifier(flaky)
+
+	if err := nq.PublishEvent(testEvent("evt-1"), "security_events"); err != nil {
+		t.Fatalf("PublishEvent: %v", err)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		nq.StartConsumer("security_events", 1, ConsumerConfig{Stop: stop})
+	}()
+	defer func() {
+		close(stop)
+		<-done
+	}()
+
+	waitFor(t, 5*time.Second, "the message to be redelivered", func() bool { return flaky.Calls() >= 2 })
+	waitFor(t, 5*time.Second, "the queue to drain", func() bool {
+		length, err := nq.GetQueueLength("security_events")
+		return err == nil && length == 0
+	})
+	if length, _ := nq.GetQueueLength("security_events_dead"); length != 0 {
+		t.Errorf("dead letter queue holds %d messages, want 0", length)
+	}
+}
+
+func TestNATSQueueDeadLettersPoisonMessage(t *testing.T) {
+	shortNATSBackoff(t)
+	nq := newTestNATSQueue(t)
+	poison := &failingNotifier{failures: -1}
+	nq.AddBlockingNotifier(poison)
+
+	if err := nq.PublishEvent(testEvent("evt-1"), "security_events"); err != nil {
+		t.Fatalf("PublishEvent: %v", err)
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		nq.StartConsumer("security_events", 1, ConsumerConfig{Stop: stop})
+	}()
+
+	waitFor(t, 10*time.Second, "the message to be dead-lettered", func() bool {
+		length, err := nq.GetQueueLength("security_events_dead")
+		return err == nil && length == 1
+	})
+	// Give a redelivery loop time to show
+	time.Sleep(300 * time.Millisecond)
+	close(stop)
+	<-done
+
+	if calls := poison.Calls(); calls != natsMaxAttempts {
+		t.Errorf("message was processed %d times, want %d", calls, natsMaxAttempts)
+	}
+
+	dead, err := nq.ConsumeMessage("security_events_dead", 2*time.Second)
+	if err != nil {
+		t.Fatalf("ConsumeMessage from dead letter queue: %v", err)
+	}
+	if dead.ID != "evt-1" || dead.LastError == "" || dead.FailedAt == nil {
+		t.Errorf("dead letter = %+v, want evt-1 with its failure recorded", dead)
+	}
+}
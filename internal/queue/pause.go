@@ -0,0 +1,136 @@
+package queue
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+)
+
+// PauseSwitch pauses and resumes the consumers sharing it through ConsumerConfig.Pause. A paused
+// consumer finishes the messages it is processing and then takes no deliveries, and acks
+// nothing, until it is resumed. Consumers register while running, so the switch knows which
+// workers a pause affects.
+type PauseSwitch struct {
+	mu     sync.Mutex
+	paused bool
+	// changed is closed and replaced whenever the switch is paused or resumed
+	changed chan struct{}
+	// workers counts the running consumers by "<queue>/<worker ID>"
+	workers map[string]int
+}
+
+// NewPauseSwitch creates a switch that starts resumed
+func NewPauseSwitch() *PauseSwitch {
+	return &PauseSwitch{
+		changed: make(chan struct{}),
+		workers: make(map[string]int),
+	}
+}
+
+// Pause pauses the consumers; it returns false when they were already paused
+func (s *PauseSwitch) Pause() bool {
+	return s.set(true)
+}
+
+// Resume resumes the consumers; it returns false when they were not paused
+func (s *PauseSwitch) Resume() bool {
+	return s.set(false)
+}
+
+// set switches to paused and wakes the consumers waiting on the current state
+func (s *PauseSwitch) set(paused bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paused == paused {
+		return false
+	}
+	s.paused = paused
+	close(s.changed)
+	s.changed = make(chan struct{})
+	return true
+}
+
+// FollowSignals pauses the consumers on the pause signal and resumes them on the resume signal
+// until signals is closed, calling changed after every pause or resume
+func (s *PauseSwitch) FollowSignals(signals <-chan os.Signal, pause, resume os.Signal, changed func(paused bool)) {
+	for sig := range signals {
+		switch sig {
+		case pause:
+			if s.Pause() {
+				changed(true)
+			}
+		case resume:
+			if s.Resume() {
+				changed(false)
+			}
+		}
+	}
+}
+
+// Paused reports whether the consumers are paused
+func (s *PauseSwitch) Paused() bool {
+	paused, _ := s.state()
+	return paused
+}
+
+// Workers returns the running consumers as "<queue>/<worker ID>", sorted
+func (s *PauseSwitch) Workers() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	workers := make([]string, 0, len(s.workers))
+	for worker := range s.workers {
+		workers = append(workers, worker)
+	}
+	sort.Strings(workers)
+	return workers
+}
+
+// state returns whether the consumers are paused and a channel that is closed at the next
+// pause or resume. A nil switch is never paused and never changes.
+func (s *PauseSwitch) state() (bool, <-chan struct{}) {
+	if s == nil {
+		return false, nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paused, s.changed
+}
+
+// register records a running consumer and returns the function that forgets it
+func (s *PauseSwitch) register(queueName string, workerID int) func() {
+	if s == nil {
+		return func() {}
+	}
+	worker := fmt.Sprintf("%s/%d", queueName, workerID)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.workers[worker]++
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.workers[worker]--; s.workers[worker] == 0 {
+			delete(s.workers, worker)
+		}
+	}
+}
+
+// wait blocks while the consumers are paused. It returns false when stop or closed is closed
+// first.
+func (s *PauseSwitch) wait(stop, closed <-chan struct{}) bool {
+	for {
+		paused, changed := s.state()
+		if !paused {
+			return true
+		}
+		select {
+		case <-changed:
+		case <-stop:
+			return false
+		case <-closed:
+			return false
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package queue
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// awaitPauseChange returns the next state reported by a PauseSwitch.FollowSignals callback
+func awaitPauseChange(t *testing.T, changes <-chan bool) bool {
+	t.Helper()
+	select {
+	case paused := <-changes:
+		return paused
+	case <-time.After(5 * time.Second):
+		t.Fatal("signal did not pause or resume the consumers")
+		return false
+	}
+}
+
+func TestSIGUSR1PausesConsumersUntilSIGUSR2(t *testing.T) {
+	mq := NewMemoryQueue()
+	defer mq.Close()
+	processed := &countingNotifier{}
+	mq.AddBlockingNotifier(processed)
+
+	pause := NewPauseSwitch()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	defer func() {
+		signal.Stop(signals)
+		close(signals)
+	}()
+	changes := make(chan bool, 2)
+	go pause.FollowSignals(signals, syscall.SIGUSR1, syscall.SIGUSR2, func(paused bool) { changes <- paused })
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		mq.StartConsumer("security_events", 1, ConsumerConfig{Stop: stop, Pause: pause})
+	}()
+	defer func() {
+		close(stop)
+		<-stopped
+	}()
+
+	if err := mq.PublishEvent(testEvent("evt-0"), "security_events"); err != nil {
+		t.Fatalf("PublishEvent: %v", err)
+	}
+	waitFor(t, 5*time.Second, "the first message to be processed", func() bool { return processed.count.Load() == 1 })
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("send SIGUSR1: %v", err)
+	}
+	if !awaitPauseChange(t, changes) {
+		t.Fatal("SIGUSR1 resumed the consumers")
+	}
+	for i := 1; i <= 3; i++ {
+		if err := mq.PublishEvent(testEvent(fmt.Sprintf("evt-%d", i)), "security_events"); err != nil {
+			t.Fatalf("PublishEvent: %v", err)
+		}
+	}
+
+	// Outlast the consumer's one second wait for a message
+	time.Sleep(1500 * time.Millisecond)
+	if got := processed.count.Load(); got != 1 {
+		t.Errorf("%d messages processed while paused, want none", got-1)
+	}
+	if depth, _ := mq.GetQueueLength("security_events"); depth != 3 {
+		t.Errorf("queue depth while paused = %d, want 3", depth)
+	}
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatalf("send SIGUSR2: %v", err)
+	}
+	if awaitPauseChange(t, changes) {
+		t.Fatal("SIGUSR2 paused the consumers")
+	}
+	waitFor(t, 5*time.Second, "the held messages to be processed after resuming", func() bool { return processed.count.Load() == 4 })
+}
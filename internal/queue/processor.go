@@ -0,0 +1,509 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"skyhawk-security-microservice/internal/logger"
+	"skyhawk-security-microservice/internal/metrics"
+	"skyhawk-security-microservice/internal/models"
+	"skyhawk-security-microservice/internal/notifier"
+)
+
+// DefaultSeverityLogLevels maps event severities to the level used when logging their processing
+var DefaultSeverityLogLevels = map[string]logger.Level{
+	models.SeverityLow:      logger.INFO,
+	models.SeverityMedium:   logger.INFO,
+	models.SeverityHigh:     logger.WARN,
+	models.SeverityCritical: logger.ERROR,
+}
+
+// ParseSeverityLogLevels parses a mapping such as "critical=ERROR,high=WARN" on top of the defaults
+func ParseSeverityLogLevels(spec string) (map[string]logger.Level, error) {
+	levels := make(map[string]logger.Level, len(DefaultSeverityLogLevels))
+	for severity, level := range DefaultSeverityLogLevels {
+		levels[severity] = level
+	}
+
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		severity, levelName, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid severity log level mapping: %s", pair)
+		}
+		severity = strings.ToLower(strings.TrimSpace(severity))
+		if models.SeverityLevel(severity) == 0 {
+			return nil, fmt.Errorf("unknown severity: %s", severity)
+		}
+
+		level, err := logger.ParseLevel(levelName)
+		if err != nil {
+			return nil, err
+		}
+		levels[severity] = level
+	}
+
+	return levels, nil
+}
+
+// Aggregator absorbs repeated events into periodic aggregate events
+type Aggregator interface {
+	Add(event *models.Event) bool
+}
+
+// Observer receives every processed event, e.g. for rate based alerting
+type Observer interface {
+	Observe(event *models.Event)
+}
+
+// ProcessingRecorder stores every delivery of an event to a consumer, e.g. for the event timeline
+type ProcessingRecorder interface {
+	RecordProcessing(ctx context.Context, entry models.ProcessingLogEntry) error
+}
+
+// ProcessedMessageStore records processed messages in the database transaction of their side
+// effects, so a message counts as processed exactly when its side effects committed
+type ProcessedMessageStore interface {
+	// ProcessOnce begins a transaction, records messageID as processed by queueName in it and
+	// calls process with it, committing when process succeeds and rolling back otherwise. ran
+	// is false, without calling process, when messageID is recorded already. While another
+	// transaction holds an uncommitted record of messageID, ProcessOnce waits for its outcome.
+	ProcessOnce(ctx context.Context, queueName, messageID string, process func(tx *sql.Tx) error) (ran bool, err error)
+}
+
+// TxNotifier is a blocking notifier whose side effect is a database write. With
+// ConsumerConfig.ProcessedMessages set, tx is the transaction recording the message processed,
+// so the write and the record commit together; otherwise tx is nil and the write stands alone.
+type TxNotifier interface {
+	NotifyTx(ctx context.Context, tx *sql.Tx, event *models.Event) error
+}
+
+// EventProcessor holds the backend independent processing logic shared by queue consumers
+type EventProcessor struct {
+	ctx               context.Context
+	recorder          ProcessingRecorder
+	notifiers         []notifier.Notifier
+	blockingNotifiers []notifier.Notifier
+	txNotifiers       []TxNotifier
+	aggregator        Aggregator
+	observers         []Observer
+	logger            *logger.Logger
+	severityLogLevels map[string]logger.Level
+	// publisher is the queue that owns the processor, used to emit processed messages
+	publisher messagePublisher
+	// alertingDisabled skips the notifiers of processed events
+	alertingDisabled bool
+}
+
+// messagePublisher publishes a message to a named queue
+type messagePublisher interface {
+	PublishMessage(message Message, queueName string) error
+}
+
+// NewEventProcessor creates an event processor; ctx bounds background notifications
+func NewEventProcessor(ctx context.Context) *EventProcessor {
+	return &EventProcessor{
+		ctx:               ctx,
+		logger:            logger.GetLogger(),
+		severityLogLevels: DefaultSeverityLogLevels,
+	}
+}
+
+// AddNotifier registers a notifier that is called for every processed event
+func (p *EventProcessor) AddNotifier(n notifier.Notifier) {
+	p.notifiers = append(p.notifiers, n)
+}
+
+// AddBlockingNotifier registers a notifier that must succeed before an event counts as
+// processed. Failures are returned from ProcessEvent so the message takes the retry path.
+func (p *EventProcessor) AddBlockingNotifier(n notifier.Notifier) {
+	p.blockingNotifiers = append(p.blockingNotifiers, n)
+}
+
+// AddTxNotifier registers a notifier that writes to the database in the transaction recording
+// the message processed. Like blocking notifiers, a failure fails the message.
+func (p *EventProcessor) AddTxNotifier(n TxNotifier) {
+	p.txNotifiers = append(p.txNotifiers, n)
+}
+
+// SetAlertingEnabled turns the notifiers registered with AddNotifier on or off; they are on by
+// default. Blocking notifiers still run, since processing depends on them.
+func (p *EventProcessor) SetAlertingEnabled(enabled bool) {
+	p.alertingDisabled = !enabled
+}
+
+// SetAggregator feeds processed events into an aggregator
+func (p *EventProcessor) SetAggregator(aggregator Aggregator) {
+	p.aggregator = aggregator
+}
+
+// AddObserver registers an observer that sees every processed event
+func (p *EventProcessor) AddObserver(observer Observer) {
+	p.observers = append(p.observers, observer)
+}
+
+// SetProcessingRecorder records the start, end and outcome of processing every message
+func (p *EventProcessor) SetProcessingRecorder(recorder ProcessingRecorder) {
+	p.recorder = recorder
+}
+
+// SetSeverityLogLevels overrides the severity to log level mapping
+func (p *EventProcessor) SetSeverityLogLevels(levels map[string]logger.Level) {
+	p.severityLogLevels = levels
+}
+
+// logLevelFor returns the log level for an event severity, defaulting to INFO
+func (p *EventProcessor) logLevelFor(severity string) logger.Level {
+	if level, ok := p.severityLogLevels[strings.ToLower(severity)]; ok {
+		return level
+	}
+	return logger.INFO
+}
+
+// ProcessEvent processes a security event message, giving up once ctx is done
+func (p *EventProcessor) ProcessEvent(ctx context.Context, message *Message) error {
+	return p.ProcessEventTx(ctx, nil, message)
+}
+
+// ProcessEventTx processes a security event message like ProcessEvent, handing tx to the
+// registered TxNotifiers. tx may be nil.
+func (p *EventProcessor) ProcessEventTx(ctx context.Context, tx *sql.Tx, message *Message) error {
+	msgLogger := messageLogger(p.logger, message)
+
+	// Extract event data
+	eventData, ok := message.Data["event"].(map[string]interface{})
+	if !ok {
+		msgLogger.Error("Invalid event data in message", nil, logger.Fields{"message_id": message.ID})
+		return fmt.Errorf("invalid event data in message")
+	}
+
+	eventType, _ := eventData["event_type"].(string)
+	severity, _ := eventData["severity"].(string)
+	level := p.logLevelFor(severity)
+	fields := func() logger.Fields {
+		return logger.Fields{
+			"event_id":   message.ID,
+			"event_type": eventType,
+			"severity":   severity,
+		}
+	}
+
+	msgLogger.Log(level, "Processing event", fields())
+
+	// Simulate processing time
+	if err := sleepContext(ctx, 100*time.Millisecond); err != nil {
+		return err
+	}
+
+	// Simulate different processing based on event type
+	var work time.Duration
+	switch eventType {
+	case "login":
+		// Simulate login processing
+		work = 50 * time.Millisecond
+	case "data_access":
+		// Simulate data access processing
+		work = 75 * time.Millisecond
+	case "file_access":
+		// Simulate file access processing
+		work = 60 * time.Millisecond
+	default:
+		msgLogger.Debug("Processing generic event", fields())
+	}
+	if err := sleepContext(ctx, work); err != nil {
+		return err
+	}
+
+	event, err := eventFromData(eventData)
+	if err != nil {
+		msgLogger.Error("Failed to decode event", err, fields())
+		return err
+	}
+
+	// Blocking notifiers run first so a failure does not repeat the other side effects on retry
+	for _, n := range p.txNotifiers {
+		if err := n.NotifyTx(ctx, tx, event); err != nil {
+			msgLogger.Error("Transactional notification failed", err, fields())
+			return fmt.Errorf("transactional notification failed: %w", err)
+		}
+	}
+	for _, n := range p.blockingNotifiers {
+		notifyCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		err := n.Notify(notifyCtx, event)
+		cancel()
+		if err != nil {
+			msgLogger.Error("Blocking notification failed", err, fields())
+			return fmt.Errorf("blocking notification failed: %w", err)
+		}
+	}
+
+	if p.aggregator != nil && p.aggregator.Add(event) {
+		msgLogger.Debug("Event added to aggregation window", fields())
+	}
+
+	for _, observer := range p.observers {
+		observer.Observe(event)
+	}
+
+	if !p.alertingDisabled {
+		p.notify(event)
+	}
+
+	msgLogger.Log(level, "Successfully processed event", fields())
+	return nil
+}
+
+// DefaultProcessingTimeout bounds ProcessEvent when ConsumerConfig.ProcessingTimeout is not set
+const DefaultProcessingTimeout = 30 * time.Second
+
+// processWithTimeout runs ProcessEvent with a context derived from the consumer's context
+// and the per-message deadline of config. It returns when processing finishes, the deadline
+// passes (context.DeadlineExceeded) or the consumer's context is cancelled (context.Canceled),
+// whichever comes first; processing that ignores its context keeps running in the background.
+// With config.ProcessedMessages set, the message is processed in the store's transaction, so it
+// is recorded processed together with the writes of the TxNotifiers, and skipped when it was
+// recorded before. The deadline bounds the transaction, which rolls back when it passes. With
+// config.ProcessedQueue set, a successful run is published there before it is recorded. The
+// attempt is recorded when a ProcessingRecorder is set.
+func (p *EventProcessor) processWithTimeout(parent context.Context, queueName string, message *Message, config ConsumerConfig) error {
+	ctx, cancel := context.WithTimeout(parent, config.processingTimeout())
+	defer cancel()
+
+	process := func(tx *sql.Tx) error {
+		if err := p.ProcessEventTx(ctx, tx, message); err != nil {
+			return err
+		}
+		if config.ProcessedQueue != "" {
+			return p.publishProcessed(queueName, message, config)
+		}
+		return nil
+	}
+
+	startedAt := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		if config.ProcessedMessages == nil {
+			done <- process(nil)
+			return
+		}
+		ran, err := config.ProcessedMessages.ProcessOnce(ctx, queueName, message.dedupKey(), process)
+		if err == nil && !ran {
+			err = errDuplicateMessage
+		}
+		done <- err
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if errors.Is(err, errDuplicateMessage) {
+		messageLogger(p.logger, message).Info("Skipping message processed before", logger.Fields{"message_id": message.ID, "queue": queueName})
+		p.recordProcessing(parent, queueName, message, startedAt, err)
+		return nil
+	}
+
+	observeProcessing(message, time.Since(startedAt), err)
+	p.recordProcessing(parent, queueName, message, startedAt, err)
+	return err
+}
+
+// ProcessedFromHeader is the header naming the queue a message published to a processed queue
+// was consumed from
+const ProcessedFromHeader = "X-Processed-From"
+
+// publishProcessed publishes a copy of a successfully processed message to config.ProcessedQueue.
+// A failure is returned when config.FailOnProcessedPublishError is set, so the message is
+// retried, and otherwise only logged.
+func (p *EventProcessor) publishProcessed(queueName string, message *Message, config ConsumerConfig) error {
+	processed := *message
+	processed.Retries = 0
+	processed.Headers = maps.Clone(message.Headers)
+	processed.SetHeader(ProcessedFromHeader, queueName)
+
+	err := p.publisher.PublishMessage(processed, config.ProcessedQueue)
+	if err == nil {
+		return nil
+	}
+	if config.FailOnProcessedPublishError {
+		return fmt.Errorf("failed to publish processed message: %w", err)
+	}
+	messageLogger(p.logger, message).Error("Failed to publish processed message", err, logger.Fields{"message_id": message.ID, "queue": config.ProcessedQueue})
+	return nil
+}
+
+// observeProcessing records the duration and outcome of processing message in the worker
+// metrics. Processing cut short by the consumer stopping is left out.
+func observeProcessing(message *Message, elapsed time.Duration, err error) {
+	status := metrics.ProcessingStatusSuccess
+	switch {
+	case errors.Is(err, context.Canceled):
+		return
+	case errors.Is(err, context.DeadlineExceeded):
+		status = metrics.ProcessingStatusTimeout
+	case err != nil:
+		status = metrics.ProcessingStatusFailure
+	}
+	metrics.ObserveProcessing(messageEventType(message), status, elapsed)
+}
+
+// errDuplicateMessage records a delivery skipped because its message was processed before
+var errDuplicateMessage = errors.New("message processed before")
+
+// recordProcessingTimeout bounds storing one processing attempt
+const recordProcessingTimeout = 5 * time.Second
+
+// recordProcessing hands one processing attempt to the recorder, if any. Attempts cut short by
+// the consumer stopping are still recorded, so the record outlives the cancellation of ctx.
+func (p *EventProcessor) recordProcessing(ctx context.Context, queueName string, message *Message, startedAt time.Time, err error) {
+	if p.recorder == nil {
+		return
+	}
+
+	entry := models.ProcessingLogEntry{
+		EventID:    message.ID,
+		Queue:      queueName,
+		Attempt:    message.Retries + 1,
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+		Outcome:    models.ProcessingOutcomeProcessed,
+	}
+	if !message.Timestamp.IsZero() {
+		entry.PublishedAt = &message.Timestamp
+	}
+	switch {
+	case errors.Is(err, errDuplicateMessage):
+		entry.Outcome = models.ProcessingOutcomeDuplicate
+		err = nil
+	case errors.Is(err, context.DeadlineExceeded):
+		entry.Outcome = models.ProcessingOutcomeTimedOut
+	case errors.Is(err, context.Canceled):
+		entry.Outcome = models.ProcessingOutcomeCancelled
+	case err != nil:
+		entry.Outcome = models.ProcessingOutcomeFailed
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordProcessingTimeout)
+	defer cancel()
+	if recordErr := p.recorder.RecordProcessing(ctx, entry); recordErr != nil {
+		p.logger.Error("Failed to record event processing", recordErr, logger.Fields{"message_id": message.ID})
+	}
+}
+
+// recordTimeout logs a message whose processing exceeded the timeout and counts it
+func recordTimeout(l *logger.Logger, queueName string, message *Message, elapsed time.Duration) {
+	l.Error("Message processing timed out, moving to dead letter queue", context.DeadlineExceeded, logger.Fields{
+		"message_id": message.ID,
+		"elapsed":    elapsed.String(),
+	})
+	processTimeouts.WithLabelValues(queueName).Inc()
+}
+
+// timeoutError describes a processing deadline exceeded under config
+func timeoutError(config ConsumerConfig, err error) error {
+	return fmt.Errorf("processing exceeded the %s timeout: %w", config.processingTimeout(), err)
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// notify fans the event out to the registered notifiers without blocking processing
+func (p *EventProcessor) notify(event *models.Event) {
+	for _, n := range p.notifiers {
+		go func(n notifier.Notifier) {
+			ctx, cancel := context.WithTimeout(p.ctx, 30*time.Second)
+			defer cancel()
+
+			if err := n.Notify(ctx, event); err != nil {
+				p.logger.Error("Failed to notify for event", err, logger.Fields{"event_id": event.EventID})
+			}
+		}(n)
+	}
+}
+
+// messageLogger returns l with the request ID of the message attached, when it has one
+func messageLogger(l *logger.Logger, message *Message) *logger.Logger {
+	if requestID := message.RequestID(); requestID != "" {
+		return l.WithField("request_id", requestID)
+	}
+	return l
+}
+
+// eventFromData converts the generic event map carried in a message back into an Event
+func eventFromData(eventData map[string]interface{}) (*models.Event, error) {
+	data, err := json.Marshal(eventData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event data: %w", err)
+	}
+
+	var event models.Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event data: %w", err)
+	}
+
+	return &event, nil
+}
+
+// messageEventType extracts the event type from a message, "unknown" when it has none
+func messageEventType(message *Message) string {
+	eventData, _ := message.Data["event"].(map[string]interface{})
+	if eventType, ok := eventData["event_type"].(string); ok && eventType != "" {
+		return eventType
+	}
+	return "unknown"
+}
+
+// messageSeverity extracts the event severity from a message, if present
+func messageSeverity(message *Message) (string, bool) {
+	eventData, ok := message.Data["event"].(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	severity, ok := eventData["severity"].(string)
+	if !ok || severity == "" {
+		return "", false
+	}
+	return severity, true
+}
+
+// meetsMinSeverity reports whether a message should be processed under the severity filter.
+// Messages without a recognizable severity are always processed.
+func meetsMinSeverity(message *Message, minSeverity string) bool {
+	if minSeverity == "" {
+		return true
+	}
+	severity, ok := messageSeverity(message)
+	if !ok || models.SeverityLevel(severity) == 0 {
+		return true
+	}
+	return models.SeverityLevel(severity) >= models.SeverityLevel(minSeverity)
+}
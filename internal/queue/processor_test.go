@@ -0,0 +1,199 @@
+package queue
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"skyhawk-security-microservice/internal/metrics"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// fakeProcessedStore records the calls made to it in order, standing in for a transaction with
+// a nil *sql.Tx
+type fakeProcessedStore struct {
+	mu        sync.Mutex
+	calls     []string
+	processed map[string]bool
+	beginErr  error
+	commitErr error
+}
+
+func (s *fakeProcessedStore) record(call string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, call)
+}
+
+func (s *fakeProcessedStore) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+func (s *fakeProcessedStore) ProcessOnce(ctx context.Context, queueName, messageID string, process func(tx *sql.Tx) error) (bool, error) {
+	if s.beginErr != nil {
+		return false, s.beginErr
+	}
+	s.record("insert")
+	s.mu.Lock()
+	seen := s.processed[messageID]
+	s.mu.Unlock()
+	if seen {
+		s.record("rollback")
+		return false, nil
+	}
+
+	if err := process(nil); err != nil {
+		s.record("rollback")
+		return false, err
+	}
+	if s.commitErr != nil {
+		s.record("rollback")
+		return false, s.commitErr
+	}
+	s.record("commit")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.processed == nil {
+		s.processed = make(map[string]bool)
+	}
+	s.processed[messageID] = true
+	return true, nil
+}
+
+// storeRecordingNotifier records "process" in the store's call log when notified in a
+// transaction
+type storeRecordingNotifier struct {
+	store *fakeProcessedStore
+	err   error
+}
+
+func (n *storeRecordingNotifier) NotifyTx(ctx context.Context, tx *sql.Tx, event *models.Event) error {
+	n.store.record("process")
+	return n.err
+}
+
+func TestProcessWithTimeoutRecordsMessageInTheTransactionOfItsSideEffects(t *testing.T) {
+	store := &fakeProcessedStore{}
+	p := NewEventProcessor(context.Background())
+	p.AddTxNotifier(&storeRecordingNotifier{store: store})
+	message := deliveredMessage(t, testEvent("evt-1"))
+
+	if err := p.processWithTimeout(context.Background(), "security_events", &message, ConsumerConfig{ProcessedMessages: store}); err != nil {
+		t.Fatalf("processWithTimeout: %v", err)
+	}
+
+	want := []string{"insert", "process", "commit"}
+	if got := store.Calls(); !slices.Equal(got, want) {
+		t.Errorf("calls = %v, want %v", got, want)
+	}
+
+	// A redelivery finds the record and is skipped without processing
+	if err := p.processWithTimeout(context.Background(), "security_events", &message, ConsumerConfig{ProcessedMessages: store}); err != nil {
+		t.Fatalf("processWithTimeout of the redelivery: %v", err)
+	}
+	want = append(want, "insert", "rollback")
+	if got := store.Calls(); !slices.Equal(got, want) {
+		t.Errorf("calls after redelivery = %v, want %v", got, want)
+	}
+}
+
+func TestProcessWithTimeoutRollsBackOnFailure(t *testing.T) {
+	store := &fakeProcessedStore{}
+	p := NewEventProcessor(context.Background())
+	notifier := &storeRecordingNotifier{store: store, err: errors.New("insert failed")}
+	p.AddTxNotifier(notifier)
+	message := deliveredMessage(t, testEvent("evt-1"))
+
+	if err := p.processWithTimeout(context.Background(), "security_events", &message, ConsumerConfig{ProcessedMessages: store}); err == nil {
+		t.Fatal("processWithTimeout succeeded, want the processing error")
+	}
+
+	// The retry is processed, since the failed attempt left no record
+	notifier.err = nil
+	if err := p.processWithTimeout(context.Background(), "security_events", &message, ConsumerConfig{ProcessedMessages: store}); err != nil {
+		t.Fatalf("processWithTimeout of the retry: %v", err)
+	}
+
+	want := []string{"insert", "process", "rollback", "insert", "process", "commit"}
+	if got := store.Calls(); !slices.Equal(got, want) {
+		t.Errorf("calls = %v, want %v", got, want)
+	}
+}
+
+func TestProcessWithTimeoutFailsWhenCommitFails(t *testing.T) {
+	store := &fakeProcessedStore{commitErr: errors.New("connection reset")}
+	p := NewEventProcessor(context.Background())
+	p.AddTxNotifier(&storeRecordingNotifier{store: store})
+	message := deliveredMessage(t, testEvent("evt-1"))
+
+	// Neither the side effect nor the record was committed, so the message is retried
+	if err := p.processWithTimeout(context.Background(), "security_events", &message, ConsumerConfig{ProcessedMessages: store}); err == nil {
+		t.Error("processWithTimeout succeeded although the transaction did not commit")
+	}
+}
+
+func TestProcessWithTimeoutFailsWhenTransactionCannotBegin(t *testing.T) {
+	store := &fakeProcessedStore{beginErr: errors.New("connection refused")}
+	p := NewEventProcessor(context.Background())
+	p.AddTxNotifier(&storeRecordingNotifier{store: store})
+	message := deliveredMessage(t, testEvent("evt-1"))
+
+	if err := p.processWithTimeout(context.Background(), "security_events", &message, ConsumerConfig{ProcessedMessages: store}); err == nil {
+		t.Fatal("processWithTimeout succeeded without a transaction")
+	}
+	if got := store.Calls(); len(got) != 0 {
+		t.Errorf("calls = %v, want none", got)
+	}
+}
+
+// deliveredMessage returns the message of event as a consumer decodes it from the broker
+func deliveredMessage(t *testing.T, event *models.Event) Message {
+	t.Helper()
+	body, err := json.Marshal(NewEventMessage(event))
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var message Message
+	if err := json.Unmarshal(body, &message); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return message
+}
+
+func TestProcessWithTimeoutObservesDurationPerEventType(t *testing.T) {
+	p := NewEventProcessor(context.Background())
+	eventTypes := []string{"histogram_test_login", "histogram_test_data_access"}
+	series := testutil.CollectAndCount(metrics.ProcessingDuration)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		event := testEvent(fmt.Sprintf("evt-%d", i))
+		event.EventType = eventTypes[i%2]
+		message := deliveredMessage(t, event)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := p.processWithTimeout(context.Background(), "security_events", &message, ConsumerConfig{}); err != nil {
+				t.Errorf("processWithTimeout: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := testutil.CollectAndCount(metrics.ProcessingDuration); got != series+2 {
+		t.Errorf("event_processing_duration_seconds gained %d label combinations, want 2", got-series)
+	}
+	for _, eventType := range eventTypes {
+		if got := testutil.ToFloat64(metrics.EventsProcessedTotal.WithLabelValues(eventType, metrics.ProcessingStatusSuccess)); got != 5 {
+			t.Errorf("events_processed_total{event_type=%q,status=\"success\"} = %v, want 5", eventType, got)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/streadway/amqp"
+	"skyhawk-security-microservice/internal/logger"
+)
+
+// ErrChannelClosed is returned when the broker closed the channel a RabbitMQ operation used
+var ErrChannelClosed = errors.New("channel is closed")
+
+// currentChannel returns the channel shared by publishers and consumers
+func (rq *RabbitMQQueue) currentChannel() *amqp.Channel {
+	rq.connMu.RLock()
+	defer rq.connMu.RUnlock()
+	return rq.channel
+}
+
+// currentConnection returns the connection to the broker
+func (rq *RabbitMQQueue) currentConnection() *amqp.Connection {
+	rq.connMu.RLock()
+	defer rq.connMu.RUnlock()
+	return rq.conn
+}
+
+// reconnect replaces the closed channel stale with a new one, dialing the broker again when
+// the connection dropped too. Consumers sharing stale call it concurrently; only the first
+// reopens the channel and the others reuse it.
+func (rq *RabbitMQQueue) reconnect(stale *amqp.Channel) error {
+	rq.connMu.Lock()
+	if err := rq.ctx.Err(); err != nil {
+		rq.connMu.Unlock()
+		return ErrChannelClosed
+	}
+	if rq.channel != stale {
+		rq.connMu.Unlock()
+		return nil
+	}
+
+	if rq.conn.IsClosed() {
+		conn, err := amqp.DialConfig(rq.amqpURL, rq.amqpConfig)
+		if err != nil {
+			rq.connMu.Unlock()
+			return fmt.Errorf("failed to connect to RabbitMQ: %s", logger.RedactCredentials(err.Error()))
+		}
+		rq.conn = conn
+	}
+
+	channel, err := rq.conn.Channel()
+	if err != nil {
+		rq.connMu.Unlock()
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	rq.channel = channel
+	rq.connMu.Unlock()
+
+	if rq.exchangeMode == ExchangeModeTopic {
+		return rq.declareTopicExchange()
+	}
+	return nil
+}
+
+// awaitReconnect reconnects after stale closed, waiting the delays of the retry policy between
+// attempts. It keeps trying past MaxRetries, since the consumer has no other way to recover,
+// and returns false when stop is closed or the queue is closed first.
+func (rq *RabbitMQQueue) awaitReconnect(stale *amqp.Channel, stop <-chan struct{}, workerLogger *logger.Logger) bool {
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-time.After(rq.retry.delay(attempt)):
+		case <-stop:
+			workerLogger.Info("Consumer worker stopped")
+			return false
+		case <-rq.ctx.Done():
+			workerLogger.Info("Consumer worker stopping")
+			return false
+		}
+
+		err := rq.reconnect(stale)
+		if err == nil {
+			workerLogger.Info("Reconnected to RabbitMQ", logger.Fields{"attempt": attempt})
+			return true
+		}
+		workerLogger.Warn("Failed to reconnect to RabbitMQ", logger.Fields{"attempt": attempt, "error": err.Error()})
+	}
+}
+
+// channelClosed reports whether the broker closed the channel whose close notifications are
+// closes, rather than the consumer being cancelled
+func channelClosed(closes <-chan *amqp.Error) bool {
+	select {
+	case err := <-closes:
+		return err != nil
+	default:
+		return false
+	}
+}
+
+// closedChannel returns ch when err reports it closed, so the consumer reconnects, and nil
+// otherwise
+func closedChannel(ch *amqp.Channel, err error) *amqp.Channel {
+	if errors.Is(err, amqp.ErrClosed) {
+		return ch
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package queue
+
+import "skyhawk-security-microservice/internal/models"
+
+// TenantQueueName returns the queue the events of a tenant are published to when tenant queue
+// naming is enabled: <baseName>_<tenantID>
+func TenantQueueName(baseName, tenantID string) string {
+	return baseName + "_" + tenantID
+}
+
+// TenantQueueNames returns the queue of every tenant for each base queue, so a worker can
+// consume only the events of its tenants
+func TenantQueueNames(baseNames, tenantIDs []string) []string {
+	names := make([]string, 0, len(baseNames)*len(tenantIDs))
+	for _, baseName := range baseNames {
+		for _, tenantID := range tenantIDs {
+			names = append(names, TenantQueueName(baseName, tenantID))
+		}
+	}
+	return names
+}
+
+// tenantQueue returns the queue an event is published to in place of queueName. Events
+// without a tenant stay on the shared queue.
+func (rq *RabbitMQQueue) tenantQueue(event *models.Event, queueName string) string {
+	if !rq.tenantQueues || event.TenantID == "" {
+		return queueName
+	}
+	return TenantQueueName(queueName, event.TenantID)
+}
+
+// TenantQueueNaming reports whether events with a tenant are published to tenant queues
+func (rq *RabbitMQQueue) TenantQueueNaming() bool {
+	return rq.tenantQueues
+}
@@ -0,0 +1,133 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+func tenantEvent(id, tenantID string) *models.Event {
+	event := testEvent(id)
+	event.TenantID = tenantID
+	return event
+}
+
+func TestTenantQueueSeparatesTenants(t *testing.T) {
+	rq := &RabbitMQQueue{tenantQueues: true}
+
+	for event, want := range map[*models.Event]string{
+		tenantEvent("evt-1", "acme"):   "security_events_acme",
+		tenantEvent("evt-2", "globex"): "security_events_globex",
+		tenantEvent("evt-3", ""):       "security_events",
+	} {
+		if got := rq.tenantQueue(event, "security_events"); got != want {
+			t.Errorf("tenantQueue(tenant %q) = %q, want %q", event.TenantID, got, want)
+		}
+	}
+
+	rq.tenantQueues = false
+	if got := rq.tenantQueue(tenantEvent("evt-1", "acme"), "security_events"); got != "security_events" {
+		t.Errorf("tenantQueue with tenant queues disabled = %q, want security_events", got)
+	}
+}
+
+func TestTenantQueueNamesCoverEveryQueueAndTenant(t *testing.T) {
+	got := TenantQueueNames([]string{"security_events", "audit_events"}, []string{"acme", "globex"})
+	want := []string{"security_events_acme", "security_events_globex", "audit_events_acme", "audit_events_globex"}
+	if !slices.Equal(got, want) {
+		t.Errorf("TenantQueueNames() = %v, want %v", got, want)
+	}
+}
+
+func TestTenantRoutingKeyIsScopedToTenant(t *testing.T) {
+	event := tenantEvent("evt-1", "acme")
+	if got, want := TenantRoutingKey(event), "tenant.acme.event.login_failure.high"; got != want {
+		t.Errorf("TenantRoutingKey() = %q, want %q", got, want)
+	}
+	if got, want := TenantPattern("acme"), "tenant.acme.#"; got != want {
+		t.Errorf("TenantPattern() = %q, want %q", got, want)
+	}
+}
+
+// tenantRecorder records the tenant of every event it is notified of
+type tenantRecorder struct {
+	mu      sync.Mutex
+	tenants []string
+}
+
+func (n *tenantRecorder) Notify(ctx context.Context, event *models.Event) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.tenants = append(n.tenants, event.TenantID)
+	return nil
+}
+
+func (n *tenantRecorder) Tenants() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return slices.Clone(n.tenants)
+}
+
+// TestTenantQueuesAgainstRabbitMQ publishes the events of two tenants to the broker at
+// RABBITMQ_TEST_URL and checks a consumer of one tenant's queue only sees that tenant's events
+func TestTenantQueuesAgainstRabbitMQ(t *testing.T) {
+	url := os.Getenv("RABBITMQ_TEST_URL")
+	if url == "" {
+		t.Skip("RABBITMQ_TEST_URL not set")
+	}
+	t.Setenv("AMQP_TENANT_QUEUES", "true")
+
+	rq, err := NewRabbitMQQueue(url)
+	if err != nil {
+		t.Fatalf("NewRabbitMQQueue: %v", err)
+	}
+	defer rq.Close()
+	processed := &tenantRecorder{}
+	rq.AddBlockingNotifier(processed)
+
+	baseName := fmt.Sprintf("tenant_test_%d", time.Now().UnixNano())
+	tenantA, tenantB := TenantQueueName(baseName, "tenant-a"), TenantQueueName(baseName, "tenant-b")
+	t.Cleanup(func() {
+		rq.PurgeQueue(tenantA)
+		rq.PurgeQueue(tenantB)
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := rq.PublishEvent(tenantEvent(fmt.Sprintf("evt-a-%d", i), "tenant-a"), baseName); err != nil {
+			t.Fatalf("PublishEvent: %v", err)
+		}
+		if err := rq.PublishEvent(tenantEvent(fmt.Sprintf("evt-b-%d", i), "tenant-b"), baseName); err != nil {
+			t.Fatalf("PublishEvent: %v", err)
+		}
+	}
+	waitFor(t, 5*time.Second, "both tenant queues to fill", func() bool {
+		a, _ := rq.GetQueueLength(tenantA)
+		b, _ := rq.GetQueueLength(tenantB)
+		return a == 3 && b == 3
+	})
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rq.StartConsumer(tenantA, 1, ConsumerConfig{Stop: stop})
+	}()
+	waitFor(t, 10*time.Second, "the tenant A consumer to process its events", func() bool { return len(processed.Tenants()) == 3 })
+	close(stop)
+	<-done
+
+	for _, tenant := range processed.Tenants() {
+		if tenant != "tenant-a" {
+			t.Errorf("tenant A consumer received an event of %q", tenant)
+		}
+	}
+	if depth, err := rq.GetQueueLength(tenantB); err != nil || depth != 3 {
+		t.Errorf("tenant B queue depth = %d (%v), want its 3 events untouched", depth, err)
+	}
+}
@@ -0,0 +1,111 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// tracer creates the queue.publish and queue.consume spans. They are only recorded once a
+// TracerProvider is registered with otel; the trace context propagates either way.
+var tracer = otel.Tracer("skyhawk-security-microservice/internal/queue")
+
+// ContextPublisher is implemented by queues that carry the trace context of ctx along with
+// the messages they publish
+type ContextPublisher interface {
+	PublishMessageContext(ctx context.Context, message Message, queueName string) error
+	PublishEventContext(ctx context.Context, event *models.Event, queueName string, opts ...PublishOption) error
+}
+
+// PublishMessageContext publishes a message, propagating the trace context of ctx when the
+// queue supports it
+func PublishMessageContext(ctx context.Context, q QueueInterface, message Message, queueName string) error {
+	if cp, ok := q.(ContextPublisher); ok {
+		return cp.PublishMessageContext(ctx, message, queueName)
+	}
+	return q.PublishMessage(message, queueName)
+}
+
+// PublishEventContext publishes an event, propagating the trace context of ctx when the
+// queue supports it
+func PublishEventContext(ctx context.Context, q QueueInterface, event *models.Event, queueName string, opts ...PublishOption) error {
+	if cp, ok := q.(ContextPublisher); ok {
+		return cp.PublishEventContext(ctx, event, queueName, opts...)
+	}
+	return q.PublishEvent(event, queueName, opts...)
+}
+
+// amqpHeaderCarrier adapts AMQP message headers to propagation.TextMapCarrier, so the W3C
+// traceparent, tracestate and baggage headers travel with each message
+type amqpHeaderCarrier struct {
+	headers *amqp.Table
+}
+
+// Get returns the value of a header, or "" if it is missing or not a string
+func (c amqpHeaderCarrier) Get(key string) string {
+	value, _ := (*c.headers)[key].(string)
+	return value
+}
+
+// Set sets a header
+func (c amqpHeaderCarrier) Set(key, value string) {
+	if *c.headers == nil {
+		*c.headers = amqp.Table{}
+	}
+	(*c.headers)[key] = value
+}
+
+// Keys lists the header names
+func (c amqpHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.headers))
+	for key := range *c.headers {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// traceHeaders returns the AMQP headers carrying the trace context and baggage of ctx
+func traceHeaders(ctx context.Context) amqp.Table {
+	var headers amqp.Table
+	otel.GetTextMapPropagator().Inject(ctx, amqpHeaderCarrier{&headers})
+	return headers
+}
+
+// startPublishSpan starts the producer span of a message published to destination
+func startPublishSpan(ctx context.Context, message Message, destination string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "queue.publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.destination.name", destination),
+			attribute.String("messaging.message.id", message.ID),
+		),
+	)
+}
+
+// startConsumeSpan extracts the trace context a delivery was published with and starts the
+// queue.consume span as its child. parent supplies cancellation, not trace context.
+func startConsumeSpan(parent context.Context, msg amqp.Delivery, queueName string) (context.Context, trace.Span) {
+	ctx := otel.GetTextMapPropagator().Extract(parent, amqpHeaderCarrier{&msg.Headers})
+	return tracer.Start(ctx, "queue.consume",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "rabbitmq"),
+			attribute.String("messaging.source.name", queueName),
+		),
+	)
+}
+
+// endSpan marks the span as failed when err is set and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,97 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"skyhawk-security-microservice/internal/logger"
+)
+
+var (
+	spanExporterOnce sync.Once
+	spanExporter     *tracetest.InMemoryExporter
+)
+
+// recordSpans registers an in-memory span exporter with otel, once per test binary since
+// tracers created before keep the first provider, and returns it emptied
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	spanExporterOnce.Do(func() {
+		spanExporter = tracetest.NewInMemoryExporter()
+		otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(spanExporter)))
+		otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	})
+	spanExporter.Reset()
+	return spanExporter
+}
+
+// spansOf returns the recorded spans of a trace by name
+func spansOf(exporter *tracetest.InMemoryExporter, traceID trace.TraceID) map[string]tracetest.SpanStub {
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		if span.SpanContext.TraceID() == traceID {
+			spans[span.Name] = span
+		}
+	}
+	return spans
+}
+
+func TestTraceContextFlowsFromRequestThroughPublishToConsume(t *testing.T) {
+	exporter := recordSpans(t)
+
+	// The server span TracingMiddleware starts for the request
+	ctx, request := otel.Tracer("test").Start(context.Background(), "POST /api/v1/events", trace.WithSpanKind(trace.SpanKindServer))
+
+	// What PublishMessageContext sends to the broker
+	message := NewEventMessage(testEvent("evt-1"))
+	publishCtx, publish := startPublishSpan(ctx, message, "security_events")
+	delivery := deliveryOf(t, message)
+	delivery.Headers = traceHeaders(publishCtx)
+	publish.End()
+	request.End()
+
+	if _, ok := delivery.Headers["traceparent"].(string); !ok {
+		t.Fatalf("published headers %v carry no traceparent", delivery.Headers)
+	}
+
+	rq := &RabbitMQQueue{ctx: context.Background(), republisher: &fakeDeliveryPublisher{}, EventProcessor: NewEventProcessor(context.Background())}
+	rq.EventProcessor.publisher = rq
+	if outcome := rq.processDelivery(delivery, "security_events", ConsumerConfig{}, logger.GetLogger()); outcome != ackDelivery {
+		t.Fatalf("outcome = %v, want ackDelivery", outcome)
+	}
+
+	spans := spansOf(exporter, request.SpanContext().TraceID())
+	if len(spans) != 3 {
+		t.Fatalf("trace holds spans %v, want the request, publish and consume spans", spans)
+	}
+	publishSpan, consumeSpan := spans["queue.publish"], spans["queue.consume"]
+	if publishSpan.Parent.SpanID() != request.SpanContext().SpanID() {
+		t.Errorf("queue.publish parent = %s, want the request span %s", publishSpan.Parent.SpanID(), request.SpanContext().SpanID())
+	}
+	if consumeSpan.Parent.SpanID() != publishSpan.SpanContext.SpanID() || !consumeSpan.Parent.IsRemote() {
+		t.Errorf("queue.consume parent = %+v, want the remote queue.publish span %s", consumeSpan.Parent, publishSpan.SpanContext.SpanID())
+	}
+	if publishSpan.SpanKind != trace.SpanKindProducer || consumeSpan.SpanKind != trace.SpanKindConsumer {
+		t.Errorf("span kinds = %s and %s, want producer and consumer", publishSpan.SpanKind, consumeSpan.SpanKind)
+	}
+}
+
+func TestConsumeWithoutTraceContextStartsNewTrace(t *testing.T) {
+	exporter := recordSpans(t)
+
+	rq := &RabbitMQQueue{ctx: context.Background(), republisher: &fakeDeliveryPublisher{}, EventProcessor: NewEventProcessor(context.Background())}
+	rq.EventProcessor.publisher = rq
+	rq.processDelivery(amqp.Delivery{Body: []byte("not json")}, "security_events", ConsumerConfig{}, logger.GetLogger())
+
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "queue.consume" || spans[0].Parent.IsValid() {
+		t.Fatalf("recorded %+v, want one root queue.consume span", spans)
+	}
+}
@@ -0,0 +1,218 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// PruneInterval is how often inactive sources are dropped from the limiter
+const PruneInterval = 5 * time.Minute
+
+// Limit is a token bucket configuration. A Rate of zero or less disables limiting.
+type Limit struct {
+	Rate      float64 `json:"rate"`
+	BurstSize int     `json:"burst_size"`
+}
+
+// limit converts the configuration into a rate.Limit
+func (l Limit) limit() rate.Limit {
+	if l.Rate <= 0 {
+		return rate.Inf
+	}
+	return rate.Limit(l.Rate)
+}
+
+// burst returns the bucket size, allowing at least one event
+func (l Limit) burst() int {
+	if l.BurstSize < 1 {
+		return 1
+	}
+	return l.BurstSize
+}
+
+// SourceStatus describes the current state of one source's bucket
+type SourceStatus struct {
+	Source   string    `json:"source"`
+	Tokens   float64   `json:"tokens"`
+	Limit    Limit     `json:"limit"`
+	Override bool      `json:"override"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// sourceEntry holds a source's limiter and when it was last used
+type sourceEntry struct {
+	limiter  *rate.Limiter
+	lastSeen atomic.Int64
+}
+
+// SourceRateLimiter applies a token bucket per event source
+type SourceRateLimiter struct {
+	defaults  Limit
+	mu        sync.RWMutex
+	overrides map[string]Limit
+	limiters  sync.Map
+}
+
+// NewSourceRateLimiter creates a limiter applying the default limit to every source
+func NewSourceRateLimiter(defaults Limit) *SourceRateLimiter {
+	return &SourceRateLimiter{
+		defaults:  defaults,
+		overrides: make(map[string]Limit),
+	}
+}
+
+// Allow reports whether an event from source may be accepted now, consuming a token if so
+func (l *SourceRateLimiter) Allow(source string) bool {
+	entry := l.entry(source)
+	entry.lastSeen.Store(time.Now().UnixNano())
+	return entry.limiter.Allow()
+}
+
+// entry returns the limiter for a source, creating it on first use
+func (l *SourceRateLimiter) entry(source string) *sourceEntry {
+	if value, ok := l.limiters.Load(source); ok {
+		return value.(*sourceEntry)
+	}
+
+	limit := l.limitFor(source)
+	value, _ := l.limiters.LoadOrStore(source, &sourceEntry{
+		limiter: rate.NewLimiter(limit.limit(), limit.burst()),
+	})
+	return value.(*sourceEntry)
+}
+
+// limitFor returns the override for a source, or the default limit
+func (l *SourceRateLimiter) limitFor(source string) Limit {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if override, ok := l.overrides[source]; ok {
+		return override
+	}
+	return l.defaults
+}
+
+// Defaults returns the limit applied to sources without an override
+func (l *SourceRateLimiter) Defaults() Limit {
+	return l.defaults
+}
+
+// SetOverride applies a dedicated limit to a source
+func (l *SourceRateLimiter) SetOverride(source string, limit Limit) error {
+	if source == "" {
+		return fmt.Errorf("source must not be empty")
+	}
+	if limit.BurstSize < 0 {
+		return fmt.Errorf("burst_size must not be negative")
+	}
+
+	l.mu.Lock()
+	l.overrides[source] = limit
+	l.mu.Unlock()
+
+	l.apply(source, limit)
+	return nil
+}
+
+// RemoveOverride returns a source to the default limit. It reports whether an override existed.
+func (l *SourceRateLimiter) RemoveOverride(source string) bool {
+	l.mu.Lock()
+	_, ok := l.overrides[source]
+	delete(l.overrides, source)
+	l.mu.Unlock()
+
+	if ok {
+		l.apply(source, l.defaults)
+	}
+	return ok
+}
+
+// apply updates an existing limiter in place so its current tokens carry over
+func (l *SourceRateLimiter) apply(source string, limit Limit) {
+	if value, ok := l.limiters.Load(source); ok {
+		limiter := value.(*sourceEntry).limiter
+		limiter.SetLimit(limit.limit())
+		limiter.SetBurst(limit.burst())
+	}
+}
+
+// Overrides returns a copy of the per-source overrides
+func (l *SourceRateLimiter) Overrides() map[string]Limit {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	overrides := make(map[string]Limit, len(l.overrides))
+	for source, limit := range l.overrides {
+		overrides[source] = limit
+	}
+	return overrides
+}
+
+// Status returns the current bucket state of every active source, sorted by source
+func (l *SourceRateLimiter) Status() []SourceStatus {
+	overrides := l.Overrides()
+
+	var statuses []SourceStatus
+	l.limiters.Range(func(key, value interface{}) bool {
+		source := key.(string)
+		entry := value.(*sourceEntry)
+
+		limit, override := overrides[source]
+		if !override {
+			limit = l.defaults
+		}
+
+		statuses = append(statuses, SourceStatus{
+			Source:   source,
+			Tokens:   entry.limiter.Tokens(),
+			Limit:    limit,
+			Override: override,
+			LastSeen: time.Unix(0, entry.lastSeen.Load()),
+		})
+		return true
+	})
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Source < statuses[j].Source
+	})
+	return statuses
+}
+
+// Prune drops the limiters of sources not seen within idle
+func (l *SourceRateLimiter) Prune(idle time.Duration) {
+	cutoff := time.Now().Add(-idle).UnixNano()
+	l.limiters.Range(func(key, value interface{}) bool {
+		if value.(*sourceEntry).lastSeen.Load() < cutoff {
+			l.limiters.CompareAndDelete(key, value)
+		}
+		return true
+	})
+}
+
+// StartPruning prunes sources idle for longer than PruneInterval every PruneInterval.
+// The returned function stops pruning.
+func (l *SourceRateLimiter) StartPruning() func() {
+	ticker := time.NewTicker(PruneInterval)
+	done := make(chan struct{})
+
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				l.Prune(PruneInterval)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// allowed counts how many of n immediate events from source the limiter accepts
+func allowed(l *SourceRateLimiter, source string, n int) int {
+	count := 0
+	for i := 0; i < n; i++ {
+		if l.Allow(source) {
+			count++
+		}
+	}
+	return count
+}
+
+func TestAllowRejectsBurstAboveLimit(t *testing.T) {
+	limiter := NewSourceRateLimiter(Limit{Rate: 1, BurstSize: 10})
+
+	// A burst of 20 above the bucket size is rejected
+	if got := allowed(limiter, "firewall", 30); got != 10 {
+		t.Errorf("accepted %d of 30 events, want 10 with burst_size 10", got)
+	}
+}
+
+func TestAllowLimitsEachSourceSeparately(t *testing.T) {
+	limiter := NewSourceRateLimiter(Limit{Rate: 1, BurstSize: 5})
+
+	if got := allowed(limiter, "firewall", 10); got != 5 {
+		t.Errorf("firewall: accepted %d of 10 events, want 5", got)
+	}
+	if got := allowed(limiter, "ids", 5); got != 5 {
+		t.Errorf("ids: accepted %d of 5 events, want 5 despite the flooding firewall", got)
+	}
+}
+
+func TestAllowWithoutRateIsUnlimited(t *testing.T) {
+	limiter := NewSourceRateLimiter(Limit{})
+
+	if got := allowed(limiter, "firewall", 1000); got != 1000 {
+		t.Errorf("accepted %d of 1000 events, want all without a rate", got)
+	}
+}
+
+func TestSetOverride(t *testing.T) {
+	limiter := NewSourceRateLimiter(Limit{Rate: 1, BurstSize: 5})
+
+	if err := limiter.SetOverride("firewall", Limit{Rate: 1, BurstSize: 50}); err != nil {
+		t.Fatalf("SetOverride: %v", err)
+	}
+	if got := allowed(limiter, "firewall", 60); got != 50 {
+		t.Errorf("accepted %d of 60 events, want the override burst of 50", got)
+	}
+	if got := allowed(limiter, "ids", 10); got != 5 {
+		t.Errorf("accepted %d of 10 events from a source without override, want 5", got)
+	}
+
+	if !limiter.RemoveOverride("firewall") {
+		t.Error("RemoveOverride reported no override")
+	}
+	if overrides := limiter.Overrides(); len(overrides) != 0 {
+		t.Errorf("overrides = %v, want none", overrides)
+	}
+	if status := limiter.Status(); status[0].Source != "firewall" || status[0].Override || status[0].Limit.BurstSize != 5 {
+		t.Errorf("firewall status = %+v, want the default limit", status[0])
+	}
+
+	if err := limiter.SetOverride("", Limit{Rate: 1}); err == nil {
+		t.Error("SetOverride accepted an empty source")
+	}
+	if err := limiter.SetOverride("firewall", Limit{Rate: 1, BurstSize: -1}); err == nil {
+		t.Error("SetOverride accepted a negative burst size")
+	}
+}
+
+func TestStatusReportsTokens(t *testing.T) {
+	limiter := NewSourceRateLimiter(Limit{Rate: 0.001, BurstSize: 10})
+	allowed(limiter, "ids", 4)
+	allowed(limiter, "firewall", 10)
+
+	status := limiter.Status()
+	if len(status) != 2 || status[0].Source != "firewall" || status[1].Source != "ids" {
+		t.Fatalf("status = %+v, want firewall and ids sorted by source", status)
+	}
+	if tokens := status[0].Tokens; tokens < 0 || tokens > 0.1 {
+		t.Errorf("firewall tokens = %v, want an empty bucket", tokens)
+	}
+	if tokens := status[1].Tokens; tokens < 6 || tokens > 6.1 {
+		t.Errorf("ids tokens = %v, want 6 left", tokens)
+	}
+}
+
+func TestPruneDropsIdleSources(t *testing.T) {
+	limiter := NewSourceRateLimiter(Limit{Rate: 1, BurstSize: 1})
+	limiter.Allow("firewall")
+
+	limiter.Prune(time.Hour)
+	if status := limiter.Status(); len(status) != 1 {
+		t.Fatalf("status after pruning with a long idle time = %+v, want firewall kept", status)
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	limiter.Prune(5 * time.Millisecond)
+	if status := limiter.Status(); len(status) != 0 {
+		t.Errorf("status after pruning = %+v, want the idle source dropped", status)
+	}
+	// A pruned source starts over with a full bucket
+	if !limiter.Allow("firewall") {
+		t.Error("pruned source was rejected")
+	}
+}
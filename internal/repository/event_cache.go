@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"container/list"
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"skyhawk-security-microservice/internal/metrics"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// CachedEventRepository decorates an EventRepository with an LRU cache of GetEventByID. Events
+// are cached for ttl, and evicted earlier when more than size are cached or when they are
+// updated or deleted through this repository. Changes made by other processes, such as
+// workers, show once the entry expires. Every other method goes straight to the database.
+type CachedEventRepository struct {
+	*EventRepository
+
+	mu   sync.Mutex
+	size int
+	ttl  time.Duration
+	// entries indexes the elements of order by event ID; order holds the most recently used
+	// event first
+	entries map[string]*list.Element
+	order   *list.List
+	// evictions counts evictions, so a read racing an update does not cache the old event
+	evictions uint64
+}
+
+// eventCacheEntry is a cached event and when it expires
+type eventCacheEntry struct {
+	eventID string
+	event   *models.Event
+	expires time.Time
+}
+
+// NewCachedEventRepository caches up to size events of repo for ttl each
+func NewCachedEventRepository(repo *EventRepository, size int, ttl time.Duration) *CachedEventRepository {
+	return &CachedEventRepository{
+		EventRepository: repo,
+		size:            size,
+		ttl:             ttl,
+		entries:         make(map[string]*list.Element),
+		order:           list.New(),
+	}
+}
+
+// GetEventByID returns a cached copy of the event, reading it from the database on a miss
+func (r *CachedEventRepository) GetEventByID(ctx context.Context, id string) (*models.Event, error) {
+	event, evictions, ok := r.lookup(id)
+	if ok {
+		metrics.EventCacheLookups.WithLabelValues(metrics.CacheResultHit).Inc()
+		return event, nil
+	}
+	metrics.EventCacheLookups.WithLabelValues(metrics.CacheResultMiss).Inc()
+
+	event, err := r.EventRepository.GetEventByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.store(id, event, evictions)
+	return event, nil
+}
+
+// UpdateEvent updates the event and evicts it from the cache
+func (r *CachedEventRepository) UpdateEvent(ctx context.Context, eventID string, updates *models.UpdateEventRequest) (*models.Event, error) {
+	defer r.evict(eventID)
+	return r.EventRepository.UpdateEvent(ctx, eventID, updates)
+}
+
+// PatchEventData patches the event data and evicts the event from the cache
+func (r *CachedEventRepository) PatchEventData(ctx context.Context, eventID string, patch models.EventData) (*models.Event, error) {
+	defer r.evict(eventID)
+	return r.EventRepository.PatchEventData(ctx, eventID, patch)
+}
+
+// AcknowledgeEvent acknowledges the event and evicts it from the cache
+func (r *CachedEventRepository) AcknowledgeEvent(ctx context.Context, eventID string) (*models.Event, error) {
+	defer r.evict(eventID)
+	return r.EventRepository.AcknowledgeEvent(ctx, eventID)
+}
+
+// BulkUpdateStatus updates the status of the events and evicts them from the cache
+func (r *CachedEventRepository) BulkUpdateStatus(ctx context.Context, tenantID string, eventIDs []string, status, updatedBy, note string) models.BulkResult {
+	defer r.evict(eventIDs...)
+	return r.EventRepository.BulkUpdateStatus(ctx, tenantID, eventIDs, status, updatedBy, note)
+}
+
+// DeleteEvent deletes the event and evicts it from the cache
+func (r *CachedEventRepository) DeleteEvent(ctx context.Context, eventID string) error {
+	defer r.evict(eventID)
+	return r.EventRepository.DeleteEvent(ctx, eventID)
+}
+
+// DeleteEvents deletes the events and evicts them from the cache
+func (r *CachedEventRepository) DeleteEvents(ctx context.Context, eventIDs []string) (*models.DeleteEventsResult, error) {
+	defer r.evict(eventIDs...)
+	return r.EventRepository.DeleteEvents(ctx, eventIDs)
+}
+
+// lookup returns a copy of the cached event unless it is missing or expired, and the number of
+// evictions so far
+func (r *CachedEventRepository) lookup(eventID string) (*models.Event, uint64, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elem, ok := r.entries[eventID]
+	if !ok {
+		return nil, r.evictions, false
+	}
+	entry := elem.Value.(*eventCacheEntry)
+	if time.Now().After(entry.expires) {
+		r.remove(elem)
+		return nil, r.evictions, false
+	}
+	r.order.MoveToFront(elem)
+	return cloneEvent(entry.event), r.evictions, true
+}
+
+// store caches a copy of event read after the given number of evictions, evicting the least
+// recently used event when the cache is full. The event is not cached when an eviction happened
+// since, as it may have been read before an update.
+func (r *CachedEventRepository) store(eventID string, event *models.Event, evictions uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.evictions != evictions {
+		return
+	}
+
+	entry := &eventCacheEntry{eventID: eventID, event: cloneEvent(event), expires: time.Now().Add(r.ttl)}
+	if elem, ok := r.entries[eventID]; ok {
+		elem.Value = entry
+		r.order.MoveToFront(elem)
+		return
+	}
+	r.entries[eventID] = r.order.PushFront(entry)
+	for r.order.Len() > r.size {
+		r.remove(r.order.Back())
+	}
+}
+
+// evict removes events from the cache
+func (r *CachedEventRepository) evict(eventIDs ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.evictions++
+	for _, eventID := range eventIDs {
+		if elem, ok := r.entries[eventID]; ok {
+			r.remove(elem)
+		}
+	}
+}
+
+// remove drops a cache element; r.mu must be held
+func (r *CachedEventRepository) remove(elem *list.Element) {
+	r.order.Remove(elem)
+	delete(r.entries, elem.Value.(*eventCacheEntry).eventID)
+}
+
+// cloneEvent copies an event deeply enough that callers changing the copy, including its event
+// data, do not change the cached event
+func cloneEvent(event *models.Event) *models.Event {
+	clone := *event
+	clone.EventData = cloneValue(map[string]interface{}(event.EventData)).(map[string]interface{})
+	clone.ATTACKTechniques = slices.Clone(event.ATTACKTechniques)
+	if event.AcknowledgedAt != nil {
+		acknowledgedAt := *event.AcknowledgedAt
+		clone.AcknowledgedAt = &acknowledgedAt
+	}
+	return &clone
+}
+
+// cloneValue copies the maps and slices of a decoded JSON value
+func cloneValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		clone := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			clone[key] = cloneValue(item)
+		}
+		return clone
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		clone := make([]interface{}, len(v))
+		for i, item := range v {
+			clone[i] = cloneValue(item)
+		}
+		return clone
+	default:
+		return v
+	}
+}
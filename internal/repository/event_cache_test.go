@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"skyhawk-security-microservice/internal/database"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// eventRowsConnector opens connections that answer every query with one event row whose
+// event_id is the first argument, and every statement with one affected row. It counts the
+// queries.
+type eventRowsConnector struct {
+	queries atomic.Int64
+}
+
+func (c *eventRowsConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.Open("")
+}
+
+func (c *eventRowsConnector) Open(name string) (driver.Conn, error) {
+	return eventRowsConn{connector: c}, nil
+}
+
+func (c *eventRowsConnector) Driver() driver.Driver { return c }
+
+type eventRowsConn struct {
+	connector *eventRowsConnector
+}
+
+func (eventRowsConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (eventRowsConn) Close() error              { return nil }
+func (eventRowsConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (c eventRowsConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.connector.queries.Add(1)
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	return &eventRows{row: []driver.Value{
+		"1", args[0].Value, "login_failure", "high", "auth-service", "", []byte(`{"ip":"203.0.113.7"}`),
+		"", "", []byte("{}"), "open", nil, created, created, "", "",
+	}}, nil
+}
+
+func (c eventRowsConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+// eventRows holds one row selected with eventColumns
+type eventRows struct {
+	row  []driver.Value
+	done bool
+}
+
+func (r *eventRows) Columns() []string {
+	return make([]string, len(r.row))
+}
+
+func (r *eventRows) Close() error { return nil }
+
+func (r *eventRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	copy(dest, r.row)
+	return nil
+}
+
+// newCountingCache returns a cache of size events over a repository answering from
+// eventRowsConnector, and the connector
+func newCountingCache(t *testing.T, size int, ttl time.Duration) (*CachedEventRepository, *eventRowsConnector) {
+	t.Helper()
+	connector := &eventRowsConnector{}
+	db := sql.OpenDB(connector)
+	t.Cleanup(func() { db.Close() })
+	repo := &EventRepository{db: &database.DB{DB: db}, maxEventDataSize: models.DefaultMaxEventDataSize}
+	return NewCachedEventRepository(repo, size, ttl), connector
+}
+
+func mustGetEvent(t *testing.T, r *CachedEventRepository, id string) *models.Event {
+	t.Helper()
+	event, err := r.GetEventByID(context.Background(), id)
+	if err != nil {
+		t.Fatalf("GetEventByID(%q): %v", id, err)
+	}
+	return event
+}
+
+func TestCachedEventRepositoryServesRepeatedLookupsFromCache(t *testing.T) {
+	cache, connector := newCountingCache(t, 10, time.Minute)
+
+	first := mustGetEvent(t, cache, "evt-1")
+	first.EventData["ip"] = "changed by the caller"
+	second := mustGetEvent(t, cache, "evt-1")
+
+	if got := connector.queries.Load(); got != 1 {
+		t.Errorf("two lookups ran %d queries, want 1", got)
+	}
+	if second.EventID != "evt-1" || second.EventData["ip"] != "203.0.113.7" {
+		t.Errorf("cached event = %+v, want evt-1 as read from the database", second)
+	}
+}
+
+func TestCachedEventRepositoryEvictsChangedEvents(t *testing.T) {
+	cache, connector := newCountingCache(t, 10, time.Minute)
+
+	mustGetEvent(t, cache, "evt-1")
+	if err := cache.DeleteEvent(context.Background(), "evt-1"); err != nil {
+		t.Fatalf("DeleteEvent: %v", err)
+	}
+	mustGetEvent(t, cache, "evt-1")
+
+	if got := connector.queries.Load(); got != 2 {
+		t.Errorf("lookups around a delete ran %d queries, want 2", got)
+	}
+}
+
+func TestCachedEventRepositoryEvictsLeastRecentlyUsed(t *testing.T) {
+	cache, connector := newCountingCache(t, 2, time.Minute)
+
+	mustGetEvent(t, cache, "evt-1")
+	mustGetEvent(t, cache, "evt-2")
+	mustGetEvent(t, cache, "evt-1")
+	mustGetEvent(t, cache, "evt-3")
+	queries := connector.queries.Load()
+
+	mustGetEvent(t, cache, "evt-1")
+	if got := connector.queries.Load(); got != queries {
+		t.Error("recently used evt-1 was evicted")
+	}
+	mustGetEvent(t, cache, "evt-2")
+	if got := connector.queries.Load(); got != queries+1 {
+		t.Error("least recently used evt-2 was still cached")
+	}
+}
+
+func TestCachedEventRepositoryExpiresEntries(t *testing.T) {
+	cache, connector := newCountingCache(t, 10, 20*time.Millisecond)
+
+	mustGetEvent(t, cache, "evt-1")
+	time.Sleep(50 * time.Millisecond)
+	mustGetEvent(t, cache, "evt-1")
+
+	if got := connector.queries.Load(); got != 2 {
+		t.Errorf("lookups past the TTL ran %d queries, want 2", got)
+	}
+}
@@ -1,53 +1,149 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/lib/pq"
+	"skyhawk-security-microservice/internal/config"
 	"skyhawk-security-microservice/internal/database"
+	apperrors "skyhawk-security-microservice/internal/errors"
 	"skyhawk-security-microservice/internal/models"
+	"skyhawk-security-microservice/internal/pagination"
 )
 
+// ErrContextCancelled is wrapped by the errors of queries abandoned because their context was
+// cancelled or its deadline passed, so callers can tell them from database failures
+var ErrContextCancelled = errors.New("context cancelled")
+
+// queryError describes a failed query. When ctx has ended the error wraps both
+// ErrContextCancelled and the context's error.
+func queryError(ctx context.Context, message string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%s: %w: %w", message, ErrContextCancelled, ctxErr)
+	}
+	return fmt.Errorf("%s: %v", message, err)
+}
+
 type EventRepository struct {
-	db *database.DB
+	db               *database.DB
+	maxEventDataSize int
+	encryption       config.FieldEncryption
 }
 
 func NewEventRepository(db *database.DB) *EventRepository {
-	return &EventRepository{db: db}
+	return &EventRepository{
+		db:               db,
+		maxEventDataSize: maxEventDataSizeFromEnv(),
+		encryption:       fieldEncryptionFromEnv(),
+	}
 }
 
-func (r *EventRepository) CreateEvent(event *models.Event) error {
-	query := `
-		INSERT INTO security_events (event_id, event_type, severity, source, description, event_data)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at, updated_at`
+// SetMaxEventDataSize sets the limit on the serialized size of event_data in bytes
+func (r *EventRepository) SetMaxEventDataSize(size int) {
+	r.maxEventDataSize = size
+}
 
-	err := r.db.QueryRow(
-		query,
-		event.EventID,
-		event.EventType,
-		event.Severity,
-		event.Source,
-		event.Description,
-		event.EventData,
-	).Scan(&event.ID, &event.CreatedAt, &event.UpdatedAt)
+// maxEventDataSizeFromEnv reads the event_data size limit from MAX_EVENT_DATA_BYTES,
+// falling back to the default when unset or invalid
+func maxEventDataSizeFromEnv() int {
+	value := os.Getenv("MAX_EVENT_DATA_BYTES")
+	if value == "" {
+		return models.DefaultMaxEventDataSize
+	}
+
+	size, err := strconv.Atoi(value)
+	if err != nil || size <= 0 {
+		log.Printf("Warning: invalid MAX_EVENT_DATA_BYTES %q, using %d", value, models.DefaultMaxEventDataSize)
+		return models.DefaultMaxEventDataSize
+	}
+	return size
+}
+
+// SetFieldEncryption sets the event_data fields encrypted at rest
+func (r *EventRepository) SetFieldEncryption(encryption config.FieldEncryption) {
+	r.encryption = encryption
+}
 
+// FieldEncryption returns the event_data field encryption settings
+func (r *EventRepository) FieldEncryption() config.FieldEncryption {
+	return r.encryption
+}
+
+// fieldEncryptionFromEnv reads the field encryption settings. An invalid key stops the
+// process rather than storing PII in plaintext.
+func fieldEncryptionFromEnv() config.FieldEncryption {
+	encryption, err := config.FieldEncryptionFromEnv()
 	if err != nil {
-		return fmt.Errorf("failed to create event: %v", err)
+		log.Fatalf("Failed to configure field encryption: %v", err)
+	}
+	return encryption
+}
+
+// encodeEventData serializes event_data for storage, encrypting the protected fields. It
+// returns a validation error when it exceeds the size limit or a protected field holds a value
+// that looks like a ciphertext, which would fail to decrypt on every read. Nil data is stored
+// as NULL.
+func (r *EventRepository) encodeEventData(data models.EventData) (interface{}, error) {
+	if data == nil {
+		return nil, nil
 	}
 
-	return nil
+	// Checked while encryption is disabled too, so enabling it later cannot break stored rows
+	if fields := data.CiphertextFields(r.encryption.Fields); len(fields) > 0 {
+		return nil, apperrors.NewValidationError("Invalid event data",
+			fmt.Sprintf("event_data.%s must not start with an encryption prefix", fields[0])).
+			WithContext("fields", fields)
+	}
+
+	if r.encryption.Enabled() {
+		// Encrypt a copy; the caller keeps the plaintext event
+		encrypted := make(models.EventData, len(data))
+		for key, value := range data {
+			encrypted[key] = value
+		}
+		if err := encrypted.Encrypt(r.encryption.Fields, r.encryption.Encrypter); err != nil {
+			return nil, fmt.Errorf("failed to encrypt event data: %v", err)
+		}
+		data = encrypted
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, apperrors.NewValidationError("Invalid event data", err.Error())
+	}
+	if len(raw) > r.maxEventDataSize {
+		return nil, apperrors.NewValidationError("Event data too large",
+			fmt.Sprintf("event_data is %d bytes, the limit is %d", len(raw), r.maxEventDataSize)).
+			WithContext("event_data_bytes", len(raw)).
+			WithContext("max_event_data_bytes", r.maxEventDataSize)
+	}
+
+	return string(raw), nil
 }
 
-// GetEventByID retrieves an event by its ID
-func (r *EventRepository) GetEventByID(id string) (*models.Event, error) {
-	query := `
-		SELECT id, event_id, event_type, severity, source, description, event_data, created_at, updated_at
-		FROM security_events
-		WHERE event_id = $1`
+// eventColumns lists the columns read by scanEvent, in scan order
+const eventColumns = `id, event_id, event_type, severity, source, description, event_data, COALESCE(correlation_id, ''), COALESCE(tenant_id, ''), attack_techniques, status, acknowledged_at, created_at, updated_at, COALESCE(acknowledged_by, ''), COALESCE(acknowledgement_note, '')`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+// scanEvent scans a row selected with eventColumns
+func scanEvent(row rowScanner) (*models.Event, error) {
 	event := &models.Event{}
-	err := r.db.QueryRow(query, id).Scan(
+	err := row.Scan(
 		&event.ID,
 		&event.EventID,
 		&event.EventType,
@@ -55,61 +151,513 @@ func (r *EventRepository) GetEventByID(id string) (*models.Event, error) {
 		&event.Source,
 		&event.Description,
 		&event.EventData,
+		&event.CorrelationID,
+		&event.TenantID,
+		pq.Array(&event.ATTACKTechniques),
+		&event.Status,
+		&event.AcknowledgedAt,
 		&event.CreatedAt,
 		&event.UpdatedAt,
+		&event.AcknowledgedBy,
+		&event.AcknowledgementNote,
 	)
+	if err != nil {
+		return nil, err
+	}
+	if event.ATTACKTechniques == nil {
+		event.ATTACKTechniques = []string{}
+	}
+	return event, nil
+}
 
+// scan scans a row selected with eventColumns and decrypts its protected fields
+func (r *EventRepository) scan(row rowScanner) (*models.Event, error) {
+	event, err := scanEvent(row)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.decryptEventData(event.EventData); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// decryptEventData decrypts the protected fields of event_data in place
+func (r *EventRepository) decryptEventData(data models.EventData) error {
+	if !r.encryption.Enabled() || data == nil {
+		return nil
+	}
+	if err := data.Decrypt(r.encryption.Fields, r.encryption.Encrypter); err != nil {
+		return fmt.Errorf("failed to decrypt event data: %v", err)
+	}
+	return nil
+}
+
+func (r *EventRepository) CreateEvent(ctx context.Context, event *models.Event) error {
+	query := `
+		INSERT INTO security_events (event_id, event_type, severity, source, description, event_data, correlation_id, attack_techniques, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''))
+		RETURNING id, status, created_at, updated_at`
+
+	eventData, err := r.encodeEventData(event.EventData)
+	if err != nil {
+		return err
+	}
+
+	if event.ATTACKTechniques == nil {
+		event.ATTACKTechniques = []string{}
+	}
+
+	err = r.db.QueryRowContext(ctx,
+		query,
+		event.EventID,
+		event.EventType,
+		event.Severity,
+		event.Source,
+		event.Description,
+		eventData,
+		event.CorrelationID,
+		pq.Array(event.ATTACKTechniques),
+		event.TenantID,
+	).Scan(&event.ID, &event.Status, &event.CreatedAt, &event.UpdatedAt)
+
+	if pqErr, ok := database.UniqueViolation(err); ok {
+		return apperrors.NewConflictError("Event already exists", pqErr.Detail).
+			WithContext("constraint", pqErr.Constraint).
+			WithContext("event_id", event.EventID)
+	}
+	if err != nil {
+		return queryError(ctx, "failed to create event", err)
+	}
+
+	return nil
+}
+
+// GetEventByID retrieves an event by its ID
+func (r *EventRepository) GetEventByID(ctx context.Context, id string) (*models.Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM security_events
+		WHERE event_id = $1`
+
+	event, err := r.scan(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("event not found")
 		}
-		return nil, fmt.Errorf("failed to get event: %v", err)
+		return nil, queryError(ctx, "failed to get event", err)
 	}
 
 	return event, nil
 }
 
 // GetAllEvents retrieves all events from the database
-func (r *EventRepository) GetAllEvents() ([]*models.Event, error) {
+func (r *EventRepository) GetAllEvents(ctx context.Context) ([]*models.Event, error) {
+	return r.queryEvents(ctx, `
+		SELECT `+eventColumns+`
+		FROM security_events
+		ORDER BY created_at DESC`)
+}
+
+// ListEvents retrieves a page of events matching filter in the order of page.Sort using keyset
+// pagination. When page.After is set, only events past that position are returned.
+func (r *EventRepository) ListEvents(ctx context.Context, filter models.EventFilter, page models.PaginationParams) ([]*models.Event, error) {
+	query, args, err := listEventsQuery(filter, page)
+	if err != nil {
+		return nil, err
+	}
+	return r.queryEvents(ctx, query, args...)
+}
+
+// listEventsQuery returns the query selecting a page of the events matching filter, and its arguments
+func listEventsQuery(filter models.EventFilter, page models.PaginationParams) (string, []interface{}, error) {
+	conditions, args := appendFilterConditions(nil, nil, filter)
+
+	orderBy, conditions, args, err := eventOrder(page.Sort, page.After, "", conditions, args)
+	if err != nil {
+		return "", nil, err
+	}
+	args = append(args, page.Limit)
+
 	query := `
-		SELECT id, event_id, event_type, severity, source, description, event_data, created_at, updated_at
+		SELECT ` + eventColumns + `
 		FROM security_events
-		ORDER BY created_at DESC`
+		` + whereClause(conditions) + `
+		ORDER BY ` + orderBy + `
+		LIMIT $` + strconv.Itoa(len(args))
+
+	return query, args, nil
+}
+
+// severityRank ranks the severity column like models.SeverityLevel, so listings sort by level
+const severityRank = "CASE lower(%sseverity) WHEN 'low' THEN 1 WHEN 'medium' THEN 2 WHEN 'high' THEN 3 WHEN 'critical' THEN 4 ELSE 0 END"
 
-	rows, err := r.db.Query(query)
+// eventOrder returns the ORDER BY clause of order and, when after is set, appends the keyset
+// condition selecting the rows past it. Columns are qualified with prefix, e.g. "e.". Only the
+// fields of models.EventSortFields are accepted, so no client input reaches the SQL text.
+func eventOrder(order models.EventSort, after *pagination.Cursor, prefix string, conditions []string, args []interface{}) (string, []string, []interface{}, error) {
+	keys := []string{prefix + "created_at", prefix + "id"}
+	var values []interface{}
+	if after != nil {
+		values = []interface{}{after.CreatedAt, after.ID}
+	}
+
+	switch order.Field {
+	case "", models.EventSortCreatedAt:
+	case models.EventSortSeverity:
+		keys = append([]string{fmt.Sprintf(severityRank, prefix)}, keys...)
+		if after != nil {
+			values = append([]interface{}{models.SeverityLevel(after.Key)}, values...)
+		}
+	case models.EventSortEventType:
+		keys = append([]string{prefix + "event_type"}, keys...)
+		if after != nil {
+			values = append([]interface{}{after.Key}, values...)
+		}
+	default:
+		return "", nil, nil, fmt.Errorf("unsupported sort field: %s", order.Field)
+	}
+
+	direction, comparison := " DESC", "<"
+	if order.Ascending {
+		direction, comparison = " ASC", ">"
+	}
+
+	if after != nil {
+		placeholders := make([]string, len(values))
+		for i, value := range values {
+			args = append(args, value)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		conditions = append(conditions, fmt.Sprintf("(%s) %s (%s)",
+			strings.Join(keys, ", "), comparison, strings.Join(placeholders, ", ")))
+	}
+
+	return strings.Join(keys, direction+", ") + direction, conditions, args, nil
+}
+
+// GetEventSummaries retrieves a page of event summaries matching filter in the order of
+// page.Sort, along with the number of events matching filter across all pages
+func (r *EventRepository) GetEventSummaries(ctx context.Context, filter models.EventFilter, page models.PaginationParams) ([]models.EventSummary, int64, error) {
+	conditions, args := appendFilterConditions(nil, nil, filter)
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM security_events ` + whereClause(conditions)
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, queryError(ctx, "failed to count events", err)
+	}
+
+	orderBy, conditions, args, err := eventOrder(page.Sort, page.After, "e.", conditions, args)
+	if err != nil {
+		return nil, 0, err
+	}
+	args = append(args, page.Limit)
+
+	// Children are the other events that carry this event's ID as their correlation ID
+	query := `
+		SELECT e.id, e.event_id, e.event_type, e.severity, e.source,
+			e.status, e.created_at, e.acknowledged_at,
+			(SELECT COUNT(*) FROM security_events c WHERE c.correlation_id = e.event_id AND c.id <> e.id)
+		FROM security_events e
+		` + whereClause(conditions) + `
+		ORDER BY ` + orderBy + `
+		LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query events: %v", err)
+		return nil, 0, queryError(ctx, "failed to query event summaries", err)
 	}
 	defer rows.Close()
 
-	var events []*models.Event
+	summaries := []models.EventSummary{}
 	for rows.Next() {
-		event := &models.Event{}
+		var summary models.EventSummary
 		err := rows.Scan(
-			&event.ID,
-			&event.EventID,
-			&event.EventType,
-			&event.Severity,
-			&event.Source,
-			&event.Description,
-			&event.EventData,
-			&event.CreatedAt,
-			&event.UpdatedAt,
+			&summary.ID,
+			&summary.EventID,
+			&summary.EventType,
+			&summary.Severity,
+			&summary.Source,
+			&summary.Status,
+			&summary.CreatedAt,
+			&summary.AcknowledgedAt,
+			&summary.ChildrenCount,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan event: %v", err)
+			return nil, 0, queryError(ctx, "failed to scan event summary", err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, queryError(ctx, "error iterating event summaries", err)
+	}
+
+	return summaries, total, nil
+}
+
+// AcknowledgeEvent records when an event was acknowledged, moving an open event to
+// acknowledged. Acknowledging it again keeps the original time.
+func (r *EventRepository) AcknowledgeEvent(ctx context.Context, eventID string) (*models.Event, error) {
+	query := `
+		UPDATE security_events
+		SET acknowledged_at = COALESCE(acknowledged_at, NOW()),
+			status = CASE WHEN status = '` + models.EventStatusOpen + `' THEN '` + models.EventStatusAcknowledged + `' ELSE status END,
+			status_changed_by = NULL,
+			status_note = NULL,
+			updated_at = NOW()
+		WHERE event_id = $1
+		RETURNING ` + eventColumns
+
+	event, err := r.scan(r.db.QueryRowContext(ctx, query, eventID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("event not found")
+		}
+		return nil, queryError(ctx, "failed to acknowledge event", err)
+	}
+
+	return event, nil
+}
+
+// BulkUpdateStatus moves a batch of events of tenantID to status, applying
+// models.CanTransitionStatus to each one, and reports the outcome per event. Events of other
+// tenants are reported as not found; an empty tenantID reaches only events without a tenant.
+// Events are locked while their current status is checked and then updated with a single
+// statement, all in one transaction.
+func (r *EventRepository) BulkUpdateStatus(ctx context.Context, tenantID string, eventIDs []string, status, updatedBy, note string) models.BulkResult {
+	result := models.BulkResult{Status: status, Results: make([]models.BulkEventResult, 0, len(eventIDs))}
+
+	current, err := r.bulkUpdateStatus(ctx, tenantID, eventIDs, status, updatedBy, note)
+	for _, eventID := range eventIDs {
+		item := models.BulkEventResult{EventID: eventID}
+		from, found := current[eventID]
+		switch {
+		case err != nil:
+			item.Error = err.Error()
+		case !found:
+			item.Error = "event not found"
+		case !models.CanTransitionStatus(from, status):
+			item.Error = fmt.Sprintf("cannot change status from %s to %s", from, status)
+		default:
+			item.Success = true
+		}
+
+		if item.Success {
+			result.Updated++
+		} else {
+			result.Failed++
+		}
+		result.Results = append(result.Results, item)
+	}
+
+	return result
+}
+
+// bulkUpdateStatus updates the events of tenantID that may move to status and returns the
+// status each requested event of the tenant had before the update
+func (r *EventRepository) bulkUpdateStatus(ctx context.Context, tenantID string, eventIDs []string, status, updatedBy, note string) (map[string]string, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, queryError(ctx, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT event_id, status
+		FROM security_events
+		WHERE event_id = ANY($1) AND COALESCE(tenant_id, '') = $2
+		FOR UPDATE`, pq.Array(eventIDs), tenantID)
+	if err != nil {
+		return nil, queryError(ctx, "failed to load event statuses", err)
+	}
+
+	current := make(map[string]string, len(eventIDs))
+	var allowed []string
+	for rows.Next() {
+		var eventID, from string
+		if err := rows.Scan(&eventID, &from); err != nil {
+			rows.Close()
+			return nil, queryError(ctx, "failed to scan event status", err)
+		}
+		current[eventID] = from
+		if models.CanTransitionStatus(from, status) {
+			allowed = append(allowed, eventID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "error iterating event statuses", err)
+	}
+
+	if len(allowed) > 0 {
+		// The status trigger records the change with status_changed_by and status_note. An
+		// existing acknowledgement is kept as is.
+		_, err := tx.ExecContext(ctx, `
+			UPDATE security_events
+			SET status = $5::text,
+				status_changed_by = $2,
+				status_note = $3,
+				acknowledged_by = CASE WHEN $5::text = '`+models.EventStatusAcknowledged+`' AND acknowledged_at IS NULL THEN $2 ELSE acknowledged_by END,
+				acknowledgement_note = CASE WHEN $5::text = '`+models.EventStatusAcknowledged+`' AND acknowledged_at IS NULL THEN $3 ELSE acknowledgement_note END,
+				acknowledged_at = CASE WHEN $5::text = '`+models.EventStatusAcknowledged+`' THEN COALESCE(acknowledged_at, NOW()) ELSE acknowledged_at END,
+				updated_at = NOW()
+			WHERE event_id = ANY($1) AND COALESCE(tenant_id, '') = $4`, pq.Array(allowed), updatedBy, note, tenantID, status)
+		if err != nil {
+			return nil, queryError(ctx, "failed to update event statuses", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, queryError(ctx, "failed to commit transaction", err)
+	}
+
+	return current, nil
+}
+
+// FindEvents retrieves every event matching filter, ordered newest first
+func (r *EventRepository) FindEvents(ctx context.Context, filter models.EventFilter) ([]*models.Event, error) {
+	conditions, args := appendFilterConditions(nil, nil, filter)
+
+	query := `
+		SELECT ` + eventColumns + `
+		FROM security_events
+		` + whereClause(conditions) + `
+		ORDER BY created_at DESC, id DESC`
+
+	return r.queryEvents(ctx, query, args...)
+}
+
+// appendFilterConditions adds the SQL conditions for filter, numbering placeholders after args
+func appendFilterConditions(conditions []string, args []interface{}, filter models.EventFilter) ([]string, []interface{}) {
+	if filter.ATTACKTechnique != "" {
+		args = append(args, pq.Array([]string{filter.ATTACKTechnique}))
+		conditions = append(conditions, fmt.Sprintf("attack_techniques @> $%d", len(args)))
+	}
+
+	// Keys are quoted literals rather than parameters so the expression indexes match
+	fields := make([]string, 0, len(filter.DataFields))
+	for field := range filter.DataFields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		args = append(args, filter.DataFields[field])
+		conditions = append(conditions, fmt.Sprintf("event_data->>%s = $%d", pq.QuoteLiteral(field), len(args)))
+	}
+
+	return conditions, args
+}
+
+// CheckDataFieldFilter returns why event listings cannot be filtered by the event_data key
+// field, or an empty string when they can. Only indexed keys stored in plaintext are allowed.
+func (r *EventRepository) CheckDataFieldFilter(field string) string {
+	if !slices.Contains(models.IndexedEventDataFields, field) {
+		return "is not filterable, use one of " + strings.Join(models.IndexedEventDataFields, ", ")
+	}
+	if r.encryption.Enabled() && slices.Contains(r.encryption.Fields, field) {
+		return "is encrypted at rest and cannot be filtered"
+	}
+	return ""
+}
+
+// GetEventsByDataField retrieves a page of the events whose event_data key field equals value,
+// in the order of page.Sort. It returns a validation error when field cannot be filtered.
+func (r *EventRepository) GetEventsByDataField(ctx context.Context, field, value string, page models.PaginationParams) ([]*models.Event, error) {
+	if problem := r.CheckDataFieldFilter(field); problem != "" {
+		return nil, apperrors.NewValidationError("Invalid filter", "data."+field+" "+problem)
+	}
+	return r.ListEvents(ctx, models.EventFilter{DataFields: map[string]string{field: value}}, page)
+}
+
+// whereClause joins conditions into a WHERE clause, or returns an empty string
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+// ListEventsCreatedBefore retrieves up to limit of the oldest events created before the given time
+func (r *EventRepository) ListEventsCreatedBefore(ctx context.Context, before time.Time, limit int) ([]*models.Event, error) {
+	return r.queryEvents(ctx, `
+		SELECT `+eventColumns+`
+		FROM security_events
+		WHERE created_at < $1
+		ORDER BY created_at, id
+		LIMIT $2`, before, limit)
+}
+
+// StreamEventsCreatedBetween calls fn for every event created in [from, to), oldest first. Events
+// are read batchSize at a time, so memory use does not grow with the range. It stops at the
+// first error returned by fn, which it returns, or once ctx is done.
+func (r *EventRepository) StreamEventsCreatedBetween(ctx context.Context, from, to time.Time, batchSize int, fn func(*models.Event) error) error {
+	// (created_at, id) of the last event handed to fn; the nil UUID sorts before every event
+	// created at from
+	afterCreatedAt, afterID := from, "00000000-0000-0000-0000-000000000000"
+	for {
+		events, err := r.queryEvents(ctx, `
+			SELECT `+eventColumns+`
+			FROM security_events
+			WHERE (created_at, id) > ($1, $2) AND created_at < $3
+			ORDER BY created_at, id
+			LIMIT $4`, afterCreatedAt, afterID, to, batchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if err := fn(event); err != nil {
+				return err
+			}
+		}
+		if len(events) < batchSize {
+			return nil
+		}
+		last := events[len(events)-1]
+		afterCreatedAt, afterID = last.CreatedAt, last.ID
+	}
+}
+
+// recentEventsBySourceQuery selects the newest events of source $1 created after $2, up to $3.
+// It is served by idx_security_events_source_created_at.
+const recentEventsBySourceQuery = `
+	SELECT ` + eventColumns + `
+	FROM security_events
+	WHERE source = $1 AND created_at > $2
+	ORDER BY created_at DESC
+	LIMIT $3`
+
+// GetRecentEventsBySource retrieves up to limit of the newest events of a source created after since
+func (r *EventRepository) GetRecentEventsBySource(ctx context.Context, source string, limit int, since time.Time) ([]*models.Event, error) {
+	return r.queryEvents(ctx, recentEventsBySourceQuery, source, since, limit)
+}
+
+// queryEvents runs an event query bound to ctx and scans all rows
+func (r *EventRepository) queryEvents(ctx context.Context, query string, args ...interface{}) ([]*models.Event, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, queryError(ctx, "failed to query events", err)
+	}
+	defer rows.Close()
+
+	var events []*models.Event
+	for rows.Next() {
+		event, err := r.scan(rows)
+		if err != nil {
+			return nil, queryError(ctx, "failed to scan event", err)
 		}
 		events = append(events, event)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating events: %v", err)
+		return nil, queryError(ctx, "error iterating events", err)
 	}
 
 	return events, nil
 }
 
-func (r *EventRepository) UpdateEvent(eventID string, updates *models.UpdateEventRequest) (*models.Event, error) {
+func (r *EventRepository) UpdateEvent(ctx context.Context, eventID string, updates *models.UpdateEventRequest) (*models.Event, error) {
 	query := `
 		UPDATE security_events
 		SET event_type = COALESCE($2, event_type),
@@ -119,51 +667,88 @@ func (r *EventRepository) UpdateEvent(eventID string, updates *models.UpdateEven
 			event_data = COALESCE($6, event_data),
 			updated_at = NOW()
 		WHERE event_id = $1
-		RETURNING id, event_id, event_type, severity, source, description, event_data, created_at, updated_at`
+		RETURNING ` + eventColumns
 
-	event := &models.Event{}
-	err := r.db.QueryRow(
+	eventData, err := r.encodeEventData(updates.EventData)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := r.scan(r.db.QueryRowContext(ctx,
 		query,
 		eventID,
 		updates.EventType,
 		updates.Severity,
 		updates.Source,
 		updates.Description,
-		updates.EventData,
-	).Scan(
-		&event.ID,
-		&event.EventID,
-		&event.EventType,
-		&event.Severity,
-		&event.Source,
-		&event.Description,
-		&event.EventData,
-		&event.CreatedAt,
-		&event.UpdatedAt,
-	)
+		eventData,
+	))
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("event not found")
 		}
-		return nil, fmt.Errorf("failed to update event: %v", err)
+		return nil, queryError(ctx, "failed to update event", err)
+	}
+
+	return event, nil
+}
+
+// PatchEventData deep-merges patch into the stored event_data (see models.MergeEventData) and
+// returns the updated event. The row is locked while merging so concurrent patches do not
+// lose each other's keys.
+func (r *EventRepository) PatchEventData(ctx context.Context, eventID string, patch models.EventData) (*models.Event, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, queryError(ctx, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	var current models.EventData
+	err = tx.QueryRowContext(ctx, `SELECT event_data FROM security_events WHERE event_id = $1 FOR UPDATE`, eventID).Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("event not found")
+		}
+		return nil, queryError(ctx, "failed to load event data", err)
+	}
+	if err := r.decryptEventData(current); err != nil {
+		return nil, err
+	}
+
+	eventData, err := r.encodeEventData(models.MergeEventData(current, patch))
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := r.scan(tx.QueryRowContext(ctx, `
+		UPDATE security_events
+		SET event_data = $2, updated_at = NOW()
+		WHERE event_id = $1
+		RETURNING `+eventColumns, eventID, eventData))
+	if err != nil {
+		return nil, queryError(ctx, "failed to patch event data", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, queryError(ctx, "failed to commit event data patch", err)
 	}
 
 	return event, nil
 }
 
 // DeleteEvent deletes an event from the database
-func (r *EventRepository) DeleteEvent(eventID string) error {
+func (r *EventRepository) DeleteEvent(ctx context.Context, eventID string) error {
 	query := `DELETE FROM security_events WHERE event_id = $1`
 
-	result, err := r.db.Exec(query, eventID)
+	result, err := r.db.ExecContext(ctx, query, eventID)
 	if err != nil {
-		return fmt.Errorf("failed to delete event: %v", err)
+		return queryError(ctx, "failed to delete event", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %v", err)
+		return queryError(ctx, "failed to get rows affected", err)
 	}
 
 	if rowsAffected == 0 {
@@ -171,4 +756,317 @@ func (r *EventRepository) DeleteEvent(eventID string) error {
 	}
 
 	return nil
-} 
\ No newline at end of file
+}
+
+// expiredDeleteBatchSize bounds the rows removed per statement by DeleteEventsCreatedBefore
+const expiredDeleteBatchSize = 10000
+
+// DeleteEventsCreatedBefore deletes every event created before the given time, in batches so
+// a large backlog does not hold locks for long, and returns how many were deleted
+func (r *EventRepository) DeleteEventsCreatedBefore(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	for {
+		result, err := r.db.ExecContext(ctx, `
+			DELETE FROM security_events
+			WHERE id IN (SELECT id FROM security_events WHERE created_at < $1 LIMIT $2)`,
+			before, expiredDeleteBatchSize)
+		if err != nil {
+			return deleted, queryError(ctx, "failed to delete expired events", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return deleted, queryError(ctx, "failed to get rows affected", err)
+		}
+		deleted += rowsAffected
+
+		if rowsAffected < expiredDeleteBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// DeleteEvents deletes a batch of events in a single transaction, reporting which IDs did not exist
+func (r *EventRepository) DeleteEvents(ctx context.Context, eventIDs []string) (*models.DeleteEventsResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, queryError(ctx, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `DELETE FROM security_events WHERE event_id = ANY($1) RETURNING event_id`, pq.Array(eventIDs))
+	if err != nil {
+		return nil, queryError(ctx, "failed to delete events", err)
+	}
+
+	deleted := make(map[string]bool, len(eventIDs))
+	for rows.Next() {
+		var eventID string
+		if err := rows.Scan(&eventID); err != nil {
+			rows.Close()
+			return nil, queryError(ctx, "failed to scan deleted event", err)
+		}
+		deleted[eventID] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "error iterating deleted events", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, queryError(ctx, "failed to commit transaction", err)
+	}
+
+	result := &models.DeleteEventsResult{Deleted: len(deleted), NotFound: []string{}}
+	seen := make(map[string]bool, len(eventIDs))
+	for _, eventID := range eventIDs {
+		if !deleted[eventID] && !seen[eventID] {
+			result.NotFound = append(result.NotFound, eventID)
+		}
+		seen[eventID] = true
+	}
+
+	return result, nil
+}
+
+// CountEventsByBucket counts events per time bucket in [from, to). Buckets without
+// events are filled with zero counts so the series is continuous.
+func (r *EventRepository) CountEventsByBucket(ctx context.Context, bucket time.Duration, from, to time.Time) ([]models.TimeSeriesBucket, error) {
+	bucketSeconds := int64(bucket / time.Second)
+
+	query := `
+		SELECT to_timestamp(floor(extract(epoch FROM created_at) / $1) * $1) AS bucket, COUNT(*)
+		FROM security_events
+		WHERE created_at >= $2 AND created_at < $3
+		GROUP BY bucket
+		ORDER BY bucket`
+
+	rows, err := r.db.QueryContext(ctx, query, bucketSeconds, from, to)
+	if err != nil {
+		return nil, queryError(ctx, "failed to query event time series", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int64)
+	for rows.Next() {
+		var bucketStart time.Time
+		var count int64
+		if err := rows.Scan(&bucketStart, &count); err != nil {
+			return nil, queryError(ctx, "failed to scan time series bucket", err)
+		}
+		counts[bucketStart.Unix()] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, queryError(ctx, "error iterating time series", err)
+	}
+
+	return fillTimeSeries(counts, bucketSeconds, from, to), nil
+}
+
+// fillTimeSeries lists every bucket of bucketSeconds between from and to with its count in
+// counts, keyed by bucket start in Unix seconds, and zero for empty buckets. Buckets are
+// aligned on the Unix epoch like the query's floor(epoch / size) * size, so every bucket start
+// matches a key of counts.
+func fillTimeSeries(counts map[int64]int64, bucketSeconds int64, from, to time.Time) []models.TimeSeriesBucket {
+	start := from.Unix()
+	start -= start % bucketSeconds
+	if start > from.Unix() {
+		// Go truncates toward zero, so a start before the epoch is rounded up; floor it instead
+		start -= bucketSeconds
+	}
+
+	var series []models.TimeSeriesBucket
+	for t := start; time.Unix(t, 0).Before(to); t += bucketSeconds {
+		series = append(series, models.TimeSeriesBucket{
+			Timestamp: time.Unix(t, 0).UTC(),
+			Count:     counts[t],
+		})
+	}
+	return series
+}
+
+// CountEventsBySeverity counts the events matching filter per severity. Every severity is
+// included, with zero when no event has it.
+func (r *EventRepository) CountEventsBySeverity(ctx context.Context, filter models.EventFilter) (map[string]int64, error) {
+	conditions, args := appendFilterConditions(nil, nil, filter)
+	query := `
+		SELECT severity, COUNT(*)
+		FROM security_events
+		` + whereClause(conditions) + `
+		GROUP BY severity`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, queryError(ctx, "failed to count events by severity", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64, len(models.Severities))
+	for _, severity := range models.Severities {
+		counts[severity] = 0
+	}
+	for rows.Next() {
+		var severity string
+		var count int64
+		if err := rows.Scan(&severity, &count); err != nil {
+			return nil, queryError(ctx, "failed to scan severity count", err)
+		}
+		counts[severity] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, queryError(ctx, "error iterating severity counts", err)
+	}
+
+	return counts, nil
+}
+
+// CountEventsByHour counts events created in [from, to) per UTC hour. Hours without events are
+// filled with zero counts so the series is continuous.
+func (r *EventRepository) CountEventsByHour(ctx context.Context, from, to time.Time) ([]models.HourlyCount, error) {
+	query := `
+		SELECT date_trunc('hour', created_at AT TIME ZONE 'UTC') AS hour, COUNT(*)
+		FROM security_events
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY hour
+		ORDER BY hour`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, queryError(ctx, "failed to count events by hour", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]int64)
+	for rows.Next() {
+		var hour time.Time
+		var count int64
+		if err := rows.Scan(&hour, &count); err != nil {
+			return nil, queryError(ctx, "failed to scan hourly count", err)
+		}
+		counts[hour.Unix()] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, queryError(ctx, "error iterating hourly counts", err)
+	}
+
+	var series []models.HourlyCount
+	for t := from.UTC().Truncate(time.Hour); t.Before(to); t = t.Add(time.Hour) {
+		series = append(series, models.HourlyCount{Hour: t, Count: counts[t.Unix()]})
+	}
+
+	return series, nil
+}
+
+// TopSources returns the limit sources with the most events matching filter, busiest first
+func (r *EventRepository) TopSources(ctx context.Context, limit int, filter models.EventFilter) ([]models.SourceCount, error) {
+	conditions, args := appendFilterConditions(nil, nil, filter)
+	args = append(args, limit)
+	query := `
+		SELECT source, COUNT(*) AS count
+		FROM security_events
+		` + whereClause(conditions) + `
+		GROUP BY source
+		ORDER BY count DESC, source
+		LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, queryError(ctx, "failed to count events by source", err)
+	}
+	defer rows.Close()
+
+	sources := []models.SourceCount{}
+	for rows.Next() {
+		var source models.SourceCount
+		if err := rows.Scan(&source.Source, &source.Count); err != nil {
+			return nil, queryError(ctx, "failed to scan source count", err)
+		}
+		sources = append(sources, source)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, queryError(ctx, "error iterating source counts", err)
+	}
+
+	return sources, nil
+}
+
+// maxFacetValues caps the distinct values returned per facet, keeping the most common
+const maxFacetValues = 100
+
+// GetEventFacets returns the distinct event types, severities and sources with their event
+// counts, up to maxFacetValues of each
+func (r *EventRepository) GetEventFacets(ctx context.Context) (*models.EventFacets, error) {
+	var facets models.EventFacets
+	var err error
+	if facets.EventTypes, err = r.facetValues(ctx, "event_type"); err != nil {
+		return nil, err
+	}
+	if facets.Severities, err = r.facetValues(ctx, "severity"); err != nil {
+		return nil, err
+	}
+	if facets.Sources, err = r.facetValues(ctx, "source"); err != nil {
+		return nil, err
+	}
+	return &facets, nil
+}
+
+// facetValues counts the events per distinct value of column, which must be a trusted column
+// name, most common first
+func (r *EventRepository) facetValues(ctx context.Context, column string) ([]models.FacetValue, error) {
+	query := `
+		SELECT ` + column + `, COUNT(*) AS count
+		FROM security_events
+		GROUP BY ` + column + `
+		ORDER BY count DESC, ` + column + `
+		LIMIT $1`
+
+	rows, err := r.db.QueryContext(ctx, query, maxFacetValues)
+	if err != nil {
+		return nil, queryError(ctx, "failed to query "+column+" facet", err)
+	}
+	defer rows.Close()
+
+	values := []models.FacetValue{}
+	for rows.Next() {
+		var value models.FacetValue
+		if err := rows.Scan(&value.Value, &value.Count); err != nil {
+			return nil, queryError(ctx, "failed to scan "+column+" facet", err)
+		}
+		values = append(values, value)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, queryError(ctx, "error iterating "+column+" facet", err)
+	}
+
+	return values, nil
+}
+
+// ListTenantIDs returns the distinct tenants of the stored events, sorted
+func (r *EventRepository) ListTenantIDs(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT DISTINCT tenant_id FROM security_events
+		WHERE tenant_id IS NOT NULL
+		ORDER BY tenant_id`)
+	if err != nil {
+		return nil, queryError(ctx, "failed to list tenants", err)
+	}
+	defer rows.Close()
+
+	tenantIDs := []string{}
+	for rows.Next() {
+		var tenantID string
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, queryError(ctx, "failed to scan tenant", err)
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "failed to list tenants", err)
+	}
+	return tenantIDs, nil
+}
@@ -0,0 +1,465 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"skyhawk-security-microservice/internal/config"
+	"skyhawk-security-microservice/internal/crypto"
+	"skyhawk-security-microservice/internal/database"
+	apperrors "skyhawk-security-microservice/internal/errors"
+	"skyhawk-security-microservice/internal/models"
+	"skyhawk-security-microservice/internal/pagination"
+)
+
+func TestEncodeEventDataRejectsCiphertextLookalikes(t *testing.T) {
+	enc, err := crypto.NewFieldEncrypterFromHex(strings.Repeat("01", 32))
+	if err != nil {
+		t.Fatalf("NewFieldEncrypterFromHex: %v", err)
+	}
+
+	for name, encryption := range map[string]config.FieldEncryption{
+		"enabled":  {Encrypter: enc, Fields: []string{"email"}},
+		"disabled": {Fields: []string{"email"}},
+	} {
+		r := &EventRepository{maxEventDataSize: models.DefaultMaxEventDataSize, encryption: encryption}
+
+		_, err := r.encodeEventData(models.EventData{"email": "enc:v1:garbage"})
+		appErr, ok := err.(*apperrors.AppError)
+		if !ok || appErr.Type != apperrors.ErrorTypeValidation {
+			t.Errorf("%s: encodeEventData returned %v, want a validation error", name, err)
+		}
+
+		if _, err := r.encodeEventData(models.EventData{"email": "alice@example.com"}); err != nil {
+			t.Errorf("%s: encodeEventData of a plaintext email returned %v", name, err)
+		}
+	}
+}
+
+func TestEncodeEventDataEncryptsProtectedFields(t *testing.T) {
+	enc, err := crypto.NewFieldEncrypterFromHex(strings.Repeat("01", 32))
+	if err != nil {
+		t.Fatalf("NewFieldEncrypterFromHex: %v", err)
+	}
+	r := &EventRepository{
+		maxEventDataSize: models.DefaultMaxEventDataSize,
+		encryption:       config.FieldEncryption{Encrypter: enc, Fields: []string{"email"}},
+	}
+
+	data := models.EventData{"email": "alice@example.com"}
+	encoded, err := r.encodeEventData(data)
+	if err != nil {
+		t.Fatalf("encodeEventData: %v", err)
+	}
+	if raw := encoded.(string); strings.Contains(raw, "alice") || !strings.Contains(raw, "enc:v2:") {
+		t.Errorf("stored event_data %s does not hold an encrypted email", raw)
+	}
+	if data["email"] != "alice@example.com" {
+		t.Errorf("caller's event data was modified: %v", data)
+	}
+}
+
+func TestCheckDataFieldFilterAllowsOnlyIndexedPlaintextKeys(t *testing.T) {
+	enc, err := crypto.NewFieldEncrypterFromHex(strings.Repeat("01", 32))
+	if err != nil {
+		t.Fatalf("NewFieldEncrypterFromHex: %v", err)
+	}
+	r := &EventRepository{encryption: config.FieldEncryption{Encrypter: enc, Fields: []string{"username"}}}
+
+	for field, allowed := range map[string]bool{
+		"source_ip":  true,
+		"session_id": true,
+		"file_path":  true,
+		"username":   false,
+		"password":   false,
+		"":           false,
+	} {
+		if problem := r.CheckDataFieldFilter(field); (problem == "") != allowed {
+			t.Errorf("CheckDataFieldFilter(%q) = %q, want allowed %v", field, problem, allowed)
+		}
+	}
+
+	_, err = r.GetEventsByDataField(context.Background(), "password", "hunter2", models.PaginationParams{Limit: 10})
+	if appErr, ok := err.(*apperrors.AppError); !ok || appErr.Type != apperrors.ErrorTypeValidation {
+		t.Errorf("GetEventsByDataField of an unindexed key returned %v, want a validation error", err)
+	}
+}
+
+func TestFillTimeSeriesAlignsOnUnixEpoch(t *testing.T) {
+	from := time.Date(2024, 3, 5, 12, 2, 30, 0, time.UTC)
+	to := time.Date(2024, 3, 5, 12, 15, 0, 0, time.UTC)
+	counts := map[int64]int64{time.Date(2024, 3, 5, 12, 5, 0, 0, time.UTC).Unix(): 4}
+
+	series := fillTimeSeries(counts, 300, from, to)
+
+	want := []models.TimeSeriesBucket{
+		{Timestamp: time.Date(2024, 3, 5, 12, 0, 0, 0, time.UTC), Count: 0},
+		{Timestamp: time.Date(2024, 3, 5, 12, 5, 0, 0, time.UTC), Count: 4},
+		{Timestamp: time.Date(2024, 3, 5, 12, 10, 0, 0, time.UTC), Count: 0},
+	}
+	if !reflect.DeepEqual(series, want) {
+		t.Errorf("series = %v, want %v", series, want)
+	}
+}
+
+func TestFillTimeSeriesMatchesQueryBucketsNotDividingADay(t *testing.T) {
+	// Weekly buckets counted from the epoch start on Thursdays, where time.Truncate would
+	// start them on Mondays and miss every count
+	week := int64(7 * 24 * 60 * 60)
+	from := time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC)
+	bucketStart := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	counts := map[int64]int64{bucketStart.Unix(): 9}
+
+	series := fillTimeSeries(counts, week, from, from.Add(time.Hour))
+
+	if len(series) != 1 || !series[0].Timestamp.Equal(bucketStart) || series[0].Count != 9 {
+		t.Errorf("series = %v, want one bucket at %s with 9 events", series, bucketStart)
+	}
+}
+
+// slowQueryDriver opens connections whose queries take slowQueryDuration unless their context
+// ends first. Connections of the data source "fail" fail every query instead.
+type slowQueryDriver struct{}
+
+const slowQueryDuration = 10 * time.Second
+
+var errQueryFailed = errors.New("relation does not exist")
+
+func (slowQueryDriver) Open(name string) (driver.Conn, error) {
+	return slowQueryConn{fail: name == "fail"}, nil
+}
+
+type slowQueryConn struct {
+	fail bool
+}
+
+func (slowQueryConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (slowQueryConn) Close() error              { return nil }
+func (slowQueryConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (c slowQueryConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if c.fail {
+		return nil, errQueryFailed
+	}
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(slowQueryDuration):
+		return nil, errors.New("slow query finished")
+	}
+}
+
+func init() {
+	sql.Register("slowquery", slowQueryDriver{})
+}
+
+func newSlowQueryRepository(t *testing.T, dsn string) *EventRepository {
+	t.Helper()
+	db, err := sql.Open("slowquery", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &EventRepository{db: &database.DB{DB: db}, maxEventDataSize: models.DefaultMaxEventDataSize}
+}
+
+func TestGetEventByIDReturnsErrContextCancelledWhenCancelled(t *testing.T) {
+	r := newSlowQueryRepository(t, "")
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := r.GetEventByID(ctx, "evt-1")
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetEventByID returned after %s, want it to stop with the context", elapsed)
+	}
+	if !errors.Is(err, ErrContextCancelled) || !errors.Is(err, context.Canceled) {
+		t.Errorf("GetEventByID returned %v, want ErrContextCancelled wrapping context.Canceled", err)
+	}
+}
+
+func TestGetAllEventsReturnsErrContextCancelledPastDeadline(t *testing.T) {
+	r := newSlowQueryRepository(t, "")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := r.GetAllEvents(ctx)
+	if !errors.Is(err, ErrContextCancelled) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetAllEvents returned %v, want ErrContextCancelled wrapping context.DeadlineExceeded", err)
+	}
+}
+
+func TestQueryFailureIsNotErrContextCancelled(t *testing.T) {
+	r := newSlowQueryRepository(t, "fail")
+
+	_, err := r.GetEventByID(context.Background(), "evt-1")
+	if err == nil || errors.Is(err, ErrContextCancelled) {
+		t.Errorf("GetEventByID returned %v, want a database error other than ErrContextCancelled", err)
+	}
+}
+
+// createTestEvent stores a login_failure event of tenantID
+func createTestEvent(tb testing.TB, r *EventRepository, eventID, tenantID string) *models.Event {
+	tb.Helper()
+	event := &models.Event{EventID: eventID, EventType: "login_failure", Severity: "high", Source: "auth-service", TenantID: tenantID}
+	if err := r.CreateEvent(context.Background(), event); err != nil {
+		tb.Fatalf("CreateEvent(%s): %v", eventID, err)
+	}
+	return event
+}
+
+func TestBulkUpdateStatusIsScopedToTenantAgainstPostgres(t *testing.T) {
+	r := migratedRepository(t)
+	ctx := context.Background()
+	createTestEvent(t, r, "evt-acme", "acme")
+	createTestEvent(t, r, "evt-globex", "globex")
+	createTestEvent(t, r, "evt-none", "")
+
+	result := r.BulkUpdateStatus(ctx, "acme", []string{"evt-acme", "evt-globex", "evt-none"}, models.EventStatusAcknowledged, "user-7", "triage")
+	if result.Updated != 1 || result.Failed != 2 {
+		t.Errorf("updated %d and failed %d events, want 1 and 2: %+v", result.Updated, result.Failed, result.Results)
+	}
+	for _, item := range result.Results[1:] {
+		if item.Error != "event not found" {
+			t.Errorf("%s of another tenant: error %q, want event not found", item.EventID, item.Error)
+		}
+	}
+
+	for eventID, wantStatus := range map[string]string{
+		"evt-acme":   models.EventStatusAcknowledged,
+		"evt-globex": models.EventStatusOpen,
+		"evt-none":   models.EventStatusOpen,
+	} {
+		event, err := r.GetEventByID(ctx, eventID)
+		if err != nil {
+			t.Fatalf("GetEventByID(%s): %v", eventID, err)
+		}
+		if event.Status != wantStatus {
+			t.Errorf("%s is %s, want %s", eventID, event.Status, wantStatus)
+		}
+		if eventID == "evt-acme" && event.AcknowledgedBy != "user-7" {
+			t.Errorf("%s acknowledged by %q, want user-7", eventID, event.AcknowledgedBy)
+		}
+	}
+
+	// Without a tenant only events without one are reached
+	result = r.BulkUpdateStatus(ctx, "", []string{"evt-globex", "evt-none"}, models.EventStatusAcknowledged, "user-7", "")
+	if result.Updated != 1 || !result.Results[1].Success {
+		t.Errorf("untenanted update = %+v, want only evt-none updated", result.Results)
+	}
+}
+
+// BenchmarkGetRecentEventsBySourceAgainstPostgres reads the newest 50 events of one source
+// among 100,000 events, which must take under 10ms through the source index
+func BenchmarkGetRecentEventsBySourceAgainstPostgres(b *testing.B) {
+	r := migratedRepository(b)
+	seedEvents(b, r, 100000)
+	ctx := context.Background()
+	since := time.Now().Add(-24 * time.Hour)
+
+	if plan := explain(b, r, recentEventsBySourceQuery, "source-7", since, 50); !strings.Contains(plan, "idx_security_events_source_created_at") {
+		b.Fatalf("query does not use idx_security_events_source_created_at:\n%s", plan)
+	}
+
+	b.Run("Limit=50", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			events, err := r.GetRecentEventsBySource(ctx, "source-7", 50, since)
+			if err != nil {
+				b.Fatalf("GetRecentEventsBySource: %v", err)
+			}
+			if len(events) != 50 {
+				b.Fatalf("got %d events, want 50", len(events))
+			}
+		}
+		if perQuery := b.Elapsed() / time.Duration(b.N); perQuery > 10*time.Millisecond {
+			b.Errorf("query took %s, want under 10ms", perQuery)
+		}
+	})
+}
+
+// TestDataFieldFiltersUseExpressionIndexesAgainstPostgres checks the plan of listings filtered by
+// each indexed event_data key over 100,000 events. The filter must repeat the expression of
+// its index, with the key as a literal, for the planner to use it.
+func TestDataFieldFiltersUseExpressionIndexesAgainstPostgres(t *testing.T) {
+	r := migratedRepository(t)
+	seedEvents(t, r, 100000)
+
+	values := map[string]string{
+		"source_ip":  "10.0.7.7",
+		"username":   "user-7",
+		"session_id": "session-7",
+		"file_path":  "/var/log/app-7.log",
+	}
+	for _, field := range models.IndexedEventDataFields {
+		query, args, err := listEventsQuery(models.EventFilter{DataFields: map[string]string{field: values[field]}}, models.PaginationParams{Limit: 50})
+		if err != nil {
+			t.Fatalf("%s: listEventsQuery: %v", field, err)
+		}
+		if want := "event_data->>'" + field + "' = $1"; !strings.Contains(query, want) {
+			t.Errorf("%s: query does not filter on %s:\n%s", field, want, query)
+		}
+
+		plan := explain(t, r, query, args...)
+		index := "idx_security_events_data_" + field
+		if !strings.Contains(plan, "Index Scan") || !strings.Contains(plan, index) || strings.Contains(plan, "Seq Scan") {
+			t.Errorf("%s: plan does not scan %s:\n%s", field, index, plan)
+		}
+	}
+}
+
+func TestEventOrderBuildsKeysetForEachSortFieldAndDirection(t *testing.T) {
+	createdAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rank := fmt.Sprintf(severityRank, "e.")
+	tests := []struct {
+		sort      models.EventSort
+		key       string
+		orderBy   string
+		condition string
+		values    []interface{}
+	}{
+		{
+			sort:      models.EventSort{},
+			orderBy:   "e.created_at DESC, e.id DESC",
+			condition: "(e.created_at, e.id) < ($2, $3)",
+			values:    []interface{}{createdAt, "id-1"},
+		},
+		{
+			sort:      models.EventSort{Field: models.EventSortCreatedAt, Ascending: true},
+			orderBy:   "e.created_at ASC, e.id ASC",
+			condition: "(e.created_at, e.id) > ($2, $3)",
+			values:    []interface{}{createdAt, "id-1"},
+		},
+		{
+			sort:      models.EventSort{Field: models.EventSortSeverity},
+			key:       "high",
+			orderBy:   rank + " DESC, e.created_at DESC, e.id DESC",
+			condition: "(" + rank + ", e.created_at, e.id) < ($2, $3, $4)",
+			values:    []interface{}{models.SeverityLevel("high"), createdAt, "id-1"},
+		},
+		{
+			sort:      models.EventSort{Field: models.EventSortSeverity, Ascending: true},
+			key:       "high",
+			orderBy:   rank + " ASC, e.created_at ASC, e.id ASC",
+			condition: "(" + rank + ", e.created_at, e.id) > ($2, $3, $4)",
+			values:    []interface{}{models.SeverityLevel("high"), createdAt, "id-1"},
+		},
+		{
+			sort:      models.EventSort{Field: models.EventSortEventType},
+			key:       "login_failure",
+			orderBy:   "e.event_type DESC, e.created_at DESC, e.id DESC",
+			condition: "(e.event_type, e.created_at, e.id) < ($2, $3, $4)",
+			values:    []interface{}{"login_failure", createdAt, "id-1"},
+		},
+		{
+			sort:      models.EventSort{Field: models.EventSortEventType, Ascending: true},
+			key:       "login_failure",
+			orderBy:   "e.event_type ASC, e.created_at ASC, e.id ASC",
+			condition: "(e.event_type, e.created_at, e.id) > ($2, $3, $4)",
+			values:    []interface{}{"login_failure", createdAt, "id-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		name := tt.sort.Token()
+		// A filter argument comes first, so the keyset placeholders are numbered after it
+		orderBy, conditions, args, err := eventOrder(tt.sort, nil, "e.", []string{"e.source = $1"}, []interface{}{"auth-service"})
+		if err != nil {
+			t.Fatalf("%q: eventOrder: %v", name, err)
+		}
+		if orderBy != tt.orderBy || len(conditions) != 1 || len(args) != 1 {
+			t.Errorf("%q: first page ordered by %q with %q, %v, want %q and only the filter", name, orderBy, conditions, args, tt.orderBy)
+		}
+
+		after := &pagination.Cursor{CreatedAt: createdAt, ID: "id-1", Key: tt.key, Sort: tt.sort.Token()}
+		orderBy, conditions, args, err = eventOrder(tt.sort, after, "e.", []string{"e.source = $1"}, []interface{}{"auth-service"})
+		if err != nil {
+			t.Fatalf("%q: eventOrder after a cursor: %v", name, err)
+		}
+		if orderBy != tt.orderBy {
+			t.Errorf("%q: ordered by %q, want %q", name, orderBy, tt.orderBy)
+		}
+		if len(conditions) != 2 || conditions[1] != tt.condition {
+			t.Errorf("%q: conditions = %q, want the keyset condition %q", name, conditions, tt.condition)
+		}
+		if want := append([]interface{}{"auth-service"}, tt.values...); !reflect.DeepEqual(args, want) {
+			t.Errorf("%q: args = %v, want %v", name, args, want)
+		}
+	}
+
+	for _, field := range []string{"description", "created_at; DROP TABLE security_events"} {
+		if _, _, _, err := eventOrder(models.EventSort{Field: field}, nil, "", nil, nil); err == nil {
+			t.Errorf("sorting by %q was accepted", field)
+		}
+	}
+}
+
+func TestPatchEventDataAgainstPostgres(t *testing.T) {
+	r := migratedRepository(t)
+	ctx := context.Background()
+	event := &models.Event{
+		EventID:   "evt-1",
+		EventType: "login_failure",
+		Severity:  "high",
+		Source:    "auth-service",
+		EventData: models.EventData{
+			"user":   "alice",
+			"device": map[string]interface{}{"os": "linux", "agent": map[string]interface{}{"version": "1.2", "mode": "audit"}},
+		},
+	}
+	if err := r.CreateEvent(ctx, event); err != nil {
+		t.Fatalf("CreateEvent: %v", err)
+	}
+
+	patch := models.EventData{
+		"device": map[string]interface{}{"agent": map[string]interface{}{"version": "1.3", "mode": nil}},
+		"user":   nil,
+	}
+	if _, err := r.PatchEventData(ctx, "evt-1", patch); err != nil {
+		t.Fatalf("PatchEventData: %v", err)
+	}
+
+	stored, err := r.GetEventByID(ctx, "evt-1")
+	if err != nil {
+		t.Fatalf("GetEventByID: %v", err)
+	}
+	want := models.EventData{"device": map[string]interface{}{"os": "linux", "agent": map[string]interface{}{"version": "1.3"}}}
+	if !reflect.DeepEqual(stored.EventData, want) {
+		t.Errorf("stored event_data = %v, want %v", stored.EventData, want)
+	}
+
+	if _, err := r.PatchEventData(ctx, "evt-404", patch); err == nil || err.Error() != "event not found" {
+		t.Errorf("patching a missing event returned %v, want event not found", err)
+	}
+}
+
+func TestDeleteEventsAgainstPostgres(t *testing.T) {
+	r := migratedRepository(t)
+	ctx := context.Background()
+	for _, eventID := range []string{"evt-1", "evt-2", "evt-3"} {
+		createTestEvent(t, r, eventID, "")
+	}
+
+	result, err := r.DeleteEvents(ctx, []string{"evt-1", "evt-404", "evt-2", "evt-404", "evt-1"})
+	if err != nil {
+		t.Fatalf("DeleteEvents: %v", err)
+	}
+	if result.Deleted != 2 || !reflect.DeepEqual(result.NotFound, []string{"evt-404"}) {
+		t.Errorf("result = %+v, want 2 deleted and evt-404 not found once", result)
+	}
+
+	for eventID, wantFound := range map[string]bool{"evt-1": false, "evt-2": false, "evt-3": true} {
+		_, err := r.GetEventByID(ctx, eventID)
+		if found := err == nil; found != wantFound {
+			t.Errorf("%s found = %t after the delete, want %t (%v)", eventID, found, wantFound, err)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"skyhawk-security-microservice/internal/database"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// fixedRowsConnector opens connections that answer every query with the same rows
+type fixedRowsConnector struct {
+	rows [][]driver.Value
+}
+
+func (c *fixedRowsConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.Open("")
+}
+
+func (c *fixedRowsConnector) Open(name string) (driver.Conn, error) {
+	return fixedRowsConn{rows: c.rows}, nil
+}
+
+func (c *fixedRowsConnector) Driver() driver.Driver { return c }
+
+type fixedRowsConn struct {
+	rows [][]driver.Value
+}
+
+func (fixedRowsConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (fixedRowsConn) Close() error              { return nil }
+func (fixedRowsConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (c fixedRowsConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &fixedRows{rows: c.rows}, nil
+}
+
+type fixedRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *fixedRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+
+func (r *fixedRows) Close() error { return nil }
+
+func (r *fixedRows) Next(dest []driver.Value) error {
+	if r.next == len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
+
+// newFixedRowsRepository returns a repository whose queries all return rows
+func newFixedRowsRepository(t *testing.T, rows ...[]driver.Value) *EventRepository {
+	t.Helper()
+	db := sql.OpenDB(&fixedRowsConnector{rows: rows})
+	t.Cleanup(func() { db.Close() })
+	return &EventRepository{db: &database.DB{DB: db}, maxEventDataSize: models.DefaultMaxEventDataSize}
+}
+
+func TestCountEventsBySeverityIncludesEverySeverity(t *testing.T) {
+	r := newFixedRowsRepository(t, []driver.Value{"high", int64(3)}, []driver.Value{"critical", int64(1)})
+
+	counts, err := r.CountEventsBySeverity(context.Background(), models.EventFilter{})
+	if err != nil {
+		t.Fatalf("CountEventsBySeverity: %v", err)
+	}
+	want := map[string]int64{"low": 0, "medium": 0, "high": 3, "critical": 1}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("CountEventsBySeverity() = %v, want %v", counts, want)
+	}
+}
+
+func TestCountEventsByHourFillsEmptyHours(t *testing.T) {
+	ten := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	r := newFixedRowsRepository(t, []driver.Value{ten, int64(2)}, []driver.Value{ten.Add(2 * time.Hour), int64(4)})
+
+	series, err := r.CountEventsByHour(context.Background(), ten.Add(30*time.Minute), ten.Add(3*time.Hour))
+	if err != nil {
+		t.Fatalf("CountEventsByHour: %v", err)
+	}
+	want := []models.HourlyCount{
+		{Hour: ten, Count: 2},
+		{Hour: ten.Add(time.Hour), Count: 0},
+		{Hour: ten.Add(2 * time.Hour), Count: 4},
+	}
+	if !reflect.DeepEqual(series, want) {
+		t.Errorf("CountEventsByHour() = %v, want %v", series, want)
+	}
+}
+
+// TestEventAggregatesAgainstPostgres checks the aggregates against fixture events in a
+// security_events table created in a schema of its own in the database at POSTGRES_TEST_DSN
+func TestEventAggregatesAgainstPostgres(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN not set")
+	}
+
+	conn, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("sql.Open: %v", err)
+	}
+	defer conn.Close()
+	// One connection, so the search_path below applies to every query
+	conn.SetMaxOpenConns(1)
+	r := &EventRepository{db: &database.DB{DB: conn}, maxEventDataSize: models.DefaultMaxEventDataSize}
+	ctx := context.Background()
+
+	schema := fmt.Sprintf("stats_test_%d", time.Now().UnixNano())
+	for _, query := range []string{
+		"CREATE SCHEMA " + schema,
+		"SET search_path TO " + schema,
+		`CREATE TABLE security_events (
+			event_id VARCHAR(255) PRIMARY KEY,
+			severity VARCHAR(20) NOT NULL,
+			source VARCHAR(255) NOT NULL,
+			attack_techniques TEXT[] NOT NULL DEFAULT '{}',
+			event_data JSONB,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL
+		)`,
+	} {
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+	}
+	t.Cleanup(func() { conn.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE") })
+
+	ten := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	fixtures := []struct {
+		severity, source, technique string
+		createdAt                   time.Time
+	}{
+		{"high", "ids", "T1110", ten.Add(5 * time.Minute)},
+		{"high", "ids", "T1110", ten.Add(50 * time.Minute)},
+		{"high", "waf", "", ten.Add(2*time.Hour + time.Minute)},
+		{"critical", "ids", "T1078", ten.Add(2*time.Hour + 30*time.Minute)},
+		{"low", "waf", "", ten.Add(2*time.Hour + 59*time.Minute)},
+		{"low", "vpn-gateway", "", ten.Add(5 * time.Hour)},
+	}
+	for i, f := range fixtures {
+		techniques := "{}"
+		if f.technique != "" {
+			techniques = "{" + f.technique + "}"
+		}
+		if _, err := conn.ExecContext(ctx, `INSERT INTO security_events (event_id, severity, source, attack_techniques, created_at)
+			VALUES ($1, $2, $3, $4, $5)`, fmt.Sprintf("evt-%d", i), f.severity, f.source, techniques, f.createdAt); err != nil {
+			t.Fatalf("insert fixture %d: %v", i, err)
+		}
+	}
+
+	severities, err := r.CountEventsBySeverity(ctx, models.EventFilter{})
+	if err != nil {
+		t.Fatalf("CountEventsBySeverity: %v", err)
+	}
+	if want := map[string]int64{"low": 2, "medium": 0, "high": 3, "critical": 1}; !reflect.DeepEqual(severities, want) {
+		t.Errorf("CountEventsBySeverity() = %v, want %v", severities, want)
+	}
+	severities, err = r.CountEventsBySeverity(ctx, models.EventFilter{ATTACKTechnique: "T1110"})
+	if err != nil {
+		t.Fatalf("CountEventsBySeverity: %v", err)
+	}
+	if want := map[string]int64{"low": 0, "medium": 0, "high": 2, "critical": 0}; !reflect.DeepEqual(severities, want) {
+		t.Errorf("CountEventsBySeverity(T1110) = %v, want %v", severities, want)
+	}
+
+	hours, err := r.CountEventsByHour(ctx, ten, ten.Add(3*time.Hour))
+	if err != nil {
+		t.Fatalf("CountEventsByHour: %v", err)
+	}
+	wantHours := []models.HourlyCount{
+		{Hour: ten, Count: 2},
+		{Hour: ten.Add(time.Hour), Count: 0},
+		{Hour: ten.Add(2 * time.Hour), Count: 3},
+	}
+	if len(hours) != len(wantHours) {
+		t.Fatalf("CountEventsByHour() = %v, want %v", hours, wantHours)
+	}
+	for i, hour := range hours {
+		if !hour.Hour.Equal(wantHours[i].Hour) || hour.Count != wantHours[i].Count {
+			t.Errorf("hour %d = %v, want %v", i, hour, wantHours[i])
+		}
+	}
+
+	sources, err := r.TopSources(ctx, 2, models.EventFilter{})
+	if err != nil {
+		t.Fatalf("TopSources: %v", err)
+	}
+	if want := []models.SourceCount{{Source: "ids", Count: 3}, {Source: "waf", Count: 2}}; !reflect.DeepEqual(sources, want) {
+		t.Errorf("TopSources(2) = %v, want %v", sources, want)
+	}
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// ProcessOnce runs process in a transaction that records messageID as processed by queueName,
+// committing both together when process succeeds. It returns false without calling process when
+// the message was recorded before. A concurrent transaction that recorded the same message
+// holds its row lock until it ends, so a redelivery waits for the first attempt to commit, and
+// is then skipped, or to roll back, and is then processed.
+func (r *EventRepository) ProcessOnce(ctx context.Context, queueName, messageID string, process func(tx *sql.Tx) error) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, queryError(ctx, "failed to begin transaction", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO processed_messages (queue_name, message_id, processed_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (queue_name, message_id) DO NOTHING`, queueName, messageID)
+	if err != nil {
+		return false, queryError(ctx, "failed to record processed message", err)
+	}
+	recorded, err := result.RowsAffected()
+	if err != nil {
+		return false, queryError(ctx, "failed to record processed message", err)
+	}
+	if recorded == 0 {
+		return false, nil
+	}
+
+	if err := process(tx); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, queryError(ctx, "failed to commit processed message", err)
+	}
+	return true, nil
+}
+
+// DeleteProcessedMessagesBefore forgets the messages processed before cutoff and returns how
+// many were removed
+func (r *EventRepository) DeleteProcessedMessagesBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM processed_messages WHERE processed_at < $1", cutoff)
+	if err != nil {
+		return 0, queryError(ctx, "failed to delete processed messages", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, queryError(ctx, "failed to get rows affected", err)
+	}
+	return deleted, nil
+}
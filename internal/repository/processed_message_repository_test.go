@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProcessOnceAgainstPostgres(t *testing.T) {
+	r := migratedRepository(t)
+	ctx := context.Background()
+	if _, err := r.db.ExecContext(ctx, "CREATE TABLE side_effects (message_id VARCHAR(255) NOT NULL)"); err != nil {
+		t.Fatalf("create side_effects: %v", err)
+	}
+
+	// The side effect of a message, written in the transaction that records it processed
+	sideEffect := func(messageID string, err error) func(tx *sql.Tx) error {
+		return func(tx *sql.Tx) error {
+			if _, execErr := tx.ExecContext(ctx, "INSERT INTO side_effects (message_id) VALUES ($1)", messageID); execErr != nil {
+				return execErr
+			}
+			return err
+		}
+	}
+	count := func(query, messageID string) int {
+		t.Helper()
+		var n int
+		if err := r.db.QueryRowContext(ctx, query, messageID).Scan(&n); err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		return n
+	}
+	sideEffects := func(messageID string) int {
+		return count("SELECT COUNT(*) FROM side_effects WHERE message_id = $1", messageID)
+	}
+	records := func(messageID string) int {
+		return count("SELECT COUNT(*) FROM processed_messages WHERE message_id = $1", messageID)
+	}
+
+	// A failed side effect rolls back together with the record, so the retry runs
+	ran, err := r.ProcessOnce(ctx, "security_events", "msg-1", sideEffect("msg-1", errors.New("notification failed")))
+	if err == nil || ran {
+		t.Fatalf("ProcessOnce = %t, %v, want the side effect's error", ran, err)
+	}
+	if sideEffects("msg-1") != 0 || records("msg-1") != 0 {
+		t.Fatal("a failed attempt left its side effect or record behind")
+	}
+
+	ran, err = r.ProcessOnce(ctx, "security_events", "msg-1", sideEffect("msg-1", nil))
+	if err != nil || !ran {
+		t.Fatalf("ProcessOnce = %t, %v, want it to run", ran, err)
+	}
+	if sideEffects("msg-1") != 1 || records("msg-1") != 1 {
+		t.Fatal("the side effect and the record were not committed together")
+	}
+
+	// The redelivery reads the record and does not run
+	ran, err = r.ProcessOnce(ctx, "security_events", "msg-1", func(tx *sql.Tx) error {
+		t.Error("a message processed before ran again")
+		return nil
+	})
+	if err != nil || ran {
+		t.Fatalf("ProcessOnce of the redelivery = %t, %v, want it skipped", ran, err)
+	}
+
+	// A redelivery during the first attempt waits for its commit and is then skipped
+	started, release := make(chan struct{}), make(chan struct{})
+	first := make(chan error, 1)
+	go func() {
+		_, err := r.ProcessOnce(ctx, "security_events", "msg-2", func(tx *sql.Tx) error {
+			close(started)
+			<-release
+			return sideEffect("msg-2", nil)(tx)
+		})
+		first <- err
+	}()
+	<-started
+	second := make(chan bool, 1)
+	go func() {
+		ran, err := r.ProcessOnce(ctx, "security_events", "msg-2", sideEffect("msg-2", nil))
+		if err != nil {
+			t.Errorf("ProcessOnce of the concurrent redelivery: %v", err)
+		}
+		second <- ran
+	}()
+	select {
+	case <-second:
+		t.Fatal("the redelivery did not wait for the first attempt")
+	case <-time.After(200 * time.Millisecond):
+	}
+	close(release)
+	if err := <-first; err != nil {
+		t.Fatalf("ProcessOnce of the first attempt: %v", err)
+	}
+	if <-second {
+		t.Error("the redelivery ran after the first attempt committed")
+	}
+	if got := sideEffects("msg-2"); got != 1 {
+		t.Errorf("msg-2 has %d side effects, want 1", got)
+	}
+
+	deleted, err := r.DeleteProcessedMessagesBefore(ctx, time.Now().Add(time.Minute))
+	if err != nil || deleted != 2 {
+		t.Errorf("DeleteProcessedMessagesBefore = %d, %v, want both records removed", deleted, err)
+	}
+}
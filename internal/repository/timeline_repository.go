@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// GetEventTransitions retrieves the status transitions of an event, oldest first
+func (r *EventRepository) GetEventTransitions(ctx context.Context, eventID string) ([]models.EventTransition, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT from_status, to_status, COALESCE(changed_by, ''), COALESCE(note, ''), changed_at
+		FROM event_transitions
+		WHERE event_id = $1
+		ORDER BY changed_at, id`, eventID)
+	if err != nil {
+		return nil, queryError(ctx, "failed to query event transitions", err)
+	}
+	defer rows.Close()
+
+	transitions := []models.EventTransition{}
+	for rows.Next() {
+		var t models.EventTransition
+		if err := rows.Scan(&t.FromStatus, &t.ToStatus, &t.ChangedBy, &t.Note, &t.ChangedAt); err != nil {
+			return nil, queryError(ctx, "failed to scan event transition", err)
+		}
+		transitions = append(transitions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "error iterating event transitions", err)
+	}
+
+	return transitions, nil
+}
+
+// GetProcessingLog retrieves the queue deliveries of an event, oldest first
+func (r *EventRepository) GetProcessingLog(ctx context.Context, eventID string) ([]models.ProcessingLogEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT queue_name, attempt, published_at, started_at, finished_at, outcome, error
+		FROM event_processing_log
+		WHERE event_id = $1
+		ORDER BY started_at, id`, eventID)
+	if err != nil {
+		return nil, queryError(ctx, "failed to query processing log", err)
+	}
+	defer rows.Close()
+
+	entries := []models.ProcessingLogEntry{}
+	for rows.Next() {
+		entry := models.ProcessingLogEntry{EventID: eventID}
+		if err := rows.Scan(&entry.Queue, &entry.Attempt, &entry.PublishedAt, &entry.StartedAt, &entry.FinishedAt, &entry.Outcome, &entry.Error); err != nil {
+			return nil, queryError(ctx, "failed to scan processing log entry", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, "error iterating processing log", err)
+	}
+
+	return entries, nil
+}
+
+// RecordProcessing stores a queue delivery of an event
+func (r *EventRepository) RecordProcessing(ctx context.Context, entry models.ProcessingLogEntry) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO event_processing_log (event_id, queue_name, attempt, published_at, started_at, finished_at, outcome, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		entry.EventID, entry.Queue, entry.Attempt, entry.PublishedAt, entry.StartedAt, entry.FinishedAt, entry.Outcome, entry.Error)
+	if err != nil {
+		return queryError(ctx, "failed to record event processing", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+func TestEventTransitionsFollowTheLifecycleAgainstPostgres(t *testing.T) {
+	r := migratedRepository(t)
+	ctx := context.Background()
+	createTestEvent(t, r, "evt-1", "")
+
+	if _, err := r.AcknowledgeEvent(ctx, "evt-1"); err != nil {
+		t.Fatalf("AcknowledgeEvent: %v", err)
+	}
+	result := r.BulkUpdateStatus(ctx, "", []string{"evt-1"}, models.EventStatusResolved, "user-7", "false positive")
+	if result.Updated != 1 {
+		t.Fatalf("resolving failed: %+v", result.Results)
+	}
+
+	event, err := r.GetEventByID(ctx, "evt-1")
+	if err != nil {
+		t.Fatalf("GetEventByID: %v", err)
+	}
+	if event.Status != models.EventStatusResolved {
+		t.Errorf("status = %s, want resolved", event.Status)
+	}
+
+	transitions, err := r.GetEventTransitions(ctx, "evt-1")
+	if err != nil {
+		t.Fatalf("GetEventTransitions: %v", err)
+	}
+	if len(transitions) != 3 {
+		t.Fatalf("got %d transitions, want 3: %+v", len(transitions), transitions)
+	}
+	want := []struct{ from, to string }{
+		{"", models.EventStatusOpen},
+		{models.EventStatusOpen, models.EventStatusAcknowledged},
+		{models.EventStatusAcknowledged, models.EventStatusResolved},
+	}
+	for i, transition := range transitions {
+		from := ""
+		if transition.FromStatus != nil {
+			from = *transition.FromStatus
+		}
+		if from != want[i].from || transition.ToStatus != want[i].to {
+			t.Errorf("transition %d = %q to %q, want %q to %q", i, from, transition.ToStatus, want[i].from, want[i].to)
+		}
+		if i > 0 && transition.ChangedAt.Before(transitions[i-1].ChangedAt) {
+			t.Errorf("transition %d is older than the one before it", i)
+		}
+	}
+	if resolved := transitions[2]; resolved.ChangedBy != "user-7" || resolved.Note != "false positive" {
+		t.Errorf("resolution by %q with note %q, want user-7 and the note", resolved.ChangedBy, resolved.Note)
+	}
+
+	// A resolved event can be reopened, which is recorded as a fourth transition
+	if result := r.BulkUpdateStatus(ctx, "", []string{"evt-1"}, models.EventStatusOpen, "user-7", ""); result.Updated != 1 {
+		t.Fatalf("reopening failed: %+v", result.Results)
+	}
+	transitions, err = r.GetEventTransitions(ctx, "evt-1")
+	if err != nil {
+		t.Fatalf("GetEventTransitions: %v", err)
+	}
+	if len(transitions) != 4 || transitions[3].ToStatus != models.EventStatusOpen {
+		t.Errorf("transitions after reopening = %+v, want a fourth one to open", transitions)
+	}
+}
@@ -3,21 +3,42 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"skyhawk-security-microservice/internal/handler"
+	"skyhawk-security-microservice/internal/logger"
+	"skyhawk-security-microservice/internal/metrics"
 	"skyhawk-security-microservice/internal/middleware"
 )
 
 // SetupRoutes configures all application routes
 func SetupRoutes(router *gin.Engine, handlers *handler.Handler) {
 	// Apply global middleware
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
+	router.Use(middleware.RequestLoggerMiddleware(logger.NewRequestLogger(logger.GetLogger())))
+	router.Use(middleware.RecoveryMiddleware(logger.GetLogger()))
 	router.Use(middleware.CORSMiddleware())
 	router.Use(middleware.RequestIDMiddleware())
+	router.Use(middleware.IdentityMiddleware())
+	router.Use(middleware.StructuredLogMiddleware(logger.GetLogger()))
+	router.Use(middleware.TracingMiddleware())
+	router.Use(middleware.ErrorHandlerMiddleware(logger.GetLogger()))
+	router.Use(middleware.RouteTimeoutMiddleware(handlers.Timeouts))
 
 	// Health check endpoints
 	router.GET("/health", handlers.HealthHandler.HealthCheck)
+	router.GET("/health/history", handlers.HealthHandler.GetHistory)
 	router.GET("/", handlers.HealthHandler.GetRoot)
 	router.GET("/api/v1/status", handlers.HealthHandler.GetStatus)
+	if handlers.Features.MetricsEnabled {
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	}
+
+	// Runtime inspection, only registered when debug endpoints are enabled
+	if handlers.DebugHandler != nil {
+		debug := router.Group("/admin/debug", middleware.AdminAPIKeyMiddleware(handlers.DebugHandler.APIKey()))
+		{
+			debug.GET("/goroutines", handlers.DebugHandler.GetGoroutines)
+			debug.GET("/memstats", handlers.DebugHandler.GetMemStats)
+			debug.GET("/config", handlers.DebugHandler.GetConfig)
+		}
+	}
 
 	// API v1 routes
 	apiV1 := router.Group("/api/v1")
@@ -27,20 +48,92 @@ func SetupRoutes(router *gin.Engine, handlers *handler.Handler) {
 		{
 			events.POST("/", handlers.EventHandler.CreateEvent)
 			events.GET("/", handlers.EventHandler.GetEvents)
+			events.POST("/delete-batch", handlers.EventHandler.DeleteEvents)
+			events.PATCH("/batch", handlers.EventHandler.BulkUpdateStatus)
+			events.POST("/replay", middleware.AdminAPIKeyMiddleware(handlers.AdminAPIKey), handlers.EventHandler.ReplayEvents)
+			events.GET("/export", handlers.EventHandler.ExportEvents)
+			events.GET("/schema", handlers.EventHandler.GetEventSchema)
+			events.GET("/timeseries", handlers.EventHandler.GetEventTimeSeries)
+			events.GET("/stats", handlers.EventHandler.GetEventStats)
+			events.GET("/facets", handlers.EventHandler.GetEventFacets)
+			events.GET("/by-source/:source", handlers.EventHandler.GetRecentEventsBySource)
 			events.GET("/:id", handlers.EventHandler.GetEvent)
+			events.GET("/:id/stix", handlers.EventHandler.GetEventSTIX)
+			events.GET("/:id/timeline", handlers.EventHandler.GetEventTimeline)
 			events.PUT("/:id", handlers.EventHandler.UpdateEvent)
+			events.PATCH("/:id", handlers.EventHandler.PatchEvent)
 			events.DELETE("/:id", handlers.EventHandler.DeleteEvent)
+			events.POST("/:id/acknowledge", handlers.EventHandler.AcknowledgeEvent)
 		}
 
 		// Queue routes
 		queue := apiV1.Group("/queue")
 		{
 			queue.GET("/stats", handlers.EventHandler.GetQueueStats)
+			queue.GET("/tenants", handlers.EventHandler.GetTenantQueueStats)
+			queue.GET("/:name/peek", middleware.AdminAPIKeyMiddleware(handlers.AdminAPIKey), handlers.EventHandler.PeekMessage)
+		}
+
+		// Admin routes, all requiring the admin API key
+		admin := apiV1.Group("/admin", middleware.AdminAPIKeyMiddleware(handlers.AdminAPIKey))
+		{
+			webhooks := admin.Group("/webhooks")
+			{
+				webhooks.POST("", handlers.WebhookHandler.CreateSubscription)
+				webhooks.GET("", handlers.WebhookHandler.GetSubscriptions)
+				webhooks.GET("/:id", handlers.WebhookHandler.GetSubscription)
+				webhooks.PUT("/:id", handlers.WebhookHandler.UpdateSubscription)
+				webhooks.DELETE("/:id", handlers.WebhookHandler.DeleteSubscription)
+				webhooks.GET("/:id/deliveries", handlers.WebhookHandler.GetDeliveries)
+				webhooks.POST("/:id/rotate-secret", handlers.WebhookHandler.RotateSecret)
+			}
+
+			admin.GET("/aggregation", handlers.AggregationHandler.GetConfig)
+			admin.POST("/aggregation", handlers.AggregationHandler.UpdateConfig)
+
+			admin.GET("/severity-map", handlers.SeverityMapHandler.GetSeverityMap)
+			admin.POST("/severity-map", handlers.SeverityMapHandler.AddSeverityMappings)
+
+			sourceLimits := admin.Group("/source-limits")
+			{
+				sourceLimits.GET("", handlers.SourceLimitHandler.GetSourceLimits)
+				sourceLimits.POST("", handlers.SourceLimitHandler.SetSourceLimit)
+				sourceLimits.DELETE("/:source", handlers.SourceLimitHandler.DeleteSourceLimit)
+			}
+
+			admin.GET("/mitre/techniques", handlers.MITREHandler.GetTechniques)
+
+			admin.GET("/archive/status", handlers.ArchiveHandler.GetStatus)
+
+			admin.GET("/scheduler/status", handlers.SchedulerHandler.GetStatus)
+
+			admin.GET("/db/index-bloat", handlers.DatabaseHandler.GetIndexBloat)
+
+			admin.PUT("/config/reload", handlers.ConfigHandler.Reload)
+			admin.GET("/features", handlers.FeatureHandler.GetFeatures)
+
+			admin.GET("/crypto/status", handlers.CryptoHandler.GetStatus)
+
+			admin.POST("/bruteforce/reset", handlers.BruteForceHandler.ResetLockout)
+
+			admin.GET("/workers", handlers.WorkerHandler.GetWorkers)
+			admin.POST("/queue/replay", handlers.EventHandler.ReplayDeadLetters)
+			admin.POST("/queue/:name/purge", handlers.EventHandler.PurgeQueue)
+			admin.GET("/queue/:name/dead-count", handlers.EventHandler.GetDeadLetterCount)
+
+			thresholds := admin.Group("/alerts/thresholds")
+			{
+				thresholds.POST("", handlers.AlertHandler.CreateThresholdRule)
+				thresholds.GET("", handlers.AlertHandler.GetThresholdRules)
+				thresholds.DELETE("/:id", handlers.AlertHandler.DeleteThresholdRule)
+			}
 		}
 
-		// Future route groups can be added here:
+		// Future route groups can be added here. Authentication routes should be wrapped with
+		// middleware.BruteForceMiddleware(handlers.BruteForceHandler.Store(), 5, 15*time.Minute):
+		// auth := apiV1.Group("/auth")
 		// users := apiV1.Group("/users")
 		// incidents := apiV1.Group("/incidents")
 		// rules := apiV1.Group("/rules")
 	}
-} 
\ No newline at end of file
+}
@@ -0,0 +1,218 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"skyhawk-security-microservice/internal/handler"
+	"skyhawk-security-microservice/internal/middleware"
+	"skyhawk-security-microservice/internal/queue"
+)
+
+func newTestRouter(t *testing.T, adminAPIKey string) (*gin.Engine, *queue.MemoryQueue) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mq := queue.NewMemoryQueue()
+	t.Cleanup(func() { mq.Close() })
+
+	router := gin.New()
+	SetupRoutes(router, &handler.Handler{
+		EventHandler: handler.NewEventHandler(nil, mq),
+		AdminAPIKey:  adminAPIKey,
+	})
+	return router, mq
+}
+
+func TestAdminRoutesRequireAPIKey(t *testing.T) {
+	router, _ := newTestRouter(t, "secret")
+
+	routes := []struct{ method, path string }{
+		{http.MethodPost, "/api/v1/admin/queue/security_events/purge"},
+		{http.MethodGet, "/api/v1/admin/queue/security_events/dead-count"},
+		{http.MethodPost, "/api/v1/admin/queue/replay"},
+		{http.MethodGet, "/api/v1/admin/webhooks"},
+		{http.MethodPost, "/api/v1/admin/bruteforce/reset"},
+		{http.MethodPut, "/api/v1/admin/config/reload"},
+	}
+	for _, route := range routes {
+		for _, key := range []string{"", "wrong"} {
+			req := httptest.NewRequest(route.method, route.path, nil)
+			if key != "" {
+				req.Header.Set(middleware.AdminAPIKeyHeader, key)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("%s %s with key %q: status = %d, want %d", route.method, route.path, key, rec.Code, http.StatusUnauthorized)
+			}
+		}
+	}
+}
+
+func TestAdminRoutesForbiddenWithoutConfiguredKey(t *testing.T) {
+	router, _ := newTestRouter(t, "")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/queue/security_events/purge", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestPurgeQueue(t *testing.T) {
+	router, mq := newTestRouter(t, "secret")
+
+	for i := 0; i < 10; i++ {
+		if err := mq.PublishMessage(queue.Message{ID: fmt.Sprintf("msg-%d", i)}, "security_events"); err != nil {
+			t.Fatalf("PublishMessage: %v", err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/queue/security_events/purge", nil)
+	req.Header.Set(middleware.AdminAPIKeyHeader, "secret")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var body struct {
+		Data struct {
+			Queue  string `json:"queue"`
+			Purged int    `json:"purged"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid response body: %v", err)
+	}
+	if body.Data.Queue != "security_events" || body.Data.Purged != 10 {
+		t.Errorf("response = %+v, want 10 messages purged from security_events", body.Data)
+	}
+
+	if length, _ := mq.GetQueueLength("security_events"); length != 0 {
+		t.Errorf("queue length after purge = %d, want 0", length)
+	}
+	if purges := mq.Purges(); len(purges) != 1 || purges[0] != "security_events" {
+		t.Errorf("Purges() = %v, want [security_events]", purges)
+	}
+}
+
+func TestPeekMessageRequiresAPIKey(t *testing.T) {
+	router, mq := newTestRouter(t, "secret")
+	if err := mq.PublishMessage(queue.Message{ID: "msg-1"}, "security_events"); err != nil {
+		t.Fatalf("PublishMessage: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/queue/security_events/peek", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("status without key = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/queue/security_events/peek", nil)
+	req.Header.Set(middleware.AdminAPIKeyHeader, "secret")
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status with key = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var body struct {
+		Data struct {
+			Message *queue.Message `json:"message"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid response body: %v", err)
+	}
+	if body.Data.Message == nil || body.Data.Message.ID != "msg-1" {
+		t.Errorf("peeked message = %+v, want msg-1", body.Data.Message)
+	}
+	if length, _ := mq.GetQueueLength("security_events"); length != 1 {
+		t.Errorf("queue length after peek = %d, want 1", length)
+	}
+}
+
+// newDebugTestRouter serves the routes with the debug endpoints enabled under apiKey
+func newDebugTestRouter(t *testing.T, apiKey string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	mq := queue.NewMemoryQueue()
+	t.Cleanup(func() { mq.Close() })
+
+	router := gin.New()
+	SetupRoutes(router, &handler.Handler{
+		EventHandler: handler.NewEventHandler(nil, mq),
+		DebugHandler: handler.NewDebugHandler(apiKey, nil),
+	})
+	return router
+}
+
+func TestDebugMemStatsReportsNumericFields(t *testing.T) {
+	router := newDebugTestRouter(t, "debug-secret")
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/debug/memstats", nil)
+	req.Header.Set(middleware.AdminAPIKeyHeader, "debug-secret")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode response %s: %v", rec.Body.String(), err)
+	}
+	for _, field := range []string{"HeapInuse", "HeapIdle", "HeapSys", "NumGC", "GCCPUFraction", "NextGC"} {
+		value, ok := body.Data[field].(float64)
+		if !ok {
+			t.Errorf("%s = %v, want a number", field, body.Data[field])
+			continue
+		}
+		if value < 0 {
+			t.Errorf("%s = %v, want a non-negative number", field, value)
+		}
+	}
+	if heapSys, _ := body.Data["HeapSys"].(float64); heapSys == 0 {
+		t.Error("HeapSys = 0, want the heap obtained from the OS")
+	}
+}
+
+func TestDebugRoutesRequireAPIKey(t *testing.T) {
+	router := newDebugTestRouter(t, "debug-secret")
+
+	for _, path := range []string{"/admin/debug/memstats", "/admin/debug/goroutines", "/admin/debug/config"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(middleware.AdminAPIKeyHeader, "wrong")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("GET %s with a wrong key: status = %d, want %d", path, rec.Code, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestDebugRoutesAbsentWhenDisabled(t *testing.T) {
+	router, _ := newTestRouter(t, "secret")
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/debug/memstats", nil)
+	req.Header.Set(middleware.AdminAPIKeyHeader, "secret")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d without debug endpoints", rec.Code, http.StatusNotFound)
+	}
+}
@@ -0,0 +1,208 @@
+package routing
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+	"skyhawk-security-microservice/internal/models"
+)
+
+// DefaultQueue is used when no rule matches an event
+const DefaultQueue = "security_events"
+
+// Condition decides whether a rule applies to an event
+type Condition func(*models.Event) bool
+
+// Rule routes events matching Condition to TargetQueue. Rules with a lower
+// Priority value are evaluated first.
+type Rule struct {
+	Priority    int
+	Condition   Condition
+	TargetQueue string
+}
+
+// EventRouter selects the queue an event is published to
+type EventRouter struct {
+	mu           sync.RWMutex
+	rules        []Rule
+	typeQueues   map[string]string
+	defaultQueue string
+}
+
+// NewEventRouter creates a router with no rules
+func NewEventRouter(defaultQueue string) *EventRouter {
+	if defaultQueue == "" {
+		defaultQueue = DefaultQueue
+	}
+	return &EventRouter{defaultQueue: defaultQueue}
+}
+
+// AddRule registers a rule, keeping rules ordered by priority
+func (r *EventRouter) AddRule(priority int, cond Condition, queue string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.rules = append(r.rules, Rule{Priority: priority, Condition: cond, TargetQueue: queue})
+	sort.SliceStable(r.rules, func(i, j int) bool {
+		return r.rules[i].Priority < r.rules[j].Priority
+	})
+}
+
+// SetEventTypeQueues routes events by type, e.g. file_access -> fs_events. The map is
+// consulted after the rules, and types it does not list go to the default queue.
+func (r *EventRouter) SetEventTypeQueues(queues map[string]string) {
+	normalized := make(map[string]string, len(queues))
+	for eventType, queue := range queues {
+		normalized[strings.ToLower(eventType)] = queue
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.typeQueues = normalized
+}
+
+// Route returns the queue for the first matching rule, then the queue mapped to the
+// event type, or the default queue
+func (r *EventRouter) Route(event *models.Event) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rule := range r.rules {
+		if rule.Condition(event) {
+			return rule.TargetQueue
+		}
+	}
+	if queue, ok := r.typeQueues[strings.ToLower(event.EventType)]; ok {
+		return queue
+	}
+	return r.defaultQueue
+}
+
+// ParseEventTypeQueues parses a mapping such as "file_access=fs_events,login=auth_events"
+func ParseEventTypeQueues(spec string) (map[string]string, error) {
+	queues := make(map[string]string)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		eventType, queue, ok := strings.Cut(pair, "=")
+		eventType = strings.TrimSpace(eventType)
+		queue = strings.TrimSpace(queue)
+		if !ok || eventType == "" || queue == "" {
+			return nil, fmt.Errorf("invalid event type queue mapping: %s", pair)
+		}
+		queues[eventType] = queue
+	}
+	return queues, nil
+}
+
+// RoutingRule is a single rule in the YAML configuration file:
+//
+//	# routing.yaml
+//	- priority: 10
+//	  match: {severity: critical}
+//	  target: security_events_critical
+type RoutingRule struct {
+	Priority *int              `yaml:"priority"`
+	Match    map[string]string `yaml:"match"`
+	Target   string            `yaml:"target"`
+}
+
+// LoadRules reads routing rules from a YAML file into the router. Rules without an
+// explicit priority are evaluated in file order.
+func (r *EventRouter) LoadRules(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read routing rules: %v", err)
+	}
+
+	var configs []RoutingRule
+	if err := yaml.Unmarshal(data, &configs); err != nil {
+		return fmt.Errorf("failed to parse routing rules: %v", err)
+	}
+
+	rules, err := compileRules(configs)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		r.AddRule(rule.Priority, rule.Condition, rule.TargetQueue)
+	}
+
+	return nil
+}
+
+// Reload replaces every rule of the router. The rules are validated first, so an
+// invalid configuration leaves the current rules in place.
+func (r *EventRouter) Reload(rules []RoutingRule) error {
+	compiled, err := compileRules(rules)
+	if err != nil {
+		return err
+	}
+	sort.SliceStable(compiled, func(i, j int) bool {
+		return compiled[i].Priority < compiled[j].Priority
+	})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rules = compiled
+	return nil
+}
+
+// compileRules converts configured rules into router rules
+func compileRules(configs []RoutingRule) ([]Rule, error) {
+	rules := make([]Rule, 0, len(configs))
+	for i, cfg := range configs {
+		if cfg.Target == "" {
+			return nil, fmt.Errorf("routing rule %d has no target", i+1)
+		}
+
+		cond, err := MatchCondition(cfg.Match)
+		if err != nil {
+			return nil, fmt.Errorf("routing rule %d: %v", i+1, err)
+		}
+
+		priority := i
+		if cfg.Priority != nil {
+			priority = *cfg.Priority
+		}
+		rules = append(rules, Rule{Priority: priority, Condition: cond, TargetQueue: cfg.Target})
+	}
+	return rules, nil
+}
+
+// MatchCondition builds a condition requiring every field to equal the given value.
+// Supported fields are event_type, severity and source; comparisons ignore case.
+func MatchCondition(match map[string]string) (Condition, error) {
+	for field := range match {
+		switch field {
+		case "event_type", "severity", "source":
+		default:
+			return nil, fmt.Errorf("unsupported match field: %s", field)
+		}
+	}
+
+	return func(event *models.Event) bool {
+		for field, want := range match {
+			var got string
+			switch field {
+			case "event_type":
+				got = event.EventType
+			case "severity":
+				got = event.Severity
+			case "source":
+				got = event.Source
+			}
+			if !strings.EqualFold(got, want) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
@@ -0,0 +1,91 @@
+package routing
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+func severityIs(severity string) Condition {
+	return func(event *models.Event) bool { return event.Severity == severity }
+}
+
+func TestRouteCriticalEventToCriticalQueue(t *testing.T) {
+	router := NewEventRouter("")
+	router.AddRule(10, severityIs(models.SeverityCritical), "security_events_critical")
+
+	if queue := router.Route(&models.Event{Severity: models.SeverityCritical}); queue != "security_events_critical" {
+		t.Errorf("critical event routed to %q, want security_events_critical", queue)
+	}
+	if queue := router.Route(&models.Event{Severity: models.SeverityLow}); queue != DefaultQueue {
+		t.Errorf("low event routed to %q, want the default queue %q", queue, DefaultQueue)
+	}
+}
+
+func TestRouteEvaluatesRulesInPriorityOrder(t *testing.T) {
+	router := NewEventRouter("fallback")
+	router.AddRule(20, severityIs(models.SeverityCritical), "second")
+	router.AddRule(10, func(event *models.Event) bool { return event.Source == "auth-service" }, "first")
+
+	event := &models.Event{Severity: models.SeverityCritical, Source: "auth-service"}
+	if queue := router.Route(event); queue != "first" {
+		t.Errorf("event routed to %q, want the lower priority value rule %q", queue, "first")
+	}
+	if queue := router.Route(&models.Event{Severity: models.SeverityCritical}); queue != "second" {
+		t.Errorf("event routed to %q, want %q", queue, "second")
+	}
+	if queue := router.Route(&models.Event{Severity: models.SeverityLow}); queue != "fallback" {
+		t.Errorf("event routed to %q, want the configured default %q", queue, "fallback")
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "routing.yaml")
+	config := `
+- match: {severity: critical}
+  target: security_events_critical
+- match: {event_type: login_failure, source: auth-service}
+  target: auth_events
+`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	router := NewEventRouter("")
+	if err := router.LoadRules(path); err != nil {
+		t.Fatalf("LoadRules: %v", err)
+	}
+
+	tests := []struct {
+		event *models.Event
+		want  string
+	}{
+		{&models.Event{Severity: "CRITICAL", EventType: "login_failure", Source: "auth-service"}, "security_events_critical"},
+		{&models.Event{Severity: models.SeverityHigh, EventType: "login_failure", Source: "auth-service"}, "auth_events"},
+		{&models.Event{Severity: models.SeverityHigh, EventType: "login_failure", Source: "vpn"}, DefaultQueue},
+	}
+	for _, tt := range tests {
+		if queue := router.Route(tt.event); queue != tt.want {
+			t.Errorf("Route(%+v) = %q, want %q", tt.event, queue, tt.want)
+		}
+	}
+}
+
+func TestLoadRulesRejectsInvalidRules(t *testing.T) {
+	configs := map[string]string{
+		"missing target": "- match: {severity: critical}\n",
+		"unknown field":  "- match: {region: eu}\n  target: eu_events\n",
+		"invalid yaml":   "- match: [\n",
+	}
+	for name, config := range configs {
+		path := filepath.Join(t.TempDir(), "routing.yaml")
+		if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+			t.Fatalf("write config: %v", err)
+		}
+		if err := NewEventRouter("").LoadRules(path); err == nil {
+			t.Errorf("%s: LoadRules returned nil, want an error", name)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a task run by the Scheduler on a fixed interval
+type Job struct {
+	Name     string
+	Interval time.Duration
+	// Aligned runs the job at multiples of Interval since the zero time instead of counting
+	// from Start, e.g. at midnight UTC for 24h or on Monday at midnight UTC for a week
+	Aligned bool
+	Fn      func(ctx context.Context) error
+}
+
+// JobStatus reports the latest run and the next scheduled run of a job
+type JobStatus struct {
+	Name      string     `json:"name"`
+	Interval  string     `json:"interval"`
+	Running   bool       `json:"running"`
+	LastRun   *time.Time `json:"last_run"`
+	LastError string     `json:"last_error,omitempty"`
+	NextRun   *time.Time `json:"next_run"`
+}
+
+// Scheduler runs each registered job in its own goroutine
+type Scheduler struct {
+	mu     sync.Mutex
+	jobs   []Job
+	status map[string]*JobStatus
+	store  RunRecorder
+	locker Locker
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// New creates a scheduler without jobs
+func New() *Scheduler {
+	return &Scheduler{status: make(map[string]*JobStatus)}
+}
+
+// SetRunStore records the outcome of every run in store
+func (s *Scheduler) SetRunStore(store RunRecorder) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store = store
+}
+
+// SetLocker runs each job only while holding its lock in locker; a run whose lock is held
+// elsewhere is skipped
+func (s *Scheduler) SetLocker(locker Locker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.locker = locker
+}
+
+// Register adds a job; jobs registered after Start are not run
+func (s *Scheduler) Register(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs = append(s.jobs, job)
+	s.status[job.Name] = &JobStatus{Name: job.Name, Interval: job.Interval.String()}
+}
+
+// Start runs every registered job on its interval until ctx is done or Stop is called.
+// The first run of a job is one interval after Start, or the next aligned time.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+}
+
+// Stop cancels running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	s.wg.Wait()
+}
+
+// Status returns the status of every job in registration order
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		statuses = append(statuses, *s.status[job.Name])
+	}
+	return statuses
+}
+
+// loop waits for each scheduled time of a job and runs it. A run that overruns the next
+// scheduled time skips it rather than running twice in a row.
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	next := firstRun(job, time.Now())
+	for {
+		s.update(job.Name, func(st *JobStatus) { st.NextRun = &next })
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		s.runOnce(ctx, job)
+
+		now := time.Now()
+		for !next.After(now) {
+			next = next.Add(job.Interval)
+		}
+	}
+}
+
+// firstRun returns the first time a job is due after now
+func firstRun(job Job, now time.Time) time.Time {
+	if job.Aligned {
+		return now.Truncate(job.Interval).Add(job.Interval)
+	}
+	return now.Add(job.Interval)
+}
+
+// runOnce runs a job, updates its status and records the run. With a locker the run is
+// skipped when the job's lock cannot be taken.
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	s.mu.Lock()
+	locker := s.locker
+	s.mu.Unlock()
+	if locker != nil {
+		unlock, acquired, err := locker.TryLock(ctx, job.Name)
+		if err != nil {
+			log.Printf("Skipping scheduled job %s: %v", job.Name, err)
+			return
+		}
+		if !acquired {
+			log.Printf("Skipping scheduled job %s: another instance is running it", job.Name)
+			return
+		}
+		defer unlock()
+	}
+
+	startedAt := time.Now()
+	s.update(job.Name, func(st *JobStatus) { st.Running = true })
+
+	err := job.Fn(ctx)
+
+	run := Run{
+		Job:        job.Name,
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+	}
+	if err != nil {
+		run.Error = err.Error()
+		if ctx.Err() == nil {
+			log.Printf("Scheduled job %s failed: %v", job.Name, err)
+		}
+	}
+
+	s.update(job.Name, func(st *JobStatus) {
+		st.Running = false
+		st.LastRun = &run.StartedAt
+		st.LastError = run.Error
+	})
+
+	s.mu.Lock()
+	store := s.store
+	s.mu.Unlock()
+	if store != nil {
+		if err := store.RecordRun(run); err != nil {
+			log.Printf("Failed to record run of scheduled job %s: %v", job.Name, err)
+		}
+	}
+}
+
+// update changes the status of a job under the lock
+func (s *Scheduler) update(name string, fn func(*JobStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fn(s.status[name])
+}
@@ -0,0 +1,174 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeLocker hands out the lock of each job once until it is released
+type fakeLocker struct {
+	mu       sync.Mutex
+	held     map[string]bool
+	err      error
+	unlocked int
+}
+
+func (l *fakeLocker) TryLock(ctx context.Context, job string) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return nil, false, l.err
+	}
+	if l.held[job] {
+		return nil, false, nil
+	}
+	l.held[job] = true
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.held[job] = false
+		l.unlocked++
+	}, true, nil
+}
+
+// recordingStore records every run
+type recordingStore struct {
+	mu   sync.Mutex
+	runs []Run
+}
+
+func (s *recordingStore) RecordRun(run Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs = append(s.runs, run)
+	return nil
+}
+
+func newTestScheduler(locker Locker) (*Scheduler, *recordingStore, *int) {
+	s := New()
+	store := &recordingStore{}
+	s.SetRunStore(store)
+	if locker != nil {
+		s.SetLocker(locker)
+	}
+	calls := new(int)
+	s.Register(Job{Name: "DeleteExpiredEvents", Interval: time.Hour, Fn: func(ctx context.Context) error {
+		*calls++
+		return nil
+	}})
+	return s, store, calls
+}
+
+func TestRunOnceRunsAndReleasesLock(t *testing.T) {
+	locker := &fakeLocker{held: make(map[string]bool)}
+	s, store, calls := newTestScheduler(locker)
+
+	s.runOnce(context.Background(), s.jobs[0])
+
+	if *calls != 1 {
+		t.Errorf("job ran %d times, want 1", *calls)
+	}
+	if locker.unlocked != 1 || locker.held["DeleteExpiredEvents"] {
+		t.Errorf("lock not released after the run")
+	}
+	if len(store.runs) != 1 {
+		t.Errorf("recorded %d runs, want 1", len(store.runs))
+	}
+	if status := s.Status()[0]; status.LastRun == nil || status.Running {
+		t.Errorf("status = %+v, want a finished run", status)
+	}
+}
+
+func TestRunOnceSkipsJobLockedElsewhere(t *testing.T) {
+	locker := &fakeLocker{held: map[string]bool{"DeleteExpiredEvents": true}}
+	s, store, calls := newTestScheduler(locker)
+
+	s.runOnce(context.Background(), s.jobs[0])
+
+	if *calls != 0 {
+		t.Errorf("job ran %d times while locked elsewhere, want 0", *calls)
+	}
+	if len(store.runs) != 0 {
+		t.Errorf("recorded %d runs, want none", len(store.runs))
+	}
+}
+
+func TestRunOnceSkipsJobWhenLockFails(t *testing.T) {
+	locker := &fakeLocker{err: errors.New("connection refused")}
+	s, _, calls := newTestScheduler(locker)
+
+	s.runOnce(context.Background(), s.jobs[0])
+
+	if *calls != 0 {
+		t.Errorf("job ran %d times without its lock, want 0", *calls)
+	}
+}
+
+func TestRunOnceWithoutLocker(t *testing.T) {
+	s, _, calls := newTestScheduler(nil)
+
+	s.runOnce(context.Background(), s.jobs[0])
+
+	if *calls != 1 {
+		t.Errorf("job ran %d times, want 1", *calls)
+	}
+}
+
+func TestFirstRunAligned(t *testing.T) {
+	now := time.Date(2024, 3, 5, 13, 45, 0, 0, time.UTC)
+
+	got := firstRun(Job{Interval: 24 * time.Hour, Aligned: true}, now)
+	if want := time.Date(2024, 3, 6, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("aligned first run = %s, want %s", got, want)
+	}
+	if got := firstRun(Job{Interval: time.Hour}, now); !got.Equal(now.Add(time.Hour)) {
+		t.Errorf("first run = %s, want an hour after now", got)
+	}
+}
+
+func TestJobLockKeyIsStablePerJob(t *testing.T) {
+	if jobLockKey("VacuumJob") != jobLockKey("VacuumJob") {
+		t.Error("jobLockKey is not stable")
+	}
+	if jobLockKey("VacuumJob") == jobLockKey("DeleteExpiredEvents") {
+		t.Error("different jobs share a lock key")
+	}
+}
+
+func TestStartRunsJobOnInterval(t *testing.T) {
+	s := New()
+	store := &recordingStore{}
+	s.SetRunStore(store)
+	var calls atomic.Int64
+	s.Register(Job{Name: "Tick", Interval: 100 * time.Millisecond, Fn: func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}})
+
+	s.Start(context.Background())
+	time.Sleep(600 * time.Millisecond)
+	s.Stop()
+
+	runs := calls.Load()
+	if runs < 5 {
+		t.Errorf("job ran %d times in 600ms at a 100ms interval, want at least 5", runs)
+	}
+	store.mu.Lock()
+	recorded := len(store.runs)
+	store.mu.Unlock()
+	if int64(recorded) != runs {
+		t.Errorf("recorded %d runs, want %d", recorded, runs)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if after := calls.Load(); after != runs {
+		t.Errorf("job ran %d more times after Stop", after-runs)
+	}
+	if status := s.Status()[0]; status.LastRun == nil || status.NextRun == nil {
+		t.Errorf("status = %+v, want the last and next run", status)
+	}
+}
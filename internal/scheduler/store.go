@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"skyhawk-security-microservice/internal/database"
+)
+
+// Run records the outcome of one run of a scheduled job
+type Run struct {
+	Job        string    `json:"job"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// RunRecorder stores the runs of scheduled jobs
+type RunRecorder interface {
+	RecordRun(run Run) error
+}
+
+// RunStore persists scheduled job runs in the scheduled_job_runs table
+type RunStore struct {
+	db *database.DB
+}
+
+// NewRunStore creates a new scheduled job run store
+func NewRunStore(db *database.DB) *RunStore {
+	return &RunStore{db: db}
+}
+
+// RecordRun stores a scheduled job run
+func (s *RunStore) RecordRun(run Run) error {
+	query := `
+		INSERT INTO scheduled_job_runs (job_name, started_at, finished_at, error)
+		VALUES ($1, $2, $3, $4)`
+
+	if _, err := s.db.Exec(query, run.Job, run.StartedAt, run.FinishedAt, run.Error); err != nil {
+		return fmt.Errorf("failed to record scheduled job run: %v", err)
+	}
+
+	return nil
+}
+
+// Locker keeps instances sharing a database from running the same job at the same time
+type Locker interface {
+	// TryLock takes the lock of a job without waiting. acquired is false when another instance
+	// holds it; otherwise unlock releases it.
+	TryLock(ctx context.Context, job string) (unlock func(), acquired bool, err error)
+}
+
+// AdvisoryLocker locks jobs with PostgreSQL session level advisory locks keyed by job name
+type AdvisoryLocker struct {
+	db *database.DB
+}
+
+// NewAdvisoryLocker creates a new advisory job locker
+func NewAdvisoryLocker(db *database.DB) *AdvisoryLocker {
+	return &AdvisoryLocker{db: db}
+}
+
+// TryLock takes the advisory lock of a job on a dedicated connection, which holds it until unlock
+func (l *AdvisoryLocker) TryLock(ctx context.Context, job string) (func(), bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to acquire connection: %v", err)
+	}
+
+	key := jobLockKey(job)
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("failed to lock scheduled job: %v", err)
+	}
+	if !acquired {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	return func() {
+		// The job's context may be cancelled by now, which must not keep the lock held
+		conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, key)
+		conn.Close()
+	}, true, nil
+}
+
+// jobLockKey derives the advisory lock key of a job from its name
+func jobLockKey(job string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("scheduler:" + job))
+	return int64(h.Sum64())
+}
@@ -11,42 +11,75 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"skyhawk-security-microservice/internal/config"
 	"skyhawk-security-microservice/internal/database"
+	grpcserver "skyhawk-security-microservice/internal/grpc"
 	"skyhawk-security-microservice/internal/handler"
+	"skyhawk-security-microservice/internal/repository"
 	"skyhawk-security-microservice/internal/routes"
+	"skyhawk-security-microservice/internal/scheduler"
 )
 
 type Server struct {
-	router *gin.Engine
-	server *http.Server
-	db     *database.DB
+	router   *gin.Engine
+	server   *http.Server
+	db       *database.DB
+	handlers *handler.Handler
+
+	// grpcServer is nil unless EnableGRPC was called
+	grpcServer *grpc.Server
+	grpcPort   int
 }
 
-func NewServer(db *database.DB) *Server {
-	// Set Gin mode
-	if os.Getenv("ENV") == "production" {
-		gin.SetMode(gin.ReleaseMode)
-	}
+// NewServer creates the HTTP server, running Gin in ginMode
+func NewServer(db *database.DB, jobs *scheduler.Scheduler, features config.FeatureFlags, ginMode config.GinMode) *Server {
+	gin.SetMode(string(ginMode))
 
 	router := gin.New()
 
 	// Setup routes and middleware
-	handlers := handler.NewHandler(db)
+	handlers := handler.NewHandler(db, jobs, features)
 	routes.SetupRoutes(router, handlers)
 
 	return &Server{
-		router: router,
-		db:     db,
+		router:   router,
+		db:       db,
+		handlers: handlers,
 	}
 }
 
+// EnableGRPC serves the gRPC event service on port alongside HTTP. Events created over gRPC
+// are validated, enriched and queued like those created over HTTP. Authentication is enabled
+// when authToken is not empty.
+func (s *Server) EnableGRPC(port int, authToken string) {
+	eventServer := grpcserver.NewEventServer(repository.NewEventRepository(s.db))
+	s.handlers.EventHandler.ConfigureGRPC(eventServer)
+
+	s.grpcServer = grpcserver.NewServer(eventServer, authToken)
+	s.grpcPort = port
+}
+
+// timeoutResponseGrace is how much longer than the default request deadline the server lets
+// a response take to write, so a request answered at its deadline still reaches the client
+const timeoutResponseGrace = 5 * time.Second
+
+// writeTimeout returns the write timeout of the HTTP server for a default request deadline;
+// 30 seconds when requests have no deadline
+func writeTimeout(requestTimeout time.Duration) time.Duration {
+	if requestTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return requestTimeout + timeoutResponseGrace
+}
+
 // Start starts the HTTP server
 func (s *Server) Start(port int) error {
 	s.server = &http.Server{
 		Addr:         fmt.Sprintf(":%d", port),
 		Handler:      s.router,
 		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
+		WriteTimeout: writeTimeout(s.handlers.Timeouts.Default),
 		IdleTimeout:  60 * time.Second,
 	}
 
@@ -57,6 +90,14 @@ func (s *Server) Start(port int) error {
 		}
 	}()
 
+	if s.grpcServer != nil {
+		go func() {
+			if err := grpcserver.Serve(s.grpcServer, s.grpcPort); err != nil {
+				log.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
@@ -67,6 +108,9 @@ func (s *Server) Start(port int) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	if s.grpcServer != nil {
+		grpcserver.Shutdown(ctx, s.grpcServer)
+	}
 	if err := s.server.Shutdown(ctx); err != nil {
 		log.Fatal("Server forced to shutdown:", err)
 	}
@@ -78,4 +122,4 @@ func (s *Server) Start(port int) error {
 // GetRouter returns the router for testing purposes
 func (s *Server) GetRouter() *gin.Engine {
 	return s.router
-} 
\ No newline at end of file
+}
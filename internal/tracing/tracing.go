@@ -0,0 +1,16 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// SetupPropagation registers the W3C TraceContext and Baggage propagators globally, so the
+// trace context of incoming HTTP requests is carried across the message queue to workers.
+// Spans are recorded once a TracerProvider is registered with otel.SetTracerProvider.
+func SetupPropagation() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
@@ -0,0 +1,79 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	apperrors "skyhawk-security-microservice/internal/errors"
+	"skyhawk-security-microservice/internal/models"
+	"skyhawk-security-microservice/internal/normalization"
+)
+
+// EventValidator checks event creation requests. The HTTP and gRPC APIs share one so events
+// are defaulted, normalized and limited the same way whichever API they arrive through.
+type EventValidator struct {
+	defaults   models.EventDefaults
+	normalizer *normalization.SeverityNormalizer
+	// maxDescriptionLength limits descriptions in characters
+	maxDescriptionLength int
+}
+
+// NewEventValidator creates a validator without defaults or severity normalization
+func NewEventValidator() *EventValidator {
+	return &EventValidator{maxDescriptionLength: models.DefaultMaxDescriptionLength}
+}
+
+// SetEventDefaults configures the severity and source given to events created without them
+func (v *EventValidator) SetEventDefaults(defaults models.EventDefaults) {
+	v.defaults = defaults
+}
+
+// SetNormalizer configures the normalizer that maps raw severity labels to canonical severities
+func (v *EventValidator) SetNormalizer(normalizer *normalization.SeverityNormalizer) {
+	v.normalizer = normalizer
+}
+
+// SetMaxDescriptionLength sets the limit on the length of event descriptions in characters
+func (v *EventValidator) SetMaxDescriptionLength(length int) {
+	v.maxDescriptionLength = length
+}
+
+// Validate fills in the default severity and source and normalizes the severity of req,
+// adding every missing or invalid field to problems. Checks declared by binding tags are left
+// to the caller.
+func (v *EventValidator) Validate(req *models.CreateEventRequest, problems *apperrors.ValidationErrors) {
+	for _, field := range v.defaults.Apply(req) {
+		problems.Add(field, "is required")
+	}
+	v.CheckDescription(problems, req.Description)
+	CheckTenantID(problems, req.TenantID)
+
+	if v.normalizer != nil && strings.TrimSpace(req.Severity) != "" {
+		severity, ok := v.normalizer.Normalize(req.Severity)
+		if ok {
+			req.Severity = severity
+		} else {
+			problems.Add("severity", "is not a known severity: "+req.Severity)
+		}
+	}
+}
+
+// CheckDescription reports a description longer than the configured limit
+func (v *EventValidator) CheckDescription(problems *apperrors.ValidationErrors, description string) {
+	if utf8.RuneCountInString(description) > v.maxDescriptionLength {
+		problems.Add("description", fmt.Sprintf("must be at most %d characters", v.maxDescriptionLength))
+	}
+}
+
+// tenantIDPattern limits tenant IDs to characters that are safe in queue names
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// CheckTenantID reports a tenant ID that cannot be used in a queue name. An empty tenant ID
+// is valid.
+func CheckTenantID(problems *apperrors.ValidationErrors, tenantID string) {
+	if tenantID != "" && !tenantIDPattern.MatchString(tenantID) {
+		problems.Add("tenant_id", "must be at most 64 letters, digits, '-' or '_'")
+	}
+}
@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DeliveryResult describes the outcome of delivering a payload
+type DeliveryResult struct {
+	Attempts     int
+	ResponseCode int
+}
+
+// Deliverer POSTs signed payloads to subscription targets with retries
+type Deliverer struct {
+	client         *http.Client
+	maxAttempts    int
+	initialBackoff time.Duration
+}
+
+// NewDeliverer creates a deliverer that retries with exponential backoff
+// (1s, 2s, 4s, ...) and gives up after 5 attempts
+func NewDeliverer() *Deliverer {
+	return &Deliverer{
+		client:         &http.Client{Timeout: 10 * time.Second},
+		maxAttempts:    5,
+		initialBackoff: time.Second,
+	}
+}
+
+// Deliver POSTs the body to the target URL, signed by signer
+func (d *Deliverer) Deliver(ctx context.Context, targetURL string, signer PayloadSigner, body []byte) (DeliveryResult, error) {
+	var result DeliveryResult
+	backoff := d.initialBackoff
+
+	var lastErr error
+	for attempt := 1; attempt <= d.maxAttempts; attempt++ {
+		result.Attempts = attempt
+
+		statusCode, err := d.post(ctx, targetURL, signer, body)
+		result.ResponseCode = statusCode
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+
+		// Client errors other than rate limiting will not succeed on retry
+		if statusCode >= 400 && statusCode < 500 && statusCode != http.StatusTooManyRequests {
+			break
+		}
+
+		if attempt == d.maxAttempts {
+			break
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-ctx.Done():
+			return result, ctx.Err()
+		}
+	}
+
+	return result, fmt.Errorf("delivery failed after %d attempts: %w", result.Attempts, lastErr)
+}
+
+// post performs a single signed delivery attempt
+func (d *Deliverer) post(ctx context.Context, targetURL string, signer PayloadSigner, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, targetURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, signer.Sign(body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receivedDelivery is a request seen by the mock webhook endpoint
+type receivedDelivery struct {
+	signature string
+	body      []byte
+}
+
+// newWebhookEndpoint serves the responses in statuses in turn, repeating the last one, and
+// records every request
+func newWebhookEndpoint(t *testing.T, statuses ...int) (*httptest.Server, func() []receivedDelivery) {
+	t.Helper()
+	var mu sync.Mutex
+	var received []receivedDelivery
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, receivedDelivery{signature: r.Header.Get(SignatureHeader), body: body})
+		status := statuses[len(statuses)-1]
+		if len(received) <= len(statuses) {
+			status = statuses[len(received)-1]
+		}
+		mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []receivedDelivery {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]receivedDelivery(nil), received...)
+	}
+}
+
+func newTestDeliverer() *Deliverer {
+	return &Deliverer{client: &http.Client{Timeout: time.Second}, maxAttempts: 3, initialBackoff: time.Millisecond}
+}
+
+func TestDeliverSignsBodyWithSubscriptionSecret(t *testing.T) {
+	server, received := newWebhookEndpoint(t, http.StatusOK)
+	sub := &Subscription{TargetURL: server.URL, Secret: "s3cret", Active: true}
+	body := []byte(`{"event_id":"evt-1","event_type":"login_failure"}`)
+
+	if _, err := newTestDeliverer().Deliver(context.Background(), sub.TargetURL, sub.Signer(), body); err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	deliveries := received()
+	if len(deliveries) != 1 {
+		t.Fatalf("endpoint received %d requests, want 1", len(deliveries))
+	}
+	if deliveries[0].signature != want {
+		t.Errorf("%s = %q, want %q", SignatureHeader, deliveries[0].signature, want)
+	}
+	if !Verify("s3cret", deliveries[0].body, deliveries[0].signature) {
+		t.Error("Verify rejected the delivered signature")
+	}
+}
+
+func TestDeliverRetriesServerErrors(t *testing.T) {
+	server, received := newWebhookEndpoint(t, http.StatusServiceUnavailable, http.StatusOK)
+
+	result, err := newTestDeliverer().Deliver(context.Background(), server.URL, HMACSHA256Signer{Secret: []byte("s3cret")}, []byte(`{}`))
+	if err != nil {
+		t.Fatalf("Deliver: %v", err)
+	}
+	if result.Attempts != 2 || result.ResponseCode != http.StatusOK {
+		t.Errorf("result = %+v, want success on attempt 2", result)
+	}
+	if deliveries := received(); len(deliveries) != 2 || deliveries[0].signature != deliveries[1].signature {
+		t.Errorf("retries carried signatures %+v, want the same one twice", deliveries)
+	}
+}
+
+func TestDeliverDoesNotRetryClientErrors(t *testing.T) {
+	server, received := newWebhookEndpoint(t, http.StatusUnauthorized)
+
+	result, err := newTestDeliverer().Deliver(context.Background(), server.URL, HMACSHA256Signer{Secret: []byte("s3cret")}, []byte(`{}`))
+	if err == nil {
+		t.Fatal("Deliver returned nil for a 401")
+	}
+	if result.Attempts != 1 || len(received()) != 1 {
+		t.Errorf("made %d attempts, want 1", result.Attempts)
+	}
+}
@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"skyhawk-security-microservice/internal/models"
+)
+
+// PushPolicy decides whether message acknowledgement waits for processed event delivery
+type PushPolicy string
+
+const (
+	// PushPolicyAsync delivers in the background; failures never delay or fail the message
+	PushPolicyAsync PushPolicy = "async"
+	// PushPolicySync delivers before the message is acked; failures send it down the retry path
+	PushPolicySync PushPolicy = "sync"
+)
+
+// defaultPushTimeout bounds all attempts of one push, covering the deliverer's backoff
+const defaultPushTimeout = 30 * time.Second
+
+// PushConfig configures delivery of processed events to fixed webhook URLs
+type PushConfig struct {
+	URLs    []string
+	Secret  string
+	Timeout time.Duration
+	Policy  PushPolicy
+}
+
+// PushConfigFromEnv reads PROCESSED_WEBHOOK_URLS, PROCESSED_WEBHOOK_SECRET,
+// PROCESSED_WEBHOOK_TIMEOUT and PROCESSED_WEBHOOK_POLICY. It returns nil when no URLs are set.
+func PushConfigFromEnv() (*PushConfig, error) {
+	var urls []string
+	for _, url := range strings.Split(os.Getenv("PROCESSED_WEBHOOK_URLS"), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+	if len(urls) == 0 {
+		return nil, nil
+	}
+
+	config := &PushConfig{
+		URLs:    urls,
+		Secret:  os.Getenv("PROCESSED_WEBHOOK_SECRET"),
+		Timeout: defaultPushTimeout,
+		Policy:  PushPolicyAsync,
+	}
+	if config.Secret == "" {
+		return nil, fmt.Errorf("PROCESSED_WEBHOOK_SECRET is required when PROCESSED_WEBHOOK_URLS is set")
+	}
+
+	if value := os.Getenv("PROCESSED_WEBHOOK_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid PROCESSED_WEBHOOK_TIMEOUT: %s", value)
+		}
+		config.Timeout = timeout
+	}
+
+	if value := os.Getenv("PROCESSED_WEBHOOK_POLICY"); value != "" {
+		switch policy := PushPolicy(strings.ToLower(value)); policy {
+		case PushPolicyAsync, PushPolicySync:
+			config.Policy = policy
+		default:
+			return nil, fmt.Errorf("invalid PROCESSED_WEBHOOK_POLICY: %s", value)
+		}
+	}
+
+	return config, nil
+}
+
+// EventPusher POSTs processed events, signed with the shared secret, to every configured URL
+type EventPusher struct {
+	config    PushConfig
+	deliverer *Deliverer
+}
+
+// NewEventPusher creates a pusher for the given configuration
+func NewEventPusher(config PushConfig) *EventPusher {
+	if config.Timeout <= 0 {
+		config.Timeout = defaultPushTimeout
+	}
+	return &EventPusher{
+		config:    config,
+		deliverer: NewDeliverer(),
+	}
+}
+
+// Policy returns the acknowledgement policy for pushed events
+func (p *EventPusher) Policy() PushPolicy {
+	return p.config.Policy
+}
+
+// Notify delivers the event to all URLs in parallel, retrying each with backoff
+// until the push timeout expires
+func (p *EventPusher) Notify(ctx context.Context, event *models.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(p.config.URLs))
+	for i, url := range p.config.URLs {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			if _, err := p.deliverer.Deliver(ctx, url, HMACSHA256Signer{Secret: []byte(p.config.Secret)}, body); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", url, err)
+			}
+		}(i, url)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,264 @@
+package webhook
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/lib/pq"
+	"skyhawk-security-microservice/internal/database"
+)
+
+// Repository persists webhook subscriptions and delivery records
+type Repository struct {
+	db *database.DB
+}
+
+// NewRepository creates a new webhook repository
+func NewRepository(db *database.DB) *Repository {
+	return &Repository{db: db}
+}
+
+// CreateSubscription stores a new subscription
+func (r *Repository) CreateSubscription(sub *Subscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (target_url, secret, event_types, active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at`
+
+	err := r.db.QueryRow(
+		query,
+		sub.TargetURL,
+		sub.Secret,
+		pq.Array(sub.EventTypes),
+		sub.Active,
+	).Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create subscription: %v", err)
+	}
+	sub.SecretHash = HashSecret(sub.Secret)
+
+	return nil
+}
+
+// GetSubscription retrieves a subscription by its ID
+func (r *Repository) GetSubscription(id string) (*Subscription, error) {
+	query := `
+		SELECT id, target_url, secret, event_types, active, created_at, updated_at
+		FROM webhook_subscriptions
+		WHERE id = $1`
+
+	sub, err := scanSubscription(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("subscription not found")
+		}
+		return nil, fmt.Errorf("failed to get subscription: %v", err)
+	}
+
+	return sub, nil
+}
+
+// ListSubscriptions retrieves all subscriptions
+func (r *Repository) ListSubscriptions() ([]*Subscription, error) {
+	return r.querySubscriptions(`
+		SELECT id, target_url, secret, event_types, active, created_at, updated_at
+		FROM webhook_subscriptions
+		ORDER BY created_at DESC`)
+}
+
+// ListActiveSubscriptions retrieves the active subscriptions matching an event type
+func (r *Repository) ListActiveSubscriptions(eventType string) ([]*Subscription, error) {
+	return r.querySubscriptions(`
+		SELECT id, target_url, secret, event_types, active, created_at, updated_at
+		FROM webhook_subscriptions
+		WHERE active = TRUE
+		  AND (cardinality(event_types) = 0 OR $1 = ANY(event_types) OR '*' = ANY(event_types))`, eventType)
+}
+
+// UpdateSubscription applies a partial update to a subscription
+func (r *Repository) UpdateSubscription(id string, updates *UpdateSubscriptionRequest) (*Subscription, error) {
+	query := `
+		UPDATE webhook_subscriptions
+		SET target_url = COALESCE($2, target_url),
+			secret = COALESCE($3, secret),
+			event_types = COALESCE($4, event_types),
+			active = COALESCE($5, active),
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING id, target_url, secret, event_types, active, created_at, updated_at`
+
+	var eventTypes interface{}
+	if updates.EventTypes != nil {
+		eventTypes = pq.Array(updates.EventTypes)
+	}
+
+	sub, err := scanSubscription(r.db.QueryRow(
+		query,
+		id,
+		updates.TargetURL,
+		updates.Secret,
+		eventTypes,
+		updates.Active,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("subscription not found")
+		}
+		return nil, fmt.Errorf("failed to update subscription: %v", err)
+	}
+
+	return sub, nil
+}
+
+// RotateSecret replaces a subscription's signing secret
+func (r *Repository) RotateSecret(id, secret string) (*Subscription, error) {
+	query := `
+		UPDATE webhook_subscriptions
+		SET secret = $2,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING id, target_url, secret, event_types, active, created_at, updated_at`
+
+	sub, err := scanSubscription(r.db.QueryRow(query, id, secret))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("subscription not found")
+		}
+		return nil, fmt.Errorf("failed to rotate secret: %v", err)
+	}
+
+	return sub, nil
+}
+
+// DeleteSubscription deletes a subscription and its delivery history
+func (r *Repository) DeleteSubscription(id string) error {
+	result, err := r.db.Exec(`DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete subscription: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("subscription not found")
+	}
+
+	return nil
+}
+
+// CreateDelivery records the outcome of a delivery
+func (r *Repository) CreateDelivery(delivery *Delivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, status, attempts, response_code, error)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`
+
+	err := r.db.QueryRow(
+		query,
+		delivery.SubscriptionID,
+		delivery.EventID,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseCode,
+		delivery.Error,
+	).Scan(&delivery.ID, &delivery.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create delivery: %v", err)
+	}
+
+	return nil
+}
+
+// ListDeliveries retrieves the most recent deliveries for a subscription
+func (r *Repository) ListDeliveries(subscriptionID string, limit int) ([]*Delivery, error) {
+	query := `
+		SELECT id, subscription_id, event_id, status, attempts, response_code, error, created_at
+		FROM webhook_deliveries
+		WHERE subscription_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	rows, err := r.db.Query(query, subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*Delivery
+	for rows.Next() {
+		delivery := &Delivery{}
+		err := rows.Scan(
+			&delivery.ID,
+			&delivery.SubscriptionID,
+			&delivery.EventID,
+			&delivery.Status,
+			&delivery.Attempts,
+			&delivery.ResponseCode,
+			&delivery.Error,
+			&delivery.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan delivery: %v", err)
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+// querySubscriptions runs a subscription query and scans all rows
+func (r *Repository) querySubscriptions(query string, args ...interface{}) ([]*Subscription, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query subscriptions: %v", err)
+	}
+	defer rows.Close()
+
+	var subs []*Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subscription: %v", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating subscriptions: %v", err)
+	}
+
+	return subs, nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanSubscription scans a single subscription row
+func scanSubscription(row rowScanner) (*Subscription, error) {
+	sub := &Subscription{}
+	err := row.Scan(
+		&sub.ID,
+		&sub.TargetURL,
+		&sub.Secret,
+		pq.Array(&sub.EventTypes),
+		&sub.Active,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	sub.SecretHash = HashSecret(sub.Secret)
+	return sub, nil
+}
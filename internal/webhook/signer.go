@@ -0,0 +1,59 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// SignatureHeader carries the HMAC-SHA256 signature of the request body
+const SignatureHeader = "X-Skyhawk-Signature"
+
+// PayloadSigner computes the SignatureHeader value for a delivery body
+type PayloadSigner interface {
+	Sign(body []byte) string
+}
+
+// HMACSHA256Signer signs bodies as sha256=<hex HMAC-SHA256 of the body>
+type HMACSHA256Signer struct {
+	Secret []byte
+}
+
+// Sign computes the signature header value for a body
+func (s HMACSHA256Signer) Sign(body []byte) string {
+	mac := hmac.New(sha256.New, s.Secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether a signature header value matches the body
+func (s HMACSHA256Signer) Verify(body []byte, signature string) bool {
+	return hmac.Equal([]byte(s.Sign(body)), []byte(signature))
+}
+
+// Sign computes the signature header value for a body using a shared secret
+func Sign(secret string, body []byte) string {
+	return HMACSHA256Signer{Secret: []byte(secret)}.Sign(body)
+}
+
+// Verify reports whether a signature header value matches the body
+func Verify(secret string, body []byte, signature string) bool {
+	return HMACSHA256Signer{Secret: []byte(secret)}.Verify(body, signature)
+}
+
+// HashSecret returns the hex SHA-256 of a secret, shown in place of the secret itself
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateSecret returns a random 32-byte secret, hex encoded
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,40 @@
+package webhook
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestVerifyAcceptsOnlyMatchingSignature(t *testing.T) {
+	signer := HMACSHA256Signer{Secret: []byte("s3cret")}
+	body := []byte(`{"event_id":"evt-1"}`)
+	signature := signer.Sign(body)
+
+	if !signer.Verify(body, signature) {
+		t.Error("Verify rejected the signature of the body")
+	}
+	if signer.Verify([]byte(`{"event_id":"evt-2"}`), signature) {
+		t.Error("Verify accepted the signature for a different body")
+	}
+	if (HMACSHA256Signer{Secret: []byte("other")}).Verify(body, signature) {
+		t.Error("Verify accepted a signature made with another secret")
+	}
+	if signer.Verify(body, strings.TrimPrefix(signature, "sha256=")) {
+		t.Error("Verify accepted a signature without the sha256= prefix")
+	}
+}
+
+func TestSubscriptionJSONShowsOnlySecretHash(t *testing.T) {
+	sub := Subscription{ID: "sub-1", Secret: "s3cret", SecretHash: HashSecret("s3cret")}
+	data, err := json.Marshal(sub)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(data), `"s3cret"`) {
+		t.Errorf("subscription JSON %s contains the plaintext secret", data)
+	}
+	if !strings.Contains(string(data), HashSecret("s3cret")) {
+		t.Errorf("subscription JSON %s lacks the secret hash", data)
+	}
+}
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"time"
+)
+
+// Subscription represents an external endpoint subscribed to security events
+type Subscription struct {
+	ID         string    `json:"id" db:"id"`
+	TargetURL  string    `json:"target_url" db:"target_url"`
+	Secret     string    `json:"-" db:"secret"`
+	SecretHash string    `json:"secret_hash" db:"-"`
+	EventTypes []string  `json:"event_types" db:"event_types"`
+	Active     bool      `json:"active" db:"active"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Matches reports whether the subscription should receive events of the given type.
+// An empty filter list matches every event type.
+func (s *Subscription) Matches(eventType string) bool {
+	if !s.Active {
+		return false
+	}
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType || t == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Signer returns the signer for deliveries to the subscription
+func (s *Subscription) Signer() PayloadSigner {
+	return HMACSHA256Signer{Secret: []byte(s.Secret)}
+}
+
+// Delivery records an attempt to deliver an event to a subscription
+type Delivery struct {
+	ID             string    `json:"id" db:"id"`
+	SubscriptionID string    `json:"subscription_id" db:"subscription_id"`
+	EventID        string    `json:"event_id" db:"event_id"`
+	Status         string    `json:"status" db:"status"`
+	Attempts       int       `json:"attempts" db:"attempts"`
+	ResponseCode   int       `json:"response_code,omitempty" db:"response_code"`
+	Error          string    `json:"error,omitempty" db:"error"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// Delivery statuses
+const (
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// CreateSubscriptionRequest represents the request to create a subscription
+type CreateSubscriptionRequest struct {
+	TargetURL  string   `json:"target_url" binding:"required,url"`
+	Secret     string   `json:"secret" binding:"required"`
+	EventTypes []string `json:"event_types"`
+	Active     *bool    `json:"active"`
+}
+
+// UpdateSubscriptionRequest represents the request to update a subscription
+type UpdateSubscriptionRequest struct {
+	TargetURL  *string  `json:"target_url" binding:"omitempty,url"`
+	Secret     *string  `json:"secret"`
+	EventTypes []string `json:"event_types"`
+	Active     *bool    `json:"active"`
+}
+
+// DispatchMessageType is the queue message type used for webhook deliveries
+const DispatchMessageType = "webhook_dispatch"
+
+// DispatchQueue is the queue consumed by the webhook worker
+const DispatchQueue = "webhook_dispatch"
@@ -0,0 +1,76 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"skyhawk-security-microservice/internal/queue"
+)
+
+// WebhookWorker delivers webhook_dispatch messages to subscription targets
+type WebhookWorker struct {
+	repo      *Repository
+	deliverer *Deliverer
+}
+
+// NewWebhookWorker creates a new webhook worker
+func NewWebhookWorker(repo *Repository, deliverer *Deliverer) *WebhookWorker {
+	return &WebhookWorker{
+		repo:      repo,
+		deliverer: deliverer,
+	}
+}
+
+// HandleMessage delivers a single dispatch message and records the outcome.
+// Delivery failures are recorded rather than returned, since retries happen here.
+func (w *WebhookWorker) HandleMessage(ctx context.Context, message *queue.Message) error {
+	subscriptionID, _ := message.Data["subscription_id"].(string)
+	if subscriptionID == "" {
+		return fmt.Errorf("missing subscription_id in message %s", message.ID)
+	}
+
+	event, ok := message.Data["event"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid event data in message %s", message.ID)
+	}
+	eventID, _ := event["event_id"].(string)
+
+	sub, err := w.repo.GetSubscription(subscriptionID)
+	if err != nil {
+		return err
+	}
+	if !sub.Active {
+		log.Printf("Skipping delivery of event %s to inactive subscription %s", eventID, sub.ID)
+		return nil
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	result, deliveryErr := w.deliverer.Deliver(ctx, sub.TargetURL, sub.Signer(), body)
+
+	delivery := &Delivery{
+		SubscriptionID: sub.ID,
+		EventID:        eventID,
+		Status:         DeliveryStatusSucceeded,
+		Attempts:       result.Attempts,
+		ResponseCode:   result.ResponseCode,
+	}
+	if deliveryErr != nil {
+		delivery.Status = DeliveryStatusFailed
+		delivery.Error = deliveryErr.Error()
+		log.Printf("Failed to deliver event %s to subscription %s: %v", eventID, sub.ID, deliveryErr)
+	} else {
+		log.Printf("Delivered event %s to subscription %s", eventID, sub.ID)
+	}
+
+	if err := w.repo.CreateDelivery(delivery); err != nil {
+		log.Printf("Failed to record delivery for subscription %s: %v", sub.ID, err)
+	}
+
+	return nil
+}